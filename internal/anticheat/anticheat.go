@@ -0,0 +1,177 @@
+// Package anticheat looks for scripted play in the moves a player made during a multiplayer game:
+// timing too regular or too fast for a person, long streaks of perfectly optimal moves, reactions
+// to a new pearl faster than a person can see it, and moves sent before the countdown ended.
+package anticheat
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Reasons a player's play is flagged
+const (
+	FlagRegularTiming   = "regular_timing"      // The time between moves hardly varies
+	FlagInhumanSpeed    = "inhuman_speed"       // The typical time between moves is too short to type
+	FlagOptimalStreak   = "optimal_path_streak" // Too many shortest path moves in a row
+	FlagInstantReaction = "instant_reaction"    // Headed for new pearls before they could be seen
+	FlagCountdownMoves  = "countdown_moves"     // Kept sending moves while the countdown ran
+)
+
+// Thresholds of the checks
+const (
+	MinTimedIntervals   = 30                     // Intervals between moves needed to judge timing
+	MinIntervalSpread   = 0.12                   // Coefficient of variation of intervals people stay above
+	MinMedianInterval   = 60 * time.Millisecond  // Median interval people stay above
+	MaxTimedInterval    = 2 * time.Second        // Longer pauses are thinking rather than typing
+	MaxOptimalStreak    = 40                     // Longest run of shortest path moves people reach
+	MinReactionTime     = 120 * time.Millisecond // Quickest people head for a pearl after it appears
+	MaxInstantReactions = 3                      // Quicker reactions tolerated, for lucky guesses
+	MaxCountdownMoves   = 3                      // Moves tolerated before the countdown ends
+)
+
+// Move is one move a player made
+type Move struct {
+	At  time.Time
+	Key string // Motion and count; a repeated key is the key held down, so its timing isn't judged
+	// Optimal tells whether the move was a step on a shortest path to the player's target, such as
+	// the pearl; nil when it isn't known
+	Optimal *bool
+	// Reaction is the time between a new pearl appearing and the player's first move after it;
+	// 0 for the player's other moves
+	Reaction time.Duration
+}
+
+// Trace is the play of one player during a game
+type Trace struct {
+	Moves          []Move // In the order they were made
+	CountdownMoves int    // Moves sent while the countdown ran, which were rejected
+}
+
+// Report is the analysis of a player's play
+type Report struct {
+	Flags                []string `json:"flags"`
+	Moves                int      `json:"moves"`
+	TimedIntervals       int      `json:"timed_intervals"`
+	MedianIntervalMs     float64  `json:"median_interval_ms"`
+	IntervalSpread       float64  `json:"interval_spread"` // Coefficient of variation of the intervals
+	JudgedMoves          int      `json:"judged_moves"`    // Moves whose optimality is known
+	OptimalMoves         int      `json:"optimal_moves"`
+	LongestOptimalStreak int      `json:"longest_optimal_streak"`
+	Reactions            int      `json:"reactions"`
+	InstantReactions     int      `json:"instant_reactions"`
+	FastestReactionMs    float64  `json:"fastest_reaction_ms,omitempty"`
+	CountdownMoves       int      `json:"countdown_moves"`
+}
+
+// Flagged reports whether any check found the play suspicious
+func (r Report) Flagged() bool {
+	return len(r.Flags) > 0
+}
+
+// Analyze runs every check on a player's play
+func Analyze(trace Trace) Report {
+	report := Report{
+		Flags:          []string{},
+		Moves:          len(trace.Moves),
+		CountdownMoves: trace.CountdownMoves,
+	}
+
+	analyzeTiming(trace.Moves, &report)
+	analyzePaths(trace.Moves, &report)
+	analyzeReactions(trace.Moves, &report)
+
+	if trace.CountdownMoves > MaxCountdownMoves {
+		report.Flags = append(report.Flags, FlagCountdownMoves)
+	}
+	return report
+}
+
+// analyzeTiming judges the intervals between moves with different keys. Scripts send moves at a
+// steady or impossibly fast pace, while people's timing varies from one key to the next.
+func analyzeTiming(moves []Move, report *Report) {
+	var intervals []float64
+	for i := 1; i < len(moves); i++ {
+		interval := moves[i].At.Sub(moves[i-1].At)
+		if moves[i].Key == moves[i-1].Key || interval <= 0 || interval > MaxTimedInterval {
+			continue
+		}
+		intervals = append(intervals, float64(interval)/float64(time.Millisecond))
+	}
+
+	report.TimedIntervals = len(intervals)
+	if len(intervals) < MinTimedIntervals {
+		return
+	}
+
+	var sum float64
+	for _, interval := range intervals {
+		sum += interval
+	}
+	mean := sum / float64(len(intervals))
+
+	var squares float64
+	for _, interval := range intervals {
+		squares += (interval - mean) * (interval - mean)
+	}
+	report.IntervalSpread = math.Sqrt(squares/float64(len(intervals))) / mean
+
+	sort.Float64s(intervals)
+	report.MedianIntervalMs = intervals[len(intervals)/2]
+
+	if report.IntervalSpread < MinIntervalSpread {
+		report.Flags = append(report.Flags, FlagRegularTiming)
+	}
+	if report.MedianIntervalMs < float64(MinMedianInterval/time.Millisecond) {
+		report.Flags = append(report.Flags, FlagInhumanSpeed)
+	}
+}
+
+// analyzePaths finds the longest streak of shortest path moves. Moves whose optimality isn't
+// known neither extend nor break a streak.
+func analyzePaths(moves []Move, report *Report) {
+	streak := 0
+	for _, move := range moves {
+		if move.Optimal == nil {
+			continue
+		}
+		report.JudgedMoves++
+		if !*move.Optimal {
+			streak = 0
+			continue
+		}
+
+		report.OptimalMoves++
+		streak++
+		if streak > report.LongestOptimalStreak {
+			report.LongestOptimalStreak = streak
+		}
+	}
+
+	if report.LongestOptimalStreak > MaxOptimalStreak {
+		report.Flags = append(report.Flags, FlagOptimalStreak)
+	}
+}
+
+// analyzeReactions counts the first moves after a new pearl appeared that headed straight for it
+// sooner than a person can react
+func analyzeReactions(moves []Move, report *Report) {
+	for _, move := range moves {
+		if move.Reaction <= 0 {
+			continue
+		}
+		report.Reactions++
+
+		reactionMs := float64(move.Reaction) / float64(time.Millisecond)
+		if report.FastestReactionMs == 0 || reactionMs < report.FastestReactionMs {
+			report.FastestReactionMs = reactionMs
+		}
+		if move.Reaction < MinReactionTime && move.Optimal != nil && *move.Optimal {
+			report.InstantReactions++
+		}
+	}
+
+	if report.InstantReactions > MaxInstantReactions {
+		report.Flags = append(report.Flags, FlagInstantReaction)
+	}
+}
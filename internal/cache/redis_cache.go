@@ -89,6 +89,11 @@ func (r *RedisCache) IsAvailable() bool {
 	return r.client != nil
 }
 
+// Client returns the underlying Redis client, or nil when Redis is not available
+func (r *RedisCache) Client() *redis.Client {
+	return r.client
+}
+
 // Set stores a value in Redis with expiration
 func (r *RedisCache) Set(key string, value interface{}, expiration time.Duration) error {
 	if !r.IsAvailable() {
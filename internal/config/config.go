@@ -20,6 +20,10 @@ type Config struct {
 	FrontendLogLevel string
 	AdminUsername string
 	AdminPassword string
+	ReconnectGrace time.Duration // How long a multiplayer player may be away before forfeiting; 0 forfeits at once
+	StateBackend  string // Where multiplayer state lives: "memory" for a single instance, "redis" to share it between instances
+	NodeID        string // Name of this instance in the cluster; generated when empty
+	HoldFlaggedResults bool // Keep multiplayer results anti-cheat flagged out of stats until an admin reviews them
 }
 
 func Load() *Config {
@@ -37,6 +41,10 @@ func Load() *Config {
 		FrontendLogLevel: getEnv("FRONTEND_LOG_LEVEL", "debug"),
 		AdminUsername: getEnv("ADMIN_USERNAME", "test"),
 		AdminPassword: getEnv("ADMIN_PASSWORD", "test"),
+		ReconnectGrace: time.Duration(getEnvInt("MULTIPLAYER_RECONNECT_GRACE", 30)) * time.Second, // 30 seconds
+		StateBackend:  getEnv("MULTIPLAYER_STATE_BACKEND", "memory"),
+		NodeID:        getEnv("NODE_ID", ""),
+		HoldFlaggedResults: getEnv("MULTIPLAYER_HOLD_FLAGGED_RESULTS", "true") == "true",
 	}
 }
 
@@ -58,13 +58,29 @@ func Initialize(databaseURL string) (*gorm.DB, error) {
 			&models.MatchmakingQueue{},
 			&models.OnlineMatch{},
 			&models.MultiplayerGameResult{},
+			&models.MultiplayerGameParticipant{},
+			&models.MultiplayerMapVetoAction{},
+			&models.MultiplayerSeriesResult{},
 			&models.MultiplayerPlayerStats{},
+			&models.RankedSeason{},
+			&models.SeasonStanding{},
+			&models.Tournament{},
+			&models.TournamentEntrant{},
+			&models.TournamentMatch{},
+			&models.TournamentGame{},
+			&models.Challenge{},
+			&models.ChallengeAttempt{},
+			&models.Friendship{},
+			&models.MatchChatMessage{},
+			&models.MatchReview{},
+			&models.RankedMapPoolEntry{},
 			&models.Survey{},
 			&models.SurveyQuestion{},
 			&models.SurveyVote{},
 			&models.Newsletter{},
 			&models.NewsletterRead{},
 			&models.Admin{},
+			&models.PlayerVimOptions{},
 		)
 		if err != nil {
 			utils.Error("Warning: Failed to drop some tables: %v", err)
@@ -85,13 +101,29 @@ func Initialize(databaseURL string) (*gorm.DB, error) {
 		&models.MatchmakingQueue{},
 		&models.OnlineMatch{},
 		&models.MultiplayerGameResult{},
+		&models.MultiplayerGameParticipant{},
+		&models.MultiplayerMapVetoAction{},
+		&models.MultiplayerSeriesResult{},
 		&models.MultiplayerPlayerStats{},
+		&models.RankedSeason{},
+		&models.SeasonStanding{},
+		&models.Tournament{},
+		&models.TournamentEntrant{},
+		&models.TournamentMatch{},
+		&models.TournamentGame{},
+		&models.Challenge{},
+		&models.ChallengeAttempt{},
+		&models.Friendship{},
+		&models.MatchChatMessage{},
+		&models.MatchReview{},
+		&models.RankedMapPoolEntry{},
 		&models.Survey{},
 		&models.SurveyQuestion{},
 		&models.SurveyVote{},
 		&models.Newsletter{},
 		&models.NewsletterRead{},
 		&models.Admin{},
+		&models.PlayerVimOptions{},
 	)
 	if err != nil {
 		return nil, err
@@ -79,6 +79,16 @@ func CreateTextGridFromString(text string) [][]string {
 
 // createGameMap creates initial game map with player at (0,0)
 func createGameMap(textGrid [][]string) [][]int {
+	gameMap := createEmptyGameMap(textGrid)
+
+	// Place one pearl randomly
+	placeNewPearl(gameMap, 0, 0)
+	
+	return gameMap
+}
+
+// createEmptyGameMap creates a game map holding only the player at (0,0)
+func createEmptyGameMap(textGrid [][]string) [][]int {
 	gameMap := make([][]int, len(textGrid))
 
 	for rowIdx, row := range textGrid {
@@ -93,9 +103,6 @@ func createGameMap(textGrid [][]string) [][]int {
 		gameMap[rowIdx] = mapRow
 	}
 
-	// Place one pearl randomly
-	placeNewPearl(gameMap, 0, 0)
-	
 	return gameMap
 }
 
@@ -0,0 +1,33 @@
+package game
+
+// A client that knows the version of the game map it holds is sent only the cells that changed
+// since then. The version goes up whenever the map changes, so a client whose version is behind
+// missed a change and is sent the whole map again.
+
+// CellChange is a game map cell with its new value
+type CellChange struct {
+	Row   int `json:"row"`
+	Col   int `json:"col"`
+	Value int `json:"value"`
+}
+
+// DiffGameMap returns the cells whose value differs between two versions of a game map. It
+// reports false when the maps aren't the same size, so no list of cells turns one into the other.
+func DiffGameMap(before, after [][]int) ([]CellChange, bool) {
+	if len(before) != len(after) {
+		return nil, false
+	}
+
+	changes := []CellChange{}
+	for row := range after {
+		if len(before[row]) != len(after[row]) {
+			return nil, false
+		}
+		for col, value := range after[row] {
+			if before[row][col] != value {
+				changes = append(changes, CellChange{Row: row, Col: col, Value: value})
+			}
+		}
+	}
+	return changes, true
+}
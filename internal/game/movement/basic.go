@@ -3,31 +3,48 @@ package movement
 import "boba-vim/internal/utils"
 
 // HandleBasicMovement handles basic directional movements (h, j, k, l)
-func HandleBasicMovement(direction string, currentRow, currentCol, preferredColumn int, gameMap [][]int) (int, int, int) {
+func HandleBasicMovement(direction string, currentRow, currentCol, preferredColumn int, gameMap [][]int, textGrid [][]string, opts Options) (int, int, int) {
 	newRow, newCol := currentRow, currentCol
 	newPreferredColumn := preferredColumn
 
 	switch direction {
 	case "left":
-		// h: move left one character
-		if currentCol > 0 {
-			newCol = currentCol - 1
+		// h: move left one character, continuing from the virtual column when 'virtualedit' allows it
+		virtualCol := currentVirtualColumn(currentRow, currentCol, preferredColumn, gameMap, opts)
+		if virtualCol > 0 {
+			virtualCol--
+			newCol = utils.ClampToRow(virtualCol, currentRow, gameMap)
+		} else if opts.WrapsKey("h") && currentRow > 0 {
+			// 'whichwrap' contains h: wrap to the end of the previous line (column 0 when it is empty)
+			newRow = currentRow - 1
+			newCol = len(gameMap[newRow]) - 1
+			if newCol < 0 {
+				newCol = 0
+			}
+			virtualCol = newCol
 		}
 		// Update preferred column to new position
-		newPreferredColumn = newCol
+		newPreferredColumn = virtualCol
 	case "right":
-		// l: move right one character
-		if currentCol < len(gameMap[currentRow])-1 {
-			newCol = currentCol + 1
+		// l: move right one character, allowing virtual columns past the end with 'virtualedit'
+		virtualCol := currentVirtualColumn(currentRow, currentCol, preferredColumn, gameMap, opts)
+		if virtualCol < opts.LastVirtualColumn(len(gameMap[currentRow]), BoardWidth(gameMap)) {
+			virtualCol++
+			newCol = utils.ClampToRow(virtualCol, currentRow, gameMap)
+		} else if opts.WrapsKey("l") && currentRow < len(gameMap)-1 {
+			// 'whichwrap' contains l: wrap to the start of the next line
+			newRow = currentRow + 1
+			newCol = 0
+			virtualCol = newCol
 		}
 		// Update preferred column to new position
-		newPreferredColumn = newCol
+		newPreferredColumn = virtualCol
 	case "up":
 		// k: move up one line
 		if currentRow > 0 {
 			newRow = currentRow - 1
 			// Use preferred column, but clamp to target line length
-			newCol = utils.ClampToRow(preferredColumn, newRow, gameMap)
+			newCol = columnBelowCursor(preferredColumn, currentRow, newRow, gameMap, textGrid, opts)
 		}
 		// Don't update preferred column for vertical movements
 	case "down":
@@ -35,7 +52,7 @@ func HandleBasicMovement(direction string, currentRow, currentCol, preferredColu
 		if currentRow < len(gameMap)-1 {
 			newRow = currentRow + 1
 			// Use preferred column, but clamp to target line length
-			newCol = utils.ClampToRow(preferredColumn, newRow, gameMap)
+			newCol = columnBelowCursor(preferredColumn, currentRow, newRow, gameMap, textGrid, opts)
 		}
 		// Don't update preferred column for vertical movements
 	}
@@ -43,16 +60,62 @@ func HandleBasicMovement(direction string, currentRow, currentCol, preferredColu
 	return newRow, newCol, newPreferredColumn
 }
 
-// HandleLineMovement handles line-based movements ($, 0, ^, g_)
-func HandleLineMovement(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string) (int, int, int) {
+// currentVirtualColumn returns the column the cursor is really on: past the end of a short line
+// when 'virtualedit' kept the preferred column there, otherwise the cursor column
+func currentVirtualColumn(currentRow, currentCol, preferredColumn int, gameMap [][]int, opts Options) int {
+	rowLength := len(gameMap[currentRow])
+	if currentCol == rowLength-1 && preferredColumn > currentCol {
+		lastVirtualCol := opts.LastVirtualColumn(rowLength, BoardWidth(gameMap))
+		if preferredColumn > lastVirtualCol {
+			return lastVirtualCol
+		}
+		return preferredColumn
+	}
+	return currentCol
+}
+
+// BoardWidth returns the length of the widest line of the board
+func BoardWidth(gameMap [][]int) int {
+	width := 0
+	for _, row := range gameMap {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	return width
+}
+
+// columnBelowCursor finds the column on targetRow at the same screen column as preferredColumn on
+// currentRow, so that tabs ('tabstop') keep the cursor visually aligned
+func columnBelowCursor(preferredColumn, currentRow, targetRow int, gameMap [][]int, textGrid [][]string, opts Options) int {
+	if currentRow >= len(textGrid) || targetRow >= len(textGrid) {
+		return utils.ClampToRow(preferredColumn, targetRow, gameMap)
+	}
+
+	tabStop := opts.TabWidth()
+	virtualCol := VirtualColumn(textGrid[currentRow], preferredColumn, tabStop)
+	return utils.ClampToRow(ColumnForVirtual(textGrid[targetRow], virtualCol, tabStop), targetRow, gameMap)
+}
+
+// HandleLineMovement handles line-based movements ($, 0, ^, g_, |).
+// With a count, $ and g_ move count-1 lines down first and | goes to screen column count.
+func HandleLineMovement(direction string, currentRow, currentCol, count int, gameMap [][]int, textGrid [][]string, opts Options) (int, int, int) {
 	newRow, newCol := currentRow, currentCol
 	newPreferredColumn := 0
 
+	if count < 1 {
+		count = 1
+	}
+
 	switch direction {
 	case "line_end":
-		// $: move to end of line
-		if len(gameMap[currentRow]) > 0 {
-			newCol = len(gameMap[currentRow]) - 1
+		// $: move to end of line, count-1 lines down
+		if currentRow+count-1 >= len(gameMap) {
+			return currentRow, currentCol, currentCol
+		}
+		newRow = currentRow + count - 1
+		if len(gameMap[newRow]) > 0 {
+			newCol = len(gameMap[newRow]) - 1
 		}
 		newPreferredColumn = newCol
 	case "line_start":
@@ -64,8 +127,20 @@ func HandleLineMovement(direction string, currentRow, currentCol int, gameMap []
 		newCol = findFirstNonBlank(currentRow, textGrid)
 		newPreferredColumn = newCol
 	case "line_last_non_blank":
-		// g_: move to last non-blank character
-		newCol = findLastNonBlank(currentRow, textGrid)
+		// g_: move to last non-blank character, count-1 lines down
+		if currentRow+count-1 >= len(gameMap) {
+			return currentRow, currentCol, currentCol
+		}
+		newRow = currentRow + count - 1
+		newCol = findLastNonBlank(newRow, textGrid)
+		newPreferredColumn = newCol
+	case "line_column":
+		// |: move to screen column count (tabs expanded), or the last character of shorter lines
+		newCol = 0
+		if currentRow < len(textGrid) {
+			newCol = ColumnForVirtual(textGrid[currentRow], count-1, opts.TabWidth())
+		}
+		newCol = utils.ClampToRow(newCol, currentRow, gameMap)
 		newPreferredColumn = newCol
 	}
 
@@ -73,38 +148,27 @@ func HandleLineMovement(direction string, currentRow, currentCol int, gameMap []
 }
 
 // HandleFileMovement handles file-wide movements (gg, G)
-func HandleFileMovement(direction string, currentRow, currentCol, preferredColumn int, gameMap [][]int) (int, int, int) {
-	newRow, newCol := currentRow, currentCol
-	newPreferredColumn := 0
+func HandleFileMovement(direction string, currentRow, currentCol, preferredColumn int, gameMap [][]int, textGrid [][]string, opts Options) (int, int, int) {
+	newRow := currentRow
 
 	switch direction {
 	case "file_start":
 		newRow = 0
-		newCol = 0
-		newPreferredColumn = newCol
 	case "file_end":
 		newRow = len(gameMap) - 1
-		// Preserve the current column position, but clamp to last line's length
-		newCol = utils.ClampToRow(currentCol, newRow, gameMap)
-		newPreferredColumn = newCol
 	}
 
-	return newRow, newCol, newPreferredColumn
+	return landOnLine(newRow, preferredColumn, gameMap, textGrid, opts)
 }
 
 // HandleFileMovementWithCount handles file-wide movements with count (e.g., 5G goes to line 5)
-func HandleFileMovementWithCount(direction string, currentRow, currentCol, preferredColumn int, gameMap [][]int, count int, hasExplicitCount bool) (int, int, int) {
-	newRow, newCol := currentRow, currentCol
-	newPreferredColumn := 0
+func HandleFileMovementWithCount(direction string, currentRow, currentCol, preferredColumn int, gameMap [][]int, textGrid [][]string, count int, hasExplicitCount bool, opts Options) (int, int, int) {
+	newRow := currentRow
 
 	switch direction {
-	case "file_start":
-		newRow = 0
-		newCol = 0
-		newPreferredColumn = newCol
-	case "file_end":
+	case "file_start", "file_end":
 		if hasExplicitCount {
-			// With explicit count, G goes to absolute line number (count - 1 since we're 0-indexed)
+			// With explicit count, gg and G go to absolute line number (count - 1 since we're 0-indexed)
 			newRow = count - 1
 			// Clamp to valid range
 			if newRow >= len(gameMap) {
@@ -113,36 +177,66 @@ func HandleFileMovementWithCount(direction string, currentRow, currentCol, prefe
 			if newRow < 0 {
 				newRow = 0
 			}
+		} else if direction == "file_start" {
+			newRow = 0
 		} else {
 			// Without explicit count, G goes to last line
 			newRow = len(gameMap) - 1
 		}
-		// Preserve the current column position, but clamp to target line's length
-		newCol = utils.ClampToRow(currentCol, newRow, gameMap)
-		newPreferredColumn = newCol
 	}
 
-	return newRow, newCol, newPreferredColumn
+	return landOnLine(newRow, preferredColumn, gameMap, textGrid, opts)
 }
 
-// HandleScreenMovement handles screen-relative movements (H, M, L)
-func HandleScreenMovement(direction string, currentRow, currentCol, preferredColumn int, gameMap [][]int) (int, int, int) {
-	newRow, newCol := currentRow, currentCol
-	newPreferredColumn := preferredColumn
+// HandleScreenMovement handles screen-relative movements (H, M, L).
+// With a count, H goes to the count-th line from the top and L to the count-th line from the bottom.
+func HandleScreenMovement(direction string, currentRow, currentCol, preferredColumn, count int, gameMap [][]int, textGrid [][]string, opts Options) (int, int, int) {
+	newRow := currentRow
+
+	if count < 1 {
+		count = 1
+	}
 
 	switch direction {
 	case "screen_top":
-		newRow = 0
-		newCol = utils.ClampToRow(preferredColumn, newRow, gameMap)
+		newRow = count - 1
+		if newRow > len(gameMap)-1 {
+			newRow = len(gameMap) - 1
+		}
 	case "screen_middle":
 		newRow = len(gameMap) / 2
-		newCol = utils.ClampToRow(preferredColumn, newRow, gameMap)
 	case "screen_bottom":
-		newRow = len(gameMap) - 1
-		newCol = utils.ClampToRow(preferredColumn, newRow, gameMap)
+		newRow = len(gameMap) - count
+		if newRow < 0 {
+			newRow = 0
+		}
 	}
 
-	return newRow, newCol, newPreferredColumn
+	return landOnLine(newRow, preferredColumn, gameMap, textGrid, opts)
+}
+
+// HandlePercentMovement handles {count}% which goes to the line count percent of the way through the file.
+// Counts above 100 are rejected like in vim.
+func HandlePercentMovement(currentRow, currentCol, preferredColumn, count int, gameMap [][]int, textGrid [][]string, opts Options) (int, int, int) {
+	if count < 1 || count > 100 || len(gameMap) == 0 {
+		return currentRow, currentCol, preferredColumn
+	}
+
+	// Same rounding as vim: (count * lines + 99) / 100, 1-based
+	newRow := (count*len(gameMap)+99)/100 - 1
+
+	return landOnLine(newRow, preferredColumn, gameMap, textGrid, opts)
+}
+
+// landOnLine picks the column for linewise jumps: the first non-blank character with
+// 'startofline', otherwise the preferred column clamped to the line
+func landOnLine(row, preferredColumn int, gameMap [][]int, textGrid [][]string, opts Options) (int, int, int) {
+	if opts.StartOfLine {
+		col := utils.ClampToRow(findFirstNonBlank(row, textGrid), row, gameMap)
+		return row, col, col
+	}
+
+	return row, utils.ClampToRow(preferredColumn, row, gameMap), preferredColumn
 }
 
 // findFirstNonBlank finds first non-blank character in the row
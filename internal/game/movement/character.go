@@ -2,6 +2,8 @@ package movement
 
 import (
 	"boba-vim/internal/utils"
+	"strings"
+	"unicode/utf8"
 )
 
 // CharSearchState stores the last character search operation for ; and , repetition
@@ -13,11 +15,12 @@ type CharSearchState struct {
 // LastCharSearch global state for character search repetition
 var LastCharSearch CharSearchState
 
-// HandleCharacterSearch handles character search movements (f, F, t, T, ;, ,)
-func HandleCharacterSearch(direction string, currentRow, currentCol int, textGrid [][]string) (int, int, int) {
+// HandleCharacterSearch handles character search movements (f, F, t, T, ;, ,).
+// With a count the search lands on the count-th occurrence (3fa) and fails when the line has fewer.
+func HandleCharacterSearch(direction string, currentRow, currentCol, count int, textGrid [][]string) (int, int, int) {
 	// Comprehensive debug logging
-	utils.Debug("HandleCharacterSearch called with direction='%s', row=%d, col=%d, textGrid_len=%d", 
-		direction, currentRow, currentCol, len(textGrid))
+	utils.Debug("HandleCharacterSearch called with direction='%s', row=%d, col=%d, count=%d, textGrid_len=%d", 
+		direction, currentRow, currentCol, count, len(textGrid))
 	
 	// Handle empty or invalid direction strings
 	if direction == "" {
@@ -42,99 +45,91 @@ func HandleCharacterSearch(direction string, currentRow, currentCol int, textGri
 		utils.Debug("[DEBUG] Current row %d is empty", currentRow)
 		return currentRow, currentCol, 0
 	}
-	
-	newRow, newCol := currentRow, currentCol
-	newPreferredColumn := 0
+
+	if count < 1 {
+		count = 1
+	}
+
+	var command, targetChar string
+	repeat := false
 
 	switch direction {
 	case "repeat_char_search_same":
 		if LastCharSearch.Command == "" {
-			return currentRow, currentCol, newPreferredColumn
+			return currentRow, currentCol, 0
 		}
-		switch LastCharSearch.Command {
-		case "f":
-			newRow, newCol = FindCharForward(currentRow, currentCol, textGrid, LastCharSearch.Char)
-		case "F":
-			newRow, newCol = FindCharBackward(currentRow, currentCol, textGrid, LastCharSearch.Char)
-		case "t":
-			newRow, newCol = TillCharForward(currentRow, currentCol, textGrid, LastCharSearch.Char)
-		case "T":
-			newRow, newCol = TillCharBackward(currentRow, currentCol, textGrid, LastCharSearch.Char)
-		}
-		newPreferredColumn = newCol
+		command, targetChar, repeat = LastCharSearch.Command, LastCharSearch.Char, true
 	case "repeat_char_search_opposite":
 		if LastCharSearch.Command == "" {
-			return currentRow, currentCol, newPreferredColumn
-		}
-		switch LastCharSearch.Command {
-		case "f":
-			newRow, newCol = FindCharBackward(currentRow, currentCol, textGrid, LastCharSearch.Char)
-		case "F":
-			newRow, newCol = FindCharForward(currentRow, currentCol, textGrid, LastCharSearch.Char)
-		case "t":
-			newRow, newCol = TillCharBackward(currentRow, currentCol, textGrid, LastCharSearch.Char)
-		case "T":
-			newRow, newCol = TillCharForward(currentRow, currentCol, textGrid, LastCharSearch.Char)
+			return currentRow, currentCol, 0
 		}
-		newPreferredColumn = newCol
+		command, targetChar, repeat = oppositeCharSearch[LastCharSearch.Command], LastCharSearch.Char, true
 	default:
 		utils.Debug("[DEBUG] Processing direction in default case: '%s' (len=%d)", direction, len(direction))
-		
-		// Handle parameterized character searches with strict bounds checking
-		if len(direction) >= 19 && direction[:17] == "find_char_forward" {
-			utils.Debug("[DEBUG] Checking find_char_forward pattern")
-			if len(direction) == 19 && direction[17] == '_' {
-				targetChar := string(direction[18])
-				utils.Debug("[DEBUG] find_char_forward target: '%s' (ASCII %d)", targetChar, direction[18])
-				LastCharSearch.Command = "f"
-				LastCharSearch.Char = targetChar
-				newRow, newCol = FindCharForward(currentRow, currentCol, textGrid, targetChar)
-				newPreferredColumn = newCol
-			} else {
-				utils.Debug("[DEBUG] find_char_forward validation failed: len=%d, expected=19", len(direction))
-			}
-		} else if len(direction) >= 20 && direction[:18] == "find_char_backward" {
-			utils.Debug("[DEBUG] Checking find_char_backward pattern")
-			if len(direction) == 20 && direction[18] == '_' {
-				targetChar := string(direction[19])
-				utils.Debug("[DEBUG] find_char_backward target: '%s' (ASCII %d)", targetChar, direction[19])
-				LastCharSearch.Command = "F"
-				LastCharSearch.Char = targetChar
-				newRow, newCol = FindCharBackward(currentRow, currentCol, textGrid, targetChar)
-				newPreferredColumn = newCol
-			} else {
-				utils.Debug("[DEBUG] find_char_backward validation failed: len=%d, expected=20", len(direction))
-			}
-		} else if len(direction) >= 19 && direction[:17] == "till_char_forward" {
-			utils.Debug("[DEBUG] Checking till_char_forward pattern")
-			if len(direction) == 19 && direction[17] == '_' {
-				targetChar := string(direction[18])
-				utils.Debug("[DEBUG] till_char_forward target: '%s' (ASCII %d)", targetChar, direction[18])
-				LastCharSearch.Command = "t"
-				LastCharSearch.Char = targetChar
-				newRow, newCol = TillCharForward(currentRow, currentCol, textGrid, targetChar)
-				newPreferredColumn = newCol
-			} else {
-				utils.Debug("[DEBUG] till_char_forward validation failed: len=%d, expected=19", len(direction))
-			}
-		} else if len(direction) >= 20 && direction[:18] == "till_char_backward" {
-			utils.Debug("[DEBUG] Checking till_char_backward pattern")
-			if len(direction) == 20 && direction[18] == '_' {
-				targetChar := string(direction[19])
-				utils.Debug("[DEBUG] till_char_backward target: '%s' (ASCII %d)", targetChar, direction[19])
-				LastCharSearch.Command = "T"
-				LastCharSearch.Char = targetChar
-				newRow, newCol = TillCharBackward(currentRow, currentCol, textGrid, targetChar)
-				newPreferredColumn = newCol
-			} else {
-				utils.Debug("[DEBUG] till_char_backward validation failed: len=%d, expected=20", len(direction))
-			}
-		} else {
+
+		// Handle parameterized character searches, whose argument is exactly one character
+		var prefix string
+		switch {
+		case strings.HasPrefix(direction, "find_char_forward_"):
+			command, prefix = "f", "find_char_forward_"
+		case strings.HasPrefix(direction, "find_char_backward_"):
+			command, prefix = "F", "find_char_backward_"
+		case strings.HasPrefix(direction, "till_char_forward_"):
+			command, prefix = "t", "till_char_forward_"
+		case strings.HasPrefix(direction, "till_char_backward_"):
+			command, prefix = "T", "till_char_backward_"
+		}
+		targetChar = direction[len(prefix):]
+		if command == "" || utf8.RuneCountInString(targetChar) != 1 {
 			utils.Debug("[DEBUG] No character search pattern matched for direction: '%s'", direction)
+			return currentRow, currentCol, 0
+		}
+		utils.Debug("[DEBUG] %s target: '%s'", command, targetChar)
+		LastCharSearch.Command = command
+		LastCharSearch.Char = targetChar
+	}
+
+	newCol, found := searchCharInLine(command, textGrid[currentRow], currentCol, targetChar, count, repeat)
+	if !found {
+		return currentRow, currentCol, 0
+	}
+	return currentRow, newCol, newCol
+}
+
+// oppositeCharSearch maps each character search to the search in the other direction, for ,
+var oppositeCharSearch = map[string]string{"f": "F", "F": "f", "t": "T", "T": "t"}
+
+// searchCharInLine finds the column of the count-th occurrence of targetChar for a f, F, t or T search.
+// Like vim, a repeated t/T (; and ,) skips a match right next to the cursor instead of getting stuck.
+func searchCharInLine(command string, line []string, col int, targetChar string, count int, repeat bool) (int, bool) {
+	forward := command == "f" || command == "t"
+	till := command == "t" || command == "T"
+
+	step := 1
+	if !forward {
+		step = -1
+	}
+
+	start := col + step
+	if till && repeat {
+		start += step
+	}
+
+	found := 0
+	for searchCol := start; searchCol >= 0 && searchCol < len(line); searchCol += step {
+		if line[searchCol] != targetChar {
+			continue
+		}
+		found++
+		if found == count {
+			if till {
+				return searchCol - step, true
+			}
+			return searchCol, true
 		}
 	}
 
-	return newRow, newCol, newPreferredColumn
+	return col, false
 }
 
 // FindCharForward finds character forward in current line
@@ -0,0 +1,197 @@
+package movement
+
+// HandleCodeMovement handles code-structure movements ([{, ]}, [(, ]), [[, ]], [], ][, gd, gD)
+func HandleCodeMovement(direction string, currentRow, currentCol int, textGrid [][]string, opts Options) (int, int, int) {
+	newRow, newCol := currentRow, currentCol
+
+	switch direction {
+	case "unmatched_brace_prev":
+		// [{: go to previous unmatched '{'
+		newRow, newCol = FindUnmatchedBracketBackward(currentRow, currentCol, "{", "}", textGrid)
+	case "unmatched_brace_next":
+		// ]}: go to next unmatched '}'
+		newRow, newCol = FindUnmatchedBracketForward(currentRow, currentCol, "{", "}", textGrid)
+	case "unmatched_paren_prev":
+		// [(: go to previous unmatched '('
+		newRow, newCol = FindUnmatchedBracketBackward(currentRow, currentCol, "(", ")", textGrid)
+	case "unmatched_paren_next":
+		// ]): go to next unmatched ')'
+		newRow, newCol = FindUnmatchedBracketForward(currentRow, currentCol, "(", ")", textGrid)
+	case "section_prev":
+		// [[: go to previous '{' in the first column
+		newRow, newCol = FindSectionBackward(currentRow, currentCol, "{", textGrid)
+	case "section_next":
+		// ]]: go to next '{' in the first column
+		newRow, newCol = FindSectionForward(currentRow, currentCol, "{", textGrid)
+	case "section_end_prev":
+		// []: go to previous '}' in the first column
+		newRow, newCol = FindSectionBackward(currentRow, currentCol, "}", textGrid)
+	case "section_end_next":
+		// ][: go to next '}' in the first column
+		newRow, newCol = FindSectionForward(currentRow, currentCol, "}", textGrid)
+	case "goto_local_declaration":
+		// gd: first occurrence of the identifier in the current section
+		newRow, newCol = FindDeclaration(currentRow, currentCol, textGrid, true, opts.Keywords())
+	case "goto_global_declaration":
+		// gD: first occurrence of the identifier in the file
+		newRow, newCol = FindDeclaration(currentRow, currentCol, textGrid, false, opts.Keywords())
+	}
+
+	newPreferredColumn := newCol
+	return newRow, newCol, newPreferredColumn
+}
+
+// FindUnmatchedBracketBackward finds the previous opening bracket that is not closed before the cursor
+func FindUnmatchedBracketBackward(row, col int, openBracket, closeBracket string, textGrid [][]string) (int, int) {
+	if row < 0 || row >= len(textGrid) {
+		return row, col
+	}
+
+	nestLevel := 0
+	currentRow := row
+	currentCol := col - 1
+
+	for currentRow >= 0 {
+		for currentCol >= 0 {
+			if currentCol < len(textGrid[currentRow]) {
+				char := textGrid[currentRow][currentCol]
+				if char == closeBracket {
+					nestLevel++
+				} else if char == openBracket {
+					if nestLevel == 0 {
+						return currentRow, currentCol
+					}
+					nestLevel--
+				}
+			}
+			currentCol--
+		}
+		currentRow--
+		if currentRow >= 0 {
+			currentCol = len(textGrid[currentRow]) - 1
+		}
+	}
+
+	return row, col
+}
+
+// FindUnmatchedBracketForward finds the next closing bracket that was not opened after the cursor
+func FindUnmatchedBracketForward(row, col int, openBracket, closeBracket string, textGrid [][]string) (int, int) {
+	if row < 0 || row >= len(textGrid) {
+		return row, col
+	}
+
+	nestLevel := 0
+	currentRow := row
+	currentCol := col + 1
+
+	for currentRow < len(textGrid) {
+		for currentCol < len(textGrid[currentRow]) {
+			char := textGrid[currentRow][currentCol]
+			if char == openBracket {
+				nestLevel++
+			} else if char == closeBracket {
+				if nestLevel == 0 {
+					return currentRow, currentCol
+				}
+				nestLevel--
+			}
+			currentCol++
+		}
+		currentRow++
+		currentCol = 0
+	}
+
+	return row, col
+}
+
+// FindSectionBackward finds the previous line starting with the given character, or the top of the file
+func FindSectionBackward(row, col int, sectionChar string, textGrid [][]string) (int, int) {
+	if row < 0 || row >= len(textGrid) {
+		return row, col
+	}
+
+	for currentRow := row - 1; currentRow >= 0; currentRow-- {
+		if len(textGrid[currentRow]) > 0 && textGrid[currentRow][0] == sectionChar {
+			return currentRow, 0
+		}
+	}
+
+	// Like vim, fall back to the first line when no section is found
+	return 0, 0
+}
+
+// FindSectionForward finds the next line starting with the given character, or the bottom of the file
+func FindSectionForward(row, col int, sectionChar string, textGrid [][]string) (int, int) {
+	if row < 0 || row >= len(textGrid) {
+		return row, col
+	}
+
+	for currentRow := row + 1; currentRow < len(textGrid); currentRow++ {
+		if len(textGrid[currentRow]) > 0 && textGrid[currentRow][0] == sectionChar {
+			return currentRow, 0
+		}
+	}
+
+	// Like vim, fall back to the last line when no section is found
+	lastRow := len(textGrid) - 1
+	return lastRow, 0
+}
+
+// FindDeclaration finds the first occurrence of the identifier under the cursor.
+// When local is true the search starts at the beginning of the current section (gd),
+// otherwise at the top of the file (gD).
+func FindDeclaration(row, col int, textGrid [][]string, local bool, keywords *KeywordSet) (int, int) {
+	if row < 0 || row >= len(textGrid) || col < 0 || col >= len(textGrid[row]) {
+		return row, col
+	}
+
+	if !keywords.IsWordChar(textGrid[row][col]) {
+		return row, col
+	}
+
+	// Extract the identifier under the cursor
+	start := col
+	for start > 0 && keywords.IsWordChar(textGrid[row][start-1]) {
+		start--
+	}
+	end := col
+	for end < len(textGrid[row])-1 && keywords.IsWordChar(textGrid[row][end+1]) {
+		end++
+	}
+	identifier := textGrid[row][start : end+1]
+
+	startRow := 0
+	if local {
+		startRow, _ = FindSectionBackward(row, col, "{", textGrid)
+	}
+
+	for searchRow := startRow; searchRow <= row; searchRow++ {
+		line := textGrid[searchRow]
+		for searchCol := 0; searchCol+len(identifier) <= len(line); searchCol++ {
+			if searchRow == row && searchCol >= start {
+				break
+			}
+			if isIdentifierAt(line, searchCol, identifier, keywords) {
+				return searchRow, searchCol
+			}
+		}
+	}
+
+	// The identifier under the cursor is its own first occurrence
+	return row, start
+}
+
+// isIdentifierAt checks whether the whole identifier starts at the given column
+func isIdentifierAt(line []string, col int, identifier []string, keywords *KeywordSet) bool {
+	if col > 0 && keywords.IsWordChar(line[col-1]) {
+		return false
+	}
+	for i, char := range identifier {
+		if line[col+i] != char {
+			return false
+		}
+	}
+	end := col + len(identifier)
+	return end >= len(line) || !keywords.IsWordChar(line[end])
+}
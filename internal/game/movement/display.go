@@ -0,0 +1,111 @@
+package movement
+
+import "boba-vim/internal/utils"
+
+// HandleDisplayLineMovement handles display-line movements (gj, gk, g0, g^, gm, g$).
+// Long lines are split into display lines of opts.TextWidth columns when wrapping is enabled;
+// otherwise every logical line is a single display line.
+func HandleDisplayLineMovement(direction string, currentRow, currentCol, preferredColumn int, gameMap [][]int, textGrid [][]string, opts Options) (int, int, int) {
+	newRow, newCol := currentRow, currentCol
+	newPreferredColumn := preferredColumn
+
+	if currentRow < 0 || currentRow >= len(gameMap) {
+		return newRow, newCol, newPreferredColumn
+	}
+
+	width := opts.DisplayWidth()
+	lineStart, lineEnd := FindDisplayLine(currentCol, len(gameMap[currentRow]), width)
+
+	switch direction {
+	case "display_down":
+		// gj: move down one display line, keeping the screen column
+		screenCol := screenColumn(preferredColumn, width)
+		if lineEnd < len(gameMap[currentRow]) {
+			newCol = clampCol(lineEnd+screenCol, len(gameMap[currentRow]))
+		} else if currentRow < len(gameMap)-1 {
+			newRow = currentRow + 1
+			newCol = clampCol(screenCol, len(gameMap[newRow]))
+		}
+		// Don't update preferred column for vertical movements
+	case "display_up":
+		// gk: move up one display line, keeping the screen column
+		screenCol := screenColumn(preferredColumn, width)
+		if lineStart > 0 {
+			newCol = clampCol(lineStart-width+screenCol, len(gameMap[currentRow]))
+		} else if currentRow > 0 {
+			newRow = currentRow - 1
+			rowLength := len(gameMap[newRow])
+			lastStart, _ := FindDisplayLine(rowLength-1, rowLength, width)
+			newCol = clampCol(lastStart+screenCol, rowLength)
+		}
+		// Don't update preferred column for vertical movements
+	case "display_line_start":
+		// g0: move to first character of the display line
+		newCol = lineStart
+		newPreferredColumn = newCol
+	case "display_first_non_blank":
+		// g^: move to first non-blank character of the display line
+		newCol = lineStart
+		if currentRow < len(textGrid) {
+			for col := lineStart; col < lineEnd && col < len(textGrid[currentRow]); col++ {
+				char := textGrid[currentRow][col]
+				if !utils.IsSpace(char) && char != "" {
+					newCol = col
+					break
+				}
+			}
+		}
+		newPreferredColumn = newCol
+	case "display_line_middle":
+		// gm: move half a screen width to the right of the display line start
+		half := (lineEnd - lineStart) / 2
+		if width > 0 {
+			half = width / 2
+		}
+		newCol = clampCol(lineStart+half, lineEnd)
+		newPreferredColumn = newCol
+	case "display_line_end":
+		// g$: move to last character of the display line
+		newCol = clampCol(lineEnd-1, lineEnd)
+		newPreferredColumn = newCol
+	}
+
+	return newRow, newCol, newPreferredColumn
+}
+
+// FindDisplayLine returns the start (inclusive) and end (exclusive) columns of the display line
+// containing col, for a line of rowLength characters wrapped at width (0 disables wrapping)
+func FindDisplayLine(col, rowLength, width int) (int, int) {
+	if width <= 0 || rowLength <= width {
+		return 0, rowLength
+	}
+	if col < 0 {
+		col = 0
+	}
+
+	start := (col / width) * width
+	end := start + width
+	if end > rowLength {
+		end = rowLength
+	}
+	return start, end
+}
+
+// screenColumn converts a logical column into a column within its display line
+func screenColumn(col, width int) int {
+	if width <= 0 {
+		return col
+	}
+	return col % width
+}
+
+// clampCol clamps col to the characters of a line of the given length
+func clampCol(col, rowLength int) int {
+	if col >= rowLength {
+		col = rowLength - 1
+	}
+	if col < 0 {
+		col = 0
+	}
+	return col
+}
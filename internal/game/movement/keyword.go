@@ -0,0 +1,112 @@
+package movement
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"boba-vim/internal/utils"
+)
+
+// KeywordSet classifies characters as keyword characters following vim's 'iskeyword' option
+type KeywordSet struct {
+	chars [256]bool
+}
+
+// ParseKeywordSet parses an 'iskeyword' value such as "@,48-57,_,192-255".
+// Parts are separated by commas; each part is "@" (letters), a character, a character
+// code, or a range of either, optionally prefixed with "^" to exclude it.
+func ParseKeywordSet(spec string) (*KeywordSet, error) {
+	keywords := &KeywordSet{}
+	if spec == "" {
+		return nil, fmt.Errorf("iskeyword must not be empty")
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		if part == "" {
+			continue
+		}
+
+		include := true
+		if len(part) > 1 && part[0] == '^' {
+			include = false
+			part = part[1:]
+		}
+
+		if part == "@" {
+			for c := 0; c < 256; c++ {
+				if unicode.IsLetter(rune(c)) {
+					keywords.chars[c] = include
+				}
+			}
+			continue
+		}
+		if part == "@-@" {
+			keywords.chars['@'] = include
+			continue
+		}
+
+		low, high := part, part
+		if dash := strings.Index(part[1:], "-"); dash >= 0 {
+			low, high = part[:dash+1], part[dash+2:]
+		}
+
+		start, err := parseKeywordChar(low)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseKeywordChar(high)
+		if err != nil {
+			return nil, err
+		}
+		if start > end {
+			return nil, fmt.Errorf("invalid iskeyword range: %s", part)
+		}
+
+		for c := start; c <= end; c++ {
+			keywords.chars[c] = include
+		}
+	}
+
+	return keywords, nil
+}
+
+// parseKeywordChar parses a single character or decimal character code (0-255)
+func parseKeywordChar(value string) (int, error) {
+	if len(value) == 1 && (value[0] < '0' || value[0] > '9') {
+		return int(value[0]), nil
+	}
+
+	code, err := strconv.Atoi(value)
+	if err != nil || code < 0 || code > 255 {
+		return 0, fmt.Errorf("invalid iskeyword character: %s", value)
+	}
+	return code, nil
+}
+
+// IsWordChar determines if a character is a keyword character
+func (k *KeywordSet) IsWordChar(char string) bool {
+	if k == nil {
+		return utils.IsWordChar(char)
+	}
+	if len(char) == 0 {
+		return false
+	}
+
+	r, _ := utf8.DecodeRuneInString(char)
+	if r < 256 {
+		return k.chars[r]
+	}
+	// Like vim, characters above 255 are word characters unless they are punctuation
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// IsPunctuation determines if a character is neither a keyword character nor whitespace
+func (k *KeywordSet) IsPunctuation(char string) bool {
+	if len(char) == 0 {
+		return false
+	}
+	return !k.IsWordChar(char) && !utils.IsSpace(char)
+}
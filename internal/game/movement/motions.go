@@ -0,0 +1,274 @@
+package movement
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MotionSpec is a Motion assembled from plain values. The built-in motions use it,
+// and other packages can use it to register motions without writing their own type.
+type MotionSpec struct {
+	Keys  []Binding
+	Group string
+	Count CountMode
+	// ArgumentDirections lists directions that take a single character argument,
+	// sent as "<direction>_<char>" (e.g. "find_char_forward_a")
+	ArgumentDirections []string
+	Handler            func(req Request) Result
+}
+
+// Bindings lists the keys that trigger the motion
+func (ms *MotionSpec) Bindings() []Binding {
+	return ms.Keys
+}
+
+// Category names the scoring category of the motion
+func (ms *MotionSpec) Category() string {
+	return ms.Group
+}
+
+// CountMode tells how a count prefix applies to the motion
+func (ms *MotionSpec) CountMode() CountMode {
+	return ms.Count
+}
+
+// ParseArgument splits "<direction>_<char>" into the direction and its character argument,
+// which must be exactly one character (multi-byte characters included)
+func (ms *MotionSpec) ParseArgument(direction string) (string, string, bool) {
+	for _, base := range ms.ArgumentDirections {
+		if !strings.HasPrefix(direction, base+"_") {
+			continue
+		}
+		argument := direction[len(base)+1:]
+		if utf8.RuneCountInString(argument) == 1 && utf8.ValidString(argument) {
+			return base, argument, true
+		}
+	}
+	return "", "", false
+}
+
+// enemyCell is the game map value of an enemy (constant.ENEMY, which can't be imported here)
+const enemyCell = 2
+
+// Move computes the new cursor position. Motions counting with CountRepeat have their
+// handler run count times from each new position, stopping at the last position reached
+// before the motion gets stuck or would land on an enemy.
+func (ms *MotionSpec) Move(req Request) Result {
+	result := ms.Handler(req)
+	result.Steps = 1
+	// A first move onto an enemy is returned as is, to be rejected rather than stepped over
+	if ms.Count != CountRepeat || blocked(result.Row, result.Col, req.GameMap) {
+		return result
+	}
+
+	for i := 1; i < req.Count; i++ {
+		step := req
+		step.Row, step.Col, step.PreferredColumn = result.Row, result.Col, result.PreferredColumn
+		next := ms.Handler(step)
+		// Stop once the motion gets stuck (like vim, 100j stops at the last line)
+		if next.Row == result.Row && next.Col == result.Col && next.PreferredColumn == result.PreferredColumn {
+			break
+		}
+		// Stop before an enemy, keeping the moves made so far
+		if blocked(next.Row, next.Col, req.GameMap) {
+			break
+		}
+		next.Steps = result.Steps + 1
+		result = next
+	}
+	return result
+}
+
+// blocked checks whether a position is off the map or taken by an enemy
+func blocked(row, col int, gameMap [][]int) bool {
+	if row < 0 || row >= len(gameMap) || col < 0 || col >= len(gameMap[row]) {
+		return true
+	}
+	return gameMap[row][col] == enemyCell
+}
+
+// position wraps the (row, col, preferredColumn) triple returned by the movement handlers
+func position(row, col, preferredColumn int) Result {
+	return Result{Row: row, Col: col, PreferredColumn: preferredColumn}
+}
+
+func init() {
+	Register(&MotionSpec{
+		Group: "Basic Movement",
+		Keys: []Binding{
+			{Key: "h", Direction: "left", Description: "Move left (vim style)", Score: 100},
+			{Key: "j", Direction: "down", Description: "Move down (vim style)", Score: 100},
+			{Key: "k", Direction: "up", Description: "Move up (vim style)", Score: 100},
+			{Key: "l", Direction: "right", Description: "Move right (vim style)", Score: 100},
+			{Key: "ArrowLeft", Direction: "left", Description: "NOOB: Move left (arrow key)", Score: -50, Category: "Arrow Penalty"},
+			{Key: "ArrowDown", Direction: "down", Description: "NOOB: Move down (arrow key)", Score: -50, Category: "Arrow Penalty"},
+			{Key: "ArrowUp", Direction: "up", Description: "NOOB: Move up (arrow key)", Score: -50, Category: "Arrow Penalty"},
+			{Key: "ArrowRight", Direction: "right", Description: "NOOB: Move right (arrow key)", Score: -50, Category: "Arrow Penalty"},
+		},
+		Handler: func(req Request) Result {
+			result := position(HandleBasicMovement(req.BaseDirection, req.Row, req.Col, req.PreferredColumn, req.GameMap, req.TextGrid, req.Options))
+			// h/l moving through virtual columns past the end of the line ('virtualedit') are valid moves
+			rowLength := len(req.GameMap[req.Row])
+			result.KeepsPosition = (req.BaseDirection == "left" || req.BaseDirection == "right") &&
+				result.PreferredColumn != req.PreferredColumn && req.Options.LastVirtualColumn(rowLength, BoardWidth(req.GameMap)) >= rowLength
+			return result
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Word Movement",
+		Keys: []Binding{
+			{Key: "w", Direction: "word_forward", Description: "Move forward to beginning of next word", Score: 120},
+			{Key: "W", Direction: "word_forward_space", Description: "Move forward to beginning of next WORD (space-separated)", Score: 120},
+			{Key: "b", Direction: "word_backward", Description: "Move backward to beginning of current/previous word", Score: 120},
+			{Key: "B", Direction: "word_backward_space", Description: "Move backward to beginning of current/previous WORD", Score: 120},
+			{Key: "e", Direction: "word_end", Description: "Move to end of current/next word", Score: 120},
+			{Key: "E", Direction: "word_end_space", Description: "Move to end of current/next WORD (space-separated)", Score: 120},
+			{Key: "ge", Direction: "word_end_prev", Description: "Move to end of previous word", Score: 120},
+			{Key: "gE", Direction: "word_end_prev_space", Description: "Move to end of previous WORD (space-separated)", Score: 120},
+		},
+		Handler: func(req Request) Result {
+			return position(HandleWordMovement(req.BaseDirection, req.Row, req.Col, req.TextGrid, req.Options))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Line Movement",
+		Count: CountTarget,
+		Keys: []Binding{
+			{Key: "$", Direction: "line_end", Description: "Move to end of current line", Score: 120},
+			{Key: "0", Direction: "line_start", Description: "Move to beginning of current line", Score: 120},
+			{Key: "^", Direction: "line_first_non_blank", Description: "Move to first non-blank character of line", Score: 130},
+			{Key: "g_", Direction: "line_last_non_blank", Description: "Move to last non-blank character of line", Score: 130},
+			{Key: "|", Direction: "line_column", Description: "Move to screen column [count] of line", Score: 130},
+		},
+		Handler: func(req Request) Result {
+			return position(HandleLineMovement(req.BaseDirection, req.Row, req.Col, req.Count, req.GameMap, req.TextGrid, req.Options))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "File Jump",
+		Count: CountTarget,
+		Keys: []Binding{
+			{Key: "gg", Direction: "file_start", Description: "Go to top of file", Score: 130},
+			{Key: "G", Direction: "file_end", Description: "Go to bottom of file", Score: 130},
+		},
+		Handler: func(req Request) Result {
+			return position(HandleFileMovementWithCount(req.BaseDirection, req.Row, req.Col, req.PreferredColumn, req.GameMap, req.TextGrid, req.Count, req.HasExplicitCount, req.Options))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Screen Movement",
+		Count: CountTarget,
+		Keys: []Binding{
+			{Key: "H", Direction: "screen_top", Description: "Go to top of screen", Score: 150},
+			{Key: "M", Direction: "screen_middle", Description: "Go to middle of screen", Score: 150},
+			{Key: "L", Direction: "screen_bottom", Description: "Go to bottom of screen", Score: 150},
+		},
+		Handler: func(req Request) Result {
+			return position(HandleScreenMovement(req.BaseDirection, req.Row, req.Col, req.PreferredColumn, req.Count, req.GameMap, req.TextGrid, req.Options))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Paragraph Movement",
+		Keys: []Binding{
+			{Key: "{", Direction: "paragraph_prev", Description: "Go to previous paragraph", Score: 160},
+			{Key: "}", Direction: "paragraph_next", Description: "Go to next paragraph", Score: 160},
+		},
+		Handler: func(req Request) Result {
+			result := position(HandleParagraphMovement(req.BaseDirection, req.Row, req.Col, req.TextGrid))
+			// Paragraph movements may stay at the same position (like when at first/last paragraph)
+			result.KeepsPosition = true
+			return result
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Paragraph Movement",
+		Keys: []Binding{
+			{Key: "(", Direction: "sentence_prev", Description: "Go to previous sentence", Score: 160},
+			{Key: ")", Direction: "sentence_next", Description: "Go to next sentence", Score: 160},
+		},
+		Handler: func(req Request) Result {
+			return position(HandleSentenceMovement(req.BaseDirection, req.Row, req.Col, req.TextGrid))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Find Character",
+		Count: CountTarget,
+		Keys: []Binding{
+			{Key: "f", Direction: "find_char_forward", Description: "Find character forward", Score: 125},
+			{Key: "F", Direction: "find_char_backward", Description: "Find character backward", Score: 125},
+			{Key: "t", Direction: "till_char_forward", Description: "Till character forward", Score: 125},
+			{Key: "T", Direction: "till_char_backward", Description: "Till character backward", Score: 125},
+		},
+		ArgumentDirections: []string{"find_char_forward", "find_char_backward", "till_char_forward", "till_char_backward"},
+		Handler: func(req Request) Result {
+			return position(HandleCharacterSearch(req.Direction, req.Row, req.Col, req.Count, req.TextGrid))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Repeat Find",
+		Count: CountTarget,
+		Keys: []Binding{
+			{Key: ";", Direction: "repeat_char_search_same", Description: "Repeat last character search in same direction", Score: 130},
+			{Key: ",", Direction: "repeat_char_search_opposite", Description: "Repeat last character search in opposite direction", Score: 130},
+		},
+		Handler: func(req Request) Result {
+			return position(HandleCharacterSearch(req.BaseDirection, req.Row, req.Col, req.Count, req.TextGrid))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Match Movement",
+		Count: CountTarget,
+		Keys: []Binding{
+			{Key: "%", Direction: "match_bracket", Description: "Jump to matching bracket (){}[], or [count]% of the file", Score: 200},
+		},
+		Handler: func(req Request) Result {
+			if req.HasExplicitCount {
+				return position(HandlePercentMovement(req.Row, req.Col, req.PreferredColumn, req.Count, req.GameMap, req.TextGrid, req.Options))
+			}
+			return position(HandleBracketMatching(req.Row, req.Col, req.TextGrid))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Code Movement",
+		Keys: []Binding{
+			{Key: "[{", Direction: "unmatched_brace_prev", Description: "Go to previous unmatched '{'", Score: 180},
+			{Key: "]}", Direction: "unmatched_brace_next", Description: "Go to next unmatched '}'", Score: 180},
+			{Key: "[(", Direction: "unmatched_paren_prev", Description: "Go to previous unmatched '('", Score: 180},
+			{Key: "])", Direction: "unmatched_paren_next", Description: "Go to next unmatched ')'", Score: 180},
+			{Key: "[[", Direction: "section_prev", Description: "Go to previous section start ('{' in first column)", Score: 170},
+			{Key: "]]", Direction: "section_next", Description: "Go to next section start ('{' in first column)", Score: 170},
+			{Key: "[]", Direction: "section_end_prev", Description: "Go to previous section end ('}' in first column)", Score: 170},
+			{Key: "][", Direction: "section_end_next", Description: "Go to next section end ('}' in first column)", Score: 170},
+			{Key: "gd", Direction: "goto_local_declaration", Description: "Go to local declaration of identifier under cursor", Score: 180},
+			{Key: "gD", Direction: "goto_global_declaration", Description: "Go to global declaration of identifier under cursor", Score: 180},
+		},
+		Handler: func(req Request) Result {
+			return position(HandleCodeMovement(req.BaseDirection, req.Row, req.Col, req.TextGrid, req.Options))
+		},
+	})
+
+	Register(&MotionSpec{
+		Group: "Display Movement",
+		Keys: []Binding{
+			{Key: "gj", Direction: "display_down", Description: "Move down one display line (wrapped lines)", Score: 110},
+			{Key: "gk", Direction: "display_up", Description: "Move up one display line (wrapped lines)", Score: 110},
+			{Key: "g0", Direction: "display_line_start", Description: "Move to first character of display line", Score: 120},
+			{Key: "g^", Direction: "display_first_non_blank", Description: "Move to first non-blank character of display line", Score: 130},
+			{Key: "gm", Direction: "display_line_middle", Description: "Move to middle of screen width on display line", Score: 130},
+			{Key: "g$", Direction: "display_line_end", Description: "Move to last character of display line", Score: 120},
+		},
+		Handler: func(req Request) Result {
+			return position(HandleDisplayLineMovement(req.BaseDirection, req.Row, req.Col, req.PreferredColumn, req.GameMap, req.TextGrid, req.Options))
+		},
+	})
+}
@@ -0,0 +1,121 @@
+package movement
+
+import "testing"
+
+func TestParseArgument(t *testing.T) {
+	tests := []struct {
+		direction    string
+		wantBase     string
+		wantArgument string
+		wantOK       bool
+	}{
+		{"find_char_forward_a", "find_char_forward", "a", true},
+		{"find_char_backward_;", "find_char_backward", ";", true},
+		{"till_char_forward__", "till_char_forward", "_", true},
+		{"till_char_backward_é", "till_char_backward", "é", true},
+		{"find_char_forward_界", "find_char_forward", "界", true},
+		{"find_char_forward_ab", "", "", false},
+		{"find_char_forward_é!", "", "", false},
+		{"find_char_forward_", "", "", false},
+		{"find_char_forward", "", "", false},
+		{"find_char_forwardxa", "", "", false},
+		{"find_char_forward_\xff", "", "", false},
+		{"word_forward_a", "", "", false},
+	}
+
+	motion, _, _, ok := Resolve("find_char_forward")
+	if !ok {
+		t.Fatal("find_char_forward is not registered")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.direction, func(t *testing.T) {
+			base, argument, ok := motion.ParseArgument(tt.direction)
+			if ok != tt.wantOK || base != tt.wantBase || argument != tt.wantArgument {
+				t.Errorf("ParseArgument(%q) = %q, %q, %v, want %q, %q, %v", tt.direction, base, argument, ok, tt.wantBase, tt.wantArgument, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResolveArgument(t *testing.T) {
+	_, base, argument, ok := Resolve("till_char_backward_é")
+	if !ok || base != "till_char_backward" || argument != "é" {
+		t.Errorf("Resolve(till_char_backward_é) = %q, %q, %v", base, argument, ok)
+	}
+
+	for _, direction := range []string{"find_char_forward_ab", "word_forward_a"} {
+		if _, _, _, ok := Resolve(direction); ok {
+			t.Errorf("Resolve(%q) succeeded, want failure", direction)
+		}
+	}
+}
+
+func TestMoveCount(t *testing.T) {
+	textGrid := [][]string{
+		{"a", "b", "c", "d", "e", "f"},
+		{"g", "h", "i", "j", "k", "l"},
+		{"m", "n", "o", "p", "q", "r"},
+	}
+	openMap := [][]int{
+		{0, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0},
+	}
+	enemyMap := [][]int{
+		{0, 0, 0, 2, 0, 0},
+		{0, 0, 0, 0, 0, 0},
+		{2, 0, 0, 0, 0, 0},
+	}
+
+	tests := []struct {
+		name      string
+		direction string
+		row, col  int
+		count     int
+		explicit  bool
+		gameMap   [][]int
+		wantRow   int
+		wantCol   int
+		wantSteps int
+	}{
+		{name: "single step", direction: "right", count: 1, gameMap: openMap, wantCol: 1, wantSteps: 1},
+		{name: "repeats count times", direction: "right", count: 4, gameMap: openMap, wantCol: 4, wantSteps: 4},
+		{name: "stops at the end of the line", direction: "right", count: 10, gameMap: openMap, wantCol: 5, wantSteps: 5},
+		{name: "stops at the last line", direction: "down", count: 100, gameMap: openMap, wantRow: 2, wantSteps: 2},
+		{name: "stops before an enemy", direction: "right", count: 5, gameMap: enemyMap, wantCol: 2, wantSteps: 2},
+		{name: "stops before an enemy below", direction: "down", count: 3, gameMap: enemyMap, wantRow: 1, wantSteps: 1},
+		{name: "first step onto an enemy", direction: "right", col: 2, count: 5, gameMap: enemyMap, wantCol: 3, wantSteps: 1},
+		{name: "count is a target line", direction: "file_end", count: 2, explicit: true, gameMap: openMap, wantRow: 1, wantSteps: 1},
+		{name: "count is an occurrence", direction: "find_char_forward_c", count: 1, gameMap: openMap, wantCol: 2, wantSteps: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			motion, base, argument, ok := Resolve(tt.direction)
+			if !ok {
+				t.Fatalf("Resolve(%q) failed", tt.direction)
+			}
+
+			result := motion.Move(Request{
+				Direction:        tt.direction,
+				BaseDirection:    base,
+				Argument:         argument,
+				Row:              tt.row,
+				Col:              tt.col,
+				PreferredColumn:  tt.col,
+				Count:            tt.count,
+				HasExplicitCount: tt.explicit,
+				GameMap:          tt.gameMap,
+				TextGrid:         textGrid,
+				Options:          Options{TabStop: DefaultTabStop},
+			})
+			if result.Row != tt.wantRow || result.Col != tt.wantCol {
+				t.Errorf("position = (%d, %d), want (%d, %d)", result.Row, result.Col, tt.wantRow, tt.wantCol)
+			}
+			if result.Steps != tt.wantSteps {
+				t.Errorf("steps = %d, want %d", result.Steps, tt.wantSteps)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package movement
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Options holds the per-session settings that change how motions behave
+type Options struct {
+	// Wrap enables soft-wrapping of lines longer than TextWidth
+	Wrap bool
+	// TextWidth is the number of columns the client reports it can display (0 when unknown)
+	TextWidth int
+
+	// WhichWrap lists the keys allowed to move to the previous/next line (vim 'whichwrap', e.g. "b,s,h,l")
+	WhichWrap string
+	// StartOfLine moves the cursor to the first non-blank character for gg, G, H, M and L (vim 'startofline')
+	StartOfLine bool
+	// VirtualEdit lets the cursor column go past the end of the line (vim 'virtualedit': "onemore" or "all")
+	VirtualEdit string
+	// IsKeyword lists the characters that make up a word for w, b, e, ge, gd and gD (vim 'iskeyword')
+	IsKeyword string
+	// WrapScan lets searches wrap around the end of the file (vim 'wrapscan'). It is kept with the
+	// other options for the search motions to follow; none of the current motions search the file.
+	WrapScan bool
+	// TabStop is the number of screen columns a tab occupies (vim 'tabstop')
+	TabStop int
+}
+
+// DefaultTabStop is vim's default 'tabstop'
+const DefaultTabStop = 8
+
+// DisplayWidth returns the width at which lines wrap, or 0 when lines are not wrapped
+func (o Options) DisplayWidth() int {
+	if !o.Wrap || o.TextWidth <= 0 {
+		return 0
+	}
+	return o.TextWidth
+}
+
+// WrapsKey checks whether 'whichwrap' allows the given key (e.g. "h" or "l") to cross line boundaries
+func (o Options) WrapsKey(key string) bool {
+	for _, flag := range strings.Split(o.WhichWrap, ",") {
+		if flag == key {
+			return true
+		}
+	}
+	return false
+}
+
+// LastVirtualColumn returns the last column the cursor may use on a line of the given length.
+// With "all" the cursor may go as far as the widest line of the board, and one past the end of that line.
+func (o Options) LastVirtualColumn(rowLength, boardWidth int) int {
+	for _, mode := range strings.Split(o.VirtualEdit, ",") {
+		switch mode {
+		case "all":
+			if boardWidth-1 > rowLength {
+				return boardWidth - 1
+			}
+			return rowLength
+		case "onemore":
+			return rowLength
+		}
+	}
+	return rowLength - 1
+}
+
+// TabWidth returns the tabstop, falling back to vim's default for unset options
+func (o Options) TabWidth() int {
+	if o.TabStop <= 0 {
+		return DefaultTabStop
+	}
+	return o.TabStop
+}
+
+// Keywords returns the keyword character set described by 'iskeyword'.
+// A nil set is returned for unset or invalid options, which falls back to [a-zA-Z0-9_].
+func (o Options) Keywords() *KeywordSet {
+	if o.IsKeyword == "" {
+		return nil
+	}
+	keywords, err := ParseKeywordSet(o.IsKeyword)
+	if err != nil {
+		return nil
+	}
+	return keywords
+}
+
+// Validate checks that every option holds a value vim would accept
+func (o Options) Validate() error {
+	if o.TextWidth < 0 {
+		return fmt.Errorf("text width must not be negative")
+	}
+
+	for _, flag := range strings.Split(o.WhichWrap, ",") {
+		switch flag {
+		case "", "b", "s", "h", "l", "<", ">", "~", "[", "]":
+		default:
+			return fmt.Errorf("invalid whichwrap flag: %s", flag)
+		}
+	}
+
+	for _, mode := range strings.Split(o.VirtualEdit, ",") {
+		switch mode {
+		case "", "block", "insert", "all", "onemore", "none", "NONE":
+		default:
+			return fmt.Errorf("invalid virtualedit value: %s", mode)
+		}
+	}
+
+	if _, err := ParseKeywordSet(o.IsKeyword); err != nil {
+		return err
+	}
+
+	if o.TabStop < 1 || o.TabStop > 32 {
+		return fmt.Errorf("tabstop must be between 1 and 32")
+	}
+
+	return nil
+}
+
+// VirtualColumn returns the screen column at which the character at col starts, expanding tabs.
+// Columns past the end of the line count as one screen column each.
+func VirtualColumn(line []string, col, tabStop int) int {
+	virtualCol := 0
+	for i := 0; i < col; i++ {
+		if i < len(line) && line[i] == "\t" {
+			virtualCol += tabStop - virtualCol%tabStop
+		} else {
+			virtualCol++
+		}
+	}
+	return virtualCol
+}
+
+// ColumnForVirtual returns the character column covering the given screen column, expanding tabs
+func ColumnForVirtual(line []string, virtualCol, tabStop int) int {
+	current := 0
+	for i := 0; i < len(line); i++ {
+		width := 1
+		if line[i] == "\t" {
+			width = tabStop - current%tabStop
+		}
+		if virtualCol < current+width {
+			return i
+		}
+		current += width
+	}
+	return len(line) + (virtualCol - current)
+}
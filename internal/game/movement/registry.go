@@ -0,0 +1,234 @@
+package movement
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// CountMode describes how a count prefix (e.g. the 5 in 5j) applies to a motion
+type CountMode int
+
+const (
+	// CountRepeat applies the motion count times in a row, stopping once it no longer moves (5j, 3w, 3})
+	CountRepeat CountMode = iota
+	// CountTarget hands the count to the motion, which gives it its own meaning
+	// (5G goes to line 5, 3fa to the third 'a', 50% to the middle of the file, 10| to column 10)
+	CountTarget
+)
+
+// Binding maps a key sequence to the direction it triggers
+type Binding struct {
+	Key         string
+	Direction   string
+	Description string
+	// Score is the points awarded when a pearl is collected with this key
+	Score int
+	// Category overrides the motion's scoring category for this key (e.g. arrow keys)
+	Category string
+}
+
+// Request is a single motion invocation handed to a Motion
+type Request struct {
+	// Direction is the direction as sent by the client, including any argument (e.g. "find_char_forward_a")
+	Direction string
+	// BaseDirection is the registered direction without its argument (e.g. "find_char_forward")
+	BaseDirection string
+	// Argument is the character argument of motions such as f, F, t and T
+	Argument string
+
+	Row              int
+	Col              int
+	PreferredColumn  int
+	Count            int
+	HasExplicitCount bool
+	GameMap          [][]int
+	TextGrid         [][]string
+	Options          Options
+}
+
+// Result is the outcome of a motion
+type Result struct {
+	Row             int
+	Col             int
+	PreferredColumn int
+	// KeepsPosition marks a move that is valid even though the cursor didn't move
+	KeepsPosition bool
+	// Steps is the number of moves made: 1, or fewer than the count when a repeated motion is blocked
+	Steps int
+}
+
+// Motion is a vim motion that can be registered with the movement engine
+type Motion interface {
+	// Bindings lists the keys that trigger the motion
+	Bindings() []Binding
+	// Category names the scoring category of the motion (e.g. "Word Movement")
+	Category() string
+	// CountMode tells how a count prefix applies to the motion
+	CountMode() CountMode
+	// ParseArgument splits a direction carrying an argument into its base direction and argument.
+	// Motions without arguments return false.
+	ParseArgument(direction string) (string, string, bool)
+	// Move computes the new cursor position
+	Move(req Request) Result
+}
+
+var (
+	motions      []Motion
+	keyIndex     = map[string]Binding{}
+	motionByKey  = map[string]Motion{}
+	motionByDir  = map[string]Motion{}
+	keyTable     = map[string]map[string]interface{}{}
+	directionSet = map[string]bool{}
+
+	// sealed is set by the first lookup. The tables are read without locks while games run, so
+	// motions can only be registered before that, from init functions.
+	sealed atomic.Bool
+)
+
+// Register adds a motion to the registry. Like database/sql.Register it is meant to be called
+// from init functions; it panics when a key is registered twice, or once the registry was read.
+func Register(motion Motion) {
+	if sealed.Load() {
+		panic(fmt.Sprintf("movement: motion %q registered after the registry was read; register motions from init functions", motion.Category()))
+	}
+	for _, binding := range motion.Bindings() {
+		if _, exists := keyIndex[binding.Key]; exists {
+			panic(fmt.Sprintf("movement: key %q registered twice", binding.Key))
+		}
+		if other, exists := motionByDir[binding.Direction]; exists && other != motion {
+			panic(fmt.Sprintf("movement: direction %q registered twice", binding.Direction))
+		}
+		if binding.Category == "" {
+			binding.Category = motion.Category()
+		}
+
+		keyIndex[binding.Key] = binding
+		motionByKey[binding.Key] = motion
+		motionByDir[binding.Direction] = motion
+		keyTable[binding.Key] = map[string]interface{}{
+			"direction":   binding.Direction,
+			"description": binding.Description,
+		}
+		directionSet[binding.Direction] = true
+	}
+	motions = append(motions, motion)
+}
+
+// seal stops motions from being registered once the registry is read
+func seal() {
+	if !sealed.Load() {
+		sealed.Store(true)
+	}
+}
+
+// KeyTable returns the live key -> {direction, description} table of every registered motion. It
+// doesn't seal the registry, so packages can hold on to it during init; it must only be read
+// after init, once every motion is registered.
+func KeyTable() map[string]map[string]interface{} {
+	return keyTable
+}
+
+// DirectionSet returns the live set of every registered direction, which like KeyTable must only
+// be read after init
+func DirectionSet() map[string]bool {
+	return directionSet
+}
+
+// Keys returns every registered key in registration order
+func Keys() []string {
+	seal()
+	var keys []string
+	for _, motion := range motions {
+		for _, binding := range motion.Bindings() {
+			keys = append(keys, binding.Key)
+		}
+	}
+	return keys
+}
+
+// Bindings returns every registered binding sorted by key
+func Bindings() []Binding {
+	seal()
+	bindings := make([]Binding, 0, len(keyIndex))
+	for _, binding := range keyIndex {
+		bindings = append(bindings, binding)
+	}
+	sort.Slice(bindings, func(i, j int) bool { return bindings[i].Key < bindings[j].Key })
+	return bindings
+}
+
+// LookupKey returns the binding registered for a key
+func LookupKey(key string) (Binding, bool) {
+	seal()
+	binding, exists := keyIndex[key]
+	return binding, exists
+}
+
+// Resolve finds the motion handling a direction and splits off its argument
+func Resolve(direction string) (Motion, string, string, bool) {
+	seal()
+	if motion, exists := motionByDir[direction]; exists {
+		return motion, direction, "", true
+	}
+	for _, motion := range motions {
+		if base, argument, ok := motion.ParseArgument(direction); ok {
+			return motion, base, argument, true
+		}
+	}
+	return nil, "", "", false
+}
+
+// ParseKey converts a key sent by the client (e.g. "5j", "find_char_forward_a") into a direction
+func ParseKey(key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("empty direction string")
+	}
+
+	// Directions carrying an argument are sent as directions already
+	if _, base, _, ok := Resolve(key); ok && base != key {
+		return key, nil
+	}
+
+	// Strip number prefix if present (e.g., "5j" -> "j", "123B" -> "B")
+	baseKey := strings.TrimLeft(key, "0123456789")
+	if baseKey == "" {
+		baseKey = key
+	}
+
+	binding, exists := keyIndex[baseKey]
+	if !exists {
+		return "", fmt.Errorf("invalid movement key: %s", baseKey)
+	}
+	return binding.Direction, nil
+}
+
+// KeyScore returns the score and category of a key or direction, after stripping a count prefix.
+// Directions carrying an argument score like the key of their base direction.
+func KeyScore(key string) (int, string, bool) {
+	seal()
+	if binding, exists := keyIndex[key]; exists {
+		return binding.Score, binding.Category, true
+	}
+
+	// Numbered commands (e.g. "5G") score like the command itself
+	if trimmed := strings.TrimLeft(key, "0123456789"); trimmed != key && trimmed != "" {
+		if binding, exists := keyIndex[trimmed]; exists {
+			return binding.Score, binding.Category, true
+		}
+	}
+
+	if motion, base, _, ok := Resolve(key); ok {
+		for _, binding := range motion.Bindings() {
+			if binding.Direction == base {
+				if binding.Category == "" {
+					binding.Category = motion.Category()
+				}
+				return binding.Score, binding.Category, true
+			}
+		}
+	}
+
+	return 0, "", false
+}
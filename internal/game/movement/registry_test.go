@@ -0,0 +1,24 @@
+package movement
+
+import "testing"
+
+func TestRegisterAfterLookupPanics(t *testing.T) {
+	if _, _, _, ok := Resolve("right"); !ok {
+		t.Fatal("right is not registered")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register after a lookup didn't panic")
+		}
+		if _, exists := LookupKey("zz"); exists {
+			t.Error("motion registered after a lookup was added")
+		}
+	}()
+
+	Register(&MotionSpec{
+		Group:   "Late Movement",
+		Keys:    []Binding{{Key: "zz", Direction: "late_motion", Score: 1}},
+		Handler: func(req Request) Result { return Result{Row: req.Row, Col: req.Col} },
+	})
+}
@@ -3,25 +3,26 @@ package movement
 import "boba-vim/internal/utils"
 
 // HandleWordMovement handles word-based movements (w, W, b, B, e, E, ge, gE)
-func HandleWordMovement(direction string, currentRow, currentCol int, textGrid [][]string) (int, int, int) {
+func HandleWordMovement(direction string, currentRow, currentCol int, textGrid [][]string, opts Options) (int, int, int) {
+	keywords := opts.Keywords()
 	newRow, newCol := currentRow, currentCol
 	newPreferredColumn := 0
 
 	switch direction {
 	case "word_forward", "word_forward_space":
-		newRow, newCol = FindWordForward(currentRow, currentCol, textGrid, direction == "word_forward_space")
+		newRow, newCol = FindWordForward(currentRow, currentCol, textGrid, direction == "word_forward_space", keywords)
 		newPreferredColumn = newCol
 	case "word_backward", "word_backward_space":
-		newRow, newCol = FindWordBackward(currentRow, currentCol, textGrid, direction == "word_backward_space")
+		newRow, newCol = FindWordBackward(currentRow, currentCol, textGrid, direction == "word_backward_space", keywords)
 		newPreferredColumn = newCol
 	case "word_end":
-		newRow, newCol = FindWordEnd(currentRow, currentCol, textGrid)
+		newRow, newCol = FindWordEnd(currentRow, currentCol, textGrid, keywords)
 		newPreferredColumn = newCol
 	case "word_end_space":
 		newRow, newCol = FindWordEndSpace(currentRow, currentCol, textGrid)
 		newPreferredColumn = newCol
 	case "word_end_prev":
-		newRow, newCol = FindWordEndPrev(currentRow, currentCol, textGrid)
+		newRow, newCol = FindWordEndPrev(currentRow, currentCol, textGrid, keywords)
 		newPreferredColumn = newCol
 	case "word_end_prev_space":
 		newRow, newCol = FindWordEndPrevSpace(currentRow, currentCol, textGrid)
@@ -32,7 +33,7 @@ func HandleWordMovement(direction string, currentRow, currentCol int, textGrid [
 }
 
 // FindWordForward finds next word forward
-func FindWordForward(row, col int, textGrid [][]string, spaceSeparated bool) (int, int) {
+func FindWordForward(row, col int, textGrid [][]string, spaceSeparated bool, keywords *KeywordSet) (int, int) {
 	if row < 0 || row >= len(textGrid) {
 		return row, col
 	}
@@ -54,16 +55,16 @@ func FindWordForward(row, col int, textGrid [][]string, spaceSeparated bool) (in
 		if currentCol < len(textGrid[currentRow]) {
 			currentChar := textGrid[currentRow][currentCol]
 
-			if utils.IsWordChar(currentChar) {
+			if keywords.IsWordChar(currentChar) {
 				// Skip current word (alphanumeric + underscore)
 				for currentRow < len(textGrid) && currentCol < len(textGrid[currentRow]) {
 					char := textGrid[currentRow][currentCol]
-					if !utils.IsWordChar(char) {
+					if !keywords.IsWordChar(char) {
 						break
 					}
 					currentCol++
 				}
-			} else if utils.IsPunctuation(currentChar) {
+			} else if keywords.IsPunctuation(currentChar) {
 				// Skip current punctuation character (vim treats each punctuation as separate word)
 				currentCol++
 			} else if utils.IsSpace(currentChar) {
@@ -108,7 +109,7 @@ func FindWordForward(row, col int, textGrid [][]string, spaceSeparated bool) (in
 }
 
 // FindWordBackward finds previous word backward
-func FindWordBackward(row, col int, textGrid [][]string, spaceSeparated bool) (int, int) {
+func FindWordBackward(row, col int, textGrid [][]string, spaceSeparated bool, keywords *KeywordSet) (int, int) {
 	if row < 0 || row >= len(textGrid) {
 		return row, col
 	}
@@ -166,16 +167,16 @@ func FindWordBackward(row, col int, textGrid [][]string, spaceSeparated bool) (i
 			}
 		} else {
 			// For b: vim-like word boundaries
-			if utils.IsWordChar(currentChar) {
+			if keywords.IsWordChar(currentChar) {
 				// Move to beginning of word
 				for currentCol > 0 {
 					prevChar := textGrid[currentRow][currentCol-1]
-					if !utils.IsWordChar(prevChar) {
+					if !keywords.IsWordChar(prevChar) {
 						break
 					}
 					currentCol--
 				}
-			} else if utils.IsPunctuation(currentChar) {
+			} else if keywords.IsPunctuation(currentChar) {
 				// Each punctuation is its own word, already at beginning
 			}
 		}
@@ -193,7 +194,7 @@ func FindWordBackward(row, col int, textGrid [][]string, spaceSeparated bool) (i
 }
 
 // FindWordEnd finds end of word
-func FindWordEnd(row, col int, textGrid [][]string) (int, int) {
+func FindWordEnd(row, col int, textGrid [][]string, keywords *KeywordSet) (int, int) {
 	if row < 0 || row >= len(textGrid) {
 		return row, col
 	}
@@ -229,11 +230,11 @@ func FindWordEnd(row, col int, textGrid [][]string) (int, int) {
 	if currentRow < len(textGrid) && currentCol < len(textGrid[currentRow]) {
 		currentChar := textGrid[currentRow][currentCol]
 
-		if utils.IsWordChar(currentChar) {
+		if keywords.IsWordChar(currentChar) {
 			// Check if we're already at the end of a word
 			isAtWordEnd := (currentCol == len(textGrid[currentRow])-1) ||
 				(currentCol+1 < len(textGrid[currentRow]) &&
-					!utils.IsWordChar(textGrid[currentRow][currentCol+1]))
+					!keywords.IsWordChar(textGrid[currentRow][currentCol+1]))
 
 			if isAtWordEnd {
 				// Already at end of word, move to end of next word
@@ -263,16 +264,16 @@ func FindWordEnd(row, col int, textGrid [][]string) (int, int) {
 				// Now find end of this word
 				if currentRow < len(textGrid) && currentCol < len(textGrid[currentRow]) {
 					currentChar = textGrid[currentRow][currentCol]
-					if utils.IsWordChar(currentChar) {
+					if keywords.IsWordChar(currentChar) {
 						// Move to end of word
 						for currentCol < len(textGrid[currentRow])-1 {
 							nextChar := textGrid[currentRow][currentCol+1]
-							if !utils.IsWordChar(nextChar) {
+							if !keywords.IsWordChar(nextChar) {
 								break
 							}
 							currentCol++
 						}
-					} else if utils.IsPunctuation(currentChar) {
+					} else if keywords.IsPunctuation(currentChar) {
 						// Punctuation is its own word, already at end
 					}
 				}
@@ -280,17 +281,17 @@ func FindWordEnd(row, col int, textGrid [][]string) (int, int) {
 				// Not at end of word, move to end of current word
 				for currentCol < len(textGrid[currentRow])-1 {
 					nextChar := textGrid[currentRow][currentCol+1]
-					if !utils.IsWordChar(nextChar) {
+					if !keywords.IsWordChar(nextChar) {
 						break
 					}
 					currentCol++
 				}
 			}
-		} else if utils.IsPunctuation(currentChar) {
+		} else if keywords.IsPunctuation(currentChar) {
 			// Check if we're already at the end of a punctuation sequence
 			isAtPuncEnd := (currentCol == len(textGrid[currentRow])-1) ||
 				(currentCol+1 < len(textGrid[currentRow]) &&
-					!utils.IsPunctuation(textGrid[currentRow][currentCol+1]))
+					!keywords.IsPunctuation(textGrid[currentRow][currentCol+1]))
 
 			if isAtPuncEnd {
 				// Move to next word
@@ -319,10 +320,10 @@ func FindWordEnd(row, col int, textGrid [][]string) (int, int) {
 				// Find end of this word
 				if currentRow < len(textGrid) && currentCol < len(textGrid[currentRow]) {
 					currentChar = textGrid[currentRow][currentCol]
-					if utils.IsWordChar(currentChar) {
+					if keywords.IsWordChar(currentChar) {
 						for currentCol < len(textGrid[currentRow])-1 {
 							nextChar := textGrid[currentRow][currentCol+1]
-							if !utils.IsWordChar(nextChar) {
+							if !keywords.IsWordChar(nextChar) {
 								break
 							}
 							currentCol++
@@ -333,7 +334,7 @@ func FindWordEnd(row, col int, textGrid [][]string) (int, int) {
 				// Move to end of current punctuation sequence
 				for currentCol < len(textGrid[currentRow])-1 {
 					nextChar := textGrid[currentRow][currentCol+1]
-					if !utils.IsPunctuation(nextChar) {
+					if !keywords.IsPunctuation(nextChar) {
 						break
 					}
 					currentCol++
@@ -424,7 +425,7 @@ func FindWordEndSpace(row, col int, textGrid [][]string) (int, int) {
 }
 
 // FindWordEndPrev finds end of previous word
-func FindWordEndPrev(row, col int, textGrid [][]string) (int, int) {
+func FindWordEndPrev(row, col int, textGrid [][]string, keywords *KeywordSet) (int, int) {
 	if row < 0 || row >= len(textGrid) {
 		return row, col
 	}
@@ -438,19 +439,19 @@ func FindWordEndPrev(row, col int, textGrid [][]string) (int, int) {
 		currentChar := textGrid[currentRow][currentCol]
 
 		// If we're in the middle of a word, move to its beginning first
-		if utils.IsWordChar(currentChar) {
+		if keywords.IsWordChar(currentChar) {
 			for currentCol > 0 {
 				prevChar := textGrid[currentRow][currentCol-1]
-				if !utils.IsWordChar(prevChar) {
+				if !keywords.IsWordChar(prevChar) {
 					break
 				}
 				currentCol--
 			}
-		} else if utils.IsPunctuation(currentChar) {
+		} else if keywords.IsPunctuation(currentChar) {
 			// If we're on punctuation, move to beginning of punctuation sequence
 			for currentCol > 0 {
 				prevChar := textGrid[currentRow][currentCol-1]
-				if !utils.IsPunctuation(prevChar) {
+				if !keywords.IsPunctuation(prevChar) {
 					break
 				}
 				currentCol--
@@ -498,20 +499,20 @@ func FindWordEndPrev(row, col int, textGrid [][]string) (int, int) {
 	if currentRow >= 0 && currentCol >= 0 && currentRow < len(textGrid) && currentCol < len(textGrid[currentRow]) {
 		currentChar := textGrid[currentRow][currentCol]
 
-		if utils.IsWordChar(currentChar) {
+		if keywords.IsWordChar(currentChar) {
 			// Find end of this word
 			for currentCol < len(textGrid[currentRow])-1 {
 				nextChar := textGrid[currentRow][currentCol+1]
-				if !utils.IsWordChar(nextChar) {
+				if !keywords.IsWordChar(nextChar) {
 					break
 				}
 				currentCol++
 			}
-		} else if utils.IsPunctuation(currentChar) {
+		} else if keywords.IsPunctuation(currentChar) {
 			// Find end of punctuation sequence
 			for currentCol < len(textGrid[currentRow])-1 {
 				nextChar := textGrid[currentRow][currentCol+1]
-				if !utils.IsPunctuation(nextChar) {
+				if !keywords.IsPunctuation(nextChar) {
 					break
 				}
 				currentCol++
@@ -0,0 +1,116 @@
+package multiplayer
+
+import (
+	"math/rand"
+)
+
+// moldSteps are the cells a mold can step to from where it is
+var moldSteps = [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+// SpawnMolds places count pearl molds on free cells, keeping them at least minDistance cells
+// (in rows plus columns) away from every player when the map allows it
+func (gs *GameState) SpawnMolds(count, minDistance int) []Position {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
+	var far, near []Position
+	for rowIdx := range gs.GameMap {
+		for colIdx := range gs.GameMap[rowIdx] {
+			if gs.GameMap[rowIdx][colIdx] != EMPTY || gs.isOccupied(rowIdx, colIdx) {
+				continue
+			}
+			cell := Position{Row: rowIdx, Col: colIdx}
+			if gs.distanceToPlayers(cell) >= minDistance {
+				far = append(far, cell)
+			} else {
+				near = append(near, cell)
+			}
+		}
+	}
+	rand.Shuffle(len(far), func(i, j int) { far[i], far[j] = far[j], far[i] })
+	rand.Shuffle(len(near), func(i, j int) { near[i], near[j] = near[j], near[i] })
+
+	for _, cell := range append(far, near...) {
+		if count <= 0 {
+			break
+		}
+		gs.GameMap[cell.Row][cell.Col] = PEARL_MOLD
+		gs.Molds = append(gs.Molds, cell)
+		count--
+	}
+
+	molds := make([]Position, len(gs.Molds))
+	copy(molds, gs.Molds)
+	return molds
+}
+
+// MoveMolds steps every mold to a random free neighbouring cell. Molds may step onto players but
+// not onto the pearl or another mold; a mold with nowhere to go stays put.
+func (gs *GameState) MoveMolds() []Position {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
+	for i, mold := range gs.Molds {
+		var options []Position
+		for _, step := range moldSteps {
+			row, col := mold.Row+step[0], mold.Col+step[1]
+			if row < 0 || row >= len(gs.GameMap) || col < 0 || col >= len(gs.GameMap[row]) {
+				continue
+			}
+			if gs.GameMap[row][col] == EMPTY {
+				options = append(options, Position{Row: row, Col: col})
+			}
+		}
+		if len(options) == 0 {
+			continue
+		}
+
+		next := options[rand.Intn(len(options))]
+		gs.GameMap[mold.Row][mold.Col] = EMPTY
+		gs.GameMap[next.Row][next.Col] = PEARL_MOLD
+		gs.Molds[i] = next
+	}
+
+	molds := make([]Position, len(gs.Molds))
+	copy(molds, gs.Molds)
+	return molds
+}
+
+// GetMoldPositions returns a copy of every mold's position
+func (gs *GameState) GetMoldPositions() []Position {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+
+	molds := make([]Position, len(gs.Molds))
+	copy(molds, gs.Molds)
+	return molds
+}
+
+// MoldAt reports whether a mold is on the given cell
+func (gs *GameState) MoldAt(row, col int) bool {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+
+	for _, mold := range gs.Molds {
+		if mold.Row == row && mold.Col == col {
+			return true
+		}
+	}
+	return false
+}
+
+// distanceToPlayers returns the distance in rows plus columns from a cell to the nearest player.
+// The caller must hold the lock.
+func (gs *GameState) distanceToPlayers(cell Position) int {
+	nearest := -1
+	for _, pos := range gs.Players {
+		distance := Max(cell.Row-pos.Row, pos.Row-cell.Row) + Max(cell.Col-pos.Col, pos.Col-cell.Col)
+		if nearest < 0 || distance < nearest {
+			nearest = distance
+		}
+	}
+	if nearest < 0 {
+		return len(gs.GameMap) + 1
+	}
+	return nearest
+}
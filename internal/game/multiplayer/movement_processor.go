@@ -1,6 +1,7 @@
 package multiplayer
 
 import (
+	"boba-vim/internal/game/movement"
 	"boba-vim/internal/utils"
 )
 
@@ -14,8 +15,8 @@ type MovementResult struct {
 
 // MovementCalculator interface to avoid circular imports
 type MovementCalculator interface {
-	CalculateNewPosition(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string, preferredColumn int) (*MovementResult, error)
-	CalculateNewPositionWithCount(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string, preferredColumn int, count int, hasExplicitCount bool) (*MovementResult, error)
+	CalculateNewPosition(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string, preferredColumn int, opts movement.Options) (*MovementResult, error)
+	CalculateNewPositionWithCount(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string, preferredColumn int, count int, hasExplicitCount bool, opts movement.Options) (*MovementResult, error)
 }
 
 // Global movement calculator instance (to be set by the game package)
@@ -27,105 +28,27 @@ func SetMovementCalculator(calc MovementCalculator) {
 }
 
 // ProcessMove processes a move for multiplayer and returns new position, preferred column, and score
-func ProcessMove(gameState *GameState, currentRow, currentCol int, direction string, count int, hasExplicitCount bool, preferredColumn int) (int, int, int, int) {
+func ProcessMove(gameState *GameState, currentRow, currentCol int, direction string, count int, hasExplicitCount bool, preferredColumn int, opts movement.Options) (int, int, int, int) {
 	utils.Debug("MULTIPLAYER ProcessMove: direction=%s, count=%d, position=(%d,%d)", direction, count, currentRow, currentCol)
 	
 	// Get text grid and game map for movement calculation
 	textGrid := gameState.GetTextGrid()
 	gameMap := gameState.GetGameMap()
 	
-	// Use the same movement logic as single-player mode
-	var movementResult *MovementResult
-	var err error
-	
-	if count == 1 {
-		// Single move - process normally (same logic as solo game)
-		if direction == "file_end" || direction == "file_start" {
-			// Use count-aware function for G commands even with count=1
-			movementResult, err = movementCalc.CalculateNewPositionWithCount(
-				direction,
-				currentRow,
-				currentCol,
-				gameMap,
-				textGrid,
-				preferredColumn, // Use actual preferred column
-				count,
-				hasExplicitCount,
-			)
-		} else {
-			// For regular movements, use standard function
-			movementResult, err = movementCalc.CalculateNewPosition(
-				direction,
-				currentRow,
-				currentCol,
-				gameMap,
-				textGrid,
-				preferredColumn, // Use actual preferred column
-			)
-		}
-	} else {
-		// Multiplier move - same logic as solo game
-		if direction == "file_end" || direction == "file_start" {
-			// For G commands, use absolute positioning directly
-			movementResult, err = movementCalc.CalculateNewPositionWithCount(
-				direction,
-				currentRow,
-				currentCol,
-				gameMap,
-				textGrid,
-				preferredColumn, // Use actual preferred column
-				count,
-				hasExplicitCount,
-			)
-		} else {
-			// For other movements, iterate count times - optimized
-			utils.Debug("Iterating %s movement %d times", direction, count)
-			tempRow := currentRow
-			tempCol := currentCol
-			tempPreferredColumn := preferredColumn
-			var finalResult *MovementResult
-			
-			for i := 0; i < count; i++ {
-				tempResult, tempErr := movementCalc.CalculateNewPosition(
-					direction,
-					tempRow,
-					tempCol,
-					gameMap,
-					textGrid,
-					tempPreferredColumn,
-				)
-				
-				if tempErr != nil {
-					err = tempErr
-					utils.Error("Movement calculation error at iteration %d: %v", i+1, tempErr)
-					break
-				}
-				
-				// If movement is blocked, stop here
-				if !tempResult.IsValid {
-					utils.Debug("Movement blocked at iteration %d/%d", i+1, count)
-					break
-				}
-				
-				// Update temporary position and preferred column for next iteration
-				tempRow = tempResult.NewRow
-				tempCol = tempResult.NewCol
-				tempPreferredColumn = tempResult.PreferredColumn
-				finalResult = tempResult
-			}
-			
-			if finalResult != nil {
-				movementResult = finalResult
-			} else {
-				// No valid moves were made, create a blocked result
-				movementResult = &MovementResult{
-					NewRow:         currentRow,
-					NewCol:         currentCol,
-					PreferredColumn: preferredColumn, // Keep original preferred column
-					IsValid:        false,
-				}
-			}
-		}
+	// Use the same movement logic as single-player mode: the motion applies the count itself
+	movementResult, err := movementCalc.CalculateNewPositionWithCount(
+		direction,
+		currentRow,
+		currentCol,
+		gameMap,
+		textGrid,
+		preferredColumn, // Use actual preferred column
+		count,
+		hasExplicitCount,
+		opts,
+	)
+	if err != nil {
+		utils.Error("Movement calculation error for %s (count %d): %v", direction, count, err)
 	}
 	
 	if err != nil || !movementResult.IsValid {
@@ -30,23 +30,36 @@ func (gs *GameState) CollectPearl(playerRow, playerCol int) int {
 	// Clear current pearl
 	gs.GameMap[gs.PearlRow][gs.PearlCol] = EMPTY
 	
-	// Place new pearl, avoiding both players
+	// Place new pearl, avoiding every player
 	gs.PearlRow, gs.PearlCol = gs.placeNewPearl()
 	
 	return pearlScore
 }
 
-// placeNewPearl places a new pearl avoiding both players
+// RemovePearl takes the pearl off the map for good, for games that aren't played for pearls
+func (gs *GameState) RemovePearl() {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+	
+	if gs.PearlRow >= 0 && gs.PearlRow < len(gs.GameMap) && gs.PearlCol >= 0 && gs.PearlCol < len(gs.GameMap[gs.PearlRow]) {
+		gs.GameMap[gs.PearlRow][gs.PearlCol] = EMPTY
+	}
+	gs.PearlRow, gs.PearlCol = -1, -1
+}
+
+// placeNewPearl places a new pearl avoiding every player
 func (gs *GameState) placeNewPearl() (int, int) {
-	// Try to place pearl avoiding both players
+	// Try to place pearl avoiding all players
 	for attempts := 0; attempts < 100; attempts++ {
 		row, col := placePearlInMap(gs.GameMap, -1, -1)
 		
-		// Check if it's not at either player's position
-		if (row != gs.Player1Row || col != gs.Player1Col) && 
-		   (row != gs.Player2Row || col != gs.Player2Col) {
+		// Check if it's not at any player's position
+		if !gs.isOccupied(row, col) {
 			return row, col
 		}
+		
+		// Players aren't on the map, so undo the pearl before retrying
+		gs.GameMap[row][col] = EMPTY
 	}
 	
 	// Fallback: place anywhere
@@ -1,53 +1,104 @@
 package multiplayer
 
-// SetPlayerPosition sets player 1's position
-func (gs *GameState) SetPlayerPosition(row, col int) {
+// AddPlayer seats a new player at the given position and returns their seat
+func (gs *GameState) AddPlayer(row, col int) int {
 	gs.mutex.Lock()
 	defer gs.mutex.Unlock()
 	
-	// Clear old position
-	if gs.Player1Row < len(gs.GameMap) && gs.Player1Row >= 0 && len(gs.GameMap[gs.Player1Row]) > 0 && gs.Player1Col < len(gs.GameMap[gs.Player1Row]) && gs.Player1Col >= 0 {
-		gs.GameMap[gs.Player1Row][gs.Player1Col] = EMPTY
+	gs.Players = append(gs.Players, Position{Row: row, Col: col})
+	return len(gs.Players) - 1
+}
+
+// SetPlayerPosition sets the position of the player in the given seat
+func (gs *GameState) SetPlayerPosition(seat, row, col int) {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+	
+	if seat < 0 || seat >= len(gs.Players) {
+		return
+	}
+	gs.Players[seat] = Position{Row: row, Col: col}
+}
+
+// GetPlayerPosition returns the position of the player in the given seat
+func (gs *GameState) GetPlayerPosition(seat int) Position {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+	
+	if seat < 0 || seat >= len(gs.Players) {
+		return Position{}
 	}
+	return gs.Players[seat]
+}
+
+// GetPlayerPositions returns a copy of every seated player's position
+func (gs *GameState) GetPlayerPositions() []Position {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
 	
-	// Set new position
-	gs.Player1Row = row
-	gs.Player1Col = col
-	if row < len(gs.GameMap) && row >= 0 && len(gs.GameMap[row]) > 0 && col < len(gs.GameMap[row]) && col >= 0 {
-		gs.GameMap[row][col] = PLAYER
+	positions := make([]Position, len(gs.Players))
+	copy(positions, gs.Players)
+	return positions
+}
+
+// PlayerCount returns the number of seated players
+func (gs *GameState) PlayerCount() int {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+	
+	return len(gs.Players)
+}
+
+// isOccupied reports whether any player stands on the given cell. The caller must hold the lock.
+func (gs *GameState) isOccupied(row, col int) bool {
+	for _, pos := range gs.Players {
+		if pos.Row == row && pos.Col == col {
+			return true
+		}
 	}
+	return false
 }
 
-// SetPlayer2Position sets player 2's position
-func (gs *GameState) SetPlayer2Position(row, col int) {
+// SetPlayerTeam puts the player in the given seat on a team. Pearls collected by any player of a
+// team count for the whole team.
+func (gs *GameState) SetPlayerTeam(seat, team int) {
 	gs.mutex.Lock()
 	defer gs.mutex.Unlock()
 	
-	// Clear old position
-	if gs.Player2Row < len(gs.GameMap) && gs.Player2Row >= 0 && len(gs.GameMap[gs.Player2Row]) > 0 && gs.Player2Col < len(gs.GameMap[gs.Player2Row]) && gs.Player2Col >= 0 {
-		gs.GameMap[gs.Player2Row][gs.Player2Col] = EMPTY
+	if seat < 0 || seat >= len(gs.Players) {
+		return
 	}
-	
-	// Set new position
-	gs.Player2Row = row
-	gs.Player2Col = col
-	if row < len(gs.GameMap) && row >= 0 && len(gs.GameMap[row]) > 0 && col < len(gs.GameMap[row]) && col >= 0 {
-		gs.GameMap[row][col] = PLAYER // Both players use the same value for now
+	for len(gs.Teams) < len(gs.Players) {
+		gs.Teams = append(gs.Teams, 0)
 	}
+	gs.Teams[seat] = team
 }
 
-// GetPlayerPosition returns player 1's position
-func (gs *GameState) GetPlayerPosition() Position {
+// GetPlayerTeam returns the team of the player in the given seat, or 0 if they play alone
+func (gs *GameState) GetPlayerTeam(seat int) int {
 	gs.mutex.RLock()
 	defer gs.mutex.RUnlock()
 	
-	return Position{Row: gs.Player1Row, Col: gs.Player1Col}
+	if seat < 0 || seat >= len(gs.Teams) {
+		return 0
+	}
+	return gs.Teams[seat]
 }
 
-// GetPlayer2Position returns player 2's position
-func (gs *GameState) GetPlayer2Position() Position {
+// Teammates returns the seats of the other players on the team of the given seat
+func (gs *GameState) Teammates(seat int) []int {
 	gs.mutex.RLock()
 	defer gs.mutex.RUnlock()
 	
-	return Position{Row: gs.Player2Row, Col: gs.Player2Col}
-}
\ No newline at end of file
+	if seat < 0 || seat >= len(gs.Teams) || gs.Teams[seat] == 0 {
+		return nil
+	}
+	
+	var teammates []int
+	for other, team := range gs.Teams {
+		if other != seat && team == gs.Teams[seat] {
+			teammates = append(teammates, other)
+		}
+	}
+	return teammates
+}
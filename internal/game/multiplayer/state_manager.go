@@ -14,15 +14,16 @@ const (
 	PEARL_MOLD     = constant.PEARL_MOLD
 )
 
-// GameState represents the state of a multiplayer game
+// GameState represents the state of a multiplayer game.
+// Player positions are tracked per seat and never written into GameMap, so other players
+// don't read as ENEMY cells to the movement code and several players can share a cell.
 type GameState struct {
 	TextGrid        [][]string
 	GameMap         [][]int
 	MapID           int
-	Player1Row      int
-	Player1Col      int
-	Player2Row      int
-	Player2Col      int
+	Players         []Position // Indexed by seat
+	Teams           []int      // Team of each seat, indexed by seat; empty when everyone plays alone
+	Molds           []Position // Pearl molds roaming the map, also marked in GameMap
 	PearlRow        int
 	PearlCol        int
 	mutex           sync.RWMutex
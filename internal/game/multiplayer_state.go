@@ -1,6 +1,7 @@
 package game
 
 import (
+	"boba-vim/internal/game/movement"
 	"boba-vim/internal/game/multiplayer"
 	"boba-vim/internal/utils"
 )
@@ -16,8 +17,8 @@ var ProcessMove = multiplayer.ProcessMove
 // Movement calculator implementation for multiplayer
 type gameMovementCalculator struct{}
 
-func (gmc *gameMovementCalculator) CalculateNewPosition(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string, preferredColumn int) (*multiplayer.MovementResult, error) {
-	result, err := CalculateNewPosition(direction, currentRow, currentCol, gameMap, textGrid, preferredColumn)
+func (gmc *gameMovementCalculator) CalculateNewPosition(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string, preferredColumn int, opts movement.Options) (*multiplayer.MovementResult, error) {
+	result, err := CalculateNewPosition(direction, currentRow, currentCol, gameMap, textGrid, preferredColumn, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -29,8 +30,8 @@ func (gmc *gameMovementCalculator) CalculateNewPosition(direction string, curren
 	}, nil
 }
 
-func (gmc *gameMovementCalculator) CalculateNewPositionWithCount(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string, preferredColumn int, count int, hasExplicitCount bool) (*multiplayer.MovementResult, error) {
-	result, err := CalculateNewPositionWithCount(direction, currentRow, currentCol, gameMap, textGrid, preferredColumn, count, hasExplicitCount)
+func (gmc *gameMovementCalculator) CalculateNewPositionWithCount(direction string, currentRow, currentCol int, gameMap [][]int, textGrid [][]string, preferredColumn int, count int, hasExplicitCount bool, opts movement.Options) (*multiplayer.MovementResult, error) {
+	result, err := CalculateNewPositionWithCount(direction, currentRow, currentCol, gameMap, textGrid, preferredColumn, count, hasExplicitCount, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,93 @@
+package game
+
+import (
+	"math/rand"
+
+	"boba-vim/internal/constant"
+)
+
+// Seeded games let players who never meet play the same game. Their pearls, enemies and pearl
+// mold are placed from the seed and the number of pearls collected so far rather than at random,
+// so every player of a seed gets the same cells whenever the board allows: a cell the player
+// stands on is skipped for the next one in line. Only the pearl mold's timed wandering stays random.
+
+// Streams of a seed, so pearls, enemies and the pearl mold each get their own cell order
+const (
+	seedStreamPearl = iota
+	seedStreamEnemies
+	seedStreamMold
+)
+
+// InitializeSeededGameSession creates a new game on a specific map, laid out from a seed
+func InitializeSeededGameSession(mapID int, seed int64) map[string]interface{} {
+	textGrid := createTextLinesWithMap(mapID)
+	gameMap := createEmptyGameMap(textGrid)
+	PlaceSeededPearl(gameMap, 0, 0, seed, 0)
+
+	// Hard and medium maps get their enemies, and hard maps their pearl mold, like unseeded games
+	gameMapData := constant.GetMapByID(mapID)
+	if gameMapData != nil {
+		switch gameMapData.Difficulty {
+		case "hard":
+			placeSeeded(gameMap, 0, 0, ENEMY, 5, seededCells(gameMap, seed, seedStreamEnemies, 0))
+			placeSeeded(gameMap, 0, 0, PEARL_MOLD, 1, seededCells(gameMap, seed, seedStreamMold, 0))
+		case "medium":
+			placeSeeded(gameMap, 0, 0, ENEMY, 3, seededCells(gameMap, seed, seedStreamEnemies, 0))
+		}
+	}
+
+	return map[string]interface{}{
+		"text_grid":        textGrid,
+		"game_map":         gameMap,
+		"player_pos":       map[string]int{"row": 0, "col": 0},
+		"preferred_column": 0,
+		"map_id":           mapID,
+	}
+}
+
+// PlaceSeededPearl places the pearl of a seeded game that follows pearlsCollected collected pearls
+func PlaceSeededPearl(gameMap [][]int, excludeRow, excludeCol int, seed int64, pearlsCollected int) {
+	placeSeeded(gameMap, excludeRow, excludeCol, PEARL, 1, seededCells(gameMap, seed, seedStreamPearl, pearlsCollected))
+}
+
+// RepositionSeededEnemies moves the enemies of a seeded game to where they stand after
+// pearlsCollected collected pearls
+func RepositionSeededEnemies(gameMap [][]int, playerRow, playerCol, numEnemies int, seed int64, pearlsCollected int) {
+	for rowIdx := range gameMap {
+		for colIdx := range gameMap[rowIdx] {
+			if gameMap[rowIdx][colIdx] == ENEMY {
+				gameMap[rowIdx][colIdx] = EMPTY
+			}
+		}
+	}
+
+	placeSeeded(gameMap, playerRow, playerCol, ENEMY, numEnemies, seededCells(gameMap, seed, seedStreamEnemies, pearlsCollected))
+}
+
+// seededCells returns every cell of the map in an order fixed by the seed, stream and step
+func seededCells(gameMap [][]int, seed int64, stream, step int) [][2]int {
+	var cells [][2]int
+	for rowIdx := range gameMap {
+		for colIdx := range gameMap[rowIdx] {
+			cells = append(cells, [2]int{rowIdx, colIdx})
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed ^ int64(step)<<8 ^ int64(stream)))
+	rng.Shuffle(len(cells), func(i, j int) { cells[i], cells[j] = cells[j], cells[i] })
+	return cells
+}
+
+// placeSeeded puts an item on the first count empty cells of order, skipping the player's cell
+func placeSeeded(gameMap [][]int, playerRow, playerCol, item, count int, order [][2]int) {
+	for _, cell := range order {
+		if count <= 0 {
+			return
+		}
+		if gameMap[cell[0]][cell[1]] != EMPTY || (cell[0] == playerRow && cell[1] == playerCol) {
+			continue
+		}
+		gameMap[cell[0]][cell[1]] = item
+		count--
+	}
+}
@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"boba-vim/internal/config"
+	"boba-vim/internal/services/challenge"
 	"boba-vim/internal/handlers/game_handler_modules"
+	"boba-vim/internal/services/cluster"
+	"boba-vim/internal/services/friends"
 	gameService "boba-vim/internal/services/game"
+	"boba-vim/internal/services/lobby"
 	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/services/tournament"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -14,41 +19,81 @@ type GameHandler struct {
 	gameService        *gameService.GameService
 	matchmakingService *matchmaking.MatchmakingService
 	multiplayerGame    *gameService.MultiplayerGameService
+	lobbyService       *lobby.LobbyService
+	tournamentService  *tournament.TournamentService
+	challengeService   *challenge.ChallengeService
+	friendsService     *friends.FriendsService
 	cfg                *config.Config
 	db                 *gorm.DB
 }
 
-func NewGameHandler(db *gorm.DB) *GameHandler {
+func NewGameHandler(db *gorm.DB, node *cluster.Node) *GameHandler {
 	cfg := config.Load()
 	
 	// Create multiplayer game service with its own WebSocket manager
-	multiplayerGame := gameService.NewMultiplayerGameService(db, cfg, nil)
+	multiplayerGame := gameService.NewMultiplayerGameService(db, cfg, node, nil)
 	
 	// Create matchmaking service with multiplayer game service
-	matchmakingService := matchmaking.NewMatchmakingService(db, multiplayerGame)
+	matchmakingService := matchmaking.NewMatchmakingService(db, node, multiplayerGame)
+	
+	// Create lobby service; lobby members are notified over the matchmaking WebSocket
+	lobbyService := lobby.NewLobbyService(cfg.BaseURL, multiplayerGame, matchmakingService.WebSocketManager())
+	
+	// Create tournament service; its games are started like lobby games
+	tournamentService := tournament.NewTournamentService(db, node, multiplayerGame, matchmakingService.WebSocketManager())
+	tournamentService.Start()
+	
+	// Create challenge service; challenge games are solo games and players hear about results over the matchmaking WebSocket
+	challengeService := challenge.NewChallengeService(db, node, cfg.BaseURL, gameService.NewSessionService(db, cfg), matchmakingService.WebSocketManager())
+	challengeService.Start()
+	
+	// Create friends service; presence comes from matchmaking and the games being played
+	friendsService := friends.NewFriendsService(db, matchmakingService, multiplayerGame, matchmakingService.WebSocketManager())
 	
 	return &GameHandler{
 		gameService:        gameService.NewGameService(db, cfg, nil),
 		matchmakingService: matchmakingService,
 		multiplayerGame:    multiplayerGame,
+		lobbyService:       lobbyService,
+		tournamentService:  tournamentService,
+		challengeService:   challengeService,
+		friendsService:     friendsService,
 		cfg:                cfg,
 		db:                 db,
 	}
 }
 
-func NewGameHandlerWithPearlMold(db *gorm.DB, pearlMoldService *gameService.PearlMoldService) *GameHandler {
+func NewGameHandlerWithPearlMold(db *gorm.DB, pearlMoldService *gameService.PearlMoldService, node *cluster.Node) *GameHandler {
 	cfg := config.Load()
 	
 	// Create multiplayer game service with its own WebSocket manager
-	multiplayerGame := gameService.NewMultiplayerGameService(db, cfg, nil)
+	multiplayerGame := gameService.NewMultiplayerGameService(db, cfg, node, nil)
 	
 	// Create matchmaking service with multiplayer game service
-	matchmakingService := matchmaking.NewMatchmakingService(db, multiplayerGame)
+	matchmakingService := matchmaking.NewMatchmakingService(db, node, multiplayerGame)
+	
+	// Create lobby service; lobby members are notified over the matchmaking WebSocket
+	lobbyService := lobby.NewLobbyService(cfg.BaseURL, multiplayerGame, matchmakingService.WebSocketManager())
+	
+	// Create tournament service; its games are started like lobby games
+	tournamentService := tournament.NewTournamentService(db, node, multiplayerGame, matchmakingService.WebSocketManager())
+	tournamentService.Start()
+	
+	// Create challenge service; challenge games are solo games and players hear about results over the matchmaking WebSocket
+	challengeService := challenge.NewChallengeService(db, node, cfg.BaseURL, gameService.NewSessionService(db, cfg), matchmakingService.WebSocketManager())
+	challengeService.Start()
+	
+	// Create friends service; presence comes from matchmaking and the games being played
+	friendsService := friends.NewFriendsService(db, matchmakingService, multiplayerGame, matchmakingService.WebSocketManager())
 	
 	return &GameHandler{
 		gameService:        gameService.NewGameService(db, cfg, pearlMoldService),
 		matchmakingService: matchmakingService,
 		multiplayerGame:    multiplayerGame,
+		lobbyService:       lobbyService,
+		tournamentService:  tournamentService,
+		challengeService:   challengeService,
+		friendsService:     friendsService,
 		cfg:                cfg,
 		db:                 db,
 	}
@@ -83,6 +128,27 @@ func (gh *GameHandler) RestartGame(c *gin.Context) {
 	game_handler_modules.RestartGame(gh.gameService, c)
 }
 
+func (gh *GameHandler) UpdateDisplayOptions(c *gin.Context) {
+	game_handler_modules.UpdateDisplayOptions(gh.db, c)
+}
+
+// Vim Options Handlers
+func (gh *GameHandler) GetSessionVimOptions(c *gin.Context) {
+	game_handler_modules.GetSessionVimOptions(gh.db, c)
+}
+
+func (gh *GameHandler) UpdateSessionVimOptions(c *gin.Context) {
+	game_handler_modules.UpdateSessionVimOptions(gh.db, gh.gameService, c)
+}
+
+func (gh *GameHandler) GetPlayerVimOptions(c *gin.Context) {
+	game_handler_modules.GetPlayerVimOptions(gh.gameService, c)
+}
+
+func (gh *GameHandler) UpdatePlayerVimOptions(c *gin.Context) {
+	game_handler_modules.UpdatePlayerVimOptions(gh.gameService, c)
+}
+
 // Movement & Gameplay Handlers
 func (gh *GameHandler) MovePlayer(c *gin.Context) {
 	game_handler_modules.MovePlayer(gh.gameService, c)
@@ -161,6 +227,135 @@ func (gh *GameHandler) GetQueueStatus(c *gin.Context) {
 	gh.matchmakingService.GetQueueStatus(c)
 }
 
+// Ranked Map Pool Handlers
+func (gh *GameHandler) GetRankedMapPool(c *gin.Context) {
+	game_handler_modules.GetRankedMapPool(gh.matchmakingService, c)
+}
+
+func (gh *GameHandler) AddRankedMap(c *gin.Context) {
+	game_handler_modules.AddRankedMap(gh.matchmakingService, c)
+}
+
+func (gh *GameHandler) RemoveRankedMap(c *gin.Context) {
+	game_handler_modules.RemoveRankedMap(gh.matchmakingService, c)
+}
+
+// Private Lobby Handlers
+func (gh *GameHandler) CreateLobby(c *gin.Context) {
+	game_handler_modules.CreateLobby(gh.lobbyService, c)
+}
+
+func (gh *GameHandler) GetLobby(c *gin.Context) {
+	game_handler_modules.GetLobby(gh.lobbyService, c)
+}
+
+func (gh *GameHandler) JoinLobby(c *gin.Context) {
+	game_handler_modules.JoinLobby(gh.lobbyService, c)
+}
+
+func (gh *GameHandler) LeaveLobby(c *gin.Context) {
+	game_handler_modules.LeaveLobby(gh.lobbyService, c)
+}
+
+func (gh *GameHandler) UpdateLobbySettings(c *gin.Context) {
+	game_handler_modules.UpdateLobbySettings(gh.lobbyService, c)
+}
+
+func (gh *GameHandler) KickLobbyMember(c *gin.Context) {
+	game_handler_modules.KickLobbyMember(gh.lobbyService, c)
+}
+
+func (gh *GameHandler) StartLobby(c *gin.Context) {
+	game_handler_modules.StartLobby(gh.lobbyService, c)
+}
+
+// Tournament Handlers
+func (gh *GameHandler) GetTournaments(c *gin.Context) {
+	game_handler_modules.GetTournaments(gh.tournamentService, c)
+}
+
+func (gh *GameHandler) GetTournament(c *gin.Context) {
+	game_handler_modules.GetTournament(gh.tournamentService, c)
+}
+
+func (gh *GameHandler) RegisterForTournament(c *gin.Context) {
+	game_handler_modules.RegisterForTournament(gh.tournamentService, c)
+}
+
+func (gh *GameHandler) WithdrawFromTournament(c *gin.Context) {
+	game_handler_modules.WithdrawFromTournament(gh.tournamentService, c)
+}
+
+func (gh *GameHandler) CreateTournament(c *gin.Context) {
+	game_handler_modules.CreateTournament(gh.tournamentService, c)
+}
+
+func (gh *GameHandler) StartTournament(c *gin.Context) {
+	game_handler_modules.StartTournament(gh.tournamentService, c)
+}
+
+func (gh *GameHandler) CancelTournament(c *gin.Context) {
+	game_handler_modules.CancelTournament(gh.tournamentService, c)
+}
+
+func (gh *GameHandler) SettleTournamentMatch(c *gin.Context) {
+	game_handler_modules.SettleTournamentMatch(gh.tournamentService, c)
+}
+
+// Challenge Handlers
+func (gh *GameHandler) CreateChallenge(c *gin.Context) {
+	game_handler_modules.CreateChallenge(gh.challengeService, c)
+}
+
+func (gh *GameHandler) GetChallenges(c *gin.Context) {
+	game_handler_modules.GetChallenges(gh.challengeService, c)
+}
+
+func (gh *GameHandler) GetChallenge(c *gin.Context) {
+	game_handler_modules.GetChallenge(gh.challengeService, c)
+}
+
+func (gh *GameHandler) PlayChallenge(c *gin.Context) {
+	game_handler_modules.PlayChallenge(gh.challengeService, c)
+}
+
+func (gh *GameHandler) DeclineChallenge(c *gin.Context) {
+	game_handler_modules.DeclineChallenge(gh.challengeService, c)
+}
+
+func (gh *GameHandler) CancelChallenge(c *gin.Context) {
+	game_handler_modules.CancelChallenge(gh.challengeService, c)
+}
+
+// Friends Handlers
+func (gh *GameHandler) GetFriends(c *gin.Context) {
+	game_handler_modules.GetFriends(gh.friendsService, c)
+}
+
+func (gh *GameHandler) SendFriendRequest(c *gin.Context) {
+	game_handler_modules.SendFriendRequest(gh.friendsService, c)
+}
+
+func (gh *GameHandler) AcceptFriendRequest(c *gin.Context) {
+	game_handler_modules.AcceptFriendRequest(gh.friendsService, c)
+}
+
+func (gh *GameHandler) RemoveFriend(c *gin.Context) {
+	game_handler_modules.RemoveFriend(gh.friendsService, c)
+}
+
+func (gh *GameHandler) BlockPlayer(c *gin.Context) {
+	game_handler_modules.BlockPlayer(gh.friendsService, c)
+}
+
+func (gh *GameHandler) UnblockPlayer(c *gin.Context) {
+	game_handler_modules.UnblockPlayer(gh.friendsService, c)
+}
+
+func (gh *GameHandler) ChallengeFriend(c *gin.Context) {
+	game_handler_modules.ChallengeFriend(gh.friendsService, c)
+}
+
 // Multiplayer Game Handlers
 func (gh *GameHandler) GetMultiplayerGameState(c *gin.Context) {
 	game_handler_modules.GetMultiplayerGameState(gh.multiplayerGame, c)
@@ -183,6 +378,51 @@ func (gh *GameHandler) HandleMultiplayerGameWebSocket(c *gin.Context) {
 	game_handler_modules.HandleMultiplayerGameWebSocket(gh.multiplayerGame, c)
 }
 
+// Practice Handlers
+func (gh *GameHandler) GetPracticeBots(c *gin.Context) {
+	game_handler_modules.GetPracticeBots(c)
+}
+
+func (gh *GameHandler) StartPracticeGame(c *gin.Context) {
+	game_handler_modules.StartPracticeGame(gh.multiplayerGame, gh.matchmakingService, c)
+}
+
+// Rule Set Handlers
+func (gh *GameHandler) GetMultiplayerRuleSets(c *gin.Context) {
+	game_handler_modules.GetMultiplayerRuleSets(c)
+}
+
+// Chat Handlers
+func (gh *GameHandler) GetMultiplayerChatOptions(c *gin.Context) {
+	game_handler_modules.GetMultiplayerChatOptions(c)
+}
+
+func (gh *GameHandler) GetMultiplayerChatLog(c *gin.Context) {
+	game_handler_modules.GetMultiplayerChatLog(gh.multiplayerGame, c)
+}
+
+// Match Review Handlers
+func (gh *GameHandler) GetMatchReviews(c *gin.Context) {
+	game_handler_modules.GetMatchReviews(gh.multiplayerGame, c)
+}
+
+func (gh *GameHandler) GetMatchReview(c *gin.Context) {
+	game_handler_modules.GetMatchReview(gh.multiplayerGame, c)
+}
+
+func (gh *GameHandler) ResolveMatchReview(c *gin.Context) {
+	game_handler_modules.ResolveMatchReview(gh.multiplayerGame, c)
+}
+
+// Spectator Handlers
+func (gh *GameHandler) GetLiveMultiplayerGames(c *gin.Context) {
+	game_handler_modules.GetLiveMultiplayerGames(gh.multiplayerGame, c)
+}
+
+func (gh *GameHandler) HandleSpectatorWebSocket(c *gin.Context) {
+	game_handler_modules.HandleSpectatorWebSocket(gh.multiplayerGame, c)
+}
+
 // User Progress Handlers
 func (gh *GameHandler) GetCompletedMaps(c *gin.Context) {
 	game_handler_modules.GetCompletedMaps(gh.db, c)
@@ -194,6 +434,15 @@ func (gh *GameHandler) MigrateGuestProgress(c *gin.Context) {
 
 // Cleanup shuts down the game handler and its services
 func (gh *GameHandler) Cleanup() {
+	if gh.tournamentService != nil {
+		gh.tournamentService.Stop()
+	}
+	if gh.challengeService != nil {
+		gh.challengeService.Stop()
+	}
+	if gh.lobbyService != nil {
+		gh.lobbyService.Cleanup()
+	}
 	if gh.matchmakingService != nil {
 		gh.matchmakingService.Cleanup()
 	}
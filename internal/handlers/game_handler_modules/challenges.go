@@ -0,0 +1,176 @@
+package game_handler_modules
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"boba-vim/internal/services/challenge"
+	"boba-vim/internal/services/matchmaking"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultChallengeListLimit = 20
+	maxChallengeListLimit     = 100
+)
+
+// CreateChallenge handles a player challenging another player, or opening a challenge to share by link
+func CreateChallenge(challengeService *challenge.ChallengeService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to send a challenge"})
+		return
+	}
+
+	var request challenge.ChallengeSettings
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+
+	created, err := challengeService.CreateChallenge(playerID, username, request)
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"challenge": created,
+	})
+}
+
+// GetChallenges handles listing the challenges a player sent or received, optionally filtered by status
+func GetChallenges(challengeService *challenge.ChallengeService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultChallengeListLimit)))
+	if limit <= 0 || limit > maxChallengeListLimit {
+		limit = defaultChallengeListLimit
+	}
+
+	challenges, err := challengeService.ListChallenges(playerID, c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get challenges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"challenges": challenges,
+	})
+}
+
+// GetChallenge handles getting a challenge by code, with the results played so far
+func GetChallenge(challengeService *challenge.ChallengeService, c *gin.Context) {
+	found, err := challengeService.GetChallenge(c.Param("code"))
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"challenge": found,
+	})
+}
+
+// PlayChallenge handles a player starting their game of a challenge. The game is a solo game,
+// played through the usual game session.
+func PlayChallenge(challengeService *challenge.ChallengeService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to play a challenge"})
+		return
+	}
+
+	var request struct {
+		SelectedCharacter string `json:"selected_character"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+
+	result, err := challengeService.PlayChallenge(c.Param("code"), playerID, username, request.SelectedCharacter)
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	// Store session token so moves go to the challenge game
+	session := sessions.Default(c)
+	session.Set("game_session_token", result["session_token"])
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to save session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeclineChallenge handles the opponent turning a challenge down
+func DeclineChallenge(challengeService *challenge.ChallengeService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	if err := challengeService.DeclineChallenge(c.Param("code"), playerID); err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Challenge declined",
+	})
+}
+
+// CancelChallenge handles the challenger withdrawing a challenge
+func CancelChallenge(challengeService *challenge.ChallengeService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	if err := challengeService.CancelChallenge(c.Param("code"), playerID); err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Challenge cancelled",
+	})
+}
+
+// respondChallengeError maps challenge errors to HTTP responses
+func respondChallengeError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, challenge.ErrChallengeNotFound), errors.Is(err, challenge.ErrOpponentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, challenge.ErrNotChallengePlayer):
+		status = http.StatusForbidden
+	case errors.Is(err, challenge.ErrChallengerPlaysFirst), errors.Is(err, challenge.ErrAlreadyPlayed),
+		errors.Is(err, challenge.ErrChallengeClosed), errors.Is(err, challenge.ErrChallengeTaken):
+		status = http.StatusConflict
+	case errors.Is(err, challenge.ErrInvalidMap), errors.Is(err, challenge.ErrInvalidDays),
+		errors.Is(err, challenge.ErrChallengeSelf):
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to update the challenge"})
+		return
+	}
+
+	c.JSON(status, gin.H{"success": false, "error": err.Error()})
+}
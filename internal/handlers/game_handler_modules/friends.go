@@ -0,0 +1,225 @@
+package game_handler_modules
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"boba-vim/internal/services/friends"
+	"boba-vim/internal/services/matchmaking"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFriends handles listing a player's friends with their presence, their friend requests and
+// the players they blocked
+func GetFriends(friendsService *friends.FriendsService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to have friends"})
+		return
+	}
+
+	list, err := friendsService.ListFriends(playerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get friends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"friends": list,
+	})
+}
+
+// SendFriendRequest handles a player sending a friend request by username
+func SendFriendRequest(friendsService *friends.FriendsService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to add friends"})
+		return
+	}
+
+	var request struct {
+		Username string `json:"username" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+
+	friend, err := friendsService.SendRequest(playerID, username, request.Username)
+	if err != nil {
+		respondFriendsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"friend":  friend,
+	})
+}
+
+// AcceptFriendRequest handles a player accepting a friend request
+func AcceptFriendRequest(friendsService *friends.FriendsService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	friendID, ok := friendIDParam(c)
+	if !ok {
+		return
+	}
+
+	friend, err := friendsService.AcceptRequest(playerID, username, friendID)
+	if err != nil {
+		respondFriendsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"friend":  friend,
+	})
+}
+
+// RemoveFriend handles a player removing a friend, declining a request or withdrawing their own
+func RemoveFriend(friendsService *friends.FriendsService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	friendID, ok := friendIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := friendsService.RemoveFriend(playerID, friendID); err != nil {
+		respondFriendsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Friend removed",
+	})
+}
+
+// BlockPlayer handles a player blocking another player
+func BlockPlayer(friendsService *friends.FriendsService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	blockedID, ok := friendIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := friendsService.BlockPlayer(playerID, blockedID); err != nil {
+		respondFriendsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Player blocked",
+	})
+}
+
+// UnblockPlayer handles a player lifting a block
+func UnblockPlayer(friendsService *friends.FriendsService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	blockedID, ok := friendIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := friendsService.UnblockPlayer(playerID, blockedID); err != nil {
+		respondFriendsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Player unblocked",
+	})
+}
+
+// ChallengeFriend handles a player challenging an online friend to a duel. The friend is asked
+// to accept over the matchmaking WebSocket, like a match found in the queue.
+func ChallengeFriend(friendsService *friends.FriendsService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to challenge friends"})
+		return
+	}
+
+	friendID, ok := friendIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		SelectedCharacter string `json:"selected_character"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+
+	matchID, err := friendsService.ChallengeFriend(playerID, username, request.SelectedCharacter, friendID)
+	if err != nil {
+		respondFriendsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"match_id": matchID,
+		"message":  "Challenge sent",
+	})
+}
+
+// friendIDParam parses the player ID in the URL, responding with an error if it is invalid
+func friendIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid player ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondFriendsError maps friends errors to HTTP responses
+func respondFriendsError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, friends.ErrPlayerNotFound), errors.Is(err, friends.ErrRequestNotFound),
+		errors.Is(err, friends.ErrNotFriends), errors.Is(err, friends.ErrNotBlocked):
+		status = http.StatusNotFound
+	case errors.Is(err, friends.ErrAlreadyFriends), errors.Is(err, friends.ErrRequestPending),
+		errors.Is(err, friends.ErrFriendOffline), errors.Is(err, friends.ErrFriendBusy),
+		errors.Is(err, friends.ErrPlayerBusy):
+		status = http.StatusConflict
+	case errors.Is(err, friends.ErrPlayerBlocked):
+		status = http.StatusForbidden
+	case errors.Is(err, friends.ErrFriendSelf):
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to update friends"})
+		return
+	}
+
+	c.JSON(status, gin.H{"success": false, "error": err.Error()})
+}
@@ -0,0 +1,196 @@
+package game_handler_modules
+
+import (
+	"errors"
+	"net/http"
+
+	"boba-vim/internal/services/lobby"
+	"boba-vim/internal/services/matchmaking"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateLobby handles creating a private lobby hosted by the player
+func CreateLobby(lobbyService *lobby.LobbyService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to create a lobby"})
+		return
+	}
+
+	var request lobby.LobbyCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+	if request.SelectedCharacter == "" {
+		request.SelectedCharacter = "boba" // Default character
+	}
+
+	lobbyData, err := lobbyService.CreateLobby(playerID, username, request.SelectedCharacter, request.LobbySettingsUpdate)
+	if err != nil {
+		respondLobbyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"lobby":   lobbyData,
+	})
+}
+
+// GetLobby handles getting a lobby by its code
+func GetLobby(lobbyService *lobby.LobbyService, c *gin.Context) {
+	if _, _, err := matchmaking.ValidatePlayerSession(c); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	lobbyData, err := lobbyService.GetLobby(c.Param("code"))
+	if err != nil {
+		respondLobbyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"lobby":   lobbyData,
+	})
+}
+
+// JoinLobby handles joining a lobby with its code
+func JoinLobby(lobbyService *lobby.LobbyService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to join a lobby"})
+		return
+	}
+
+	var request lobby.LobbyJoinRequest
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+	if request.SelectedCharacter == "" {
+		request.SelectedCharacter = "boba" // Default character
+	}
+
+	lobbyData, err := lobbyService.JoinLobby(c.Param("code"), playerID, username, request.SelectedCharacter)
+	if err != nil {
+		respondLobbyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"lobby":   lobbyData,
+	})
+}
+
+// LeaveLobby handles leaving a lobby
+func LeaveLobby(lobbyService *lobby.LobbyService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	if err := lobbyService.LeaveLobby(c.Param("code"), playerID); err != nil {
+		respondLobbyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Left lobby",
+	})
+}
+
+// UpdateLobbySettings handles the host changing the lobby's map, pearl target, time limit or ranking
+func UpdateLobbySettings(lobbyService *lobby.LobbyService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	var request lobby.LobbySettingsUpdate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+
+	lobbyData, err := lobbyService.UpdateSettings(c.Param("code"), playerID, request)
+	if err != nil {
+		respondLobbyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"lobby":   lobbyData,
+	})
+}
+
+// KickLobbyMember handles the host removing a player from the lobby
+func KickLobbyMember(lobbyService *lobby.LobbyService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	var request lobby.LobbyKickRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.PlayerID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "player_id is required"})
+		return
+	}
+
+	lobbyData, err := lobbyService.KickMember(c.Param("code"), playerID, request.PlayerID)
+	if err != nil {
+		respondLobbyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"lobby":   lobbyData,
+	})
+}
+
+// StartLobby handles the host starting the lobby's match
+func StartLobby(lobbyService *lobby.LobbyService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	lobbyData, err := lobbyService.StartLobby(c.Param("code"), playerID)
+	if err != nil {
+		respondLobbyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"message":  "Match started",
+		"match_id": lobbyData.MatchID,
+		"lobby":    lobbyData,
+	})
+}
+
+// respondLobbyError maps lobby service errors to HTTP responses
+func respondLobbyError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, lobby.ErrLobbyNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, lobby.ErrNotLobbyHost):
+		status = http.StatusForbidden
+	case errors.Is(err, lobby.ErrLobbyFull), errors.Is(err, lobby.ErrLobbyNotOpen), errors.Is(err, lobby.ErrAlreadyInLobby):
+		status = http.StatusConflict
+	}
+
+	c.JSON(status, gin.H{"success": false, "error": err.Error()})
+}
@@ -0,0 +1,76 @@
+package game_handler_modules
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"boba-vim/internal/services/matchmaking"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRankedMapPool handles listing the maps ranked matches are played on
+func GetRankedMapPool(matchmakingService *matchmaking.MatchmakingService, c *gin.Context) {
+	maps, isDefault := matchmakingService.MapPool().Pool()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"maps":    maps,
+		"default": isDefault, // No maps were added yet, so every non-tutorial map is ranked
+	})
+}
+
+// AddRankedMap handles an admin adding a map to the ranked pool
+func AddRankedMap(matchmakingService *matchmaking.MatchmakingService, c *gin.Context) {
+	var request struct {
+		MapID int `json:"map_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+
+	added, err := matchmakingService.MapPool().AddMap(request.MapID)
+	if err != nil {
+		respondMapPoolError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"map":     added,
+	})
+}
+
+// RemoveRankedMap handles an admin taking a map out of the ranked pool
+func RemoveRankedMap(matchmakingService *matchmaking.MatchmakingService, c *gin.Context) {
+	mapID, err := strconv.Atoi(c.Param("mapID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid map ID"})
+		return
+	}
+
+	if err := matchmakingService.MapPool().RemoveMap(mapID); err != nil {
+		respondMapPoolError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// respondMapPoolError maps ranked map pool errors to HTTP responses
+func respondMapPoolError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, matchmaking.ErrUnknownMap), errors.Is(err, matchmaking.ErrMapNotInPool):
+		status = http.StatusNotFound
+	case errors.Is(err, matchmaking.ErrMapAlreadyInPool):
+		status = http.StatusConflict
+	default:
+		c.JSON(status, gin.H{"success": false, "error": "Failed to update the ranked map pool"})
+		return
+	}
+
+	c.JSON(status, gin.H{"success": false, "error": err.Error()})
+}
@@ -0,0 +1,120 @@
+package game_handler_modules
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"boba-vim/internal/models"
+	"boba-vim/internal/services/game"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultMatchReviewListLimit = 50
+	maxMatchReviewListLimit     = 200
+)
+
+// GetMatchReviews handles admins listing the play anti-cheat flagged, newest first. Filter with
+// status=pending, cleared or confirmed.
+func GetMatchReviews(multiplayerGame *game.MultiplayerGameService, c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultMatchReviewListLimit)))
+	if limit <= 0 || limit > maxMatchReviewListLimit {
+		limit = defaultMatchReviewListLimit
+	}
+
+	reviews, err := multiplayerGame.ListMatchReviews(c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get match reviews"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"reviews": reviews,
+	})
+}
+
+// GetMatchReview handles admins getting a match review
+func GetMatchReview(multiplayerGame *game.MultiplayerGameService, c *gin.Context) {
+	id, ok := matchReviewIDParam(c)
+	if !ok {
+		return
+	}
+
+	review, err := multiplayerGame.GetMatchReview(id)
+	if err != nil {
+		respondMatchReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"review":  review,
+	})
+}
+
+// ResolveMatchReview handles an admin clearing or confirming flagged play. Clearing the last open
+// review of a held result counts it toward the players' stats.
+func ResolveMatchReview(multiplayerGame *game.MultiplayerGameService, c *gin.Context) {
+	id, ok := matchReviewIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Decision string `json:"decision" binding:"required"`
+		Notes    string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+
+	reviewer := ""
+	if admin, exists := c.Get("admin"); exists {
+		if admin, ok := admin.(*models.Admin); ok {
+			reviewer = admin.Username
+		}
+	}
+
+	review, err := multiplayerGame.ResolveMatchReview(id, request.Decision, reviewer, request.Notes)
+	if err != nil {
+		respondMatchReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"review":  review,
+	})
+}
+
+// matchReviewIDParam reads the match review ID from the path, responding with an error if it's
+// invalid
+func matchReviewIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid match review ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondMatchReviewError maps match review errors to HTTP responses
+func respondMatchReviewError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, game.ErrMatchReviewNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, game.ErrMatchReviewResolved):
+		status = http.StatusConflict
+	case errors.Is(err, game.ErrInvalidReviewDecision):
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to update the match review"})
+		return
+	}
+
+	c.JSON(status, gin.H{"success": false, "error": err.Error()})
+}
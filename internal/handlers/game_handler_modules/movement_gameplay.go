@@ -44,7 +44,7 @@ func MovePlayer(gameService *gameService.GameService, c *gin.Context) {
 	}
 
 	// Process move
-	result, err := gameService.ProcessMove(sessionToken.(string), request.Direction, count, request.HasExplicitCount)
+	result, err := gameService.ProcessMove(sessionToken.(string), request.Direction, count, request.HasExplicitCount, request.StateVersion)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
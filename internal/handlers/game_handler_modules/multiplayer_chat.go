@@ -0,0 +1,35 @@
+package game_handler_modules
+
+import (
+	"net/http"
+
+	"boba-vim/internal/services/game"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMultiplayerChatOptions handles listing the quick chat messages and emotes players can send
+// during a game, and the chat limits
+func GetMultiplayerChatOptions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"chat":    game.GetChatCatalog(),
+	})
+}
+
+// GetMultiplayerChatLog handles moderators reading the chat of a game, or of every game of a match.
+// Set filtered=true to only see messages whose profanity was masked.
+func GetMultiplayerChatLog(multiplayerGame *game.MultiplayerGameService, c *gin.Context) {
+	gameID := c.Param("gameID")
+	messages, err := multiplayerGame.GetChatLog(gameID, c.Query("filtered") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get chat log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"game_id":  gameID,
+		"messages": messages,
+	})
+}
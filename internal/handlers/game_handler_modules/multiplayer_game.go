@@ -1,14 +1,14 @@
 package game_handler_modules
 
 import (
-	"encoding/json"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
 
 	"boba-vim/internal/services/game"
 	"boba-vim/internal/services/matchmaking"
 	"boba-vim/internal/utils"
+	"boba-vim/internal/wsproto"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -127,194 +127,87 @@ func HandleMultiplayerDisconnect(multiplayerGame *game.MultiplayerGameService, c
 	})
 }
 
-// WebSocket upgrader
-var wsUpgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow connections from any origin
-	},
-}
-
-// GameConnectionManager manages WebSocket connections per game for better concurrency
-type GameConnectionManager struct {
-	games sync.Map // map[string]*GameConnections - lock-free for better performance
-}
-
-type GameConnections struct {
-	connections map[uint]*websocket.Conn
-	mutex       sync.RWMutex
-}
-
-var gameConnectionManager = &GameConnectionManager{}
+// WebSocket upgrader, negotiating the encoding and schema version of messages
+var wsUpgrader = wsproto.NewUpgrader()
 
-// addGameConnection adds a WebSocket connection for a specific game and player
-func addGameConnection(gameID string, playerID uint, conn *websocket.Conn) {
-	// Get or create game connections
-	gameConns, _ := gameConnectionManager.games.LoadOrStore(gameID, &GameConnections{
-		connections: make(map[uint]*websocket.Conn),
-	})
-	
-	gc := gameConns.(*GameConnections)
-	gc.mutex.Lock()
-	gc.connections[playerID] = conn
-	gc.mutex.Unlock()
-	
-	utils.Debug("Added WebSocket connection for player %d in game %s", playerID, gameID)
-}
+// handleWebSocketMove processes a move sent over the game WebSocket and acknowledges it with the authoritative position.
+// Moves from one connection are handled in order since the read loop processes one message at a time.
+func handleWebSocketMove(multiplayerGame *game.MultiplayerGameService, gameID string, playerID uint, frameType int, message []byte) {
+	var moveMessage WebSocketMoveMessage
+	if err := wsproto.Unmarshal(frameType, message, &moveMessage); err != nil {
+		utils.Error("Invalid move message from player %d in game %s: %v", playerID, gameID, err)
+		return
+	}
 
-// removeGameConnection removes a WebSocket connection for a specific game and player
-func removeGameConnection(gameID string, playerID uint) {
-	if gameConns, exists := gameConnectionManager.games.Load(gameID); exists {
-		gc := gameConns.(*GameConnections)
-		gc.mutex.Lock()
-		delete(gc.connections, playerID)
-		isEmpty := len(gc.connections) == 0
-		gc.mutex.Unlock()
-		
-		// Clean up empty game connections
-		if isEmpty {
-			gameConnectionManager.games.Delete(gameID)
-		}
+	// Set default count if not provided
+	if moveMessage.Count == 0 {
+		moveMessage.Count = 1
 	}
-	utils.Debug("Removed WebSocket connection for player %d in game %s", playerID, gameID)
-}
 
-// areBothPlayersConnectedToGame checks if both players are connected to a game
-func areBothPlayersConnectedToGame(multiplayerGame *game.MultiplayerGameService, gameID string) bool {
-	game := multiplayerGame.GetGameByID(gameID)
-	if game == nil {
-		return false
+	ack, err := multiplayerGame.ProcessSequencedMove(gameID, playerID, moveMessage.Seq, moveMessage.Direction, moveMessage.Count, moveMessage.HasExplicitCount, moveMessage.Predicted)
+	if err != nil {
+		utils.Error("Failed to process move seq %d from player %d in game %s: %v", moveMessage.Seq, playerID, gameID, err)
+		ack = &game.MoveAck{Seq: moveMessage.Seq, Error: "Failed to process move"}
 	}
-	
-	gameConns, exists := gameConnectionManager.games.Load(gameID)
-	if !exists {
-		return false
+
+	if err := multiplayerGame.SendMessageToPlayer(playerID, "move_ack", ack); err != nil {
+		utils.Error("Failed to send move ack to player %d in game %s: %v", playerID, gameID, err)
 	}
-	
-	gc := gameConns.(*GameConnections)
-	gc.mutex.RLock()
-	_, player1Connected := gc.connections[game.Player1ID]
-	_, player2Connected := gc.connections[game.Player2ID]
-	gc.mutex.RUnlock()
-	
-	utils.Debug("Game %s connection status: P1(%d)=%v, P2(%d)=%v", 
-		gameID, game.Player1ID, player1Connected, game.Player2ID, player2Connected)
-	
-	return player1Connected && player2Connected
 }
 
-// sendCountdownToGame sends countdown messages to both players in a game
-func sendCountdownToGame(multiplayerGame *game.MultiplayerGameService, gameID string) {
-	game := multiplayerGame.GetGameByID(gameID)
-	if game == nil {
-		utils.Error("Game %s not found for countdown", gameID)
+// handleWebSocketRematch processes a rematch offer, acceptance or decline sent over the game WebSocket once the
+// game is over. Rejected requests are answered with a rematch_error message.
+func handleWebSocketRematch(multiplayerGame *game.MultiplayerGameService, gameID string, playerID uint, frameType int, message []byte) {
+	var rematchMessage WebSocketRematchMessage
+	if err := wsproto.Unmarshal(frameType, message, &rematchMessage); err != nil {
+		utils.Error("Invalid rematch message from player %d in game %s: %v", playerID, gameID, err)
 		return
 	}
-	
-	// Check if countdown can be started and set initial state
-	if !multiplayerGame.SetCountdownState(gameID, true, true) {
-		utils.Debug("Countdown already started for game %s", gameID)
-		return
-	}
-	
-	utils.Info("Starting countdown for game %s", gameID)
-	
-	// Use ticker for more efficient timing
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-	
-	// Send 3-2-1 countdown
-	for countdown := 3; countdown > 0; countdown-- {
-		message := map[string]interface{}{
-			"type": "countdown",
-			"data": map[string]interface{}{
-				"value":  countdown,
-				"active": true,
-			},
-		}
-		
-		sendMessageToGamePlayersAsync(gameID, message)
-		utils.Debug("Sent countdown %d for game %s", countdown, gameID)
-		
-		if countdown > 1 {
-			<-ticker.C // Wait for next tick
+
+	action := strings.TrimPrefix(rematchMessage.Type, "rematch_")
+	if err := multiplayerGame.HandleRematch(gameID, playerID, action, rematchMessage.BestOf); err != nil {
+		utils.Debug("Rematch %s from player %d in game %s rejected: %v", action, playerID, gameID, err)
+		if err := multiplayerGame.SendMessageToPlayer(playerID, "rematch_error", map[string]string{"error": err.Error()}); err != nil {
+			utils.Error("Failed to send rematch error to player %d in game %s: %v", playerID, gameID, err)
 		}
 	}
-	
-	// Send GO message
-	goMessage := map[string]interface{}{
-		"type": "countdown",
-		"data": map[string]interface{}{
-			"value":  "GO",
-			"active": false,
-		},
-	}
-	
-	sendMessageToGamePlayersAsync(gameID, goMessage)
-	
-	// Wait a moment to ensure GO message is received before allowing movement
-	time.Sleep(500 * time.Millisecond)
-	
-	// Unblock movement after countdown finishes
-	multiplayerGame.SetCountdownState(gameID, true, false)
-	
-	utils.Info("Countdown finished for game %s - game is now active and movement unlocked", gameID)
 }
 
-// sendMessageToGamePlayersAsync sends a message to all players in a game asynchronously
-func sendMessageToGamePlayersAsync(gameID string, message interface{}) {
-	gameConns, exists := gameConnectionManager.games.Load(gameID)
-	if !exists {
-		utils.Error("No connections found for game %s", gameID)
+// handleWebSocketChat processes a chat message, mute or unmute sent over the game WebSocket. Rejected requests are
+// answered with a chat_error message.
+func handleWebSocketChat(multiplayerGame *game.MultiplayerGameService, gameID string, playerID uint, frameType int, message []byte) {
+	var chatMessage WebSocketChatMessage
+	if err := wsproto.Unmarshal(frameType, message, &chatMessage); err != nil {
+		utils.Error("Invalid chat message from player %d in game %s: %v", playerID, gameID, err)
 		return
 	}
-	
-	gc := gameConns.(*GameConnections)
-	gc.mutex.RLock()
-	
-	// Create a copy of connections to avoid holding lock during writes
-	connsCopy := make(map[uint]*websocket.Conn, len(gc.connections))
-	for playerID, conn := range gc.connections {
-		connsCopy[playerID] = conn
+
+	action := game.ChatActionSend
+	if chatMessage.Type != "chat" {
+		action = strings.TrimPrefix(chatMessage.Type, "chat_")
 	}
-	gc.mutex.RUnlock()
-	
-	// Send messages in parallel with timeout protection
-	var wg sync.WaitGroup
-	for playerID, conn := range connsCopy {
-		wg.Add(1)
-		go func(pID uint, c *websocket.Conn) {
-			defer wg.Done()
-			
-			// Set write deadline to prevent blocking
-			c.SetWriteDeadline(time.Now().Add(2 * time.Second))
-			
-			err := c.WriteJSON(message)
-			if err != nil {
-				utils.Error("Failed to send message to player %d in game %s: %v", pID, gameID, err)
-			} else {
-				utils.Debug("Sent message to player %d in game %s", pID, gameID)
-			}
-		}(playerID, conn)
+
+	request := game.ChatRequest{
+		Action:   action,
+		Quick:    chatMessage.Quick,
+		Emote:    chatMessage.Emote,
+		Text:     chatMessage.Text,
+		PlayerID: chatMessage.PlayerID,
 	}
-	
-	// Wait for all messages to be sent with timeout
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-	
-	select {
-	case <-done:
-		// All messages sent
-	case <-time.After(5 * time.Second):
-		utils.Warn("Timeout sending messages to game %s", gameID)
+	if err := multiplayerGame.HandleChat(gameID, playerID, request); err != nil {
+		utils.Debug("Chat %s from player %d in game %s rejected: %v", action, playerID, gameID, err)
+		if err := multiplayerGame.SendMessageToPlayer(playerID, "chat_error", map[string]string{"error": err.Error()}); err != nil {
+			utils.Error("Failed to send chat error to player %d in game %s: %v", playerID, gameID, err)
+		}
 	}
 }
 
-// sendMessageToGamePlayers sends a message to all players in a game (kept for compatibility)
-func sendMessageToGamePlayers(gameID string, message interface{}) {
-	sendMessageToGamePlayersAsync(gameID, message)
+// handleWebSocketResync sends a player the whole game state and switches them to deltas, for
+// clients that follow state versions
+func handleWebSocketResync(multiplayerGame *game.MultiplayerGameService, gameID string, playerID uint) {
+	if err := multiplayerGame.ResyncGameState(gameID, playerID); err != nil {
+		utils.Error("Failed to resync player %d in game %s: %v", playerID, gameID, err)
+	}
 }
 
 // HandleMultiplayerGameWebSocket handles WebSocket connections for multiplayer games
@@ -350,20 +243,38 @@ func HandleMultiplayerGameWebSocket(multiplayerGame *game.MultiplayerGameService
 	
 	// Register connection with the WebSocket manager for game updates
 	multiplayerGame.RegisterWebSocketConnection(playerID, conn)
-	// Also register with our game-specific connection manager
-	addGameConnection(gameID, playerID, conn)
 	utils.Debug("WebSocket connection registered for player %d in game %s", playerID, gameID)
 	
 	defer func() {
 		conn.Close()
 		// Remove connection from WebSocket manager
-		multiplayerGame.UnregisterWebSocketConnection(playerID)
-		// Remove from game-specific connection manager
-		removeGameConnection(gameID, playerID)
-		// Handle player disconnect when WebSocket closes
-		multiplayerGame.HandlePlayerDisconnect(playerID)
+		current := multiplayerGame.UnregisterWebSocketConnection(playerID, conn)
+		// A dropped connection starts the reconnect grace period; a replaced one was a reconnect
+		if current {
+			multiplayerGame.HandleConnectionLost(gameID, playerID)
+		}
 		utils.Info("Player %d disconnected from multiplayer game %s", playerID, gameID)
 	}()
+	
+	// Restore the state of a player reattaching to a game that is underway
+	state, resync, err := multiplayerGame.HandlePlayerReconnect(gameID, playerID)
+	if err != nil {
+		protocol := wsproto.Negotiated(conn)
+		data, encodeErr := protocol.Marshal(map[string]interface{}{
+			"type": "reconnect_failed",
+			"data": map[string]string{"reason": err.Error()},
+		})
+		if encodeErr == nil {
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			conn.WriteMessage(protocol.FrameType(), data)
+		}
+		return
+	}
+	if resync {
+		if err := multiplayerGame.SendMessageToPlayer(playerID, "game_resync", state); err != nil {
+			utils.Error("Failed to resync player %d in game %s: %v", playerID, gameID, err)
+		}
+	}
 
 	utils.Info("Player %d connected to multiplayer game %s via WebSocket", playerID, gameID)
 
@@ -402,28 +313,26 @@ func HandleMultiplayerGameWebSocket(multiplayerGame *game.MultiplayerGameService
 
 			// Handle different message types
 			switch messageType {
-			case websocket.TextMessage:
-				// Handle text messages (e.g., countdown status requests)
-				var msg map[string]interface{}
-				if err := json.Unmarshal(message, &msg); err == nil {
-					if msgType, ok := msg["type"].(string); ok {
-						if msgType == "request_countdown_status" {
-							utils.Debug("Player %d requested countdown status for game %s", playerID, gameID)
-							
-							// Check if both players are connected to this game
-							if areBothPlayersConnectedToGame(multiplayerGame, gameID) {
-								utils.Info("Both players connected, starting countdown for game %s", gameID)
-								go sendCountdownToGame(multiplayerGame, gameID)
-							} else {
-								utils.Debug("Waiting for both players to connect to game %s", gameID)
-							}
-						}
+			case websocket.TextMessage, websocket.BinaryMessage:
+				// Text frames carry JSON and binary frames MessagePack (e.g., countdown status requests, moves)
+				if envelope, err := wsproto.ReadEnvelope(messageType, message); err == nil {
+					switch envelope.Type {
+					case "request_countdown_status":
+						utils.Debug("Player %d requested countdown status for game %s", playerID, gameID)
+						
+						// The countdown starts once every player is connected to this game
+						multiplayerGame.RequestCountdown(gameID)
+					case "move":
+						handleWebSocketMove(multiplayerGame, gameID, playerID, messageType, message)
+					case "rematch_offer", "rematch_accept", "rematch_decline":
+						handleWebSocketRematch(multiplayerGame, gameID, playerID, messageType, message)
+					case "chat", "chat_mute", "chat_unmute":
+						handleWebSocketChat(multiplayerGame, gameID, playerID, messageType, message)
+					case "resync":
+						handleWebSocketResync(multiplayerGame, gameID, playerID)
 					}
 				}
-				utils.Debug("Received text message from player %d: %s", playerID, string(message))
-			case websocket.BinaryMessage:
-				// Handle binary messages if needed
-				utils.Debug("Received binary message from player %d", playerID)
+				utils.Debug("Received message from player %d (%d bytes)", playerID, len(message))
 			case websocket.CloseMessage:
 				utils.Debug("WebSocket connection closed by client")
 				return
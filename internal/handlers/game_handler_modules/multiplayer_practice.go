@@ -0,0 +1,84 @@
+package game_handler_modules
+
+import (
+	"errors"
+	"net/http"
+
+	"boba-vim/internal/services/game"
+	"boba-vim/internal/services/matchmaking"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PracticeGameRequest is a request to play against bots. Mode defaults to a duel; a free-for-all
+// takes a room size of 3 to 8 players, filled with bots, and a team game pairs the player with a
+// bot against two bots.
+type PracticeGameRequest struct {
+	Difficulty        string `json:"difficulty"`
+	SelectedCharacter string `json:"selected_character"`
+	Mode              string `json:"mode"`
+	RoomSize          int    `json:"room_size"`
+}
+
+// GetPracticeBots handles listing the bot difficulties players can practice against
+func GetPracticeBots(c *gin.Context) {
+	profiles := game.BotProfiles()
+	bots := make([]gin.H, len(profiles))
+	for i, profile := range profiles {
+		bots[i] = gin.H{
+			"difficulty":        profile.Difficulty,
+			"name":              profile.Name,
+			"reaction_delay_ms": profile.ReactionDelay.Milliseconds(),
+			"motions":           profile.Keys,
+			"max_count":         profile.MaxCount,
+			"optimality":        profile.Optimality,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"bots":    bots,
+	})
+}
+
+// StartPracticeGame handles starting an unranked game against bots. A player still queued for a
+// match leaves the queue.
+func StartPracticeGame(multiplayerGame *game.MultiplayerGameService, matchmakingService *matchmaking.MatchmakingService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to play practice games"})
+		return
+	}
+
+	var request PracticeGameRequest
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+	if request.Difficulty == "" {
+		request.Difficulty = game.BotDifficultyMedium
+	}
+	if request.SelectedCharacter == "" {
+		request.SelectedCharacter = "boba" // Default character
+	}
+
+	matchmakingService.RemoveFromQueue(playerID)
+
+	mpGame, err := multiplayerGame.StartPracticeGame(playerID, username, request.SelectedCharacter, request.Mode, request.RoomSize, request.Difficulty)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, game.ErrUnknownBotDifficulty) || errors.Is(err, game.ErrPracticeMode) || errors.Is(err, game.ErrPracticeRoomSize) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"game_id":    mpGame.ID,
+		"match_id":   mpGame.MatchID,
+		"mode":       mpGame.Mode,
+		"difficulty": request.Difficulty,
+	})
+}
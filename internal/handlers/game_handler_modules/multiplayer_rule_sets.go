@@ -0,0 +1,18 @@
+package game_handler_modules
+
+import (
+	"net/http"
+
+	"boba-vim/internal/services/game"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMultiplayerRuleSets handles listing the rule sets lobby hosts can pick for their game
+func GetMultiplayerRuleSets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"rule_sets": game.RuleSets(),
+		"default":   game.DefaultRuleSet,
+	})
+}
@@ -0,0 +1,100 @@
+package game_handler_modules
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"boba-vim/internal/services/game"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// GetLiveMultiplayerGames handles listing the running games that can be watched
+func GetLiveMultiplayerGames(multiplayerGame *game.MultiplayerGameService, c *gin.Context) {
+	liveGames := multiplayerGame.GetLiveGames()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"games":   liveGames,
+		"count":   len(liveGames),
+	})
+}
+
+// HandleSpectatorWebSocket streams a running game to a read-only viewer. The optional delay query
+// parameter holds every update back by that many seconds. Anything the spectator sends is
+// ignored, so spectators can never move.
+func HandleSpectatorWebSocket(multiplayerGame *game.MultiplayerGameService, c *gin.Context) {
+	gameID := c.Param("gameID")
+	if gameID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Game ID is required"})
+		return
+	}
+
+	delaySeconds := 0
+	if delayParam := c.Query("delay"); delayParam != "" {
+		parsed, err := strconv.Atoi(delayParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": game.ErrInvalidSpectatorDelay.Error()})
+			return
+		}
+		delaySeconds = parsed
+	}
+
+	// Guests can watch too; registered viewers are kept from spectating their own game
+	viewerID, _, _ := matchmaking.ValidatePlayerSession(c)
+
+	if multiplayerGame.GetGameByID(gameID) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": game.ErrSpectateGameNotFound.Error()})
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		utils.Error("Failed to upgrade spectator to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	spectator, err := multiplayerGame.AddSpectator(gameID, viewerID, conn, time.Duration(delaySeconds)*time.Second)
+	if err != nil {
+		closeCode := websocket.ClosePolicyViolation
+		if errors.Is(err, game.ErrSpectatorsFull) {
+			closeCode = websocket.CloseTryAgainLater
+		}
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, err.Error()), time.Now().Add(time.Second))
+		return
+	}
+	defer multiplayerGame.RemoveSpectator(gameID, spectator)
+
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		return nil
+	})
+
+	// Read only to notice the viewer leaving and to process pongs
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+
+		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+					utils.Debug("Spectator WebSocket read error: %v", err)
+				}
+				return
+			}
+			conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		}
+	}()
+
+	select {
+	case <-readDone:
+	case <-spectator.Done():
+	}
+}
@@ -381,4 +381,63 @@ func RestartGame(gameService *gameService.GameService, c *gin.Context) {
 		"map_id": mapIDStr,
 		"game_state": result,
 	})
-}
\ No newline at end of file
+}
+
+// UpdateDisplayOptions stores the client-reported text width and wrap setting used by display-line motions
+func UpdateDisplayOptions(db *gorm.DB, c *gin.Context) {
+	var request DisplayOptionsRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid display options format",
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	sessionToken := session.Get("game_session_token")
+
+	if sessionToken == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No active game session",
+		})
+		return
+	}
+
+	var gameSession models.GameSession
+	if err := db.Where("session_token = ? AND is_active = ?", sessionToken.(string), true).First(&gameSession).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Active game session not found",
+		})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if request.Wrap != nil {
+		updates["wrap_lines"] = *request.Wrap
+		gameSession.WrapLines = *request.Wrap
+	}
+	if request.TextWidth != nil {
+		updates["text_width"] = *request.TextWidth
+		gameSession.TextWidth = *request.TextWidth
+	}
+
+	if len(updates) > 0 {
+		if err := db.Model(&gameSession).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to save display options",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"wrap":       gameSession.WrapLines,
+		"text_width": gameSession.TextWidth,
+	})
+}
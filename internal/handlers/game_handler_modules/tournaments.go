@@ -0,0 +1,237 @@
+package game_handler_modules
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/services/tournament"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTournamentListLimit = 20
+	maxTournamentListLimit     = 100
+)
+
+// GetTournaments handles listing tournaments, optionally filtered by status
+func GetTournaments(tournamentService *tournament.TournamentService, c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultTournamentListLimit)))
+	if limit <= 0 || limit > maxTournamentListLimit {
+		limit = defaultTournamentListLimit
+	}
+
+	tournaments, err := tournamentService.ListTournaments(c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get tournaments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"tournaments": tournaments,
+	})
+}
+
+// GetTournament handles getting a tournament's bracket: its rules, standings and every match
+func GetTournament(tournamentService *tournament.TournamentService, c *gin.Context) {
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	bracket, err := tournamentService.GetBracket(id)
+	if err != nil {
+		respondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"bracket": bracket,
+	})
+}
+
+// RegisterForTournament handles a player registering for a tournament
+func RegisterForTournament(tournamentService *tournament.TournamentService, c *gin.Context) {
+	playerID, username, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Please register to enter a tournament"})
+		return
+	}
+
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		SelectedCharacter string `json:"selected_character"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+	if request.SelectedCharacter == "" {
+		request.SelectedCharacter = "boba" // Default character
+	}
+
+	entrant, err := tournamentService.Register(id, playerID, username, request.SelectedCharacter)
+	if err != nil {
+		respondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"entrant": entrant,
+	})
+}
+
+// WithdrawFromTournament handles a player withdrawing from a tournament before it starts
+func WithdrawFromTournament(tournamentService *tournament.TournamentService, c *gin.Context) {
+	playerID, _, err := matchmaking.ValidatePlayerSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Unauthorized"})
+		return
+	}
+
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := tournamentService.Withdraw(id, playerID); err != nil {
+		respondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Withdrew from tournament",
+	})
+}
+
+// CreateTournament handles an admin opening a tournament for registration
+func CreateTournament(tournamentService *tournament.TournamentService, c *gin.Context) {
+	var request tournament.TournamentSettings
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request"})
+		return
+	}
+
+	created, err := tournamentService.CreateTournament(request)
+	if err != nil {
+		respondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"tournament": created,
+	})
+}
+
+// StartTournament handles an admin closing registration, seeding the players and drawing the bracket
+func StartTournament(tournamentService *tournament.TournamentService, c *gin.Context) {
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	bracket, err := tournamentService.StartTournament(id)
+	if err != nil {
+		respondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"bracket": bracket,
+	})
+}
+
+// CancelTournament handles an admin cancelling a tournament that hasn't finished
+func CancelTournament(tournamentService *tournament.TournamentService, c *gin.Context) {
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := tournamentService.CancelTournament(id); err != nil {
+		respondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// SettleTournamentMatch handles an admin deciding a match for one of its players, for no-shows
+// and games that never finished
+func SettleTournamentMatch(tournamentService *tournament.TournamentService, c *gin.Context) {
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid match ID"})
+		return
+	}
+
+	var request struct {
+		WinnerID uint `json:"winner_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "winner_id is required"})
+		return
+	}
+
+	match, err := tournamentService.SettleMatch(id, uint(matchID), request.WinnerID)
+	if err != nil {
+		respondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"match":   match,
+	})
+}
+
+// tournamentIDParam reads the tournament ID from the path, responding with an error if it's invalid
+func tournamentIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid tournament ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondTournamentError maps tournament errors to HTTP responses
+func respondTournamentError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, tournament.ErrTournamentNotFound), errors.Is(err, tournament.ErrTournamentMatchNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, tournament.ErrRegistrationClosed), errors.Is(err, tournament.ErrTournamentFull),
+		errors.Is(err, tournament.ErrAlreadyRegistered), errors.Is(err, tournament.ErrTournamentNotRunning),
+		errors.Is(err, tournament.ErrTournamentFinished), errors.Is(err, tournament.ErrMatchNotInProgress):
+		status = http.StatusConflict
+	case errors.Is(err, tournament.ErrInvalidFormat), errors.Is(err, tournament.ErrInvalidSeeding),
+		errors.Is(err, tournament.ErrInvalidMaxPlayers), errors.Is(err, tournament.ErrInvalidBestOf),
+		errors.Is(err, tournament.ErrInvalidSwissRounds), errors.Is(err, tournament.ErrInvalidMap),
+		errors.Is(err, tournament.ErrInvalidTargetScore), errors.Is(err, tournament.ErrInvalidTimeLimit),
+		errors.Is(err, tournament.ErrNotRegistered), errors.Is(err, tournament.ErrNotEnoughEntrants),
+		errors.Is(err, tournament.ErrNotMatchPlayer):
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to update the tournament"})
+		return
+	}
+
+	c.JSON(status, gin.H{"success": false, "error": err.Error()})
+}
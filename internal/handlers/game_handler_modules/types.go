@@ -1,5 +1,7 @@
 package game_handler_modules
 
+import "boba-vim/internal/services/game"
+
 // Request types for game handlers
 type SetUsernameRequest struct {
 	Username string `json:"username" binding:"required,min=2,max=50"`
@@ -9,6 +11,46 @@ type MovePlayerRequest struct {
 	Direction        string `json:"direction" binding:"required"`
 	Count            int    `json:"count,omitempty"`
 	HasExplicitCount bool   `json:"has_explicit_count,omitempty"`
+	StateVersion     int64  `json:"state_version,omitempty"` // Version of the game map the client holds; 0 asks for the whole map
+}
+
+type DisplayOptionsRequest struct {
+	Wrap      *bool `json:"wrap"`
+	TextWidth *int  `json:"text_width" binding:"omitempty,min=0,max=1000"`
+}
+
+type VimOptionsRequest struct {
+	WhichWrap   *string `json:"whichwrap"`
+	StartOfLine *bool   `json:"startofline"`
+	VirtualEdit *string `json:"virtualedit"`
+	IsKeyword   *string `json:"iskeyword"`
+	WrapScan    *bool   `json:"wrapscan"`
+	TabStop     *int    `json:"tabstop"`
+}
+
+// WebSocketMoveMessage is a move sent over the multiplayer game WebSocket
+type WebSocketMoveMessage struct {
+	Type             string         `json:"type"`
+	Seq              uint64         `json:"seq"`
+	Direction        string         `json:"direction"`
+	Count            int            `json:"count,omitempty"`
+	HasExplicitCount bool           `json:"has_explicit_count,omitempty"`
+	Predicted        *game.Position `json:"predicted,omitempty"`
+}
+
+// WebSocketRematchMessage is a rematch offer, acceptance or decline sent over the multiplayer game WebSocket
+type WebSocketRematchMessage struct {
+	Type   string `json:"type"`
+	BestOf int    `json:"best_of,omitempty"` // Length of the series an offer proposes; 0 for a single game
+}
+
+// WebSocketChatMessage is a chat message, mute or unmute sent over the multiplayer game WebSocket
+type WebSocketChatMessage struct {
+	Type     string `json:"type"`
+	Quick    string `json:"quick,omitempty"`
+	Emote    string `json:"emote,omitempty"`
+	Text     string `json:"text,omitempty"`
+	PlayerID uint   `json:"player_id,omitempty"` // Player to mute or unmute; every other player when 0
 }
 
 type PlayOnlineRequest struct {
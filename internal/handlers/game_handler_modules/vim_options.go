@@ -0,0 +1,178 @@
+package game_handler_modules
+
+import (
+	"net/http"
+
+	"boba-vim/internal/models"
+	gameService "boba-vim/internal/services/game"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetSessionVimOptions returns the vim options of the current game session
+func GetSessionVimOptions(db *gorm.DB, c *gin.Context) {
+	gameSession, ok := findActiveSession(db, c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"options": gameSession.VimOptions,
+	})
+}
+
+// UpdateSessionVimOptions changes the vim options of the current game session only
+func UpdateSessionVimOptions(db *gorm.DB, gameService *gameService.GameService, c *gin.Context) {
+	var request VimOptionsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid vim options format",
+		})
+		return
+	}
+
+	gameSession, ok := findActiveSession(db, c)
+	if !ok {
+		return
+	}
+
+	options := applyVimOptionsRequest(&request, gameSession.VimOptions)
+	if err := gameService.VimOptions.UpdateSessionOptions(gameSession.SessionToken, options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"options": options,
+	})
+}
+
+// GetPlayerVimOptions returns the vim options saved in the player's preferences
+func GetPlayerVimOptions(gameService *gameService.GameService, c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id")
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	options, err := gameService.VimOptions.GetPlayerOptions(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch vim options",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"options": options,
+	})
+}
+
+// UpdatePlayerVimOptions saves the player's vim options and applies them to the active session
+func UpdatePlayerVimOptions(gameService *gameService.GameService, c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id")
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var request VimOptionsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid vim options format",
+		})
+		return
+	}
+
+	current, err := gameService.VimOptions.GetPlayerOptions(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch vim options",
+		})
+		return
+	}
+
+	options := applyVimOptionsRequest(&request, current)
+	if err := gameService.VimOptions.SavePlayerOptions(userID.(uint), options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"options": options,
+	})
+}
+
+// findActiveSession loads the active game session of the request, writing an error response when missing
+func findActiveSession(db *gorm.DB, c *gin.Context) (*models.GameSession, bool) {
+	session := sessions.Default(c)
+	sessionToken := session.Get("game_session_token")
+	if sessionToken == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No active game session",
+		})
+		return nil, false
+	}
+
+	var gameSession models.GameSession
+	if err := db.Where("session_token = ? AND is_active = ?", sessionToken.(string), true).First(&gameSession).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Active game session not found",
+		})
+		return nil, false
+	}
+
+	return &gameSession, true
+}
+
+// applyVimOptionsRequest overwrites the options that were present in the request
+func applyVimOptionsRequest(request *VimOptionsRequest, options models.VimOptions) models.VimOptions {
+	if !options.IsSet() {
+		options = models.DefaultVimOptions()
+	}
+	if request.WhichWrap != nil {
+		options.WhichWrap = *request.WhichWrap
+	}
+	if request.StartOfLine != nil {
+		options.StartOfLine = *request.StartOfLine
+	}
+	if request.VirtualEdit != nil {
+		options.VirtualEdit = *request.VirtualEdit
+	}
+	if request.IsKeyword != nil {
+		options.IsKeyword = *request.IsKeyword
+	}
+	if request.WrapScan != nil {
+		options.WrapScan = *request.WrapScan
+	}
+	if request.TabStop != nil {
+		options.TabStop = *request.TabStop
+	}
+	return options
+}
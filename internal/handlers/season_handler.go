@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"boba-vim/internal/services/season"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultStandingsLimit = 100
+	maxStandingsLimit     = 500
+)
+
+type SeasonHandler struct {
+	seasonService *season.SeasonService
+}
+
+func NewSeasonHandler(seasonService *season.SeasonService) *SeasonHandler {
+	return &SeasonHandler{
+		seasonService: seasonService,
+	}
+}
+
+// GetSeasons returns every ranked season, newest first
+func (sh *SeasonHandler) GetSeasons(c *gin.Context) {
+	seasons, err := sh.seasonService.ListSeasons()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seasons"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"seasons": seasons,
+	})
+}
+
+// GetCurrentSeason returns the active ranked season, or null between seasons
+func (sh *SeasonHandler) GetCurrentSeason(c *gin.Context) {
+	current, err := sh.seasonService.CurrentSeason()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch current season"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"season":  current,
+	})
+}
+
+// GetSeason returns a season with a page of its final standings
+func (sh *SeasonHandler) GetSeason(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultStandingsLimit)))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > maxStandingsLimit {
+		limit = defaultStandingsLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	found, err := sh.seasonService.GetSeason(uint(id))
+	if err != nil {
+		respondSeasonError(c, err)
+		return
+	}
+
+	standings, total, err := sh.seasonService.GetStandings(found.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch standings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"season":    found,
+		"standings": standings,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+// GetPlayerSeasonHistory returns a player's final standing in each past season
+func (sh *SeasonHandler) GetPlayerSeasonHistory(c *gin.Context) {
+	playerID, err := strconv.ParseUint(c.Param("playerID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	history, err := sh.seasonService.GetPlayerHistory(uint(playerID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch season history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"history": history,
+	})
+}
+
+// CreateSeason schedules a new ranked season (admin only)
+func (sh *SeasonHandler) CreateSeason(c *gin.Context) {
+	var req struct {
+		Name     string    `json:"name"`
+		StartsAt time.Time `json:"starts_at" binding:"required"`
+		EndsAt   time.Time `json:"ends_at" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	created, err := sh.seasonService.CreateSeason(req.Name, req.StartsAt, req.EndsAt)
+	if err != nil {
+		respondSeasonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"season":  created,
+	})
+}
+
+// UpdateSeason changes a season's name or dates (admin only)
+func (sh *SeasonHandler) UpdateSeason(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+		return
+	}
+
+	var req struct {
+		Name     *string    `json:"name"`
+		StartsAt *time.Time `json:"starts_at"`
+		EndsAt   *time.Time `json:"ends_at"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	updated, err := sh.seasonService.UpdateSeason(uint(id), req.Name, req.StartsAt, req.EndsAt)
+	if err != nil {
+		respondSeasonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"season":  updated,
+	})
+}
+
+// EndSeason ends the active season early and archives its standings (admin only)
+func (sh *SeasonHandler) EndSeason(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+		return
+	}
+
+	ended, err := sh.seasonService.EndSeason(uint(id))
+	if err != nil {
+		respondSeasonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"season":  ended,
+	})
+}
+
+// respondSeasonError maps season service errors to HTTP responses
+func respondSeasonError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, season.ErrSeasonNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, season.ErrSeasonOverlap),
+		errors.Is(err, season.ErrSeasonArchived),
+		errors.Is(err, season.ErrSeasonStarted),
+		errors.Is(err, season.ErrSeasonNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, season.ErrInvalidSeasonDates):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process season request"})
+	}
+}
@@ -0,0 +1,65 @@
+package model_modules
+
+import (
+	"time"
+)
+
+// Challenge statuses
+const (
+	ChallengeStatusAwaitingChallenger = "awaiting_challenger" // The challenger plays first
+	ChallengeStatusOpen               = "open"                // Waiting for the opponent to play
+	ChallengeStatusCompleted          = "completed"
+	ChallengeStatusDeclined           = "declined"
+	ChallengeStatusCancelled          = "cancelled"
+	ChallengeStatusExpired            = "expired"
+)
+
+// Challenge is an asynchronous duel: both players play the same map and pearl seed on their own
+// time, the challenger first, and their results are compared once the opponent has played
+type Challenge struct {
+	ID             uint               `gorm:"primaryKey" json:"id"`
+	Code           string             `gorm:"uniqueIndex;not null" json:"code"` // Shared in challenge links
+	ChallengerID   uint               `gorm:"not null;index" json:"challenger_id"`
+	ChallengerName string             `gorm:"not null" json:"challenger_name"`
+	OpponentID     *uint              `gorm:"index" json:"opponent_id"` // Nil for a link challenge until someone takes it up
+	OpponentName   string             `json:"opponent_name"`
+	MapID          int                `gorm:"not null" json:"map_id"`
+	Seed           int64              `gorm:"not null" json:"-"`              // Hidden so the pearls can't be worked out ahead of time
+	Days           int                `gorm:"not null;default:3" json:"days"` // Days the opponent has to play once challenged
+	Status         string             `gorm:"not null;default:'awaiting_challenger';index" json:"status"`
+	WinnerID       *uint              `json:"winner_id"` // Nil for a draw or an unfinished challenge
+	Attempts       []ChallengeAttempt `gorm:"foreignKey:ChallengeID" json:"attempts,omitempty"`
+	ExpiresAt      time.Time          `gorm:"not null;index" json:"expires_at"`
+	CompletedAt    *time.Time         `json:"completed_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// TableName for Challenge
+func (Challenge) TableName() string {
+	return "challenges"
+}
+
+// ChallengeAttempt is one player's game of a challenge. Its result is copied from the game
+// session once the game ends.
+type ChallengeAttempt struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	ChallengeID     uint       `gorm:"not null;uniqueIndex:idx_challenge_player;index" json:"challenge_id"`
+	PlayerID        uint       `gorm:"not null;uniqueIndex:idx_challenge_player;index" json:"player_id"`
+	Username        string     `gorm:"not null" json:"username"`
+	SessionToken    string     `gorm:"not null;index" json:"-"`
+	Score           int        `gorm:"default:0" json:"score"`
+	PearlsCollected int        `gorm:"default:0" json:"pearls_collected"`
+	TotalMoves      int        `gorm:"default:0" json:"total_moves"`
+	Completed       bool       `gorm:"default:false" json:"completed"` // Reached the map's target score
+	CompletionTime  *int64     `json:"completion_time"`                // milliseconds
+	StartedAt       time.Time  `gorm:"not null" json:"started_at"`
+	FinishedAt      *time.Time `gorm:"index" json:"finished_at"` // Nil while the game is being played
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName for ChallengeAttempt
+func (ChallengeAttempt) TableName() string {
+	return "challenge_attempts"
+}
@@ -0,0 +1,30 @@
+package model_modules
+
+import (
+	"time"
+)
+
+// Friendship statuses
+const (
+	FriendshipPending  = "pending" // Sent by the player, waiting for the friend to accept
+	FriendshipAccepted = "accepted"
+	FriendshipBlocked  = "blocked" // The player blocked the other player
+)
+
+// Friendship links two players. A friend request is a row from the player who sent it to the
+// player who received it, and the same row stands for both players once accepted. A block is a
+// row from the blocking player and replaces any other row from them to the blocked player.
+type Friendship struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	PlayerID   uint       `gorm:"not null;uniqueIndex:idx_friendship_pair;index" json:"player_id"`
+	FriendID   uint       `gorm:"not null;uniqueIndex:idx_friendship_pair;index" json:"friend_id"`
+	Status     string     `gorm:"not null;default:'pending';index" json:"status"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName for Friendship
+func (Friendship) TableName() string {
+	return "friendships"
+}
@@ -21,6 +21,9 @@ type GameSession struct {
 	gameMapMutex sync.RWMutex `gorm:"-" json:"-"`
 	GameMapJSON  string       `json:"-"`
 	gameMap      [][]int      `gorm:"-" json:"game_map"`
+	// StateVersion goes up whenever the game map changes, so move responses can carry only the
+	// cells that changed to a client holding the previous version
+	StateVersion int64 `gorm:"default:0" json:"state_version"`
 
 	// Text grid for movement calculations
 	textGridMutex sync.RWMutex `gorm:"-" json:"-"`
@@ -34,6 +37,13 @@ type GameSession struct {
 	CurrentScore    int  `json:"current_score"`
 	FinalScore      *int `json:"final_score"`
 
+	// Display settings reported by the client for display-line motions (gj, gk, g0, g^, gm, g$)
+	WrapLines bool `gorm:"default:true" json:"wrap"`
+	TextWidth int  `gorm:"default:0" json:"text_width"`
+
+	// Vim options for this session, copied from the player's preferences when the game starts
+	VimOptions VimOptions `gorm:"embedded;embeddedPrefix:vim_" json:"vim_options"`
+
 	// Move tracking with mutex
 	moveMutex         sync.Mutex `gorm:"-" json:"-"`
 	TotalMoves        int        `json:"total_moves"`
@@ -52,6 +62,11 @@ type GameSession struct {
 	IsMultiplayer     bool    `json:"is_multiplayer"`
 	MultiplayerGameID *string `json:"multiplayer_game_id"`
 
+	// Challenge fields; a challenge game is laid out from a pearl seed its players share.
+	// The seed stays hidden so nobody can work out where the next pearls will appear.
+	ChallengeID *uint  `gorm:"index" json:"challenge_id"`
+	PearlSeed   *int64 `json:"-"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -61,6 +76,9 @@ func (gs *GameSession) BeforeCreate(tx *gorm.DB) error {
 	gs.SessionToken = uuid.New().String()
 	now := time.Now()
 	gs.StartTime = &now
+	if !gs.VimOptions.IsSet() {
+		gs.VimOptions = DefaultVimOptions()
+	}
 	return nil
 }
 
@@ -134,6 +152,7 @@ func (gs *GameSession) SetGameMap(gameMap [][]int) {
 		gs.gameMap[i] = make([]int, len(row))
 		copy(gs.gameMap[i], row)
 	}
+	gs.StateVersion++
 }
 
 // GetTextGrid returns a copy of the text grid safely
@@ -191,6 +210,7 @@ func (gs *GameSession) ProcessMoveWithRateLimit(newRow, newCol, preferredCol int
 	if gs.gameMap != nil {
 		gs.gameMap[gs.CurrentRow][gs.CurrentCol] = 0
 		gs.gameMap[newRow][newCol] = 1
+		gs.StateVersion++
 	}
 
 	// Update position
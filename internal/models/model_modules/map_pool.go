@@ -0,0 +1,17 @@
+package model_modules
+
+import (
+	"time"
+)
+
+// RankedMapPoolEntry is a map admins put in the ranked map pool that matchmade games draw from
+type RankedMapPoolEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MapID     int       `gorm:"not null;uniqueIndex" json:"map_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName for RankedMapPoolEntry
+func (RankedMapPoolEntry) TableName() string {
+	return "ranked_map_pool"
+}
@@ -0,0 +1,31 @@
+package model_modules
+
+import (
+	"time"
+)
+
+// Kinds of chat messages players send during a multiplayer game
+const (
+	ChatKindQuick = "quick" // One of the canned messages
+	ChatKindEmote = "emote"
+	ChatKindText  = "text" // Free text, only between friends
+)
+
+// MatchChatMessage logs a chat message sent during a multiplayer game, for moderation
+type MatchChatMessage struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GameID    string    `gorm:"not null;index" json:"game_id"`
+	MatchID   string    `gorm:"index" json:"match_id"`
+	PlayerID  uint      `gorm:"not null;index" json:"player_id"`
+	Username  string    `gorm:"not null" json:"username"`
+	Kind      string    `gorm:"not null" json:"kind"`
+	Content   string    `gorm:"not null" json:"content"`             // What the player sent
+	Delivered string    `gorm:"not null" json:"delivered"`           // What the other players saw
+	Filtered  bool      `gorm:"default:false;index" json:"filtered"` // Profanity was masked
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName for MatchChatMessage
+func (MatchChatMessage) TableName() string {
+	return "match_chat_messages"
+}
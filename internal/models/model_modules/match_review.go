@@ -0,0 +1,45 @@
+package model_modules
+
+import (
+	"time"
+)
+
+// Match review statuses
+const (
+	MatchReviewPending   = "pending"
+	MatchReviewCleared   = "cleared"   // The play was fair; a held result counts toward stats
+	MatchReviewConfirmed = "confirmed" // The player cheated; a held result stays out of stats
+)
+
+// MatchReview is a player's play in a multiplayer game that anti-cheat flagged for an admin to
+// review, with the figures that got it flagged
+type MatchReview struct {
+	ID                   uint       `gorm:"primaryKey" json:"id"`
+	GameID               string     `gorm:"not null;index" json:"game_id"`
+	MatchID              string     `gorm:"index" json:"match_id"`
+	GameResultID         *uint      `gorm:"index" json:"game_result_id"`
+	PlayerID             uint       `gorm:"not null;index" json:"player_id"`
+	Username             string     `gorm:"not null" json:"username"`
+	Flags                string     `gorm:"not null" json:"flags"` // Comma-separated reasons
+	Moves                int        `gorm:"default:0" json:"moves"`
+	MedianIntervalMs     float64    `gorm:"default:0" json:"median_interval_ms"`
+	IntervalSpread       float64    `gorm:"default:0" json:"interval_spread"`
+	JudgedMoves          int        `gorm:"default:0" json:"judged_moves"`
+	OptimalMoves         int        `gorm:"default:0" json:"optimal_moves"`
+	LongestOptimalStreak int        `gorm:"default:0" json:"longest_optimal_streak"`
+	InstantReactions     int        `gorm:"default:0" json:"instant_reactions"`
+	FastestReactionMs    float64    `gorm:"default:0" json:"fastest_reaction_ms"`
+	CountdownMoves       int        `gorm:"default:0" json:"countdown_moves"`
+	ResultHeld           bool       `gorm:"default:false" json:"result_held"` // The game's result was kept out of stats
+	Status               string     `gorm:"not null;default:'pending';index" json:"status"`
+	ReviewedBy           string     `json:"reviewed_by,omitempty"`
+	ReviewNotes          string     `gorm:"type:text" json:"review_notes,omitempty"`
+	ReviewedAt           *time.Time `json:"reviewed_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// TableName for MatchReview
+func (MatchReview) TableName() string {
+	return "match_reviews"
+}
@@ -13,6 +13,9 @@ type MatchmakingQueue struct {
 	Player            Player    `gorm:"foreignKey:PlayerID" json:"player,omitempty"`
 	Username          string    `gorm:"not null" json:"username"`
 	SelectedCharacter string    `gorm:"default:boba" json:"selected_character"`
+	Mode              string    `gorm:"default:duel" json:"mode"`
+	RoomSize          int       `gorm:"default:2" json:"room_size"`
+	Partner           string    `json:"partner"` // Username of the teammate the player queued with
 	QueuedAt          time.Time `gorm:"not null" json:"queued_at"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
@@ -79,9 +82,20 @@ type MultiplayerGameResult struct {
 	Player2FinalScore  int       `gorm:"default:0" json:"player2_final_score"`
 	WinnerID           *uint     `gorm:"index" json:"winner_id"`
 	Winner             *Player   `gorm:"foreignKey:WinnerID" json:"winner,omitempty"`
+	WinningTeam        int       `gorm:"default:0" json:"winning_team"` // Team games have a winning team rather than a winner; 0 otherwise
 	GameDuration       int       `gorm:"default:0" json:"game_duration"` // in seconds
 	CompletionType     string    `gorm:"default:normal" json:"completion_type"` // normal, timeout, disconnection
 	MapID              uint      `gorm:"not null;index" json:"map_id"`
+	Mode               string    `gorm:"default:duel;index" json:"mode"` // duel, free_for_all, teams
+	RuleSet            string    `gorm:"default:first_to;index" json:"rule_set"` // Win condition the game was played with
+	PlayerCount        int       `gorm:"default:2" json:"player_count"`
+	Unranked           bool      `gorm:"default:false;index" json:"unranked"` // Private lobby games that don't count toward stats
+	SeasonID           *uint     `gorm:"index" json:"season_id"` // Ranked season the game was played in
+	SeriesID           string    `gorm:"index" json:"series_id,omitempty"` // Best-of series the game was played in
+	SeriesGame         int       `gorm:"default:0" json:"series_game,omitempty"` // Number of the game within its series
+	HeldForReview      bool      `gorm:"default:false;index" json:"held_for_review"` // Flagged by anti-cheat; stats wait for an admin's review
+	Participants       []MultiplayerGameParticipant `gorm:"foreignKey:GameResultID" json:"participants,omitempty"`
+	MapVeto            []MultiplayerMapVetoAction   `gorm:"foreignKey:GameResultID" json:"map_veto,omitempty"` // Ranked duels ban and pick their map
 	CompletedAt        time.Time `gorm:"not null" json:"completed_at"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
@@ -98,6 +112,76 @@ func (mgr *MultiplayerGameResult) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// MultiplayerGameParticipant records one player's placement in a completed multiplayer game.
+// Player1 and Player2 on the game result hold the first two seats; participants cover every seat.
+type MultiplayerGameParticipant struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	GameResultID   uint      `gorm:"not null;index" json:"game_result_id"`
+	PlayerID       uint      `gorm:"not null;index" json:"player_id"`
+	Player         Player    `gorm:"foreignKey:PlayerID" json:"player,omitempty"`
+	Username       string    `gorm:"not null" json:"username"`
+	Character      string    `gorm:"default:boba" json:"character"`
+	CharacterLevel *int      `gorm:"default:null" json:"character_level"`
+	Seat           int       `gorm:"default:0" json:"seat"`
+	FinalScore     int       `gorm:"default:0" json:"final_score"`
+	Placement      int       `gorm:"not null" json:"placement"` // 1 is first place; tied scores share a placement
+	LeftGame       bool      `gorm:"default:false" json:"left_game"`
+	Team           int       `gorm:"default:0" json:"team"` // 1 or 2 in a team game; 0 otherwise
+	TeamScore      int       `gorm:"default:0" json:"team_score"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName for MultiplayerGameParticipant
+func (MultiplayerGameParticipant) TableName() string {
+	return "multiplayer_game_participants"
+}
+
+// MultiplayerMapVetoAction records one ban or pick of the map veto played before a game
+type MultiplayerMapVetoAction struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	GameResultID uint      `gorm:"not null;index" json:"game_result_id"`
+	Step         int       `gorm:"not null" json:"step"` // Order of the action, starting at 1
+	PlayerID     uint      `gorm:"index" json:"player_id"` // 0 when the server decided the map
+	Username     string    `json:"username"`
+	Action       string    `gorm:"not null" json:"action"` // ban, pick, decider
+	MapID        int       `gorm:"not null" json:"map_id"`
+	MapName      string    `json:"map_name"`
+	Auto         bool      `gorm:"default:false" json:"auto"` // Made for a player who ran out of time
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName for MultiplayerMapVetoAction
+func (MultiplayerMapVetoAction) TableName() string {
+	return "multiplayer_map_veto_actions"
+}
+
+// MultiplayerSeriesResult records a best-of series of rematches between two players. Each of its
+// games is also recorded as a MultiplayerGameResult with the series ID.
+type MultiplayerSeriesResult struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	SeriesID        string    `gorm:"not null;uniqueIndex" json:"series_id"`
+	BestOf          int       `gorm:"not null" json:"best_of"`
+	Player1ID       uint      `gorm:"not null;index" json:"player1_id"`
+	Player1Username string    `gorm:"not null" json:"player1_username"`
+	Player1Wins     int       `gorm:"default:0" json:"player1_wins"`
+	Player2ID       uint      `gorm:"not null;index" json:"player2_id"`
+	Player2Username string    `gorm:"not null" json:"player2_username"`
+	Player2Wins     int       `gorm:"default:0" json:"player2_wins"`
+	GamesPlayed     int       `gorm:"default:0" json:"games_played"` // Including tied games
+	WinnerID        *uint     `gorm:"index" json:"winner_id"`
+	CompletionType  string    `gorm:"default:normal" json:"completion_type"` // normal, forfeit, abandoned
+	Ranked          bool      `gorm:"default:false" json:"ranked"`
+	StartedAt       time.Time `json:"started_at"`
+	CompletedAt     time.Time `gorm:"not null" json:"completed_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName for MultiplayerSeriesResult
+func (MultiplayerSeriesResult) TableName() string {
+	return "multiplayer_series_results"
+}
+
 // MultiplayerPlayerStats represents aggregated statistics for a player in multiplayer games
 type MultiplayerPlayerStats struct {
 	ID                    uint      `gorm:"primaryKey" json:"id"`
@@ -114,8 +198,21 @@ type MultiplayerPlayerStats struct {
 	TotalScore            int       `gorm:"default:0" json:"total_score"`
 	AverageScore          float64   `gorm:"default:0" json:"average_score"`
 	HighestScore          int       `gorm:"default:0" json:"highest_score"`
+	PlacedGames           int       `gorm:"default:0" json:"placed_games"`
+	TeamGamesPlayed       int       `gorm:"default:0" json:"team_games_played"`
+	TeamWins              int       `gorm:"default:0" json:"team_wins"`
+	TeamWinRate           float64   `gorm:"default:0" json:"team_win_rate"`
+	TotalTeamScore        int       `gorm:"default:0" json:"total_team_score"` // Combined score of the player's teams
+	TotalPlacement        int       `gorm:"default:0" json:"total_placement"`
+	AveragePlacement      float64   `gorm:"default:0" json:"average_placement"`
 	TotalGameTimeSeconds  int       `gorm:"default:0" json:"total_game_time_seconds"`
 	AverageGameTime       float64   `gorm:"default:0" json:"average_game_time"`
+	Rating                float64   `gorm:"default:1500" json:"rating"`             // Glicko-2 rating
+	RatingDeviation       float64   `gorm:"default:350" json:"rating_deviation"`    // Glicko-2 rating deviation
+	RatingVolatility      float64   `gorm:"default:0.06" json:"rating_volatility"`  // Glicko-2 volatility
+	ConservativeRating    float64   `gorm:"default:800;index" json:"conservative_rating"` // Rating minus twice the deviation, used for ranking
+	RatedGames            int       `gorm:"default:0" json:"rated_games"`
+	SeasonGamesPlayed     int       `gorm:"default:0" json:"season_games_played"` // Ranked games in the current season
 	LastPlayedAt          *time.Time `json:"last_played_at"`
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
@@ -135,6 +232,15 @@ func (mps *MultiplayerPlayerStats) CalculateWinRate() {
 	}
 }
 
+// CalculateTeamWinRate calculates the win rate percentage over team games
+func (mps *MultiplayerPlayerStats) CalculateTeamWinRate() {
+	if mps.TeamGamesPlayed > 0 {
+		mps.TeamWinRate = float64(mps.TeamWins) / float64(mps.TeamGamesPlayed) * 100
+	} else {
+		mps.TeamWinRate = 0
+	}
+}
+
 // CalculateAverageScore calculates the average score per game
 func (mps *MultiplayerPlayerStats) CalculateAverageScore() {
 	if mps.TotalGamesPlayed > 0 {
@@ -151,4 +257,13 @@ func (mps *MultiplayerPlayerStats) CalculateAverageGameTime() {
 	} else {
 		mps.AverageGameTime = 0
 	}
+}
+
+// CalculateAveragePlacement calculates the average finishing position over games with a recorded placement
+func (mps *MultiplayerPlayerStats) CalculateAveragePlacement() {
+	if mps.PlacedGames > 0 {
+		mps.AveragePlacement = float64(mps.TotalPlacement) / float64(mps.PlacedGames)
+	} else {
+		mps.AveragePlacement = 0
+	}
 }
\ No newline at end of file
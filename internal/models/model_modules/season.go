@@ -0,0 +1,63 @@
+package model_modules
+
+import (
+	"time"
+)
+
+// Ranked season statuses
+const (
+	SeasonStatusScheduled = "scheduled" // Created by an admin, not started yet
+	SeasonStatusActive    = "active"    // Ranked games count toward this season
+	SeasonStatusArchived  = "archived"  // Ended; standings are frozen
+)
+
+// RankedSeason is a time-boxed ranked season set up by an admin
+type RankedSeason struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Number     int        `gorm:"not null;uniqueIndex" json:"number"`
+	Name       string     `gorm:"not null" json:"name"`
+	StartsAt   time.Time  `gorm:"not null;index" json:"starts_at"`
+	EndsAt     time.Time  `gorm:"not null;index" json:"ends_at"`
+	Status     string     `gorm:"not null;default:'scheduled';index" json:"status"`
+	StartedAt  *time.Time `json:"started_at"`  // When ratings were soft-reset for this season
+	ArchivedAt *time.Time `json:"archived_at"` // When standings were frozen
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName for RankedSeason
+func (RankedSeason) TableName() string {
+	return "ranked_seasons"
+}
+
+// SeasonStanding is a player's frozen multiplayer stats and rating at the end of a season
+type SeasonStanding struct {
+	ID                 uint         `gorm:"primaryKey" json:"id"`
+	SeasonID           uint         `gorm:"not null;uniqueIndex:idx_season_player;index" json:"season_id"`
+	Season             RankedSeason `gorm:"foreignKey:SeasonID" json:"-"`
+	PlayerID           uint         `gorm:"not null;uniqueIndex:idx_season_player;index" json:"player_id"`
+	Username           string       `gorm:"not null" json:"username"`
+	SelectedCharacter  string       `json:"selected_character"`
+	Rank               int          `gorm:"not null;index" json:"rank"`
+	Rating             float64      `json:"rating"`
+	RatingDeviation    float64      `json:"rating_deviation"`
+	RatingVolatility   float64      `json:"rating_volatility"`
+	ConservativeRating float64      `json:"conservative_rating"`
+	SeasonGamesPlayed  int          `json:"season_games_played"`
+	TotalGamesPlayed   int          `json:"total_games_played"`
+	TotalWins          int          `json:"total_wins"`
+	TotalLosses        int          `json:"total_losses"`
+	TotalTies          int          `json:"total_ties"`
+	WinRate            float64      `json:"win_rate"`
+	AverageScore       float64      `json:"average_score"`
+	HighestScore       int          `json:"highest_score"`
+	AveragePlacement   float64      `json:"average_placement"`
+	RewardTier         string       `json:"reward_tier,omitempty"`      // Empty when the player earned no reward
+	RewardCharacter    string       `json:"reward_character,omitempty"` // Cosmetic granted for the tier
+	CreatedAt          time.Time    `json:"created_at"`
+}
+
+// TableName for SeasonStanding
+func (SeasonStanding) TableName() string {
+	return "season_standings"
+}
@@ -0,0 +1,154 @@
+package model_modules
+
+import (
+	"time"
+)
+
+// Tournament formats
+const (
+	TournamentFormatSingleElimination = "single_elimination"
+	TournamentFormatDoubleElimination = "double_elimination"
+	TournamentFormatSwiss             = "swiss"
+)
+
+// Tournament statuses
+const (
+	TournamentStatusRegistration = "registration" // Open for players to register
+	TournamentStatusInProgress   = "in_progress"  // Seeded; matches are being played
+	TournamentStatusCompleted    = "completed"
+	TournamentStatusCancelled    = "cancelled"
+)
+
+// Tournament seeding methods
+const (
+	TournamentSeedingRating       = "rating"       // Conservative multiplayer rating, as on the leaderboard
+	TournamentSeedingStats        = "stats"        // Win rate, then average score
+	TournamentSeedingRegistration = "registration" // Registration order
+)
+
+// Tournament brackets a match can belong to
+const (
+	TournamentBracketWinners    = "winners"     // The only bracket of single elimination
+	TournamentBracketLosers     = "losers"      // Double elimination players get a second life here
+	TournamentBracketGrandFinal = "grand_final" // Winners bracket champion against losers bracket champion
+	TournamentBracketSwiss      = "swiss"
+)
+
+// Tournament match statuses
+const (
+	TournamentMatchPending    = "pending"     // Waiting for the matches that feed it
+	TournamentMatchInProgress = "in_progress" // Its series is being played
+	TournamentMatchCompleted  = "completed"
+)
+
+// Outcomes of a match that feed a slot of a later match
+const (
+	TournamentOutcomeWinner = "winner"
+	TournamentOutcomeLoser  = "loser"
+)
+
+// Tournament is a bracket or Swiss event set up by an admin
+type Tournament struct {
+	ID               uint                `gorm:"primaryKey" json:"id"`
+	Name             string              `gorm:"not null" json:"name"`
+	Format           string              `gorm:"not null" json:"format"`
+	Status           string              `gorm:"not null;default:'registration';index" json:"status"`
+	Seeding          string              `gorm:"not null;default:'rating'" json:"seeding"`
+	MaxPlayers       int                 `gorm:"not null" json:"max_players"`
+	BestOf           string              `gorm:"not null;default:'1'" json:"best_of"` // Comma-separated games per series by round; later rounds use the last entry
+	SwissRounds      int                 `gorm:"default:0" json:"swiss_rounds"`       // Rounds of a Swiss tournament
+	MapID            int                 `gorm:"default:0" json:"map_id"`             // 0 plays a random map
+	TargetScore      int                 `gorm:"default:0" json:"target_score"`       // 0 uses the default target
+	TimeLimitSeconds int                 `gorm:"default:0" json:"time_limit_seconds"`
+	Ranked           bool                `gorm:"default:false" json:"ranked"`    // Whether games count toward player stats
+	CurrentRound     int                 `gorm:"default:0" json:"current_round"` // Swiss round being played
+	WinnerID         *uint               `gorm:"index" json:"winner_id"`
+	StartedAt        *time.Time          `json:"started_at"`
+	CompletedAt      *time.Time          `json:"completed_at"`
+	Entrants         []TournamentEntrant `gorm:"foreignKey:TournamentID" json:"entrants,omitempty"`
+	Matches          []TournamentMatch   `gorm:"foreignKey:TournamentID" json:"matches,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+}
+
+// TableName for Tournament
+func (Tournament) TableName() string {
+	return "tournaments"
+}
+
+// TournamentEntrant is a player registered for a tournament and their standing in it
+type TournamentEntrant struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	TournamentID uint      `gorm:"not null;uniqueIndex:idx_tournament_player;index" json:"tournament_id"`
+	PlayerID     uint      `gorm:"not null;uniqueIndex:idx_tournament_player;index" json:"player_id"`
+	Username     string    `gorm:"not null" json:"username"`
+	Character    string    `gorm:"default:boba" json:"character"`
+	Seed         int       `gorm:"default:0" json:"seed"`       // 1 is the top seed; set when the tournament starts
+	SeedValue    float64   `gorm:"default:0" json:"seed_value"` // Rating or stat the seed was taken from
+	MatchWins    int       `gorm:"default:0" json:"match_wins"`
+	MatchLosses  int       `gorm:"default:0" json:"match_losses"`
+	Points       float64   `gorm:"default:0" json:"points"` // Swiss points: a win or a bye is worth 1
+	Byes         int       `gorm:"default:0" json:"byes"`
+	Eliminated   bool      `gorm:"default:false" json:"eliminated"`
+	Placement    int       `gorm:"default:0" json:"placement"` // Final placement; tied players share it
+	RegisteredAt time.Time `gorm:"not null" json:"registered_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName for TournamentEntrant
+func (TournamentEntrant) TableName() string {
+	return "tournament_entrants"
+}
+
+// TournamentMatch is a best-of-N series between two players. Each slot is either filled when the
+// bracket is drawn, or by the winner or loser of an earlier match once it completes.
+type TournamentMatch struct {
+	ID             uint             `gorm:"primaryKey" json:"id"`
+	TournamentID   uint             `gorm:"not null;index" json:"tournament_id"`
+	Bracket        string           `gorm:"not null" json:"bracket"`
+	Round          int              `gorm:"not null" json:"round"`
+	Position       int              `gorm:"not null" json:"position"` // Order within the round, from the top of the bracket
+	BestOf         int              `gorm:"not null;default:1" json:"best_of"`
+	Status         string           `gorm:"not null;default:'pending';index" json:"status"`
+	Player1ID      *uint            `json:"player1_id"`
+	Player2ID      *uint            `json:"player2_id"`
+	Player1Wins    int              `gorm:"default:0" json:"player1_wins"`
+	Player2Wins    int              `gorm:"default:0" json:"player2_wins"`
+	Source1MatchID *uint            `json:"source1_match_id"` // Match whose outcome fills the first slot
+	Source1Outcome string           `json:"source1_outcome,omitempty"`
+	Source2MatchID *uint            `json:"source2_match_id"`
+	Source2Outcome string           `json:"source2_outcome,omitempty"`
+	Conditional    bool             `gorm:"default:false" json:"conditional"` // Grand final reset, played only if the losers bracket champion wins
+	Bye            bool             `gorm:"default:false" json:"bye"`         // Completed without being played
+	WinnerID       *uint            `json:"winner_id"`
+	LoserID        *uint            `json:"loser_id"`
+	MatchID        string           `gorm:"index" json:"match_id,omitempty"` // Multiplayer match of the game being played
+	GameID         string           `gorm:"index" json:"game_id,omitempty"`  // Multiplayer game being played
+	Games          []TournamentGame `gorm:"foreignKey:TournamentMatchID" json:"games,omitempty"`
+	StartedAt      *time.Time       `json:"started_at"`
+	CompletedAt    *time.Time       `json:"completed_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// TableName for TournamentMatch
+func (TournamentMatch) TableName() string {
+	return "tournament_matches"
+}
+
+// TournamentGame is one game of a tournament match's series
+type TournamentGame struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	TournamentMatchID uint       `gorm:"not null;index" json:"tournament_match_id"`
+	Number            int        `gorm:"not null" json:"number"` // 1 for the first game of the series
+	GameSessionID     string     `gorm:"not null;index" json:"game_session_id"`
+	WinnerID          *uint      `json:"winner_id"` // Nil for a tied game, which is replayed
+	CompletedAt       *time.Time `json:"completed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// TableName for TournamentGame
+func (TournamentGame) TableName() string {
+	return "tournament_games"
+}
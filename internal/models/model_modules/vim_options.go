@@ -0,0 +1,40 @@
+package model_modules
+
+import "time"
+
+// VimOptions holds the vim option values that change how motions behave
+type VimOptions struct {
+	WhichWrap   string `json:"whichwrap"`
+	StartOfLine bool   `json:"startofline"`
+	VirtualEdit string `json:"virtualedit"`
+	IsKeyword   string `json:"iskeyword"`
+	WrapScan    bool   `json:"wrapscan"`
+	TabStop     int    `json:"tabstop"`
+}
+
+// DefaultVimOptions returns vim's default option values
+func DefaultVimOptions() VimOptions {
+	return VimOptions{
+		WhichWrap:   "b,s",
+		StartOfLine: false,
+		VirtualEdit: "",
+		IsKeyword:   "@,48-57,_,192-255",
+		WrapScan:    true,
+		TabStop:     8,
+	}
+}
+
+// IsSet reports whether the options were initialised (a zero tabstop is never valid)
+func (o VimOptions) IsSet() bool {
+	return o.TabStop > 0
+}
+
+// PlayerVimOptions stores a registered player's preferred vim options, copied into each new game session
+type PlayerVimOptions struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	PlayerID   uint       `gorm:"uniqueIndex;not null" json:"player_id"`
+	Player     Player     `gorm:"foreignKey:PlayerID" json:"-"`
+	VimOptions VimOptions `gorm:"embedded" json:"options"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -15,7 +15,22 @@ type PlayerCharacterOwnership = model_modules.PlayerCharacterOwnership
 type MatchmakingQueue = model_modules.MatchmakingQueue
 type OnlineMatch = model_modules.OnlineMatch
 type MultiplayerGameResult = model_modules.MultiplayerGameResult
+type MultiplayerGameParticipant = model_modules.MultiplayerGameParticipant
+type MultiplayerMapVetoAction = model_modules.MultiplayerMapVetoAction
+type MultiplayerSeriesResult = model_modules.MultiplayerSeriesResult
 type MultiplayerPlayerStats = model_modules.MultiplayerPlayerStats
+type RankedSeason = model_modules.RankedSeason
+type SeasonStanding = model_modules.SeasonStanding
+type Tournament = model_modules.Tournament
+type TournamentEntrant = model_modules.TournamentEntrant
+type TournamentMatch = model_modules.TournamentMatch
+type TournamentGame = model_modules.TournamentGame
+type Challenge = model_modules.Challenge
+type ChallengeAttempt = model_modules.ChallengeAttempt
+type Friendship = model_modules.Friendship
+type MatchChatMessage = model_modules.MatchChatMessage
+type MatchReview = model_modules.MatchReview
+type RankedMapPoolEntry = model_modules.RankedMapPoolEntry
 type Survey = model_modules.Survey
 type SurveyQuestion = model_modules.SurveyQuestion
 type SurveyVote = model_modules.SurveyVote
@@ -23,6 +38,72 @@ type GameError = model_modules.GameError
 type Newsletter = model_modules.Newsletter
 type NewsletterRead = model_modules.NewsletterRead
 type Admin = model_modules.Admin
+type VimOptions = model_modules.VimOptions
+type PlayerVimOptions = model_modules.PlayerVimOptions
+
+// Re-export functions
+var DefaultVimOptions = model_modules.DefaultVimOptions
+
+// Re-export ranked season statuses
+const (
+	SeasonStatusScheduled = model_modules.SeasonStatusScheduled
+	SeasonStatusActive    = model_modules.SeasonStatusActive
+	SeasonStatusArchived  = model_modules.SeasonStatusArchived
+)
+
+// Re-export tournament formats, statuses, seeding methods, brackets and match outcomes
+const (
+	TournamentFormatSingleElimination = model_modules.TournamentFormatSingleElimination
+	TournamentFormatDoubleElimination = model_modules.TournamentFormatDoubleElimination
+	TournamentFormatSwiss             = model_modules.TournamentFormatSwiss
+	TournamentStatusRegistration      = model_modules.TournamentStatusRegistration
+	TournamentStatusInProgress        = model_modules.TournamentStatusInProgress
+	TournamentStatusCompleted         = model_modules.TournamentStatusCompleted
+	TournamentStatusCancelled         = model_modules.TournamentStatusCancelled
+	TournamentSeedingRating           = model_modules.TournamentSeedingRating
+	TournamentSeedingStats            = model_modules.TournamentSeedingStats
+	TournamentSeedingRegistration     = model_modules.TournamentSeedingRegistration
+	TournamentBracketWinners          = model_modules.TournamentBracketWinners
+	TournamentBracketLosers           = model_modules.TournamentBracketLosers
+	TournamentBracketGrandFinal       = model_modules.TournamentBracketGrandFinal
+	TournamentBracketSwiss            = model_modules.TournamentBracketSwiss
+	TournamentMatchPending            = model_modules.TournamentMatchPending
+	TournamentMatchInProgress         = model_modules.TournamentMatchInProgress
+	TournamentMatchCompleted          = model_modules.TournamentMatchCompleted
+	TournamentOutcomeWinner           = model_modules.TournamentOutcomeWinner
+	TournamentOutcomeLoser            = model_modules.TournamentOutcomeLoser
+)
+
+// Re-export challenge statuses
+const (
+	ChallengeStatusAwaitingChallenger = model_modules.ChallengeStatusAwaitingChallenger
+	ChallengeStatusOpen               = model_modules.ChallengeStatusOpen
+	ChallengeStatusCompleted          = model_modules.ChallengeStatusCompleted
+	ChallengeStatusDeclined           = model_modules.ChallengeStatusDeclined
+	ChallengeStatusCancelled          = model_modules.ChallengeStatusCancelled
+	ChallengeStatusExpired            = model_modules.ChallengeStatusExpired
+)
+
+// Re-export friendship statuses
+const (
+	FriendshipPending  = model_modules.FriendshipPending
+	FriendshipAccepted = model_modules.FriendshipAccepted
+	FriendshipBlocked  = model_modules.FriendshipBlocked
+)
+
+// Re-export chat message kinds
+const (
+	ChatKindQuick = model_modules.ChatKindQuick
+	ChatKindEmote = model_modules.ChatKindEmote
+	ChatKindText  = model_modules.ChatKindText
+)
+
+// Re-export match review statuses
+const (
+	MatchReviewPending   = model_modules.MatchReviewPending
+	MatchReviewCleared   = model_modules.MatchReviewCleared
+	MatchReviewConfirmed = model_modules.MatchReviewConfirmed
+)
 
 // Re-export error variables
 var (
@@ -0,0 +1,156 @@
+// Package rating implements the Glicko-2 rating system used to rank and match multiplayer players.
+// See http://www.glicko.net/glicko/glicko2.pdf for the algorithm.
+package rating
+
+import "math"
+
+// Glicko-2 constants
+const (
+	DefaultRating     = 1500.0
+	DefaultDeviation  = 350.0
+	DefaultVolatility = 0.06
+	MinDeviation      = 30.0 // Keeps ratings of very active players able to move
+	MaxDeviation      = DefaultDeviation
+
+	// Tau constrains how much volatility changes between rating periods
+	Tau = 0.5
+
+	glicko2Scale       = 173.7178
+	convergenceEpsilon = 0.000001
+)
+
+// Scores of a single game against one opponent
+const (
+	Win  = 1.0
+	Draw = 0.5
+	Loss = 0.0
+)
+
+// Rating is a player's Glicko-2 rating on the familiar 1500 scale
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// Outcome is the result of a game against one opponent, scored Win, Draw or Loss
+type Outcome struct {
+	Opponent Rating
+	Score    float64
+}
+
+// Default returns the rating of a player who hasn't played yet
+func Default() Rating {
+	return Rating{
+		Rating:     DefaultRating,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+// Conservative returns the rating the player is very likely to be above: the rating minus twice
+// the deviation. Ranking by it keeps players with few games from topping the leaderboard.
+func (r Rating) Conservative() float64 {
+	return r.Rating - 2*r.Deviation
+}
+
+// Decay grows the deviation for rating periods the player sat out, since their skill is less
+// certain the longer they don't play
+func (r Rating) Decay(periods int) Rating {
+	phi := r.Deviation / glicko2Scale
+	for i := 0; i < periods; i++ {
+		phi = math.Sqrt(phi*phi + r.Volatility*r.Volatility)
+	}
+	r.Deviation = clampDeviation(phi * glicko2Scale)
+	return r
+}
+
+// Update returns the rating after one rating period with the given outcomes. Opponent ratings
+// must be taken from before the period. Without outcomes only the deviation grows.
+func (r Rating) Update(outcomes []Outcome) Rating {
+	if len(outcomes) == 0 {
+		return r.Decay(1)
+	}
+
+	mu := (r.Rating - DefaultRating) / glicko2Scale
+	phi := r.Deviation / glicko2Scale
+	sigma := r.Volatility
+
+	// Estimated variance of the rating based on game outcomes only, and the estimated improvement
+	var varianceInverse, improvementSum float64
+	for _, outcome := range outcomes {
+		opponentMu := (outcome.Opponent.Rating - DefaultRating) / glicko2Scale
+		opponentPhi := outcome.Opponent.Deviation / glicko2Scale
+
+		g := reduceImpact(opponentPhi)
+		expected := expectedScore(mu, opponentMu, g)
+		varianceInverse += g * g * expected * (1 - expected)
+		improvementSum += g * (outcome.Score - expected)
+	}
+	variance := 1 / varianceInverse
+	delta := variance * improvementSum
+
+	newSigma := newVolatility(phi, sigma, variance, delta)
+
+	phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	newMu := mu + newPhi*newPhi*improvementSum
+
+	return Rating{
+		Rating:     newMu*glicko2Scale + DefaultRating,
+		Deviation:  clampDeviation(newPhi * glicko2Scale),
+		Volatility: newSigma,
+	}
+}
+
+// reduceImpact weighs an opponent's result by how certain their rating is
+func reduceImpact(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// expectedScore is the expected score against an opponent
+func expectedScore(mu, opponentMu, g float64) float64 {
+	return 1 / (1 + math.Exp(-g*(mu-opponentMu)))
+}
+
+// newVolatility finds the new volatility with the Illinois algorithm (step 5 of the paper)
+func newVolatility(phi, sigma, variance, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		numerator := ex * (delta*delta - phi*phi - variance - ex)
+		denominator := 2 * math.Pow(phi*phi+variance+ex, 2)
+		return numerator/denominator - (x-a)/(Tau*Tau)
+	}
+
+	upper := a
+	var lower float64
+	if delta*delta > phi*phi+variance {
+		lower = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*Tau) < 0 {
+			k++
+		}
+		lower = a - k*Tau
+	}
+
+	fUpper, fLower := f(upper), f(lower)
+	for math.Abs(lower-upper) > convergenceEpsilon {
+		next := upper + (upper-lower)*fUpper/(fLower-fUpper)
+		fNext := f(next)
+		if fNext*fLower <= 0 {
+			upper, fUpper = lower, fLower
+		} else {
+			fUpper /= 2
+		}
+		lower, fLower = next, fNext
+	}
+
+	return math.Exp(upper / 2)
+}
+
+// clampDeviation keeps a deviation within the allowed range
+func clampDeviation(deviation float64) float64 {
+	return math.Max(MinDeviation, math.Min(MaxDeviation, deviation))
+}
@@ -0,0 +1,74 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		player   Rating
+		outcomes []Outcome
+		want     Rating
+	}{
+		{
+			// The worked example of Glickman's paper (http://www.glicko.net/glicko/glicko2.pdf)
+			name:   "glickman example",
+			player: Rating{Rating: 1500, Deviation: 200, Volatility: 0.06},
+			outcomes: []Outcome{
+				{Opponent: Rating{Rating: 1400, Deviation: 30, Volatility: 0.06}, Score: Win},
+				{Opponent: Rating{Rating: 1550, Deviation: 100, Volatility: 0.06}, Score: Loss},
+				{Opponent: Rating{Rating: 1700, Deviation: 300, Volatility: 0.06}, Score: Loss},
+			},
+			want: Rating{Rating: 1464.06, Deviation: 151.52, Volatility: 0.05999},
+		},
+		{
+			name:     "win between new players",
+			player:   Default(),
+			outcomes: []Outcome{{Opponent: Default(), Score: Win}},
+			want:     Rating{Rating: 1662.31, Deviation: 290.32, Volatility: 0.06},
+		},
+		{
+			name:     "draw between new players",
+			player:   Default(),
+			outcomes: []Outcome{{Opponent: Default(), Score: Draw}},
+			want:     Rating{Rating: 1500, Deviation: 290.32, Volatility: 0.06},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.player.Update(tt.outcomes)
+			if math.Abs(got.Rating-tt.want.Rating) > 0.01 {
+				t.Errorf("rating = %.4f, want %.2f", got.Rating, tt.want.Rating)
+			}
+			if math.Abs(got.Deviation-tt.want.Deviation) > 0.01 {
+				t.Errorf("deviation = %.4f, want %.2f", got.Deviation, tt.want.Deviation)
+			}
+			if math.Abs(got.Volatility-tt.want.Volatility) > 0.00001 {
+				t.Errorf("volatility = %.6f, want %.5f", got.Volatility, tt.want.Volatility)
+			}
+		})
+	}
+}
+
+func TestUpdateWithoutOutcomes(t *testing.T) {
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+
+	got := player.Update(nil)
+	if got.Rating != player.Rating {
+		t.Errorf("rating = %.4f, want %.4f", got.Rating, player.Rating)
+	}
+	if want := math.Sqrt(200*200 + math.Pow(0.06*glicko2Scale, 2)); math.Abs(got.Deviation-want) > 0.0001 {
+		t.Errorf("deviation = %.4f, want %.4f", got.Deviation, want)
+	}
+}
+
+func TestDecayClampsDeviation(t *testing.T) {
+	player := Rating{Rating: 1500, Deviation: 340, Volatility: 0.06}
+
+	if got := player.Decay(100).Deviation; got != MaxDeviation {
+		t.Errorf("deviation = %.4f, want %.4f", got, MaxDeviation)
+	}
+}
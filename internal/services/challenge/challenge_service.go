@@ -0,0 +1,549 @@
+package challenge
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"boba-vim/internal/constant"
+	"boba-vim/internal/models"
+	"boba-vim/internal/services/cluster"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// ChallengeService runs asynchronous challenges. Both players play a solo game on the same map
+// and pearl seed in their own time, the challenger first; the opponent then has a few days to
+// play the same game. Challenge state lives in the database, so any instance can serve it; only
+// the instance holding the runner lease settles finished games and expires challenges.
+type ChallengeService struct {
+	db       *gorm.DB
+	node     *cluster.Node
+	baseURL  string
+	sessions SessionStarter
+	notifier Notifier
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewChallengeService creates a new challenge service
+func NewChallengeService(db *gorm.DB, node *cluster.Node, baseURL string, sessions SessionStarter, notifier Notifier) *ChallengeService {
+	return &ChallengeService{
+		db:       db,
+		node:     node,
+		baseURL:  baseURL,
+		sessions: sessions,
+		notifier: notifier,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start starts a goroutine that settles and expires challenges
+func (cs *ChallengeService) Start() {
+	ticker := time.NewTicker(ProcessInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !cs.node.HoldLease(runnerLease, runnerLeaseTTL) {
+					continue // Another node is running the challenges
+				}
+				cs.ProcessChallenges(time.Now())
+			case <-cs.stop:
+				return
+			}
+		}
+	}()
+
+	utils.Info("Started challenge runner")
+}
+
+// Stop stops settling challenges
+func (cs *ChallengeService) Stop() {
+	cs.stopOnce.Do(func() {
+		close(cs.stop)
+		cs.node.Store.ReleaseLease(runnerLease, cs.node.ID)
+	})
+}
+
+// CreateChallenge sends a challenge to another player, or opens one to share by link when no
+// opponent is named. The challenger plays first.
+func (cs *ChallengeService) CreateChallenge(challengerID uint, challengerName string, settings ChallengeSettings) (*ChallengeData, error) {
+	if constant.GetMapByID(settings.MapID) == nil {
+		return nil, ErrInvalidMap
+	}
+	if settings.Days == 0 {
+		settings.Days = DefaultDays
+	}
+	if settings.Days < 1 || settings.Days > MaxDays {
+		return nil, ErrInvalidDays
+	}
+
+	c := &models.Challenge{
+		ChallengerID:   challengerID,
+		ChallengerName: challengerName,
+		MapID:          settings.MapID,
+		Days:           settings.Days,
+		Status:         models.ChallengeStatusAwaitingChallenger,
+		ExpiresAt:      time.Now().Add(time.Duration(settings.Days) * 24 * time.Hour),
+	}
+
+	if opponentName := strings.TrimSpace(settings.Opponent); opponentName != "" {
+		var opponent models.Player
+		if err := cs.db.Where("username = ?", opponentName).First(&opponent).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrOpponentNotFound
+			}
+			return nil, err
+		}
+		if opponent.ID == challengerID {
+			return nil, ErrChallengeSelf
+		}
+		c.OpponentID = &opponent.ID
+		c.OpponentName = opponent.Username
+	}
+
+	seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		return nil, err
+	}
+	c.Seed = seed.Int64()
+
+	if c.Code, err = cs.generateCode(); err != nil {
+		return nil, err
+	}
+	if err := cs.db.Create(c).Error; err != nil {
+		return nil, err
+	}
+
+	utils.Info("%s created challenge %s on map %d", challengerName, c.Code, c.MapID)
+	return cs.challengeData(c), nil
+}
+
+// GetChallenge returns a challenge with the attempts played so far
+func (cs *ChallengeService) GetChallenge(code string) (*ChallengeData, error) {
+	c, err := findChallenge(cs.db.Preload("Attempts"), code)
+	if err != nil {
+		return nil, err
+	}
+	return cs.challengeData(c), nil
+}
+
+// ListChallenges returns the challenges a player sent or received, newest first. An empty status
+// lists every challenge.
+func (cs *ChallengeService) ListChallenges(playerID uint, status string, limit int) ([]ChallengeData, error) {
+	query := cs.db.Preload("Attempts").
+		Where("challenger_id = ? OR opponent_id = ?", playerID, playerID).
+		Order("created_at DESC").
+		Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var challenges []models.Challenge
+	if err := query.Find(&challenges).Error; err != nil {
+		return nil, err
+	}
+
+	data := make([]ChallengeData, len(challenges))
+	for i := range challenges {
+		data[i] = *cs.challengeData(&challenges[i])
+	}
+	return data, nil
+}
+
+// PlayChallenge starts a player's game of a challenge. The challenger plays first; after that the
+// opponent, or for a link challenge the first other player to take it up, plays the same map and
+// pearl seed. Each player gets one game.
+func (cs *ChallengeService) PlayChallenge(code string, playerID uint, username, selectedCharacter string) (map[string]interface{}, error) {
+	var c *models.Challenge
+	err := cs.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if c, err = findChallenge(tx.Preload("Attempts"), code); err != nil {
+			return err
+		}
+		if hasAttempt(c, playerID) {
+			return ErrAlreadyPlayed
+		}
+
+		switch {
+		case playerID == c.ChallengerID:
+			if c.Status != models.ChallengeStatusAwaitingChallenger || !time.Now().Before(c.ExpiresAt) {
+				return ErrChallengeClosed
+			}
+		case c.Status == models.ChallengeStatusAwaitingChallenger:
+			if c.OpponentID == nil || *c.OpponentID == playerID {
+				return ErrChallengerPlaysFirst
+			}
+			return ErrNotChallengePlayer
+		case c.Status != models.ChallengeStatusOpen || !time.Now().Before(c.ExpiresAt):
+			return ErrChallengeClosed
+		case c.OpponentID == nil:
+			// The first player to take up a link challenge becomes its opponent
+			result := tx.Model(&models.Challenge{}).
+				Where("id = ? AND opponent_id IS NULL", c.ID).
+				Updates(map[string]interface{}{"opponent_id": playerID, "opponent_name": username})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrChallengeTaken
+			}
+			c.OpponentID = &playerID
+			c.OpponentName = username
+		case *c.OpponentID != playerID:
+			return ErrNotChallengePlayer
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := cs.sessions.StartChallengeGame(username, selectedCharacter, c.MapID, c.Seed, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	sessionToken, _ := result["session_token"].(string)
+
+	attempt := models.ChallengeAttempt{
+		ChallengeID:  c.ID,
+		PlayerID:     playerID,
+		Username:     username,
+		SessionToken: sessionToken,
+		StartedAt:    time.Now(),
+	}
+	if err := cs.db.Create(&attempt).Error; err != nil {
+		// Another request started this player's game first; close the game just started
+		cs.db.Model(&models.GameSession{}).Where("session_token = ?", sessionToken).Update("is_active", false)
+		return nil, ErrAlreadyPlayed
+	}
+
+	utils.Info("%s started their game of challenge %s", username, c.Code)
+	result["challenge"] = cs.challengeData(c)
+	return result, nil
+}
+
+// DeclineChallenge lets the opponent turn a challenge down before playing it
+func (cs *ChallengeService) DeclineChallenge(code string, playerID uint) error {
+	c, err := findChallenge(cs.db.Preload("Attempts"), code)
+	if err != nil {
+		return err
+	}
+	if c.OpponentID == nil || *c.OpponentID != playerID {
+		return ErrNotChallengePlayer
+	}
+	if !isPending(c) || hasAttempt(c, playerID) {
+		return ErrChallengeClosed
+	}
+
+	if err := cs.close(c, models.ChallengeStatusDeclined); err != nil {
+		return err
+	}
+
+	cs.notify([]uint{c.ChallengerID}, MsgTypeChallengeDeclined,
+		fmt.Sprintf("%s declined your challenge", c.OpponentName), cs.challengeData(c))
+	return nil
+}
+
+// CancelChallenge lets the challenger withdraw a challenge the opponent hasn't played yet
+func (cs *ChallengeService) CancelChallenge(code string, playerID uint) error {
+	c, err := findChallenge(cs.db.Preload("Attempts"), code)
+	if err != nil {
+		return err
+	}
+	if c.ChallengerID != playerID {
+		return ErrNotChallengePlayer
+	}
+	if !isPending(c) || (c.OpponentID != nil && hasAttempt(c, *c.OpponentID)) {
+		return ErrChallengeClosed
+	}
+
+	if err := cs.close(c, models.ChallengeStatusCancelled); err != nil {
+		return err
+	}
+
+	if c.OpponentID != nil {
+		cs.notify([]uint{*c.OpponentID}, MsgTypeChallengeCancelled,
+			fmt.Sprintf("%s cancelled their challenge", c.ChallengerName), cs.challengeData(c))
+	}
+	return nil
+}
+
+// ProcessChallenges records the results of challenge games that have ended, settles challenges
+// both players have played and expires those whose time ran out
+func (cs *ChallengeService) ProcessChallenges(now time.Time) {
+	var attempts []models.ChallengeAttempt
+	if err := cs.db.Where("finished_at IS NULL").Find(&attempts).Error; err != nil {
+		utils.Error("Failed to load challenge games: %v", err)
+		return
+	}
+	for i := range attempts {
+		if err := cs.finishAttempt(&attempts[i], now); err != nil {
+			utils.Error("Failed to record challenge game %d: %v", attempts[i].ID, err)
+		}
+	}
+
+	var expired []models.Challenge
+	if err := cs.db.Preload("Attempts").
+		Where("status IN ? AND expires_at <= ?", []string{models.ChallengeStatusAwaitingChallenger, models.ChallengeStatusOpen}, now).
+		Find(&expired).Error; err != nil {
+		utils.Error("Failed to load expired challenges: %v", err)
+		return
+	}
+	for i := range expired {
+		if err := cs.expire(&expired[i]); err != nil {
+			utils.Error("Failed to expire challenge %s: %v", expired[i].Code, err)
+		}
+	}
+}
+
+// finishAttempt records the result of a challenge game once its session has ended and moves the
+// challenge on. Games still being played are left alone.
+func (cs *ChallengeService) finishAttempt(attempt *models.ChallengeAttempt, now time.Time) error {
+	var session models.GameSession
+	err := cs.db.Where("session_token = ?", attempt.SessionToken).First(&session).Error
+	switch {
+	case err == nil && session.IsActive:
+		return nil
+	case err == nil:
+		attempt.Score = session.CurrentScore
+		if session.FinalScore != nil {
+			attempt.Score = *session.FinalScore
+		}
+		attempt.PearlsCollected = session.PearlsCollected
+		attempt.TotalMoves = session.TotalMoves
+		attempt.Completed = session.IsCompleted
+		attempt.CompletionTime = session.CompletionTime
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+	// A game whose session is gone counts as played without a score
+	attempt.FinishedAt = &now
+
+	var c models.Challenge
+	err = cs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(attempt).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Attempts").First(&c, attempt.ChallengeID).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case !isPending(&c):
+			return nil // Cancelled or expired while the game was played
+		case attempt.PlayerID == c.ChallengerID:
+			// The opponent's days to play start once the challenger has set the result to beat
+			c.Status = models.ChallengeStatusOpen
+			c.ExpiresAt = now.Add(time.Duration(c.Days) * 24 * time.Hour)
+		default:
+			challenger, opponent := findAttempt(&c, c.ChallengerID), findAttempt(&c, attempt.PlayerID)
+			if challenger == nil || opponent == nil {
+				return nil
+			}
+			c.Status = models.ChallengeStatusCompleted
+			c.CompletedAt = &now
+			switch compareAttempts(challenger, opponent) {
+			case 1:
+				c.WinnerID = &challenger.PlayerID
+			case -1:
+				c.WinnerID = &opponent.PlayerID
+			}
+		}
+		return tx.Model(&c).Select("status", "expires_at", "winner_id", "completed_at").Updates(&c).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	switch c.Status {
+	case models.ChallengeStatusOpen:
+		if c.OpponentID != nil {
+			cs.notify([]uint{*c.OpponentID}, MsgTypeChallengeReceived,
+				fmt.Sprintf("%s challenged you to beat their game", c.ChallengerName), cs.challengeData(&c))
+		}
+	case models.ChallengeStatusCompleted:
+		utils.Info("Challenge %s between %s and %s was settled", c.Code, c.ChallengerName, c.OpponentName)
+		cs.notify([]uint{c.ChallengerID, *c.OpponentID}, MsgTypeChallengeCompleted,
+			resultMessage(&c), cs.challengeData(&c))
+	}
+	return nil
+}
+
+// expire closes a challenge whose time ran out, unless one of its games is still being played
+func (cs *ChallengeService) expire(c *models.Challenge) error {
+	for _, attempt := range c.Attempts {
+		if attempt.FinishedAt == nil {
+			return nil
+		}
+	}
+
+	if err := cs.close(c, models.ChallengeStatusExpired); err != nil {
+		return err
+	}
+
+	playerIDs := []uint{c.ChallengerID}
+	if c.OpponentID != nil {
+		playerIDs = append(playerIDs, *c.OpponentID)
+	}
+	cs.notify(playerIDs, MsgTypeChallengeExpired, "A challenge expired before it was played", cs.challengeData(c))
+	return nil
+}
+
+// close ends a challenge without a result, unless it has moved on since it was loaded
+func (cs *ChallengeService) close(c *models.Challenge, status string) error {
+	now := time.Now()
+	result := cs.db.Model(&models.Challenge{}).
+		Where("id = ? AND status = ?", c.ID, c.Status).
+		Updates(map[string]interface{}{"status": status, "completed_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChallengeClosed
+	}
+
+	c.Status = status
+	c.CompletedAt = &now
+	return nil
+}
+
+// notify sends a message to challenge players
+func (cs *ChallengeService) notify(playerIDs []uint, msgType matchmaking.MessageType, message string, data interface{}) {
+	if cs.notifier == nil {
+		return
+	}
+
+	for _, playerID := range playerIDs {
+		cs.notifier.SendMessage(playerID, matchmaking.WebSocketMessage{
+			Type:      msgType,
+			Message:   message,
+			Data:      data,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// challengeData adds the link that shares a challenge
+func (cs *ChallengeService) challengeData(c *models.Challenge) *ChallengeData {
+	return &ChallengeData{
+		Challenge: *c,
+		Link:      cs.baseURL + "/play?challenge=" + c.Code,
+	}
+}
+
+// generateCode returns a random challenge code no other challenge uses
+func (cs *ChallengeService) generateCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(CodeAlphabet)))
+	for {
+		var code strings.Builder
+		for i := 0; i < CodeLength; i++ {
+			index, err := rand.Int(rand.Reader, alphabetSize)
+			if err != nil {
+				return "", err
+			}
+			code.WriteByte(CodeAlphabet[index.Int64()])
+		}
+
+		var taken int64
+		if err := cs.db.Model(&models.Challenge{}).Where("code = ?", code.String()).Count(&taken).Error; err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			return code.String(), nil
+		}
+	}
+}
+
+// findChallenge loads a challenge by code
+func findChallenge(tx *gorm.DB, code string) (*models.Challenge, error) {
+	var c models.Challenge
+	if err := tx.Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&c).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChallengeNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+// findAttempt returns a player's finished game of a challenge
+func findAttempt(c *models.Challenge, playerID uint) *models.ChallengeAttempt {
+	for i := range c.Attempts {
+		if c.Attempts[i].PlayerID == playerID && c.Attempts[i].FinishedAt != nil {
+			return &c.Attempts[i]
+		}
+	}
+	return nil
+}
+
+// hasAttempt reports whether a player has started their game of a challenge
+func hasAttempt(c *models.Challenge, playerID uint) bool {
+	for _, attempt := range c.Attempts {
+		if attempt.PlayerID == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// isPending reports whether a challenge still waits for one of its games
+func isPending(c *models.Challenge) bool {
+	return c.Status == models.ChallengeStatusAwaitingChallenger || c.Status == models.ChallengeStatusOpen
+}
+
+// compareAttempts returns 1 when a beat b, -1 when b beat a and 0 for a draw. Finishing the map
+// beats not finishing it; between finished games the faster one wins, and between unfinished
+// games the higher score. Remaining ties go to the higher score, then to fewer moves.
+func compareAttempts(a, b *models.ChallengeAttempt) int {
+	if a.Completed != b.Completed {
+		if a.Completed {
+			return 1
+		}
+		return -1
+	}
+
+	if a.Completed && a.CompletionTime != nil && b.CompletionTime != nil && *a.CompletionTime != *b.CompletionTime {
+		if *a.CompletionTime < *b.CompletionTime {
+			return 1
+		}
+		return -1
+	}
+
+	switch {
+	case a.Score != b.Score:
+		if a.Score > b.Score {
+			return 1
+		}
+		return -1
+	case a.TotalMoves != b.TotalMoves:
+		if a.TotalMoves < b.TotalMoves {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// resultMessage describes the outcome of a settled challenge
+func resultMessage(c *models.Challenge) string {
+	switch {
+	case c.WinnerID == nil:
+		return fmt.Sprintf("Your challenge between %s and %s ended in a draw", c.ChallengerName, c.OpponentName)
+	case *c.WinnerID == c.ChallengerID:
+		return fmt.Sprintf("%s won the challenge against %s", c.ChallengerName, c.OpponentName)
+	default:
+		return fmt.Sprintf("%s won the challenge against %s", c.OpponentName, c.ChallengerName)
+	}
+}
@@ -0,0 +1,68 @@
+package challenge
+
+import (
+	"errors"
+	"time"
+
+	"boba-vim/internal/models"
+	"boba-vim/internal/services/matchmaking"
+)
+
+// Error definitions
+var (
+	ErrChallengeNotFound    = errors.New("challenge not found")
+	ErrInvalidMap           = errors.New("map not found")
+	ErrInvalidDays          = errors.New("a challenge gives 1 to 7 days to play")
+	ErrOpponentNotFound     = errors.New("opponent not found")
+	ErrChallengeSelf        = errors.New("players can't challenge themselves")
+	ErrNotChallengePlayer   = errors.New("player is not part of this challenge")
+	ErrChallengerPlaysFirst = errors.New("the challenger has not played yet")
+	ErrAlreadyPlayed        = errors.New("player has already played this challenge")
+	ErrChallengeClosed      = errors.New("challenge is no longer open")
+	ErrChallengeTaken       = errors.New("challenge was taken up by another player")
+)
+
+// Message types sent to challenge players over the matchmaking WebSocket
+const (
+	MsgTypeChallengeReceived  matchmaking.MessageType = "challenge_received"
+	MsgTypeChallengeCompleted matchmaking.MessageType = "challenge_completed"
+	MsgTypeChallengeDeclined  matchmaking.MessageType = "challenge_declined"
+	MsgTypeChallengeCancelled matchmaking.MessageType = "challenge_cancelled"
+	MsgTypeChallengeExpired   matchmaking.MessageType = "challenge_expired"
+)
+
+// Constants for limits and timing
+const (
+	DefaultDays     = 3
+	MaxDays         = 7
+	CodeLength      = 8
+	CodeAlphabet    = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // No 0/O or 1/I to keep codes easy to read out
+	ProcessInterval = 10 * time.Second
+	runnerLeaseTTL  = 30 * time.Second
+
+	// Only the node holding this lease settles and expires challenges
+	runnerLease = "challenges:runner"
+)
+
+// SessionStarter starts the solo games of challenges
+type SessionStarter interface {
+	StartChallengeGame(username, selectedCharacter string, mapID int, seed int64, challengeID uint) (map[string]interface{}, error)
+}
+
+// Notifier sends messages to players over the matchmaking WebSocket
+type Notifier interface {
+	SendMessage(playerID uint, message interface{}) error
+}
+
+// ChallengeSettings are what a player picks when sending a challenge
+type ChallengeSettings struct {
+	Opponent string `json:"opponent"` // Username of the player challenged; empty for a challenge sent by link
+	MapID    int    `json:"map_id"`
+	Days     int    `json:"days"` // Days the opponent has to play; 0 uses the default
+}
+
+// ChallengeData is a challenge as sent to players, with the link that shares it
+type ChallengeData struct {
+	models.Challenge
+	Link string `json:"link"`
+}
@@ -0,0 +1,198 @@
+package cluster
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// memoryLease is a lease held in memory
+type memoryLease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the shared state in this process. It suits a single instance.
+type MemoryStore struct {
+	collections map[string]map[string][]byte
+	leases      map[string]memoryLease
+	mutex       sync.Mutex
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		collections: make(map[string]map[string][]byte),
+		leases:      make(map[string]memoryLease),
+	}
+}
+
+// collection returns a collection, creating it if needed. The caller must hold the lock.
+func (ms *MemoryStore) collection(name string) map[string][]byte {
+	values, exists := ms.collections[name]
+	if !exists {
+		values = make(map[string][]byte)
+		ms.collections[name] = values
+	}
+	return values
+}
+
+// Put sets the value of a key in a collection
+func (ms *MemoryStore) Put(collection, key string, value []byte) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.collection(collection)[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// PutIfAbsent sets the value of a key unless it is already set
+func (ms *MemoryStore) PutIfAbsent(collection, key string, value []byte) (bool, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	values := ms.collection(collection)
+	if _, exists := values[key]; exists {
+		return false, nil
+	}
+	values[key] = append([]byte(nil), value...)
+	return true, nil
+}
+
+// Get returns the value of a key
+func (ms *MemoryStore) Get(collection, key string) ([]byte, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	value, exists := ms.collections[collection][key]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Delete removes a key
+func (ms *MemoryStore) Delete(collection, key string) (bool, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	values := ms.collections[collection]
+	if _, exists := values[key]; !exists {
+		return false, nil
+	}
+	delete(values, key)
+	return true, nil
+}
+
+// DeleteIf removes a key only while it still holds value
+func (ms *MemoryStore) DeleteIf(collection, key string, value []byte) (bool, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	values := ms.collections[collection]
+	current, exists := values[key]
+	if !exists || !bytes.Equal(current, value) {
+		return false, nil
+	}
+	delete(values, key)
+	return true, nil
+}
+
+// List returns every key of a collection with its value
+func (ms *MemoryStore) List(collection string) (map[string][]byte, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	values := make(map[string][]byte, len(ms.collections[collection]))
+	for key, value := range ms.collections[collection] {
+		values[key] = append([]byte(nil), value...)
+	}
+	return values, nil
+}
+
+// Count returns the number of keys in a collection
+func (ms *MemoryStore) Count(collection string) (int, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	return len(ms.collections[collection]), nil
+}
+
+// AcquireLease takes or extends a lease
+func (ms *MemoryStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	now := time.Now()
+	if lease, exists := ms.leases[name]; exists && lease.holder != holder && now.Before(lease.expiresAt) {
+		return false, nil
+	}
+	ms.leases[name] = memoryLease{holder: holder, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// ReleaseLease gives up a lease held by holder
+func (ms *MemoryStore) ReleaseLease(name, holder string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if lease, exists := ms.leases[name]; exists && lease.holder == holder {
+		delete(ms.leases, name)
+	}
+	return nil
+}
+
+// LeaseHolder returns who holds a lease
+func (ms *MemoryStore) LeaseHolder(name string) (string, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	lease, exists := ms.leases[name]
+	if !exists || !time.Now().Before(lease.expiresAt) {
+		return "", nil
+	}
+	return lease.holder, nil
+}
+
+// MemoryBus delivers messages within this process. It suits a single instance.
+type MemoryBus struct {
+	handlers map[string][]func(payload []byte)
+	mutex    sync.RWMutex
+}
+
+// NewMemoryBus creates a new in-memory bus
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		handlers: make(map[string][]func(payload []byte)),
+	}
+}
+
+// Publish calls the subscribers of a channel
+func (mb *MemoryBus) Publish(channel string, payload []byte) error {
+	mb.mutex.RLock()
+	handlers := mb.handlers[channel]
+	mb.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		go handler(append([]byte(nil), payload...))
+	}
+	return nil
+}
+
+// Subscribe adds a subscriber to a channel
+func (mb *MemoryBus) Subscribe(channel string, handler func(payload []byte)) error {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	mb.handlers[channel] = append(mb.handlers[channel], handler)
+	return nil
+}
+
+// Close removes every subscriber
+func (mb *MemoryBus) Close() error {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	mb.handlers = make(map[string][]func(payload []byte))
+	return nil
+}
@@ -0,0 +1,305 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"boba-vim/internal/cache"
+	"boba-vim/internal/config"
+	"boba-vim/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Cluster settings
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+
+	NodeLeaseTTL = 15 * time.Second // A node that stops renewing its lease for this long is gone
+	CallTimeout  = 5 * time.Second  // Time a node has to answer a call
+)
+
+// Message kinds on a node's channel
+const (
+	kindCall  = "call"
+	kindReply = "reply"
+	kindSend  = "send"
+)
+
+// Cluster errors
+var (
+	ErrNodeUnavailable = errors.New("server instance unavailable")
+	ErrUnknownMethod   = errors.New("unknown cluster method")
+	ErrCallTimeout     = errors.New("server instance did not answer in time")
+)
+
+// Handler handles a call or message from another node and returns the reply
+type Handler func(body json.RawMessage) (interface{}, error)
+
+// envelope is a message between nodes
+type envelope struct {
+	Kind   string          `json:"kind"`
+	ID     string          `json:"id,omitempty"`
+	From   string          `json:"from"`
+	Method string          `json:"method,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Node is this instance of the server in the cluster. It shares state with the other nodes
+// through the store, keeps itself alive with a lease, and calls and messages other nodes over
+// the bus.
+type Node struct {
+	ID    string
+	Store Store
+
+	bus           Bus
+	handlers      map[string]Handler
+	handlersMutex sync.RWMutex
+	pending       map[string]chan envelope
+	pendingMutex  sync.Mutex
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+// Initialize creates the node of this instance on the configured state backend. The memory
+// backend runs a single instance; the redis backend shares state between every instance using
+// the same Redis.
+func Initialize(cfg *config.Config) *Node {
+	nodeID := cfg.NodeID
+	if nodeID == "" {
+		nodeID = uuid.New().String()
+	}
+
+	var node *Node
+	var err error
+	switch cfg.StateBackend {
+	case BackendRedis:
+		if cache.GlobalCache == nil || !cache.GlobalCache.IsAvailable() {
+			utils.Fatal("Multiplayer state backend is redis but Redis is not available")
+		}
+		client := cache.GlobalCache.Client()
+		node, err = NewNode(nodeID, NewRedisStore(client), NewRedisBus(client))
+	case BackendMemory, "":
+		node, err = NewNode(nodeID, NewMemoryStore(), NewMemoryBus())
+	default:
+		utils.Fatal("Unknown multiplayer state backend %q", cfg.StateBackend)
+	}
+	if err != nil {
+		utils.Fatal("Failed to join the cluster: %v", err)
+	}
+
+	utils.Info("Cluster node %s started on the %s state backend", nodeID, cfg.StateBackend)
+	return node
+}
+
+// NewNode creates a node on a store and bus, and starts keeping it alive
+func NewNode(id string, store Store, bus Bus) (*Node, error) {
+	node := &Node{
+		ID:       id,
+		Store:    store,
+		bus:      bus,
+		handlers: make(map[string]Handler),
+		pending:  make(map[string]chan envelope),
+		stop:     make(chan struct{}),
+	}
+
+	if err := bus.Subscribe(nodeChannel(id), node.receive); err != nil {
+		return nil, err
+	}
+	if _, err := store.AcquireLease(nodeLease(id), id, NodeLeaseTTL); err != nil {
+		return nil, err
+	}
+
+	go node.keepAlive()
+	return node, nil
+}
+
+// nodeChannel returns the bus channel of a node
+func nodeChannel(nodeID string) string {
+	return "node:" + nodeID
+}
+
+// nodeLease returns the lease that keeps a node alive
+func nodeLease(nodeID string) string {
+	return "node:" + nodeID
+}
+
+// keepAlive renews the node's lease until the node stops
+func (n *Node) keepAlive() {
+	ticker := time.NewTicker(NodeLeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+			if _, err := n.Store.AcquireLease(nodeLease(n.ID), n.ID, NodeLeaseTTL); err != nil {
+				utils.Error("Failed to renew the lease of cluster node %s: %v", n.ID, err)
+			}
+		}
+	}
+}
+
+// IsAlive reports whether a node is running
+func (n *Node) IsAlive(nodeID string) bool {
+	if nodeID == n.ID {
+		return true
+	}
+
+	holder, err := n.Store.LeaseHolder(nodeLease(nodeID))
+	if err != nil {
+		utils.Error("Failed to check cluster node %s: %v", nodeID, err)
+		return false
+	}
+	return holder == nodeID
+}
+
+// HoldLease takes or extends a lease for this node. It reports whether the node has it.
+func (n *Node) HoldLease(name string, ttl time.Duration) bool {
+	held, err := n.Store.AcquireLease(name, n.ID, ttl)
+	if err != nil {
+		utils.Error("Failed to acquire lease %s: %v", name, err)
+		return false
+	}
+	return held
+}
+
+// Handle registers the handler of a method other nodes call or send
+func (n *Node) Handle(method string, handler Handler) {
+	n.handlersMutex.Lock()
+	defer n.handlersMutex.Unlock()
+
+	n.handlers[method] = handler
+}
+
+// Send delivers a message to another node without waiting for it to be handled
+func (n *Node) Send(nodeID, method string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return n.publish(nodeID, envelope{Kind: kindSend, From: n.ID, Method: method, Body: data})
+}
+
+// Call runs a method on another node and decodes its reply into reply, which may be nil
+func (n *Node) Call(nodeID, method string, args interface{}, reply interface{}) error {
+	if !n.IsAlive(nodeID) {
+		return ErrNodeUnavailable
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	callID := uuid.New().String()
+	replies := make(chan envelope, 1)
+	n.pendingMutex.Lock()
+	n.pending[callID] = replies
+	n.pendingMutex.Unlock()
+	defer func() {
+		n.pendingMutex.Lock()
+		delete(n.pending, callID)
+		n.pendingMutex.Unlock()
+	}()
+
+	if err := n.publish(nodeID, envelope{Kind: kindCall, ID: callID, From: n.ID, Method: method, Body: data}); err != nil {
+		return err
+	}
+
+	select {
+	case response := <-replies:
+		if response.Error != "" {
+			return errors.New(response.Error)
+		}
+		if reply == nil || len(response.Body) == 0 {
+			return nil
+		}
+		return json.Unmarshal(response.Body, reply)
+	case <-time.After(CallTimeout):
+		return ErrCallTimeout
+	}
+}
+
+// publish puts an envelope on a node's channel
+func (n *Node) publish(nodeID string, message envelope) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return n.bus.Publish(nodeChannel(nodeID), data)
+}
+
+// receive handles an envelope published to this node
+func (n *Node) receive(payload []byte) {
+	var message envelope
+	if err := json.Unmarshal(payload, &message); err != nil {
+		utils.Error("Invalid cluster message: %v", err)
+		return
+	}
+
+	if message.Kind == kindReply {
+		n.pendingMutex.Lock()
+		replies, exists := n.pending[message.ID]
+		n.pendingMutex.Unlock()
+		if exists {
+			replies <- message
+		}
+		return
+	}
+
+	// Handlers may call other nodes, so they don't hold up the bus
+	go n.dispatch(message)
+}
+
+// dispatch runs the handler of a call or message and answers calls
+func (n *Node) dispatch(message envelope) {
+	n.handlersMutex.RLock()
+	handler, exists := n.handlers[message.Method]
+	n.handlersMutex.RUnlock()
+
+	var result interface{}
+	var err error
+	if exists {
+		result, err = handler(message.Body)
+	} else {
+		err = fmt.Errorf("%w: %s", ErrUnknownMethod, message.Method)
+	}
+
+	if message.Kind != kindCall {
+		if err != nil {
+			utils.Error("Cluster message %s from node %s failed: %v", message.Method, message.From, err)
+		}
+		return
+	}
+
+	response := envelope{Kind: kindReply, ID: message.ID, From: n.ID}
+	if err != nil {
+		response.Error = err.Error()
+	} else if result != nil {
+		if response.Body, err = json.Marshal(result); err != nil {
+			response.Error = err.Error()
+		}
+	}
+
+	if err := n.publish(message.From, response); err != nil {
+		utils.Error("Failed to answer cluster call %s from node %s: %v", message.Method, message.From, err)
+	}
+}
+
+// Stop leaves the cluster. Other nodes see this node as gone right away.
+func (n *Node) Stop() {
+	n.stopOnce.Do(func() {
+		close(n.stop)
+		if err := n.Store.ReleaseLease(nodeLease(n.ID), n.ID); err != nil {
+			utils.Error("Failed to release the lease of cluster node %s: %v", n.ID, err)
+		}
+		n.bus.Close()
+	})
+}
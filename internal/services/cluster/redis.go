@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"boba-vim/internal/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces every key and channel the cluster uses in Redis
+const keyPrefix = "boba:cluster:"
+
+var (
+	deleteIfScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], ARGV[1]) == ARGV[2] then
+	return redis.call('HDEL', KEYS[1], ARGV[1])
+end
+return 0`)
+
+	acquireLeaseScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == false or holder == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0`)
+
+	releaseLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`)
+)
+
+// RedisStore keeps the shared state in Redis. Each collection is a hash.
+type RedisStore struct {
+	client *redis.Client
+	ctx    context.Context
+}
+
+// NewRedisStore creates a store on a Redis client
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{
+		client: client,
+		ctx:    context.Background(),
+	}
+}
+
+// collectionKey returns the Redis key of a collection
+func collectionKey(collection string) string {
+	return keyPrefix + collection
+}
+
+// leaseKey returns the Redis key of a lease
+func leaseKey(name string) string {
+	return keyPrefix + "lease:" + name
+}
+
+// Put sets the value of a key in a collection
+func (rs *RedisStore) Put(collection, key string, value []byte) error {
+	return rs.client.HSet(rs.ctx, collectionKey(collection), key, value).Err()
+}
+
+// PutIfAbsent sets the value of a key unless it is already set
+func (rs *RedisStore) PutIfAbsent(collection, key string, value []byte) (bool, error) {
+	return rs.client.HSetNX(rs.ctx, collectionKey(collection), key, value).Result()
+}
+
+// Get returns the value of a key
+func (rs *RedisStore) Get(collection, key string) ([]byte, error) {
+	value, err := rs.client.HGet(rs.ctx, collectionKey(collection), key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Delete removes a key
+func (rs *RedisStore) Delete(collection, key string) (bool, error) {
+	removed, err := rs.client.HDel(rs.ctx, collectionKey(collection), key).Result()
+	return removed > 0, err
+}
+
+// DeleteIf removes a key only while it still holds value
+func (rs *RedisStore) DeleteIf(collection, key string, value []byte) (bool, error) {
+	removed, err := deleteIfScript.Run(rs.ctx, rs.client, []string{collectionKey(collection)}, key, value).Int()
+	return removed > 0, err
+}
+
+// List returns every key of a collection with its value
+func (rs *RedisStore) List(collection string) (map[string][]byte, error) {
+	entries, err := rs.client.HGetAll(rs.ctx, collectionKey(collection)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]byte, len(entries))
+	for key, value := range entries {
+		values[key] = []byte(value)
+	}
+	return values, nil
+}
+
+// Count returns the number of keys in a collection
+func (rs *RedisStore) Count(collection string) (int, error) {
+	count, err := rs.client.HLen(rs.ctx, collectionKey(collection)).Result()
+	return int(count), err
+}
+
+// AcquireLease takes or extends a lease
+func (rs *RedisStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireLeaseScript.Run(rs.ctx, rs.client, []string{leaseKey(name)}, holder, ttl.Milliseconds()).Int()
+	return acquired == 1, err
+}
+
+// ReleaseLease gives up a lease held by holder
+func (rs *RedisStore) ReleaseLease(name, holder string) error {
+	return releaseLeaseScript.Run(rs.ctx, rs.client, []string{leaseKey(name)}, holder).Err()
+}
+
+// LeaseHolder returns who holds a lease
+func (rs *RedisStore) LeaseHolder(name string) (string, error) {
+	holder, err := rs.client.Get(rs.ctx, leaseKey(name)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return holder, err
+}
+
+// RedisBus carries messages between instances over Redis pub/sub
+type RedisBus struct {
+	client        *redis.Client
+	ctx           context.Context
+	cancel        context.CancelFunc
+	subscriptions []*redis.PubSub
+	mutex         sync.Mutex
+}
+
+// NewRedisBus creates a bus on a Redis client
+func NewRedisBus(client *redis.Client) *RedisBus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RedisBus{
+		client: client,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Publish sends a payload to every subscriber of a channel
+func (rb *RedisBus) Publish(channel string, payload []byte) error {
+	return rb.client.Publish(rb.ctx, keyPrefix+channel, payload).Err()
+}
+
+// Subscribe calls handler with every payload published to a channel
+func (rb *RedisBus) Subscribe(channel string, handler func(payload []byte)) error {
+	subscription := rb.client.Subscribe(rb.ctx, keyPrefix+channel)
+	if _, err := subscription.Receive(rb.ctx); err != nil {
+		subscription.Close()
+		return err
+	}
+
+	rb.mutex.Lock()
+	rb.subscriptions = append(rb.subscriptions, subscription)
+	rb.mutex.Unlock()
+
+	go func() {
+		for message := range subscription.Channel() {
+			handler([]byte(message.Payload))
+		}
+		utils.Debug("Subscription to %s closed", channel)
+	}()
+	return nil
+}
+
+// Close stops every subscription
+func (rb *RedisBus) Close() error {
+	rb.cancel()
+
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	for _, subscription := range rb.subscriptions {
+		subscription.Close()
+	}
+	rb.subscriptions = nil
+	return nil
+}
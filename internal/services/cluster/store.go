@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a key is not in the store
+var ErrNotFound = errors.New("not found")
+
+// Store holds the multiplayer state shared by every instance of the server. Values are kept in
+// named collections of keys; leases give one holder at a time a name until they expire.
+type Store interface {
+	// Put sets the value of a key in a collection
+	Put(collection, key string, value []byte) error
+	// PutIfAbsent sets the value of a key unless it is already set. It reports whether it did.
+	PutIfAbsent(collection, key string, value []byte) (bool, error)
+	// Get returns the value of a key, or ErrNotFound
+	Get(collection, key string) ([]byte, error)
+	// Delete removes a key. It reports whether the key was set.
+	Delete(collection, key string) (bool, error)
+	// DeleteIf removes a key only while it still holds value. It reports whether it did.
+	DeleteIf(collection, key string, value []byte) (bool, error)
+	// List returns every key of a collection with its value
+	List(collection string) (map[string][]byte, error)
+	// Count returns the number of keys in a collection
+	Count(collection string) (int, error)
+
+	// AcquireLease takes a lease for ttl, or extends it when holder already has it. It reports
+	// whether holder has the lease.
+	AcquireLease(name, holder string, ttl time.Duration) (bool, error)
+	// ReleaseLease gives up a lease held by holder
+	ReleaseLease(name, holder string) error
+	// LeaseHolder returns who holds a lease, or "" when nobody does
+	LeaseHolder(name string) (string, error)
+}
+
+// Bus carries messages between the instances of the server
+type Bus interface {
+	// Publish sends a payload to every subscriber of a channel
+	Publish(channel string, payload []byte) error
+	// Subscribe calls handler with every payload published to a channel
+	Subscribe(channel string, handler func(payload []byte)) error
+	// Close stops every subscription
+	Close() error
+}
@@ -0,0 +1,349 @@
+package friends
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"boba-vim/internal/models"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// defaultCharacter is the character a challenged friend plays, since nobody picks one for them
+const defaultCharacter = "boba"
+
+// Order of relations and presences on a friend list
+var (
+	relationOrder = map[string]int{RelationFriend: 0, RelationIncoming: 1, RelationOutgoing: 2, RelationBlocked: 3}
+	presenceOrder = map[string]int{PresenceOnline: 0, PresenceInQueue: 1, PresenceInGame: 2, PresenceOffline: 3}
+)
+
+// FriendsService manages friend requests, blocks and presence, and lets players challenge an
+// online friend to a duel without queuing. Friendships live in the database; presence comes from
+// the matchmaking WebSocket, matchmaking statuses and the games being played.
+type FriendsService struct {
+	db         *gorm.DB
+	matchmaker Matchmaker
+	games      GameTracker
+	notifier   Notifier
+}
+
+// NewFriendsService creates a new friends service
+func NewFriendsService(db *gorm.DB, matchmaker Matchmaker, games GameTracker, notifier Notifier) *FriendsService {
+	return &FriendsService{
+		db:         db,
+		matchmaker: matchmaker,
+		games:      games,
+		notifier:   notifier,
+	}
+}
+
+// SendRequest sends a friend request to a player by username. A request to a player who already
+// asked to be friends accepts theirs.
+func (fs *FriendsService) SendRequest(playerID uint, username, friendName string) (*FriendData, error) {
+	friend, err := fs.findPlayer(fs.db.Where("username = ?", strings.TrimSpace(friendName)))
+	if err != nil {
+		return nil, err
+	}
+	if friend.ID == playerID {
+		return nil, ErrFriendSelf
+	}
+
+	links, err := fs.linksBetween(playerID, friend.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.Status == models.FriendshipBlocked {
+			return nil, ErrPlayerBlocked
+		}
+	}
+	for _, link := range links {
+		switch {
+		case link.Status == models.FriendshipAccepted:
+			return nil, ErrAlreadyFriends
+		case link.PlayerID == playerID:
+			return nil, ErrRequestPending
+		default:
+			return fs.AcceptRequest(playerID, username, friend.ID)
+		}
+	}
+
+	link := models.Friendship{
+		PlayerID: playerID,
+		FriendID: friend.ID,
+		Status:   models.FriendshipPending,
+	}
+	if err := fs.db.Create(&link).Error; err != nil {
+		return nil, err
+	}
+
+	fs.notify(friend.ID, MsgTypeFriendRequest, fmt.Sprintf("%s sent you a friend request", username), FriendData{
+		PlayerID: playerID,
+		Username: username,
+		Relation: RelationIncoming,
+		Since:    link.CreatedAt,
+	})
+
+	utils.Info("%s sent a friend request to %s", username, friend.Username)
+	return &FriendData{
+		PlayerID: friend.ID,
+		Username: friend.Username,
+		Relation: RelationOutgoing,
+		Since:    link.CreatedAt,
+	}, nil
+}
+
+// AcceptRequest accepts the friend request another player sent
+func (fs *FriendsService) AcceptRequest(playerID uint, username string, requesterID uint) (*FriendData, error) {
+	now := time.Now()
+	result := fs.db.Model(&models.Friendship{}).
+		Where("player_id = ? AND friend_id = ? AND status = ?", requesterID, playerID, models.FriendshipPending).
+		Updates(map[string]interface{}{"status": models.FriendshipAccepted, "accepted_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrRequestNotFound
+	}
+
+	requester, err := fs.findPlayer(fs.db.Where("id = ?", requesterID))
+	if err != nil {
+		return nil, err
+	}
+
+	fs.notify(requesterID, MsgTypeFriendAccepted, fmt.Sprintf("%s accepted your friend request", username), FriendData{
+		PlayerID: playerID,
+		Username: username,
+		Relation: RelationFriend,
+		Presence: fs.Presence(playerID),
+		Since:    now,
+	})
+
+	utils.Info("%s and %s are now friends", requester.Username, username)
+	return &FriendData{
+		PlayerID: requester.ID,
+		Username: requester.Username,
+		Relation: RelationFriend,
+		Presence: fs.Presence(requester.ID),
+		Since:    now,
+	}, nil
+}
+
+// RemoveFriend ends a friendship, declines a request another player sent or withdraws a request
+// the player sent
+func (fs *FriendsService) RemoveFriend(playerID, friendID uint) error {
+	result := fs.db.
+		Where("((player_id = ? AND friend_id = ?) OR (player_id = ? AND friend_id = ?)) AND status IN ?",
+			playerID, friendID, friendID, playerID, []string{models.FriendshipPending, models.FriendshipAccepted}).
+		Delete(&models.Friendship{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFriends
+	}
+	return nil
+}
+
+// BlockPlayer blocks another player. Any friendship or request between the two ends, and neither
+// can send the other a friend request until the block is lifted.
+func (fs *FriendsService) BlockPlayer(playerID, blockedID uint) error {
+	if blockedID == playerID {
+		return ErrFriendSelf
+	}
+	if _, err := fs.findPlayer(fs.db.Where("id = ?", blockedID)); err != nil {
+		return err
+	}
+
+	return fs.db.Transaction(func(tx *gorm.DB) error {
+		// The other player's own block of this player stays in place
+		if err := tx.Where("(player_id = ? AND friend_id = ?) OR (player_id = ? AND friend_id = ? AND status <> ?)",
+			playerID, blockedID, blockedID, playerID, models.FriendshipBlocked).
+			Delete(&models.Friendship{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.Friendship{
+			PlayerID: playerID,
+			FriendID: blockedID,
+			Status:   models.FriendshipBlocked,
+		}).Error
+	})
+}
+
+// UnblockPlayer lifts a block
+func (fs *FriendsService) UnblockPlayer(playerID, blockedID uint) error {
+	result := fs.db.
+		Where("player_id = ? AND friend_id = ? AND status = ?", playerID, blockedID, models.FriendshipBlocked).
+		Delete(&models.Friendship{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotBlocked
+	}
+	return nil
+}
+
+// ListFriends returns a player's friends with their presence, then the requests the player
+// received and sent, then the players they blocked. Players who blocked the player are left out.
+func (fs *FriendsService) ListFriends(playerID uint) ([]FriendData, error) {
+	var links []models.Friendship
+	if err := fs.db.Where("player_id = ? OR friend_id = ?", playerID, playerID).Find(&links).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]FriendData, 0, len(links))
+	otherIDs := make([]uint, 0, len(links))
+	for _, link := range links {
+		entry := FriendData{PlayerID: link.FriendID, Since: link.CreatedAt}
+		if link.FriendID == playerID {
+			entry.PlayerID = link.PlayerID
+		}
+
+		switch {
+		case link.Status == models.FriendshipAccepted:
+			entry.Relation = RelationFriend
+			entry.Presence = fs.Presence(entry.PlayerID)
+			if link.AcceptedAt != nil {
+				entry.Since = *link.AcceptedAt
+			}
+		case link.Status == models.FriendshipPending && link.PlayerID == playerID:
+			entry.Relation = RelationOutgoing
+		case link.Status == models.FriendshipPending:
+			entry.Relation = RelationIncoming
+		case link.PlayerID == playerID:
+			entry.Relation = RelationBlocked
+		default:
+			continue // Blocked by the other player
+		}
+
+		list = append(list, entry)
+		otherIDs = append(otherIDs, entry.PlayerID)
+	}
+
+	var players []models.Player
+	if err := fs.db.Select("id", "username").Where("id IN ?", otherIDs).Find(&players).Error; err != nil {
+		return nil, err
+	}
+	usernames := make(map[uint]string, len(players))
+	for _, player := range players {
+		usernames[player.ID] = player.Username
+	}
+	for i := range list {
+		list[i].Username = usernames[list[i].PlayerID]
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if relationOrder[a.Relation] != relationOrder[b.Relation] {
+			return relationOrder[a.Relation] < relationOrder[b.Relation]
+		}
+		if presenceOrder[a.Presence] != presenceOrder[b.Presence] {
+			return presenceOrder[a.Presence] < presenceOrder[b.Presence]
+		}
+		return strings.ToLower(a.Username) < strings.ToLower(b.Username)
+	})
+	return list, nil
+}
+
+// Presence reports whether a player is offline, online, queued or playing a multiplayer game
+func (fs *FriendsService) Presence(playerID uint) string {
+	if fs.games != nil && fs.games.IsPlayerInGame(playerID) {
+		return PresenceInGame
+	}
+
+	switch fs.matchmaker.PlayerStatus(playerID) {
+	case matchmaking.StatusSearching, matchmaking.StatusMatchFound, matchmaking.StatusWaitingAccept, matchmaking.StatusMapVeto:
+		return PresenceInQueue
+	case matchmaking.StatusInGame:
+		return PresenceInGame
+	}
+
+	if fs.matchmaker.IsPlayerConnected(playerID) {
+		return PresenceOnline
+	}
+	return PresenceOffline
+}
+
+// ChallengeFriend challenges an online friend to a duel. The duel skips the queue: the friend is
+// asked to accept it right away, and it starts like a matched duel once they do. It returns the
+// ID of the match.
+func (fs *FriendsService) ChallengeFriend(playerID uint, username, character string, friendID uint) (string, error) {
+	var link models.Friendship
+	err := fs.db.Where("((player_id = ? AND friend_id = ?) OR (player_id = ? AND friend_id = ?)) AND status = ?",
+		playerID, friendID, friendID, playerID, models.FriendshipAccepted).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNotFriends
+		}
+		return "", err
+	}
+
+	if presence := fs.Presence(playerID); presence == PresenceInQueue || presence == PresenceInGame {
+		return "", ErrPlayerBusy
+	}
+	switch fs.Presence(friendID) {
+	case PresenceOffline:
+		return "", ErrFriendOffline
+	case PresenceInQueue, PresenceInGame:
+		return "", ErrFriendBusy
+	}
+
+	friend, err := fs.findPlayer(fs.db.Where("id = ?", friendID))
+	if err != nil {
+		return "", err
+	}
+	if character == "" {
+		character = defaultCharacter
+	}
+
+	matchID, err := fs.matchmaker.ChallengePlayer(
+		matchmaking.MatchParticipant{PlayerID: playerID, Username: username, Character: character},
+		matchmaking.MatchParticipant{PlayerID: friend.ID, Username: friend.Username, Character: defaultCharacter},
+	)
+	if errors.Is(err, matchmaking.ErrPlayerBusy) {
+		return "", ErrFriendBusy // Matched or queued since their presence was checked
+	}
+	return matchID, err
+}
+
+// linksBetween returns the friendship rows between two players, in either direction
+func (fs *FriendsService) linksBetween(playerID, otherID uint) ([]models.Friendship, error) {
+	var links []models.Friendship
+	err := fs.db.Where("(player_id = ? AND friend_id = ?) OR (player_id = ? AND friend_id = ?)",
+		playerID, otherID, otherID, playerID).Find(&links).Error
+	return links, err
+}
+
+// findPlayer loads the player a query picks
+func (fs *FriendsService) findPlayer(query *gorm.DB) (*models.Player, error) {
+	var player models.Player
+	if err := query.First(&player).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, err
+	}
+	return &player, nil
+}
+
+// notify sends a message to a player
+func (fs *FriendsService) notify(playerID uint, msgType matchmaking.MessageType, message string, data interface{}) {
+	if fs.notifier == nil {
+		return
+	}
+
+	fs.notifier.SendMessage(playerID, matchmaking.WebSocketMessage{
+		Type:      msgType,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
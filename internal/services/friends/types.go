@@ -0,0 +1,71 @@
+package friends
+
+import (
+	"errors"
+	"time"
+
+	"boba-vim/internal/services/matchmaking"
+)
+
+// Error definitions
+var (
+	ErrPlayerNotFound  = errors.New("player not found")
+	ErrFriendSelf      = errors.New("players can't add themselves as friends")
+	ErrAlreadyFriends  = errors.New("already friends with this player")
+	ErrRequestPending  = errors.New("friend request already sent")
+	ErrRequestNotFound = errors.New("friend request not found")
+	ErrNotFriends      = errors.New("not friends with this player")
+	ErrPlayerBlocked   = errors.New("this player can't be added as a friend")
+	ErrNotBlocked      = errors.New("player is not blocked")
+	ErrFriendOffline   = errors.New("friend is not online")
+	ErrFriendBusy      = errors.New("friend is already queued or playing")
+	ErrPlayerBusy      = errors.New("leave the queue or finish your game before challenging a friend")
+)
+
+// Presence of a player as their friends see it
+const (
+	PresenceOffline = "offline"
+	PresenceOnline  = "online"
+	PresenceInQueue = "in_queue"
+	PresenceInGame  = "in_game"
+)
+
+// Relations of another player to the player whose friend list it is
+const (
+	RelationFriend   = "friend"
+	RelationIncoming = "incoming" // Sent the player a friend request
+	RelationOutgoing = "outgoing" // Received a friend request from the player
+	RelationBlocked  = "blocked"  // Blocked by the player
+)
+
+// Message types sent to players over the matchmaking WebSocket
+const (
+	MsgTypeFriendRequest  matchmaking.MessageType = "friend_request"
+	MsgTypeFriendAccepted matchmaking.MessageType = "friend_accepted"
+)
+
+// Matchmaker reports where players stand in matchmaking and starts the duels of direct challenges
+type Matchmaker interface {
+	IsPlayerConnected(playerID uint) bool
+	PlayerStatus(playerID uint) matchmaking.MatchmakingStatus
+	ChallengePlayer(challenger, opponent matchmaking.MatchParticipant) (string, error)
+}
+
+// GameTracker reports whether players are in a multiplayer game
+type GameTracker interface {
+	IsPlayerInGame(playerID uint) bool
+}
+
+// Notifier sends messages to players over the matchmaking WebSocket
+type Notifier interface {
+	SendMessage(playerID uint, message interface{}) error
+}
+
+// FriendData is another player on a player's friend list
+type FriendData struct {
+	PlayerID uint      `json:"player_id"`
+	Username string    `json:"username"`
+	Relation string    `json:"relation"`
+	Presence string    `json:"presence,omitempty"` // Only friends see each other's presence
+	Since    time.Time `json:"since"`
+}
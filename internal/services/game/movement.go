@@ -2,7 +2,6 @@ package game
 
 import (
 	"errors"
-	"fmt"
 	"sync"
 	"time"
 
@@ -62,8 +61,10 @@ func (ms *MovementService) dbWriteWorker() {
 	}
 }
 
-// ProcessMove processes a move with full concurrency control
-func (ms *MovementService) ProcessMove(sessionToken, direction string, count int, hasExplicitCount bool) (map[string]interface{}, error) {
+// ProcessMove processes a move with full concurrency control. knownVersion is the version of the
+// game map the client holds; when it is current the response carries only the cells the move
+// changed instead of the whole map.
+func (ms *MovementService) ProcessMove(sessionToken, direction string, count int, hasExplicitCount bool, knownVersion int64) (map[string]interface{}, error) {
 	var gameSession models.GameSession
 
 	// Get session from database (works for both anonymous and registered users)
@@ -79,6 +80,10 @@ func (ms *MovementService) ProcessMove(sessionToken, direction string, count int
 
 	// Check if it's an anonymous user (PlayerID = nil)
 	isAnonymous := gameSession.PlayerID == nil
+	
+	// The map the move starts from, to send the client only what changes
+	startVersion := gameSession.StateVersion
+	startMap := gameSession.GetGameMap()
 
 	// Check if game is completed
 	if gameSession.IsCompleted {
@@ -132,244 +137,106 @@ func (ms *MovementService) ProcessMove(sessionToken, direction string, count int
 		}, nil
 	}
 
-	// Process movements count times or until blocked
-	var totalPearlsCollected int
-	movesExecuted := 0
-	var finalMovementResult *game.MovementResult
-
-	if count == 1 {
-		// Single move - process normally
-		var movementResult *game.MovementResult
-		var err error
-		
-		// Use count-aware function for G commands even with count=1
-		if finalDirection == "file_end" || finalDirection == "file_start" {
-			movementResult, err = ms.calculateNewPositionWithCount(&gameSession, finalDirection, count, hasExplicitCount)
-		} else {
-			movementResult, err = ms.calculateNewPosition(&gameSession, finalDirection)
-		}
-		
-		if err != nil {
-			return map[string]interface{}{
-				"success": false,
-				"error":   err.Error(),
-			}, nil
-		}
+	// The motion applies the count itself (5j, 3fa, 50%), so every move is computed in one step
+	movementResult, err := ms.calculateNewPositionWithCount(&gameSession, finalDirection, count, hasExplicitCount)
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		}, nil
+	}
 
-		if !movementResult.IsValid {
-			return map[string]interface{}{
-				"success":  false,
-				"error":    "Movement blocked",
-				"game_map": gameSession.GetGameMap(),
-				"player_pos": map[string]int{
-					"row": gameSession.CurrentRow,
-					"col": gameSession.CurrentCol,
-				},
-				"score":           gameSession.CurrentScore,
-				"moves_executed":  0,
-				"moves_requested": count,
-			}, nil
+	if !movementResult.IsValid {
+		result := map[string]interface{}{
+			"success":  false,
+			"error":    "Movement blocked",
+			"player_pos": map[string]int{
+				"row": gameSession.CurrentRow,
+				"col": gameSession.CurrentCol,
+			},
+			"score":           gameSession.CurrentScore,
+			"moves_executed":  0,
+			"moves_requested": count,
 		}
+		addMapState(result, &gameSession, knownVersion, startVersion, startMap)
+		return result, nil
+	}
 
-		gameMap := gameSession.GetGameMap()
-		pearlCollected := gameMap[movementResult.NewRow][movementResult.NewCol] == game.PEARL
-		if pearlCollected {
-			totalPearlsCollected++
-		}
+	// Counted motions stop early when blocked, so only the moves really made are counted
+	movesExecuted := movementResult.MovesExecuted
 
-		// Check for pearl mold collision (game failure) - only on hard difficulty maps
-		if gameMap[movementResult.NewRow][movementResult.NewCol] == game.PEARL_MOLD {
-			// Fail the game immediately
-			gameSession.FailGame()
-			err := ms.db.Save(&gameSession).Error
-			if err != nil {
-				return map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-				}, nil
-			}
-			
-			// Get current map information
-			gameMapData := constant.GetMapByID(gameSession.MapID)
-			
-			return map[string]interface{}{
-				"success":      false,
-				"error":        "Game failed - player hit pearl mold",
-				"game_failed":  true,
-				"reason":       "pearl_mold_collision",
-				"game_map":     gameSession.GetGameMap(),
-				"player_pos": map[string]int{
-					"row": gameSession.CurrentRow,
-					"col": gameSession.CurrentCol,
-				},
-				"score":           gameSession.CurrentScore,
-				"final_score":     gameSession.CurrentScore,
-				"total_moves":     gameSession.TotalMoves,
-				"completion_time": gameSession.CompletionTime,
-				"current_map":     gameMapData,
-			}, nil
-		}
+	var totalPearlsCollected int
+	gameMap := startMap
+	pearlCollected := gameMap[movementResult.NewRow][movementResult.NewCol] == game.PEARL
+	if pearlCollected {
+		totalPearlsCollected++
+	}
 
-		// Check if it's an arrow key for penalty
-		arrowKeyPenalty := 0
-		if direction == "ArrowLeft" || direction == "ArrowDown" || direction == "ArrowUp" || direction == "ArrowRight" {
-			arrowKeyPenalty = 50
+	// Check for pearl mold collision (game failure) - only on hard difficulty maps
+	moldCollision := gameMap[movementResult.NewRow][movementResult.NewCol] == game.PEARL_MOLD
+	if moldCollision && movesExecuted > 1 {
+		// Check if there was a recent mold movement for this session
+		// If so, give extra time for the frontend to update before confirming collision
+		if ms.pearlMoldService != nil && ms.pearlMoldService.HasRecentMoldMovement(gameSession.SessionToken) {
+			// Recent mold movement detected, add extra delay for visual sync
+			time.Sleep(300 * time.Millisecond)
+		} else {
+			// Normal delay to ensure frontend shows the mold position
+			time.Sleep(100 * time.Millisecond)
 		}
+		
+		// Double-check the position after delay to ensure it's still a mold collision
+		// (in case the mold moved again during the delay)
+		currentGameMap := gameSession.GetGameMap()
+		moldCollision = currentGameMap[movementResult.NewRow][movementResult.NewCol] == game.PEARL_MOLD
+	}
 
-		err = ms.db.Transaction(func(tx *gorm.DB) error {
-			return ms.processMovementTransactionWithoutRateLimit(tx, sessionToken, direction, movementResult, pearlCollected, isAnonymous, &gameSession, false, arrowKeyPenalty)
-		})
-
+	if moldCollision {
+		// Fail the game immediately
+		gameSession.FailGame()
+		err := ms.db.Save(&gameSession).Error
 		if err != nil {
 			return map[string]interface{}{
 				"success": false,
 				"error":   err.Error(),
 			}, nil
 		}
-
-		movesExecuted = 1
-	} else {
-		// Multiplier move - for G commands, use absolute positioning; for others, iterate
-		if finalDirection == "file_end" || finalDirection == "file_start" {
-			// For G commands, use absolute positioning directly
-			movementResult, err := ms.calculateNewPositionWithCount(&gameSession, finalDirection, count, hasExplicitCount)
-			if err != nil {
-				return map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-				}, nil
-			}
-
-			if movementResult.IsValid {
-				// Update session position for G commands
-				gameSession.CurrentRow = movementResult.NewRow
-				gameSession.CurrentCol = movementResult.NewCol
-				gameSession.PreferredColumn = movementResult.PreferredColumn
-				
-				finalMovementResult = movementResult
-				movesExecuted = 1 // Count as single move since it's absolute positioning
-			}
-		} else {
-			// For other movements, iterate count times
-			for i := 0; i < count; i++ {
-				movementResult, err := ms.calculateNewPosition(&gameSession, finalDirection)
-				if err != nil {
-					return map[string]interface{}{
-						"success": false,
-						"error":   err.Error(),
-					}, nil
-				}
-
-				// If movement is blocked, stop here
-				if !movementResult.IsValid {
-					break
-				}
-
-				// Temporarily update session for next calculation (but don't persist)
-				gameSession.CurrentRow = movementResult.NewRow
-				gameSession.CurrentCol = movementResult.NewCol
-				gameSession.PreferredColumn = movementResult.PreferredColumn
-
-				finalMovementResult = movementResult
-				movesExecuted++
-			}
-		}
-
-		// Process final move result if any moves were executed
-		if movesExecuted > 0 {
-			// Check for pearl only at final position
-			gameMap := gameSession.GetGameMap()
-			pearlCollected := gameMap[finalMovementResult.NewRow][finalMovementResult.NewCol] == game.PEARL
-			if pearlCollected {
-				totalPearlsCollected++
-			}
-
-			// Check for pearl mold collision (game failure) - only on hard difficulty maps
-			moldCollision := false
-			if gameMap[finalMovementResult.NewRow][finalMovementResult.NewCol] == game.PEARL_MOLD {
-				// Check if there was a recent mold movement for this session
-				// If so, give extra time for the frontend to update before confirming collision
-				if ms.pearlMoldService != nil && ms.pearlMoldService.HasRecentMoldMovement(gameSession.SessionToken) {
-					// Recent mold movement detected, add extra delay for visual sync
-					time.Sleep(300 * time.Millisecond)
-				} else {
-					// Normal delay to ensure frontend shows the mold position
-					time.Sleep(100 * time.Millisecond)
-				}
-				
-				// Double-check the position after delay to ensure it's still a mold collision
-				// (in case the mold moved again during the delay)
-				currentGameMap := gameSession.GetGameMap()
-				if currentGameMap[finalMovementResult.NewRow][finalMovementResult.NewCol] == game.PEARL_MOLD {
-					moldCollision = true
-				}
-			}
-			
-			if moldCollision {
-				// Fail the game immediately
-				gameSession.FailGame()
-				err := ms.db.Save(&gameSession).Error
-				if err != nil {
-					return map[string]interface{}{
-						"success": false,
-						"error":   err.Error(),
-					}, nil
-				}
-				
-				// Get current map information
-				gameMapData := constant.GetMapByID(gameSession.MapID)
-				
-				return map[string]interface{}{
-					"success":      false,
-					"error":        "Game failed - player hit pearl mold",
-					"game_failed":  true,
-					"reason":       "pearl_mold_collision",
-					"game_map":     gameSession.GetGameMap(),
-					"player_pos": map[string]int{
-						"row": gameSession.CurrentRow,
-						"col": gameSession.CurrentCol,
-					},
-					"score":           gameSession.CurrentScore,
-					"final_score":     gameSession.CurrentScore,
-					"total_moves":     gameSession.TotalMoves,
-					"completion_time": gameSession.CompletionTime,
-					"current_map":     gameMapData,
-				}, nil
-			}
-
-			// Check if it's an arrow key for penalty
-			arrowKeyPenalty := 0
-			if direction == "ArrowLeft" || direction == "ArrowDown" || direction == "ArrowUp" || direction == "ArrowRight" {
-				arrowKeyPenalty = 50 * movesExecuted // Apply penalty for each move executed
-			}
-
-			// Process the final move with database transaction
-			err := ms.db.Transaction(func(tx *gorm.DB) error {
-				return ms.processMovementTransactionWithoutRateLimit(tx, sessionToken, direction, finalMovementResult, pearlCollected, isAnonymous, &gameSession, true, arrowKeyPenalty)
-			})
-
-			if err != nil {
-				return map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-				}, nil
-			}
-		}
-	}
-
-	// If no moves were executed at all, return failure
-	if movesExecuted == 0 {
+		
+		// Get current map information
+		gameMapData := constant.GetMapByID(gameSession.MapID)
+		
 		return map[string]interface{}{
-			"success":  false,
-			"error":    "Movement blocked",
-			"game_map": gameSession.GetGameMap(),
+			"success":      false,
+			"error":        "Game failed - player hit pearl mold",
+			"game_failed":  true,
+			"reason":       "pearl_mold_collision",
+			"game_map":     gameSession.GetGameMap(),
 			"player_pos": map[string]int{
 				"row": gameSession.CurrentRow,
 				"col": gameSession.CurrentCol,
 			},
 			"score":           gameSession.CurrentScore,
-			"moves_executed":  0,
-			"moves_requested": count,
+			"final_score":     gameSession.CurrentScore,
+			"total_moves":     gameSession.TotalMoves,
+			"completion_time": gameSession.CompletionTime,
+			"current_map":     gameMapData,
+		}, nil
+	}
+
+	// Check if it's an arrow key for penalty (one penalty per move executed)
+	arrowKeyPenalty := 0
+	if direction == "ArrowLeft" || direction == "ArrowDown" || direction == "ArrowUp" || direction == "ArrowRight" {
+		arrowKeyPenalty = 50 * movesExecuted
+	}
+
+	err = ms.db.Transaction(func(tx *gorm.DB) error {
+		return ms.processMovementTransactionWithoutRateLimit(tx, sessionToken, direction, movementResult, pearlCollected, isAnonymous, &gameSession, count > 1, arrowKeyPenalty)
+	})
+
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
 		}, nil
 	}
 
@@ -384,7 +251,6 @@ func (ms *MovementService) ProcessMove(sessionToken, direction string, count int
 
 	result := map[string]interface{}{
 		"success":  true,
-		"game_map": gameSession.GetGameMap(),
 		"player_pos": map[string]int{
 			"row": gameSession.CurrentRow,
 			"col": gameSession.CurrentCol,
@@ -413,63 +279,31 @@ func (ms *MovementService) ProcessMove(sessionToken, direction string, count int
 			"category":    currentMap.Category,
 		}
 	}
+	addMapState(result, &gameSession, knownVersion, startVersion, startMap)
 
 	return result, nil
 }
 
-// validateDirection validates and converts direction input
-func (ms *MovementService) validateDirection(direction string) (string, error) {
-	// Check for empty direction string
-	if direction == "" {
-		return "", fmt.Errorf("empty direction string")
-	}
-	
-	// Check if this is a character search direction
-	if (len(direction) > 17 && direction[:17] == "find_char_forward") ||
-		(len(direction) > 18 && direction[:18] == "find_char_backward") ||
-		(len(direction) > 17 && direction[:17] == "till_char_forward") ||
-		(len(direction) > 18 && direction[:18] == "till_char_backward") {
-		// For character search, use the direction string directly
-		return direction, nil
-	}
+// addMapState adds the game map to a move response with its version. A client holding the version
+// the move started from gets only the cells that changed as map_changes; any other client, such as
+// one that missed a response, gets the whole map as game_map to resync.
+func addMapState(result map[string]interface{}, gameSession *models.GameSession, knownVersion, startVersion int64, startMap [][]int) {
+	gameMap := gameSession.GetGameMap()
+	result["state_version"] = gameSession.StateVersion
 
-	// Strip number prefix if present (e.g., "5j" -> "j", "123B" -> "B")
-	baseDirection := direction
-	for i, char := range direction {
-		if char < '0' || char > '9' {
-			if i < len(direction) {
-				baseDirection = direction[i:]
-			}
-			break
+	if knownVersion > 0 && knownVersion == startVersion {
+		if changes, ok := game.DiffGameMap(startMap, gameMap); ok {
+			result["map_changes"] = changes
+			return
 		}
 	}
-	
-	// Additional safety check for empty result
-	if baseDirection == "" {
-		return "", fmt.Errorf("empty direction after processing: %s", direction)
-	}
-
-	// For normal movement, look up in MovementKeys map
-	directionName, exists := game.MovementKeys[baseDirection]
-	if !exists {
-		return "", errors.New("invalid movement key")
-	}
-	return directionName["direction"].(string), nil
+	result["game_map"] = gameMap
 }
 
-// calculateNewPosition calculates the new position based on movement
-func (ms *MovementService) calculateNewPosition(gameSession *models.GameSession, direction string) (*game.MovementResult, error) {
-	gameMap := gameSession.GetGameMap()
-	textGrid := gameSession.GetTextGrid()
-
-	return game.CalculateNewPosition(
-		direction,
-		gameSession.CurrentRow,
-		gameSession.CurrentCol,
-		gameMap,
-		textGrid,
-		gameSession.PreferredColumn,
-	)
+// validateDirection validates and converts direction input
+func (ms *MovementService) validateDirection(direction string) (string, error) {
+	// Keys, count prefixes and character search directions are resolved by the motion registry
+	return game.ParseMovementKey(direction)
 }
 
 // calculateNewPositionWithCount calculates the new position based on movement with count support
@@ -486,9 +320,15 @@ func (ms *MovementService) calculateNewPositionWithCount(gameSession *models.Gam
 		gameSession.PreferredColumn,
 		count,
 		hasExplicitCount,
+		ms.movementOptions(gameSession),
 	)
 }
 
+// movementOptions builds the motion settings stored on the game session
+func (ms *MovementService) movementOptions(gameSession *models.GameSession) game.MovementOptions {
+	return MovementOptions(gameSession.VimOptions, gameSession.WrapLines, gameSession.TextWidth)
+}
+
 // processMovementTransactionWithoutRateLimit handles the database transaction for move processing with optional rate limiting bypass
 func (ms *MovementService) processMovementTransactionWithoutRateLimit(tx *gorm.DB, sessionToken string, direction string, movementResult *game.MovementResult, pearlCollected bool, isAnonymous bool, gameSession *models.GameSession, bypassRateLimit bool, arrowKeyPenalty int) error {
 	// Reload session in transaction to ensure fresh state
@@ -518,15 +358,27 @@ func (ms *MovementService) processMovementTransactionWithoutRateLimit(tx *gorm.D
 	// Update game map
 	updatedMap := txGameSession.GetGameMap()
 	if pearlCollected {
-		game.PlaceNewPearl(updatedMap, movementResult.NewRow, movementResult.NewCol)
+		// Challenge games place pearls and enemies from their shared seed
+		seed := txGameSession.PearlSeed
+		if seed != nil {
+			game.PlaceSeededPearl(updatedMap, movementResult.NewRow, movementResult.NewCol, *seed, txGameSession.PearlsCollected)
+		} else {
+			game.PlaceNewPearl(updatedMap, movementResult.NewRow, movementResult.NewCol)
+		}
 		
 		// For hard and medium difficulty maps, reposition enemies when pearl is collected
 		gameMapData := constant.GetMapByID(txGameSession.MapID)
 		if gameMapData != nil {
+			numEnemies := 0
 			if gameMapData.Difficulty == "hard" {
-				game.RepositionEnemies(updatedMap, movementResult.NewRow, movementResult.NewCol, 5)
+				numEnemies = 5
 			} else if gameMapData.Difficulty == "medium" {
-				game.RepositionEnemies(updatedMap, movementResult.NewRow, movementResult.NewCol, 3)
+				numEnemies = 3
+			}
+			if numEnemies > 0 && seed != nil {
+				game.RepositionSeededEnemies(updatedMap, movementResult.NewRow, movementResult.NewCol, numEnemies, *seed, txGameSession.PearlsCollected)
+			} else if numEnemies > 0 {
+				game.RepositionEnemies(updatedMap, movementResult.NewRow, movementResult.NewCol, numEnemies)
 			}
 		}
 		
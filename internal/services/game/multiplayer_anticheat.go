@@ -0,0 +1,393 @@
+package game
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"boba-vim/internal/anticheat"
+	"boba-vim/internal/game"
+	"boba-vim/internal/models"
+	"boba-vim/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// Anti-cheat limits
+const (
+	maxTracedMoves     = 2000 // Moves kept per player; later moves aren't judged
+	maxJudgedMoves     = 400  // Moves whose optimality is worked out per player
+	maxJudgedMotions   = 64   // Distinct motions and counts a path search uses
+	anticheatPlanDepth = 12   // Motions ahead the path search looks for the target
+)
+
+// Match review errors
+var (
+	ErrMatchReviewNotFound   = errors.New("match review not found")
+	ErrMatchReviewResolved   = errors.New("match review was already resolved")
+	ErrInvalidReviewDecision = errors.New("a review is either cleared or confirmed")
+)
+
+// tracedMove is a move a player made, with what the path check needs to judge it later
+type tracedMove struct {
+	at              time.Time
+	direction       string
+	count           int
+	from            Position
+	preferredColumn int
+	to              Position
+	target          Position
+	hasTarget       bool
+	options         game.MovementOptions
+	reaction        time.Duration
+}
+
+// moveTrace is the play of one player during a game
+type moveTrace struct {
+	moves          []tracedMove
+	countdownMoves int
+	awaitingPearl  bool // A new pearl appeared and the player hasn't moved since
+}
+
+// gameTraces records the play of a game's players for the anti-cheat checks. It has its own lock,
+// taken after the game lock when both are held.
+type gameTraces struct {
+	mutex           sync.Mutex
+	players         map[uint]*moveTrace
+	pearl           Position
+	pearlAppearedAt time.Time // Zero when the current pearl's appearance wasn't seen
+}
+
+// player returns a player's trace, creating it. The caller must hold the traces lock.
+func (traces *gameTraces) player(playerID uint) *moveTrace {
+	if traces.players == nil {
+		traces.players = make(map[uint]*moveTrace)
+	}
+	trace, exists := traces.players[playerID]
+	if !exists {
+		trace = &moveTrace{}
+		traces.players[playerID] = trace
+	}
+	return trace
+}
+
+// traceMove records a successful move. pearlBefore and pearlAfter are the pearl's position before
+// and after the move, so the first move of every player after a new pearl appears can be timed.
+// The caller must hold the game lock.
+func (mgs *MultiplayerGameService) traceMove(mpGame *MultiplayerGame, player *MultiplayerPlayer, move tracedMove, pearlBefore, pearlAfter Position) {
+	if mpGame.Settings.Practice {
+		return
+	}
+
+	traces := &mpGame.traces
+	traces.mutex.Lock()
+	defer traces.mutex.Unlock()
+
+	// The pearl moved some other way, so when it appeared isn't known
+	if pearlBefore != traces.pearl {
+		traces.pearl = pearlBefore
+		traces.pearlAppearedAt = time.Time{}
+		for _, trace := range traces.players {
+			trace.awaitingPearl = false
+		}
+	}
+
+	if player.Bot == "" {
+		trace := traces.player(player.ID)
+		if trace.awaitingPearl {
+			if !traces.pearlAppearedAt.IsZero() {
+				move.reaction = move.at.Sub(traces.pearlAppearedAt)
+			}
+			trace.awaitingPearl = false
+		}
+		if len(trace.moves) < maxTracedMoves {
+			trace.moves = append(trace.moves, move)
+		}
+	}
+
+	if pearlAfter != pearlBefore {
+		traces.pearl = pearlAfter
+		traces.pearlAppearedAt = move.at
+		for _, other := range mpGame.Players {
+			if other.Bot == "" && !other.Left {
+				traces.player(other.ID).awaitingPearl = true
+			}
+		}
+	}
+}
+
+// traceCountdownMove counts a move a player sent while the countdown ran
+func (mgs *MultiplayerGameService) traceCountdownMove(mpGame *MultiplayerGame, playerID uint) {
+	traces := &mpGame.traces
+	traces.mutex.Lock()
+	defer traces.mutex.Unlock()
+
+	traces.player(playerID).countdownMoves++
+}
+
+// screenGame runs the anti-cheat checks on the play of every player of a game and returns a
+// review for each player flagged. Bots and practice games aren't screened.
+func (mgs *MultiplayerGameService) screenGame(gameID string) []models.MatchReview {
+	mpGame := mgs.GetGameByID(gameID)
+	if mpGame == nil || mpGame.Settings.Practice {
+		return nil
+	}
+
+	mpGame.mutex.RLock()
+	matchID := mpGame.MatchID
+	gameState := mpGame.GameState
+	var humans []*MultiplayerPlayer
+	for _, player := range mpGame.Players {
+		if player.Bot == "" {
+			humans = append(humans, player)
+		}
+	}
+
+	traces := make(map[uint]moveTrace, len(humans))
+	mpGame.traces.mutex.Lock()
+	for _, player := range humans {
+		if trace, exists := mpGame.traces.players[player.ID]; exists {
+			traces[player.ID] = moveTrace{moves: append([]tracedMove(nil), trace.moves...), countdownMoves: trace.countdownMoves}
+		}
+	}
+	mpGame.traces.mutex.Unlock()
+	mpGame.mutex.RUnlock()
+
+	var reviews []models.MatchReview
+	for _, player := range humans {
+		trace, exists := traces[player.ID]
+		if !exists {
+			continue
+		}
+
+		report := anticheat.Analyze(judgeTrace(gameState, trace))
+		if !report.Flagged() {
+			continue
+		}
+
+		utils.Info("Anti-cheat flagged player %d in game %s: %s", player.ID, gameID, strings.Join(report.Flags, ", "))
+		reviews = append(reviews, models.MatchReview{
+			GameID:               gameID,
+			MatchID:              matchID,
+			PlayerID:             player.ID,
+			Username:             player.Username,
+			Flags:                strings.Join(report.Flags, ","),
+			Moves:                report.Moves,
+			MedianIntervalMs:     report.MedianIntervalMs,
+			IntervalSpread:       report.IntervalSpread,
+			JudgedMoves:          report.JudgedMoves,
+			OptimalMoves:         report.OptimalMoves,
+			LongestOptimalStreak: report.LongestOptimalStreak,
+			InstantReactions:     report.InstantReactions,
+			FastestReactionMs:    report.FastestReactionMs,
+			CountdownMoves:       report.CountdownMoves,
+			Status:               models.MatchReviewPending,
+		})
+	}
+	return reviews
+}
+
+// judgeTrace turns a player's trace into what the anti-cheat checks read, working out whether each
+// move was a step on a shortest path to the player's target. Paths are searched with the motions
+// the player used during the game, on the board as it ended.
+func judgeTrace(gameState *game.GameState, trace moveTrace) anticheat.Trace {
+	judged := anticheat.Trace{
+		Moves:          make([]anticheat.Move, len(trace.moves)),
+		CountdownMoves: trace.countdownMoves,
+	}
+
+	var motions []botMove
+	seen := make(map[botMove]bool)
+	for _, move := range trace.moves {
+		motion := botMove{direction: move.direction, count: move.count}
+		if !seen[motion] && len(motions) < maxJudgedMotions {
+			seen[motion] = true
+			motions = append(motions, motion)
+		}
+	}
+
+	search := pathSearch{
+		gameMap:  gameState.GetGameMap(),
+		textGrid: gameState.GetTextGrid(),
+		motions:  motions,
+	}
+
+	for i, move := range trace.moves {
+		judged.Moves[i] = anticheat.Move{
+			At:       move.at,
+			Key:      strconv.Itoa(move.count) + move.direction,
+			Reaction: move.reaction,
+		}
+		if i < maxJudgedMoves && move.hasTarget && move.from != move.target {
+			judged.Moves[i].Optimal = search.onShortestPath(move)
+		}
+	}
+	return judged
+}
+
+// pathSearch finds shortest paths to a target with a set of motions
+type pathSearch struct {
+	gameMap  [][]int
+	textGrid [][]string
+	motions  []botMove
+}
+
+// onShortestPath reports whether a move was the first step of a shortest path from where it
+// started to its target; nil when the target is out of reach of the search
+func (search pathSearch) onShortestPath(move tracedMove) *bool {
+	type searchStep struct {
+		position        Position
+		preferredColumn int
+		first           uint64 // First steps that reach the position in the fewest motions, as bits
+	}
+
+	// The first steps are told apart by where they end
+	var firstPositions []Position
+	firstBits := make(map[Position]uint64)
+	depths := map[Position]int{move.from: 0}
+	reached := make(map[Position]*searchStep)
+	var frontier []*searchStep
+
+	for _, motion := range search.motions {
+		result, err := game.CalculateNewPositionWithCount(motion.direction, move.from.Row, move.from.Col, search.gameMap, search.textGrid, move.preferredColumn, motion.count, motion.count > 1, move.options)
+		if err != nil || !result.IsValid {
+			continue
+		}
+		position := Position{Row: result.NewRow, Col: result.NewCol}
+		if _, exists := depths[position]; exists {
+			continue
+		}
+		firstBits[position] = 1 << uint(len(firstPositions))
+		firstPositions = append(firstPositions, position)
+		depths[position] = 1
+		step := &searchStep{position: position, preferredColumn: result.PreferredColumn, first: firstBits[position]}
+		reached[position] = step
+		frontier = append(frontier, step)
+	}
+
+	for depth := 1; depth <= anticheatPlanDepth && len(frontier) > 0; depth++ {
+		if step, exists := reached[move.target]; exists && depths[move.target] == depth {
+			optimal := step.first&firstBits[move.to] != 0
+			return &optimal
+		}
+
+		var next []*searchStep
+		for _, step := range frontier {
+			for _, motion := range search.motions {
+				result, err := game.CalculateNewPositionWithCount(motion.direction, step.position.Row, step.position.Col, search.gameMap, search.textGrid, step.preferredColumn, motion.count, motion.count > 1, move.options)
+				if err != nil || !result.IsValid {
+					continue
+				}
+				position := Position{Row: result.NewRow, Col: result.NewCol}
+				if known, exists := depths[position]; exists {
+					// Another shortest way to the same position
+					if known == depth+1 {
+						reached[position].first |= step.first
+					}
+					continue
+				}
+				depths[position] = depth + 1
+				nextStep := &searchStep{position: position, preferredColumn: result.PreferredColumn, first: step.first}
+				reached[position] = nextStep
+				next = append(next, nextStep)
+			}
+		}
+		frontier = next
+	}
+
+	if step, exists := reached[move.target]; exists {
+		optimal := step.first&firstBits[move.to] != 0
+		return &optimal
+	}
+	return nil
+}
+
+// saveMatchReviews stores the reviews of a game's flagged players
+func (mgs *MultiplayerGameService) saveMatchReviews(reviews []models.MatchReview, gameResultID *uint, held bool) {
+	for i := range reviews {
+		reviews[i].GameResultID = gameResultID
+		reviews[i].ResultHeld = held
+	}
+	if err := mgs.db.Create(&reviews).Error; err != nil {
+		utils.Error("Failed to save the match reviews of game %s: %v", reviews[0].GameID, err)
+	}
+}
+
+// ListMatchReviews returns the newest match reviews, only those with the given status unless it is
+// empty
+func (mgs *MultiplayerGameService) ListMatchReviews(status string, limit int) ([]models.MatchReview, error) {
+	query := mgs.db.Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var reviews []models.MatchReview
+	if err := query.Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// GetMatchReview returns a match review
+func (mgs *MultiplayerGameService) GetMatchReview(reviewID uint) (*models.MatchReview, error) {
+	var review models.MatchReview
+	if err := mgs.db.First(&review, reviewID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMatchReviewNotFound
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ResolveMatchReview records an admin's decision on a pending match review. Once every review of
+// a held result is cleared, the result counts toward the players' stats.
+func (mgs *MultiplayerGameService) ResolveMatchReview(reviewID uint, decision string, reviewer string, notes string) (*models.MatchReview, error) {
+	if decision != models.MatchReviewCleared && decision != models.MatchReviewConfirmed {
+		return nil, ErrInvalidReviewDecision
+	}
+
+	review, err := mgs.GetMatchReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewedAt := time.Now()
+	result := mgs.db.Model(&models.MatchReview{}).
+		Where("id = ? AND status = ?", reviewID, models.MatchReviewPending).
+		Updates(map[string]interface{}{
+			"status":       decision,
+			"reviewed_by":  reviewer,
+			"review_notes": notes,
+			"reviewed_at":  reviewedAt,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrMatchReviewResolved
+	}
+
+	review.Status = decision
+	review.ReviewedBy = reviewer
+	review.ReviewNotes = notes
+	review.ReviewedAt = &reviewedAt
+
+	if decision == models.MatchReviewCleared && review.ResultHeld && review.GameResultID != nil {
+		var open int64
+		if err := mgs.db.Model(&models.MatchReview{}).
+			Where("game_result_id = ? AND status <> ?", *review.GameResultID, models.MatchReviewCleared).
+			Count(&open).Error; err != nil {
+			return nil, err
+		}
+		if open == 0 {
+			if err := NewMultiplayerLeaderboardService(mgs.db).ReleaseGameResult(*review.GameResultID); err != nil {
+				return nil, err
+			}
+			utils.Info("Released held result %d of game %s", *review.GameResultID, review.GameID)
+		}
+	}
+	return review, nil
+}
@@ -0,0 +1,349 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"boba-vim/internal/game"
+	"boba-vim/internal/models"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Bot difficulties
+const (
+	BotDifficultyEasy   = "easy"
+	BotDifficultyMedium = "medium"
+	BotDifficultyHard   = "hard"
+)
+
+// botPlayerIDBase starts the player IDs reserved for bots, far above the IDs of registered
+// players. Bots are numbered by seat, so their IDs are only unique within a game.
+const botPlayerIDBase uint = 1 << 31
+
+// botPlanDepth caps how many motions ahead a bot searches for its target
+const botPlanDepth = 12
+
+// botCharacter is the character bots play
+const botCharacter = "boba"
+
+// Practice errors
+var (
+	ErrUnknownBotDifficulty = errors.New("unknown bot difficulty")
+	ErrPracticeMode         = errors.New("unknown practice game mode")
+	ErrPracticeRoomSize     = errors.New("invalid practice room size")
+)
+
+// BotProfile describes how a bot plays
+type BotProfile struct {
+	Difficulty    string
+	Name          string
+	ReactionDelay time.Duration // Average time the bot takes to make a move
+	Keys          []string      // Motions the bot knows
+	MaxCount      int           // Largest count prefix the bot uses on countable motions
+	Optimality    float64       // Chance the bot takes a shortest path move rather than any move
+}
+
+// botProfiles are the difficulties players can practice against, easiest first
+var botProfiles = []BotProfile{
+	{
+		Difficulty:    BotDifficultyEasy,
+		Name:          "Tapioca Bot",
+		ReactionDelay: 900 * time.Millisecond,
+		Keys:          []string{"h", "j", "k", "l"},
+		MaxCount:      1,
+		Optimality:    0.55,
+	},
+	{
+		Difficulty:    BotDifficultyMedium,
+		Name:          "Boba Bot",
+		ReactionDelay: 550 * time.Millisecond,
+		Keys:          []string{"h", "j", "k", "l", "w", "b", "e", "0", "^", "$", "gg", "G"},
+		MaxCount:      1,
+		Optimality:    0.8,
+	},
+	{
+		Difficulty:    BotDifficultyHard,
+		Name:          "Pearl Bot",
+		ReactionDelay: 300 * time.Millisecond,
+		Keys: []string{"h", "j", "k", "l", "w", "W", "b", "B", "e", "E", "ge", "0", "^", "$",
+			"gg", "G", "H", "M", "L", "{", "}", "(", ")"},
+		MaxCount:   9,
+		Optimality: 0.95,
+	},
+}
+
+// countableBotKeys are the motions bots may give a count prefix
+var countableBotKeys = map[string]bool{
+	"h": true, "j": true, "k": true, "l": true,
+	"w": true, "W": true, "b": true, "B": true, "e": true, "E": true,
+}
+
+// BotProfiles returns the difficulties players can practice against, easiest first
+func BotProfiles() []BotProfile {
+	return botProfiles
+}
+
+// GetBotProfile returns the profile of a difficulty
+func GetBotProfile(difficulty string) (BotProfile, bool) {
+	for _, profile := range botProfiles {
+		if profile.Difficulty == difficulty {
+			return profile, true
+		}
+	}
+	return BotProfile{}, false
+}
+
+// IsBotPlayer reports whether a player ID belongs to a bot
+func IsBotPlayer(playerID uint) bool {
+	return playerID >= botPlayerIDBase
+}
+
+// StartPracticeGame starts an unranked game of a player against bots of one difficulty. A duel
+// seats one bot; a free-for-all fills the room with bots; a team game pairs the player with a bot
+// against a team of two bots.
+func (mgs *MultiplayerGameService) StartPracticeGame(playerID uint, username, character, mode string, roomSize int, difficulty string) (*MultiplayerGame, error) {
+	profile, exists := GetBotProfile(difficulty)
+	if !exists {
+		return nil, ErrUnknownBotDifficulty
+	}
+
+	if mode == "" {
+		mode = matchmaking.GameModeDuel
+	}
+	switch mode {
+	case matchmaking.GameModeDuel:
+		roomSize = 2
+	case matchmaking.GameModeFreeForAll:
+		if roomSize < matchmaking.MinFreeForAllRoomSize || roomSize > matchmaking.MaxFreeForAllRoomSize {
+			return nil, ErrPracticeRoomSize
+		}
+	case matchmaking.GameModeTeams:
+		roomSize = matchmaking.TeamRoomSize
+	default:
+		return nil, ErrPracticeMode
+	}
+
+	participants := make([]matchmaking.MatchParticipant, roomSize)
+	participants[0] = matchmaking.MatchParticipant{PlayerID: playerID, Username: username, Character: character}
+	if mode == matchmaking.GameModeTeams {
+		participants[0].Team = TeamOne
+	}
+	for i := 1; i < roomSize; i++ {
+		name := profile.Name
+		if roomSize > 2 {
+			name = fmt.Sprintf("%s %d", profile.Name, i)
+		}
+		participants[i] = matchmaking.MatchParticipant{
+			PlayerID:  botPlayerIDBase + uint(i),
+			Username:  name,
+			Character: botCharacter,
+			Bot:       profile.Difficulty,
+		}
+		if mode == matchmaking.GameModeTeams {
+			participants[i].Team = i/matchmaking.TeamSize + 1
+		}
+	}
+
+	settings := DefaultMultiplayerGameSettings()
+	settings.Ranked = false
+	settings.Unlisted = true
+	settings.Practice = true
+
+	// Practice games get a match ID of their own, like rematches, so the game page finds them
+	started, err := mgs.StartMultiplayerGameWithSettings(uuid.New().String(), mode, participants, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Info("Player %d started a %s practice game against %d %s bots", playerID, mode, roomSize-1, difficulty)
+	return started.(*MultiplayerGame), nil
+}
+
+// startBots sets the bots of a game playing
+func (mgs *MultiplayerGameService) startBots(mpGame *MultiplayerGame) {
+	for _, player := range mpGame.Players {
+		if player.Bot == "" {
+			continue
+		}
+		profile, exists := GetBotProfile(player.Bot)
+		if !exists {
+			utils.Error("Bot %d of game %s has unknown difficulty %q", player.ID, mpGame.ID, player.Bot)
+			continue
+		}
+		go mgs.runBot(mpGame, player.ID, profile)
+	}
+}
+
+// runBot plays a bot until its game ends or leaves this instance
+func (mgs *MultiplayerGameService) runBot(mpGame *MultiplayerGame, botID uint, profile BotProfile) {
+	for {
+		// Vary the reaction time by up to a quarter either way
+		jitter := time.Duration((rand.Float64() - 0.5) * 0.5 * float64(profile.ReactionDelay))
+		select {
+		case <-mgs.ctx.Done():
+			return
+		case <-time.After(profile.ReactionDelay + jitter):
+		}
+
+		if mgs.GetGameByID(mpGame.ID) != mpGame {
+			return
+		}
+
+		// Plan without holding the game lock so players' moves aren't held up
+		mpGame.mutex.RLock()
+		bot := mpGame.Player(botID)
+		if mpGame.IsCompleted || bot == nil || bot.Left {
+			mpGame.mutex.RUnlock()
+			return
+		}
+		if bot.Eliminated {
+			mpGame.mutex.RUnlock()
+			return
+		}
+		waiting := mpGame.CountdownActive || mpGame.Paused
+		position, preferredColumn, options := bot.Position, bot.PreferredColumn, bot.Options
+		rules := mpGame.rules()
+		target, hasTarget := rules.Target(mpGame, bot)
+		mpGame.mutex.RUnlock()
+
+		// A bot already on its target, such as the hill, holds its ground
+		if waiting || !hasTarget || position == target {
+			continue
+		}
+
+		key, count, ok := profile.chooseMove(mpGame.GameState, position, preferredColumn, target, options)
+		if !ok {
+			continue
+		}
+
+		// The planned move is dropped if the board changed while the bot was thinking
+		mpGame.mutex.Lock()
+		current, _ := rules.Target(mpGame, bot)
+		if bot.Position == position && current == target {
+			mgs.applyMove(mpGame, botID, key, count, count > 1)
+		}
+		mpGame.mutex.Unlock()
+	}
+}
+
+// botMove is a motion a bot can make with its count
+type botMove struct {
+	key       string
+	direction string
+	count     int
+}
+
+// botStep is a position a bot reaches and the first move that leads there
+type botStep struct {
+	position        Position
+	preferredColumn int
+	first           int
+}
+
+// moves returns every motion the bot knows with the counts it may use
+func (profile BotProfile) moves() []botMove {
+	var moves []botMove
+	for _, key := range profile.Keys {
+		direction, err := game.ParseMovementKey(key)
+		if err != nil {
+			continue
+		}
+		moves = append(moves, botMove{key: key, direction: direction, count: 1})
+		if countableBotKeys[key] {
+			for count := 2; count <= profile.MaxCount; count++ {
+				moves = append(moves, botMove{key: key, direction: direction, count: count})
+			}
+		}
+	}
+	return moves
+}
+
+// chooseMove picks the bot's next move. Most of the time it is the first move of the shortest
+// path to the target the bot's motions allow; otherwise, or when the target is out of reach, it
+// is any move, the one ending closest to the target when the bot plays well. The target is the
+// pearl under most rule sets.
+func (profile BotProfile) chooseMove(gameState *game.GameState, from Position, preferredColumn int, target Position, options game.MovementOptions) (string, int, bool) {
+	gameMap := gameState.GetGameMap()
+	textGrid := gameState.GetTextGrid()
+	moves := profile.moves()
+
+	visited := map[Position]bool{from: true}
+	var frontier []botStep
+	var firstSteps []botStep
+	for i, move := range moves {
+		result, err := game.CalculateNewPositionWithCount(move.direction, from.Row, from.Col, gameMap, textGrid, preferredColumn, move.count, move.count > 1, options)
+		if err != nil || !result.IsValid {
+			continue
+		}
+		step := botStep{position: Position{Row: result.NewRow, Col: result.NewCol}, preferredColumn: result.PreferredColumn, first: i}
+		firstSteps = append(firstSteps, step)
+		if !visited[step.position] {
+			visited[step.position] = true
+			frontier = append(frontier, step)
+		}
+	}
+	if len(firstSteps) == 0 {
+		return "", 0, false
+	}
+
+	if rand.Float64() >= profile.Optimality {
+		move := moves[firstSteps[rand.Intn(len(firstSteps))].first]
+		return move.key, move.count, true
+	}
+
+	// Breadth-first search over the positions the bot's motions reach
+	for depth := 0; depth < botPlanDepth && len(frontier) > 0; depth++ {
+		var next []botStep
+		for _, step := range frontier {
+			if step.position == target {
+				move := moves[step.first]
+				return move.key, move.count, true
+			}
+			for _, move := range moves {
+				result, err := game.CalculateNewPositionWithCount(move.direction, step.position.Row, step.position.Col, gameMap, textGrid, step.preferredColumn, move.count, move.count > 1, options)
+				if err != nil || !result.IsValid {
+					continue
+				}
+				position := Position{Row: result.NewRow, Col: result.NewCol}
+				if visited[position] {
+					continue
+				}
+				visited[position] = true
+				next = append(next, botStep{position: position, preferredColumn: result.PreferredColumn, first: step.first})
+			}
+		}
+		frontier = next
+	}
+
+	// The target is out of reach for now, so close in on it
+	closest := firstSteps[0]
+	for _, step := range firstSteps[1:] {
+		if distance(step.position, target) < distance(closest.position, target) {
+			closest = step
+		}
+	}
+	move := moves[closest.first]
+	return move.key, move.count, true
+}
+
+// distance returns the number of rows and columns between two positions
+func distance(a, b Position) int {
+	rows, cols := a.Row-b.Row, a.Col-b.Col
+	if rows < 0 {
+		rows = -rows
+	}
+	if cols < 0 {
+		cols = -cols
+	}
+	return rows + cols
+}
+
+// botMovementOptions returns the vim options bots play with
+func botMovementOptions() game.MovementOptions {
+	return MovementOptions(models.DefaultVimOptions(), false, 0)
+}
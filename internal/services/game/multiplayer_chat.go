@@ -0,0 +1,406 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"boba-vim/internal/models"
+	"boba-vim/internal/utils"
+)
+
+// Chat limits
+const (
+	ChatRateLimit     = 5                // Messages a player may send within ChatRateWindow
+	ChatRateWindow    = 10 * time.Second // Window the rate limit counts messages over
+	MaxChatTextLength = 120              // Characters of a free text message
+)
+
+// Chat actions players send over the game WebSocket
+const (
+	ChatActionSend   = "send"
+	ChatActionMute   = "mute"
+	ChatActionUnmute = "unmute"
+)
+
+// Chat errors
+var (
+	ErrChatGameNotFound   = errors.New("game not found")
+	ErrChatNotInGame      = errors.New("player not in this game")
+	ErrChatPlayerNotFound = errors.New("that player isn't in this game")
+	ErrUnknownChatAction  = errors.New("unknown chat action")
+	ErrUnknownQuickChat   = errors.New("unknown quick chat message")
+	ErrUnknownEmote       = errors.New("unknown emote")
+	ErrEmptyChatMessage   = errors.New("chat message is empty")
+	ErrChatTextTooLong    = errors.New("chat message is too long")
+	ErrChatFriendsOnly    = errors.New("free text chat is only open between friends")
+	ErrChatRateLimited    = errors.New("you're sending messages too fast")
+	ErrMuteSelf           = errors.New("you can't mute yourself")
+)
+
+// ChatOption is a canned chat message or emote players pick from
+type ChatOption struct {
+	Code string `json:"code"`
+	Text string `json:"text"`
+}
+
+// Canned chat messages and emotes, in the order clients show them
+var (
+	quickChatMessages = []ChatOption{
+		{Code: "gg", Text: "gg"},
+		{Code: "glhf", Text: "Good luck, have fun!"},
+		{Code: "nice", Text: "Nice %!"},
+		{Code: "wow", Text: "Wow!"},
+		{Code: "close", Text: "That was close!"},
+		{Code: "oops", Text: "Oops"},
+		{Code: "thanks", Text: "Thanks!"},
+		{Code: "rematch", Text: "Rematch?"},
+	}
+	chatEmotes = []ChatOption{
+		{Code: "boba", Text: "🧋"},
+		{Code: "pearl", Text: "⚫"},
+		{Code: "wave", Text: "👋"},
+		{Code: "laugh", Text: "😂"},
+		{Code: "cry", Text: "😢"},
+		{Code: "fire", Text: "🔥"},
+		{Code: "thumbs_up", Text: "👍"},
+		{Code: "clap", Text: "👏"},
+	}
+)
+
+// ChatCatalog is what players can send in a game's chat
+type ChatCatalog struct {
+	QuickMessages []ChatOption `json:"quick_messages"`
+	Emotes        []ChatOption `json:"emotes"`
+	MaxTextLength int          `json:"max_text_length"` // Free text is only open between friends
+	RateLimit     int          `json:"rate_limit"`
+	RateWindow    int          `json:"rate_window"` // in seconds
+}
+
+// ChatRequest is a chat message, mute or unmute a player sends over the game WebSocket. A message
+// carries one of a quick message code, an emote code or free text.
+type ChatRequest struct {
+	Action   string `json:"action"`
+	Quick    string `json:"quick,omitempty"`
+	Emote    string `json:"emote,omitempty"`
+	Text     string `json:"text,omitempty"`
+	PlayerID uint   `json:"player_id,omitempty"` // Player to mute or unmute; every other player when 0
+}
+
+// ChatMessageData is a chat message as the game's players receive it
+type ChatMessageData struct {
+	PlayerID uint      `json:"player_id"`
+	Username string    `json:"username"`
+	Kind     string    `json:"kind"`
+	Code     string    `json:"code,omitempty"` // Quick message or emote code
+	Text     string    `json:"text"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// ChatMuteData tells a player whose messages they muted
+type ChatMuteData struct {
+	Muted []uint `json:"muted"`
+}
+
+// gameChat is the chat state a game keeps on the node running it. Friendships and blocks between
+// the game's players are loaded the first time one of them chats.
+type gameChat struct {
+	sent      map[uint][]time.Time // Player to when they sent their recent messages
+	relations map[[2]uint]string   // Friendship status from a player to another, as stored
+	loaded    bool
+}
+
+// GetChatCatalog returns the canned messages and emotes players can send, and the chat limits
+func GetChatCatalog() ChatCatalog {
+	return ChatCatalog{
+		QuickMessages: quickChatMessages,
+		Emotes:        chatEmotes,
+		MaxTextLength: MaxChatTextLength,
+		RateLimit:     ChatRateLimit,
+		RateWindow:    int(ChatRateWindow / time.Second),
+	}
+}
+
+// HandleChat sends a player's chat message to the other players of their game, or mutes or
+// unmutes other players for them. Chat stays open after the game ends, for as long as the game
+// is kept around for rematches.
+func (mgs *MultiplayerGameService) HandleChat(gameID string, playerID uint, request ChatRequest) error {
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		return mgs.node.Call(owner, clusterMethodChat, gameCall{GameID: gameID, PlayerID: playerID, Chat: &request}, nil)
+	}
+	if mpGame == nil {
+		return ErrChatGameNotFound
+	}
+
+	switch request.Action {
+	case ChatActionSend, "":
+		return mgs.sendChat(mpGame, playerID, request)
+	case ChatActionMute:
+		return mgs.setChatMute(mpGame, playerID, request.PlayerID, true)
+	case ChatActionUnmute:
+		return mgs.setChatMute(mpGame, playerID, request.PlayerID, false)
+	default:
+		return ErrUnknownChatAction
+	}
+}
+
+// sendChat delivers a chat message to the sender and to the players who neither muted nor
+// blocked them, and logs it for moderation
+func (mgs *MultiplayerGameService) sendChat(mpGame *MultiplayerGame, playerID uint, request ChatRequest) error {
+	data, content, err := newChatMessage(request)
+	if err != nil {
+		return err
+	}
+
+	mpGame.mutex.RLock()
+	sender := mpGame.Player(playerID)
+	var others []uint
+	for _, player := range mpGame.Players {
+		if player.ID != playerID && player.Bot == "" {
+			others = append(others, player.ID)
+		}
+	}
+	loaded := mpGame.chat.loaded
+	mpGame.mutex.RUnlock()
+
+	if sender == nil {
+		return ErrChatNotInGame
+	}
+	if !loaded {
+		mgs.loadChatRelations(mpGame)
+	}
+
+	now := time.Now()
+	mpGame.mutex.Lock()
+	if data.Kind == models.ChatKindText {
+		for _, otherID := range others {
+			if !mpGame.chat.areFriends(playerID, otherID) {
+				mpGame.mutex.Unlock()
+				return ErrChatFriendsOnly
+			}
+		}
+	}
+	if !mpGame.chat.allow(playerID, now) {
+		mpGame.mutex.Unlock()
+		return ErrChatRateLimited
+	}
+
+	recipients := []uint{playerID}
+	for _, otherID := range others {
+		if !mpGame.hasMuted(otherID, playerID) && !mpGame.chat.blocked(playerID, otherID) {
+			recipients = append(recipients, otherID)
+		}
+	}
+	mpGame.mutex.Unlock()
+
+	data.PlayerID = playerID
+	data.Username = sender.Username
+	data.SentAt = now
+	mgs.wsManager.BroadcastToPlayers(recipients, MultiplayerGameMessage{
+		Type:      "chat_message",
+		PlayerID:  playerID,
+		Data:      data,
+		Timestamp: now,
+	})
+
+	entry := &models.MatchChatMessage{
+		GameID:    mpGame.ID,
+		MatchID:   mpGame.MatchID,
+		PlayerID:  playerID,
+		Username:  sender.Username,
+		Kind:      data.Kind,
+		Content:   content,
+		Delivered: data.Text,
+		Filtered:  data.Kind == models.ChatKindText && content != data.Text,
+	}
+	logChat := func() {
+		if err := mgs.db.Create(entry).Error; err != nil {
+			utils.Error("Failed to log chat message of player %d in game %s: %v", playerID, mpGame.ID, err)
+		}
+	}
+	select {
+	case mgs.dbWorkerPool <- logChat:
+	default:
+		go logChat()
+	}
+	return nil
+}
+
+// setChatMute mutes or unmutes another player, or every other player, for a player
+func (mgs *MultiplayerGameService) setChatMute(mpGame *MultiplayerGame, playerID, targetID uint, muted bool) error {
+	mpGame.mutex.Lock()
+	if mpGame.Player(playerID) == nil {
+		mpGame.mutex.Unlock()
+		return ErrChatNotInGame
+	}
+	if targetID == playerID {
+		mpGame.mutex.Unlock()
+		return ErrMuteSelf
+	}
+	if targetID != 0 && mpGame.Player(targetID) == nil {
+		mpGame.mutex.Unlock()
+		return ErrChatPlayerNotFound
+	}
+
+	targets := make(map[uint]bool)
+	for _, player := range mpGame.Players {
+		if player.ID != playerID && (targetID == 0 || player.ID == targetID) {
+			targets[player.ID] = true
+		}
+	}
+
+	var list []uint
+	for _, mutedID := range mpGame.ChatMutes[playerID] {
+		if !targets[mutedID] {
+			list = append(list, mutedID)
+		}
+	}
+	if muted {
+		for _, player := range mpGame.Players {
+			if targets[player.ID] {
+				list = append(list, player.ID)
+			}
+		}
+	}
+
+	if mpGame.ChatMutes == nil {
+		mpGame.ChatMutes = make(map[uint][]uint)
+	}
+	if len(list) == 0 {
+		delete(mpGame.ChatMutes, playerID)
+	} else {
+		mpGame.ChatMutes[playerID] = list
+	}
+	mpGame.mutex.Unlock()
+
+	mgs.saveSnapshot(mpGame)
+	if list == nil {
+		list = []uint{}
+	}
+	return mgs.SendMessageToPlayer(playerID, "chat_muted", ChatMuteData{Muted: list})
+}
+
+// loadChatRelations loads the friendships and blocks between a game's players
+func (mgs *MultiplayerGameService) loadChatRelations(mpGame *MultiplayerGame) {
+	mpGame.mutex.RLock()
+	playerIDs := mpGame.PlayerIDs()
+	mpGame.mutex.RUnlock()
+
+	var links []models.Friendship
+	if err := mgs.db.Where("player_id IN ? AND friend_id IN ?", playerIDs, playerIDs).Find(&links).Error; err != nil {
+		// Chat goes on as between strangers, and the next message tries again
+		utils.Error("Failed to load friendships of game %s: %v", mpGame.ID, err)
+		return
+	}
+
+	relations := make(map[[2]uint]string, len(links))
+	for _, link := range links {
+		relations[[2]uint{link.PlayerID, link.FriendID}] = link.Status
+	}
+
+	mpGame.mutex.Lock()
+	mpGame.chat.relations = relations
+	mpGame.chat.loaded = true
+	mpGame.mutex.Unlock()
+}
+
+// newChatMessage validates a chat message and returns it as players receive it, along with what
+// the player sent. Free text is filtered for profanity.
+func newChatMessage(request ChatRequest) (ChatMessageData, string, error) {
+	switch {
+	case request.Quick != "":
+		option, ok := findChatOption(quickChatMessages, request.Quick)
+		if !ok {
+			return ChatMessageData{}, "", ErrUnknownQuickChat
+		}
+		return ChatMessageData{Kind: models.ChatKindQuick, Code: option.Code, Text: option.Text}, option.Code, nil
+	case request.Emote != "":
+		option, ok := findChatOption(chatEmotes, request.Emote)
+		if !ok {
+			return ChatMessageData{}, "", ErrUnknownEmote
+		}
+		return ChatMessageData{Kind: models.ChatKindEmote, Code: option.Code, Text: option.Text}, option.Code, nil
+	}
+
+	// Line breaks and runs of spaces are collapsed so a message stays on one line
+	text := strings.Join(strings.Fields(request.Text), " ")
+	if text == "" {
+		return ChatMessageData{}, "", ErrEmptyChatMessage
+	}
+	if utf8.RuneCountInString(text) > MaxChatTextLength {
+		return ChatMessageData{}, "", ErrChatTextTooLong
+	}
+
+	filtered, _ := utils.FilterProfanity(text)
+	return ChatMessageData{Kind: models.ChatKindText, Text: filtered}, text, nil
+}
+
+// findChatOption returns the canned message or emote with a code
+func findChatOption(options []ChatOption, code string) (ChatOption, bool) {
+	for _, option := range options {
+		if option.Code == code {
+			return option, true
+		}
+	}
+	return ChatOption{}, false
+}
+
+// hasMuted reports whether a player muted another. The caller must hold the game lock.
+func (mpGame *MultiplayerGame) hasMuted(playerID, otherID uint) bool {
+	for _, mutedID := range mpGame.ChatMutes[playerID] {
+		if mutedID == otherID {
+			return true
+		}
+	}
+	return false
+}
+
+// allow records a message a player sends, unless they already sent ChatRateLimit messages within
+// the rate window
+func (chat *gameChat) allow(playerID uint, now time.Time) bool {
+	var recent []time.Time
+	for _, sentAt := range chat.sent[playerID] {
+		if now.Sub(sentAt) < ChatRateWindow {
+			recent = append(recent, sentAt)
+		}
+	}
+	if len(recent) >= ChatRateLimit {
+		chat.sent[playerID] = recent
+		return false
+	}
+
+	if chat.sent == nil {
+		chat.sent = make(map[uint][]time.Time)
+	}
+	chat.sent[playerID] = append(recent, now)
+	return true
+}
+
+// areFriends reports whether two players are friends
+func (chat *gameChat) areFriends(playerID, otherID uint) bool {
+	return chat.relations[[2]uint{playerID, otherID}] == models.FriendshipAccepted ||
+		chat.relations[[2]uint{otherID, playerID}] == models.FriendshipAccepted
+}
+
+// blocked reports whether either of two players blocked the other
+func (chat *gameChat) blocked(playerID, otherID uint) bool {
+	return chat.relations[[2]uint{playerID, otherID}] == models.FriendshipBlocked ||
+		chat.relations[[2]uint{otherID, playerID}] == models.FriendshipBlocked
+}
+
+// GetChatLog returns the chat messages logged for a game, or for every game of a match, oldest
+// first. Only messages with masked profanity are returned when filteredOnly is set.
+func (mgs *MultiplayerGameService) GetChatLog(gameOrMatchID string, filteredOnly bool) ([]models.MatchChatMessage, error) {
+	query := mgs.db.Where("game_id = ? OR match_id = ?", gameOrMatchID, gameOrMatchID)
+	if filteredOnly {
+		query = query.Where("filtered = ?", true)
+	}
+
+	var messages []models.MatchChatMessage
+	if err := query.Order("created_at ASC, id ASC").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
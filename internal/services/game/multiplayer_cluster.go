@@ -0,0 +1,473 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"boba-vim/internal/constant"
+	"boba-vim/internal/game"
+	"boba-vim/internal/services/cluster"
+	"boba-vim/internal/utils"
+)
+
+// A game is run by one node of the cluster, which holds it in activeGames and is the only one to
+// change it. The other nodes forward the requests of players connected to them to that node. The
+// running node keeps a snapshot of the game in the cluster store, so when it goes away, during a
+// deploy or a crash, another node adopts the game from its last snapshot and it plays on.
+// Snapshots are saved on lifecycle events such as the start, a pause or the end of a game, and at
+// most every snapshotInterval while it is played, so a game adopted after a crash loses at most
+// the moves of the last interval.
+const (
+	gameNodesCollection     = "multiplayer:game_nodes" // Game ID to the node running the game
+	gameSnapshotsCollection = "multiplayer:games"      // Game ID to the game's last snapshot
+	matchGamesCollection    = "multiplayer:matches"    // Match ID to game ID
+	playerGamesCollection   = "multiplayer:players"    // Player ID to the game they play
+
+	adoptLeaseTTL         = 10 * time.Second // Time a node has to adopt a game before another may try
+	orphanedGamesInterval = 10 * time.Second // How often nodes look for games whose node is gone
+	snapshotInterval      = 2 * time.Second  // How often the snapshot of a game being played is saved
+)
+
+// Cluster methods of the multiplayer game service
+const (
+	clusterMethodMove           = "game.move"
+	clusterMethodSequencedMove  = "game.sequenced_move"
+	clusterMethodGameState      = "game.state"
+	clusterMethodConnectionLost = "game.connection_lost"
+	clusterMethodReconnect      = "game.reconnect"
+	clusterMethodDisconnect     = "game.disconnect"
+	clusterMethodCountdown      = "game.countdown"
+	clusterMethodRematch        = "game.rematch"
+	clusterMethodChat           = "game.chat"
+	clusterMethodResync         = "game.resync"
+)
+
+// gameSnapshot is the state of a game other nodes need to take it over
+type gameSnapshot struct {
+	ID               string                  `json:"id"`
+	MatchID          string                  `json:"match_id"`
+	Mode             string                  `json:"mode"`
+	Players          []*MultiplayerPlayer    `json:"players"`
+	Settings         MultiplayerGameSettings `json:"settings"`
+	MapID            int                     `json:"map_id"`
+	CreatedAt        time.Time               `json:"created_at"`
+	LastActivity     time.Time               `json:"last_activity"`
+	GameState        *game.GameState         `json:"game_state"`
+	Winner           *uint                   `json:"winner"`
+	WinningTeam      int                     `json:"winning_team,omitempty"`
+	IsCompleted      bool                    `json:"is_completed"`
+	SessionToken     string                  `json:"session_token"`
+	CountdownActive  bool                    `json:"countdown_active"`
+	CountdownStarted bool                    `json:"countdown_started"`
+	EndsAt           *time.Time              `json:"ends_at"`
+	Paused           bool                    `json:"paused"`
+	TimeRemaining    time.Duration           `json:"time_remaining"`
+	Series           *GameSeries             `json:"series,omitempty"`
+	CompletedAt      *time.Time              `json:"completed_at,omitempty"`
+	RematchGameID    string                  `json:"rematch_game_id,omitempty"`
+	RuleState        RuleState               `json:"rule_state"`
+	ChatMutes        map[uint][]uint         `json:"chat_mutes,omitempty"`
+}
+
+// gameCall is a request about a game forwarded to the node running it
+type gameCall struct {
+	GameID           string       `json:"game_id"`
+	PlayerID         uint         `json:"player_id"`
+	Seq              uint64       `json:"seq,omitempty"`
+	Direction        string       `json:"direction,omitempty"`
+	Count            int          `json:"count,omitempty"`
+	HasExplicitCount bool         `json:"has_explicit_count,omitempty"`
+	Predicted        *Position    `json:"predicted,omitempty"`
+	Rematch          string       `json:"rematch,omitempty"`
+	BestOf           int          `json:"best_of,omitempty"`
+	Chat             *ChatRequest `json:"chat,omitempty"`
+}
+
+// reconnectReply is the answer to a forwarded reconnect
+type reconnectReply struct {
+	State  map[string]interface{} `json:"state"`
+	Resync bool                   `json:"resync"`
+}
+
+// clusterKey returns the cluster key of a player
+func clusterKey(playerID uint) string {
+	return strconv.FormatUint(uint64(playerID), 10)
+}
+
+// registerClusterHandlers answers the requests other nodes forward about games this node runs
+func (mgs *MultiplayerGameService) registerClusterHandlers() {
+	mgs.node.Handle(clusterMethodMove, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		return mgs.ProcessMove(call.GameID, call.PlayerID, call.Direction, call.Count, call.HasExplicitCount)
+	}))
+	mgs.node.Handle(clusterMethodSequencedMove, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		return mgs.ProcessSequencedMove(call.GameID, call.PlayerID, call.Seq, call.Direction, call.Count, call.HasExplicitCount, call.Predicted)
+	}))
+	mgs.node.Handle(clusterMethodGameState, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		return mgs.GetGameState(call.GameID, call.PlayerID)
+	}))
+	mgs.node.Handle(clusterMethodConnectionLost, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		mgs.HandleConnectionLost(call.GameID, call.PlayerID)
+		return nil, nil
+	}))
+	mgs.node.Handle(clusterMethodReconnect, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		state, resync, err := mgs.HandlePlayerReconnect(call.GameID, call.PlayerID)
+		return reconnectReply{State: state, Resync: resync}, err
+	}))
+	mgs.node.Handle(clusterMethodDisconnect, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		mgs.HandlePlayerDisconnect(call.PlayerID)
+		return nil, nil
+	}))
+	mgs.node.Handle(clusterMethodCountdown, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		mgs.RequestCountdown(call.GameID)
+		return nil, nil
+	}))
+	mgs.node.Handle(clusterMethodRematch, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		return nil, mgs.HandleRematch(call.GameID, call.PlayerID, call.Rematch, call.BestOf)
+	}))
+	mgs.node.Handle(clusterMethodChat, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		if call.Chat == nil {
+			return nil, ErrUnknownChatAction
+		}
+		return nil, mgs.HandleChat(call.GameID, call.PlayerID, *call.Chat)
+	}))
+	mgs.node.Handle(clusterMethodResync, mgs.clusterHandler(func(call gameCall) (interface{}, error) {
+		return nil, mgs.ResyncGameState(call.GameID, call.PlayerID)
+	}))
+}
+
+// clusterHandler decodes a forwarded game request for handle
+func (mgs *MultiplayerGameService) clusterHandler(handle func(call gameCall) (interface{}, error)) cluster.Handler {
+	return func(body json.RawMessage) (interface{}, error) {
+		var call gameCall
+		if err := json.Unmarshal(body, &call); err != nil {
+			return nil, err
+		}
+		return handle(call)
+	}
+}
+
+// locateGame returns a game this node runs, or the other node that runs it. A game whose node is
+// gone is adopted by this node. Both are empty when the game doesn't exist.
+func (mgs *MultiplayerGameService) locateGame(gameID string) (*MultiplayerGame, string) {
+	if mpGame := mgs.GetGameByID(gameID); mpGame != nil {
+		return mpGame, ""
+	}
+
+	nodeID, err := mgs.node.Store.Get(gameNodesCollection, gameID)
+	if err != nil {
+		if err != cluster.ErrNotFound {
+			utils.Error("Failed to look up the node of game %s: %v", gameID, err)
+		}
+		return nil, ""
+	}
+
+	if string(nodeID) != mgs.node.ID && mgs.node.IsAlive(string(nodeID)) {
+		return nil, string(nodeID)
+	}
+	return mgs.adoptGame(gameID), ""
+}
+
+// gameForMatch returns the ID of the game started for a match
+func (mgs *MultiplayerGameService) gameForMatch(matchID string) (string, bool) {
+	gameID, err := mgs.node.Store.Get(matchGamesCollection, matchID)
+	if err != nil {
+		if err != cluster.ErrNotFound {
+			utils.Error("Failed to look up the game of match %s: %v", matchID, err)
+		}
+		return "", false
+	}
+	return string(gameID), true
+}
+
+// gameForPlayer returns the ID of the game a player plays
+func (mgs *MultiplayerGameService) gameForPlayer(playerID uint) (string, bool) {
+	gameID, err := mgs.node.Store.Get(playerGamesCollection, clusterKey(playerID))
+	if err != nil {
+		if err != cluster.ErrNotFound {
+			utils.Error("Failed to look up the game of player %d: %v", playerID, err)
+		}
+		return "", false
+	}
+	return string(gameID), true
+}
+
+// IsPlayerInGame reports whether a player plays a multiplayer game on any instance
+func (mgs *MultiplayerGameService) IsPlayerInGame(playerID uint) bool {
+	_, exists := mgs.gameForPlayer(playerID)
+	return exists
+}
+
+// publishGame records a game this node started in the cluster
+func (mgs *MultiplayerGameService) publishGame(mpGame *MultiplayerGame) {
+	store := mgs.node.Store
+	if err := store.Put(gameNodesCollection, mpGame.ID, []byte(mgs.node.ID)); err != nil {
+		utils.Error("Failed to record the node of game %s: %v", mpGame.ID, err)
+	}
+	if mpGame.MatchID != "" {
+		if err := store.Put(matchGamesCollection, mpGame.MatchID, []byte(mpGame.ID)); err != nil {
+			utils.Error("Failed to record the game of match %s: %v", mpGame.MatchID, err)
+		}
+	}
+	for _, playerID := range mpGame.PlayerIDs() {
+		if IsBotPlayer(playerID) {
+			continue
+		}
+		if err := store.Put(playerGamesCollection, clusterKey(playerID), []byte(mpGame.ID)); err != nil {
+			utils.Error("Failed to record the game of player %d: %v", playerID, err)
+		}
+	}
+	mgs.saveSnapshot(mpGame)
+}
+
+// unpublishGame removes a game that ended from the cluster
+func (mgs *MultiplayerGameService) unpublishGame(mpGame *MultiplayerGame) {
+	store := mgs.node.Store
+	store.DeleteIf(gameNodesCollection, mpGame.ID, []byte(mgs.node.ID))
+	store.Delete(gameSnapshotsCollection, mpGame.ID)
+	if mpGame.MatchID != "" {
+		store.DeleteIf(matchGamesCollection, mpGame.MatchID, []byte(mpGame.ID))
+	}
+	for _, playerID := range mpGame.PlayerIDs() {
+		if IsBotPlayer(playerID) {
+			continue
+		}
+		store.DeleteIf(playerGamesCollection, clusterKey(playerID), []byte(mpGame.ID))
+	}
+}
+
+// saveSnapshotThrottled stores the current state of a game unless its snapshot was saved less than
+// snapshotInterval ago, for the updates sent with every move
+func (mgs *MultiplayerGameService) saveSnapshotThrottled(mpGame *MultiplayerGame) {
+	if time.Since(time.Unix(0, mpGame.snapshotSavedAt.Load())) < snapshotInterval {
+		return
+	}
+	mgs.saveSnapshot(mpGame)
+}
+
+// saveSnapshot stores the current state of a game for other nodes to take it over
+func (mgs *MultiplayerGameService) saveSnapshot(mpGame *MultiplayerGame) {
+	mpGame.snapshotSavedAt.Store(time.Now().UnixNano())
+	mpGame.mutex.RLock()
+	snapshot := gameSnapshot{
+		ID:               mpGame.ID,
+		MatchID:          mpGame.MatchID,
+		Mode:             mpGame.Mode,
+		Players:          mpGame.Players,
+		Settings:         mpGame.Settings,
+		MapID:            mpGame.MapID,
+		CreatedAt:        mpGame.CreatedAt,
+		LastActivity:     mpGame.LastActivity,
+		GameState:        mpGame.GameState,
+		Winner:           mpGame.Winner,
+		WinningTeam:      mpGame.WinningTeam,
+		IsCompleted:      mpGame.IsCompleted,
+		SessionToken:     mpGame.SessionToken,
+		CountdownActive:  mpGame.CountdownActive,
+		CountdownStarted: mpGame.CountdownStarted,
+		EndsAt:           mpGame.EndsAt,
+		Paused:           mpGame.Paused,
+		TimeRemaining:    mpGame.timeRemaining,
+		Series:           mpGame.Series,
+		CompletedAt:      mpGame.CompletedAt,
+		RematchGameID:    mpGame.RematchGameID,
+		RuleState:        mpGame.RuleState,
+		ChatMutes:        mpGame.ChatMutes,
+	}
+	data, err := json.Marshal(snapshot)
+	mpGame.mutex.RUnlock()
+
+	if err != nil {
+		utils.Error("Failed to encode snapshot of game %s: %v", mpGame.ID, err)
+		return
+	}
+	if err := mgs.node.Store.Put(gameSnapshotsCollection, mpGame.ID, data); err != nil {
+		utils.Error("Failed to save snapshot of game %s: %v", mpGame.ID, err)
+	}
+}
+
+// adoptGame takes over a game whose node is gone from its last snapshot. It returns nil when the
+// game can't be adopted, or another node is adopting it.
+func (mgs *MultiplayerGameService) adoptGame(gameID string) *MultiplayerGame {
+	if !mgs.node.HoldLease("multiplayer:adopt:"+gameID, adoptLeaseTTL) {
+		return nil
+	}
+
+	// The game may have been adopted here while the lease was taken
+	if mpGame := mgs.GetGameByID(gameID); mpGame != nil {
+		return mpGame
+	}
+
+	data, err := mgs.node.Store.Get(gameSnapshotsCollection, gameID)
+	if err != nil {
+		utils.Error("Failed to load snapshot of game %s: %v", gameID, err)
+		return nil
+	}
+
+	var snapshot gameSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		utils.Error("Invalid snapshot of game %s: %v", gameID, err)
+		return nil
+	}
+
+	mpGame, err := mgs.restoreGame(&snapshot)
+	if err != nil {
+		utils.Error("Failed to adopt game %s: %v", gameID, err)
+		return nil
+	}
+
+	if err := mgs.node.Store.Put(gameNodesCollection, gameID, []byte(mgs.node.ID)); err != nil {
+		utils.Error("Failed to record the node of game %s: %v", gameID, err)
+	}
+	utils.Info("Adopted multiplayer game %s", gameID)
+
+	// Players who were connected to the node that is gone have the reconnect grace period to
+	// come back here or to any other node
+	for _, player := range snapshot.Players {
+		if player.Bot == "" && !player.Left && !player.Disconnected && !mgs.wsManager.IsPlayerConnected(player.ID) {
+			mgs.HandleConnectionLost(gameID, player.ID)
+		}
+	}
+	return mpGame
+}
+
+// restoreGame rebuilds a game from a snapshot, restarts its clocks and adds it to the games this
+// node runs
+func (mgs *MultiplayerGameService) restoreGame(snapshot *gameSnapshot) (*MultiplayerGame, error) {
+	gameMap := constant.GetMapByID(snapshot.MapID)
+	if gameMap == nil {
+		return nil, fmt.Errorf("map %d not found", snapshot.MapID)
+	}
+	if snapshot.GameState == nil || len(snapshot.Players) < 2 {
+		return nil, fmt.Errorf("incomplete snapshot")
+	}
+
+	mpGame := &MultiplayerGame{
+		ID:               snapshot.ID,
+		MatchID:          snapshot.MatchID,
+		Mode:             snapshot.Mode,
+		Players:          snapshot.Players,
+		Settings:         snapshot.Settings,
+		MapID:            snapshot.MapID,
+		GameMap:          gameMap,
+		CreatedAt:        snapshot.CreatedAt,
+		LastActivity:     time.Now(),
+		GameState:        snapshot.GameState,
+		Winner:           snapshot.Winner,
+		WinningTeam:      snapshot.WinningTeam,
+		IsCompleted:      snapshot.IsCompleted,
+		SessionToken:     snapshot.SessionToken,
+		CountdownActive:  snapshot.CountdownActive,
+		CountdownStarted: snapshot.CountdownStarted,
+		EndsAt:           snapshot.EndsAt,
+		Paused:           snapshot.Paused,
+		timeRemaining:    snapshot.TimeRemaining,
+		Series:           snapshot.Series,
+		CompletedAt:      snapshot.CompletedAt,
+		RematchGameID:    snapshot.RematchGameID,
+		RuleState:        snapshot.RuleState,
+		ChatMutes:        snapshot.ChatMutes,
+	}
+
+	// A countdown cut short runs again once the players are back
+	if mpGame.CountdownActive {
+		mpGame.CountdownStarted = false
+	}
+
+	mgs.gamesMutex.Lock()
+	if existing, exists := mgs.activeGames[mpGame.ID]; exists {
+		mgs.gamesMutex.Unlock()
+		return existing, nil
+	}
+	mgs.activeGames[mpGame.ID] = mpGame
+	mgs.gamesMutex.Unlock()
+
+	if mpGame.IsCompleted {
+		time.AfterFunc(5*time.Minute, func() {
+			mgs.cleanupGame(mpGame.ID)
+		})
+		return mpGame, nil
+	}
+
+	mpGame.mutex.Lock()
+	defer mpGame.mutex.Unlock()
+
+	if mpGame.EndsAt != nil {
+		remaining := time.Until(*mpGame.EndsAt)
+		if mpGame.Paused {
+			remaining = mpGame.timeRemaining
+		}
+		mpGame.timeLimitTimer = time.AfterFunc(remaining, func() {
+			mgs.endGameOnTimeLimit(mpGame)
+		})
+		if mpGame.Paused {
+			mpGame.timeLimitTimer.Stop()
+		}
+	}
+
+	// Rules that change the game with time carry on once its countdown is over
+	if !mpGame.CountdownActive {
+		go mgs.startRuleTicks(mpGame)
+	}
+
+	for _, player := range mpGame.Players {
+		if !player.Disconnected || player.Left || player.ReconnectDeadline == nil {
+			continue
+		}
+		playerID := player.ID
+		player.forfeitTimer = time.AfterFunc(time.Until(*player.ReconnectDeadline), func() {
+			mgs.forfeitPlayer(mpGame, playerID, true)
+		})
+	}
+
+	// Bots play on wherever their game runs
+	mgs.startBots(mpGame)
+
+	return mpGame, nil
+}
+
+// watchOrphanedGames adopts the games whose node went away, so their clocks keep running even
+// when no player is sending requests
+func (mgs *MultiplayerGameService) watchOrphanedGames() {
+	ticker := time.NewTicker(orphanedGamesInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-mgs.ctx.Done():
+			return
+		case <-ticker.C:
+			gameNodes, err := mgs.node.Store.List(gameNodesCollection)
+			if err != nil {
+				utils.Error("Failed to list the nodes of multiplayer games: %v", err)
+				continue
+			}
+
+			for gameID, nodeID := range gameNodes {
+				if string(nodeID) == mgs.node.ID || mgs.node.IsAlive(string(nodeID)) {
+					continue
+				}
+				mgs.adoptGame(gameID)
+			}
+		}
+	}
+}
+
+// handOffGames saves the snapshot of every game this node runs so other nodes can adopt them
+// once it is gone
+func (mgs *MultiplayerGameService) handOffGames() {
+	mgs.gamesMutex.RLock()
+	games := make([]*MultiplayerGame, 0, len(mgs.activeGames))
+	for _, mpGame := range mgs.activeGames {
+		games = append(games, mpGame)
+	}
+	mgs.gamesMutex.RUnlock()
+
+	for _, mpGame := range games {
+		mgs.saveSnapshot(mpGame)
+	}
+	if len(games) > 0 {
+		utils.Info("Handed off %d multiplayer games to the other instances", len(games))
+	}
+}
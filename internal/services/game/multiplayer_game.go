@@ -3,8 +3,8 @@ package game
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"boba-vim/internal/cache"
@@ -12,6 +12,7 @@ import (
 	"boba-vim/internal/constant"
 	"boba-vim/internal/game"
 	"boba-vim/internal/models"
+	"boba-vim/internal/services/cluster"
 	"boba-vim/internal/services/matchmaking"
 	"boba-vim/internal/utils"
 
@@ -24,9 +25,9 @@ import (
 type MultiplayerGameService struct {
 	db           *gorm.DB
 	cfg          *config.Config
-	activeGames  map[string]*MultiplayerGame
-	matchToGame  map[string]string // Maps match ID to game ID
+	activeGames  map[string]*MultiplayerGame // Games this instance runs
 	gamesMutex   sync.RWMutex
+	node         *cluster.Node
 	wsManager    *matchmaking.WebSocketManager
 	ctx          context.Context
 	cancel       context.CancelFunc
@@ -43,30 +44,41 @@ type MultiplayerGameService struct {
 // MultiplayerGame represents an active multiplayer game
 type MultiplayerGame struct {
 	ID                     string
-	Player1ID              uint
-	Player1Username        string
-	Player1Character       string
-	Player1Position        Position
-	Player1PreferredColumn int
-	Player1Score           int
-	Player2ID              uint
-	Player2Username        string
-	Player2Character       string
-	Player2Position        Position
-	Player2PreferredColumn int
-	Player2Score           int
+	MatchID                string
+	Mode                   string               // matchmaking.GameModeDuel, GameModeFreeForAll or GameModeTeams
+	Players                []*MultiplayerPlayer // Ordered by seat; the player set is fixed when the game starts
+	Settings               MultiplayerGameSettings
 	MapID                  int
 	GameMap                *constant.Map
 	CreatedAt              time.Time
 	LastActivity           time.Time
 	GameState              *game.GameState
 	Winner                 *uint
+	WinningTeam            int  // Team that won a team game; 0 for other games and ties
 	IsCompleted            bool
 	SessionToken           string
 	CountdownActive        bool
 	CountdownValue         int
 	CountdownStarted       bool // Prevent multiple countdown triggers
+	EndsAt                 *time.Time // Set when the time limit starts running
+	timeLimitTimer         *time.Timer
+	Paused                 bool          // A duel waits while its player reconnects
+	timeRemaining          time.Duration // Time left on the clock when the game was paused
 	mutex                  sync.RWMutex
+	spectators             map[string]*Spectator // Read-only viewers, keyed by spectator ID
+	spectatorsMutex        sync.Mutex            // Taken after mutex when both are needed
+	Series                 *GameSeries   // Best-of series the game is part of; nil for a single game
+	CompletedAt            *time.Time    // When the game ended; rematches are offered for a while after
+	RematchGameID          string        // Game started as this game's rematch or next series game
+	rematchOffer           *rematchOffer // Pending rematch offer of one of the players
+	RuleState              RuleState     // What the game's rule set keeps between moves
+	ruleTicking            bool          // The rule set's ticks are running on this instance
+	ChatMutes              map[uint][]uint // Player to the players whose chat messages they muted
+	chat                   gameChat        // Chat rate limits and the friendships between the players
+	traces                 gameTraces      // The players' moves, for the anti-cheat checks
+	stateSync              gameStateSync   // Version of the state sent to the players
+	mapVersion             uint64          // Goes up whenever a move changes the game map
+	snapshotSavedAt        atomic.Int64    // When the game's snapshot was last saved, in Unix nanoseconds
 }
 
 // Position represents a player's position
@@ -83,30 +95,36 @@ type MultiplayerGameMessage struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
-// GameUpdateData represents game state updates
+// GameUpdateData represents game state updates. Player1 and Player2 mirror the first two seats for duel clients.
 type GameUpdateData struct {
-	Player1Position Position `json:"player1_position"`
-	Player1Score    int      `json:"player1_score"`
-	Player2Position Position `json:"player2_position"`
-	Player2Score    int      `json:"player2_score"`
-	PearlPosition   Position `json:"pearl_position"`
-	GameState       string   `json:"game_state"`
+	Player1Position Position           `json:"player1_position"`
+	Player1Score    int                `json:"player1_score"`
+	Player2Position Position           `json:"player2_position"`
+	Player2Score    int                `json:"player2_score"`
+	Players         []PlayerUpdateData `json:"players"`
+	Teams           []TeamUpdateData   `json:"teams,omitempty"`
+	PearlPosition   Position           `json:"pearl_position"`
+	GameState       string             `json:"game_state"`
+	SpectatorCount  int                `json:"spectator_count"`
+	RuleSet         string             `json:"rule_set"`
+	Rules           *RuleState         `json:"rules,omitempty"`
+	Version         uint64             `json:"version"` // Version of the state, see GameStateDelta
 }
 
-// NewMultiplayerGameService creates a new multiplayer game service
-func NewMultiplayerGameService(db *gorm.DB, cfg *config.Config, wsManager *matchmaking.WebSocketManager) *MultiplayerGameService {
+// NewMultiplayerGameService creates a new multiplayer game service running games on a cluster node
+func NewMultiplayerGameService(db *gorm.DB, cfg *config.Config, node *cluster.Node, wsManager *matchmaking.WebSocketManager) *MultiplayerGameService {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	// Create WebSocket manager if not provided
 	if wsManager == nil {
-		wsManager = matchmaking.NewWebSocketManager()
+		wsManager = matchmaking.NewWebSocketManager(node, "game")
 	}
 	
 	mgs := &MultiplayerGameService{
 		db:          db,
 		cfg:         cfg,
 		activeGames: make(map[string]*MultiplayerGame),
-		matchToGame: make(map[string]string),
+		node:        node,
 		wsManager:   wsManager,
 		ctx:         ctx,
 		cancel:      cancel,
@@ -139,6 +157,10 @@ func NewMultiplayerGameService(db *gorm.DB, cfg *config.Config, wsManager *match
 	// Start periodic game state broadcasting
 	go mgs.periodicGameStateBroadcast()
 	
+	// Answer the other instances and take over the games of those that went away
+	mgs.registerClusterHandlers()
+	go mgs.watchOrphanedGames()
+	
 	return mgs
 }
 
@@ -191,14 +213,36 @@ func (mgs *MultiplayerGameService) startWorkerPools() {
 	utils.Info("Worker pools initialized: %d update workers, %d database workers", numUpdateWorkers, numDBWorkers)
 }
 
-// StartMultiplayerGame creates a new multiplayer game from a match
-func (mgs *MultiplayerGameService) StartMultiplayerGame(matchID string, player1ID uint, player1Username, player1Character string, player2ID uint, player2Username, player2Character string) (interface{}, error) {
+// StartMultiplayerGame creates a new multiplayer game from a match on the map matchmaking selected.
+// Participants are seated in order.
+func (mgs *MultiplayerGameService) StartMultiplayerGame(matchID string, mode string, participants []matchmaking.MatchParticipant, mapSelection matchmaking.MapSelection) (interface{}, error) {
+	settings := DefaultMultiplayerGameSettings()
+	settings.MapID = mapSelection.MapID
+	settings.MapVeto = mapSelection.Veto
+	return mgs.StartMultiplayerGameWithSettings(matchID, mode, participants, settings)
+}
+
+// StartMultiplayerGameWithSettings creates a new multiplayer game played with the given rules
+func (mgs *MultiplayerGameService) StartMultiplayerGameWithSettings(matchID string, mode string, participants []matchmaking.MatchParticipant, settings MultiplayerGameSettings) (interface{}, error) {
+	if err := validateParticipants(mode, participants); err != nil {
+		return nil, err
+	}
+	if settings.TargetScore <= 0 {
+		settings.TargetScore = DefaultMultiplayerTargetScore
+	}
+	if err := PrepareRuleSet(&settings); err != nil {
+		return nil, err
+	}
+	
 	// Generate unique game session ID
 	gameID := uuid.New().String()
 	sessionToken := uuid.New().String()
 	
-	// Randomly select a map (you can implement map selection logic here)
-	selectedMap := constant.GAME_MAPS[rand.Intn(len(constant.GAME_MAPS))]
+	// Use the chosen map, or select one randomly
+	selectedMap, err := settings.selectMap()
+	if err != nil {
+		return nil, err
+	}
 	
 	// Create game state using the text pattern
 	mapContent := game.CreateTextGridFromString(selectedMap.TextPattern)
@@ -208,87 +252,50 @@ func (mgs *MultiplayerGameService) StartMultiplayerGame(matchID string, player1I
 	}
 	gameState := game.NewGameState(mapContent, selectedMap.ID)
 	
-	// Determine random starting positions with safe bounds checking
-	var player1Pos, player2Pos Position
-	
 	// Validate map content is not empty
 	if len(mapContent) == 0 {
 		return nil, fmt.Errorf("empty map content")
 	}
 	
-	maxCol := 0
-	
-	// Find the maximum column across all rows
-	for _, row := range mapContent {
-		if len(row) > maxCol {
-			maxCol = len(row)
+	// Determine random starting positions spread over the map
+	startPositions, err := spawnPositions(mapContent, len(participants))
+	if err != nil {
+		utils.Error("Failed to place %d players on map %s: %v", len(participants), selectedMap.Name, err)
+		return nil, err
+	}
+	
+	// Seat every participant
+	players := make([]*MultiplayerPlayer, len(participants))
+	for i, participant := range participants {
+		startPos := startPositions[i]
+		players[i] = &MultiplayerPlayer{
+			ID:              participant.PlayerID,
+			Username:        participant.Username,
+			Character:       participant.Character,
+			Seat:            gameState.AddPlayer(startPos.Row, startPos.Col),
+			Position:        startPos,
+			PreferredColumn: startPos.Col, // Initialize with starting column
+			Bot:             participant.Bot,
+			Team:            participant.Team,
 		}
-	}
-	
-	// Validate there are columns
-	if maxCol == 0 {
-		return nil, fmt.Errorf("map has no columns")
-	}
-	
-	maxCol = maxCol - 1 // Convert to 0-based index
-	
-	// Find non-empty rows for player placement
-	firstNonEmptyRow := -1
-	lastNonEmptyRow := -1
-	
-	// Find first non-empty row
-	for i := 0; i < len(mapContent); i++ {
-		if len(mapContent[i]) > 0 {
-			firstNonEmptyRow = i
-			break
+		if participant.Team != 0 {
+			gameState.SetPlayerTeam(players[i].Seat, participant.Team)
 		}
-	}
-	
-	// Find last non-empty row
-	for i := len(mapContent) - 1; i >= 0; i-- {
-		if len(mapContent[i]) > 0 {
-			lastNonEmptyRow = i
-			break
+		if participant.Bot != "" {
+			players[i].Options = botMovementOptions()
+		} else {
+			players[i].Options = mgs.playerMovementOptions(participant.PlayerID)
 		}
 	}
-	
-	// Validate we found non-empty rows
-	if firstNonEmptyRow == -1 || lastNonEmptyRow == -1 {
-		utils.Error("No non-empty rows found in map")
-		return nil, fmt.Errorf("no non-empty rows found in map")
-	}
-	
-	firstRowMaxCol := len(mapContent[firstNonEmptyRow]) - 1
-	lastRowMaxCol := len(mapContent[lastNonEmptyRow]) - 1
-	
-	utils.Debug("firstNonEmptyRow: %d, lastNonEmptyRow: %d, firstRowMaxCol: %d, lastRowMaxCol: %d", 
-		firstNonEmptyRow, lastNonEmptyRow, firstRowMaxCol, lastRowMaxCol)
-	
-	if rand.Float32() < 0.5 {
-		// Player 1 starts at top-left, Player 2 at bottom-right
-		player1Pos = Position{Row: firstNonEmptyRow, Col: 0}
-		player2Pos = Position{Row: lastNonEmptyRow, Col: lastRowMaxCol}
-	} else {
-		// Player 2 starts at top-left, Player 1 at bottom-right
-		player2Pos = Position{Row: firstNonEmptyRow, Col: 0}
-		player1Pos = Position{Row: lastNonEmptyRow, Col: lastRowMaxCol}
-	}
+	utils.Debug("Seated %d players at %+v", len(players), startPositions)
 	
 	// Create multiplayer game
 	mpGame := &MultiplayerGame{
 		ID:                     gameID,
-		Player1ID:              player1ID,
-		Player1Username:        player1Username,
-		Player1Character:       player1Character,
-		Player1Position:        player1Pos,
-		Player1PreferredColumn: player1Pos.Col, // Initialize with starting column
-		Player1Score:           0,
-		Player2ID:              player2ID,
-		Player2Username:        player2Username,
-		Player2Character:       player2Character,
-		Player2Position:        player2Pos,
-		Player2PreferredColumn: player2Pos.Col, // Initialize with starting column
-		Player2Score:           0,
+		MatchID:                matchID,
+		Mode:                   mode,
+		Players:                players,
+		Settings:               settings,
 		MapID:                  selectedMap.ID,
 		GameMap:                &selectedMap,
 		CreatedAt:              time.Now(),
@@ -301,50 +308,18 @@ func (mgs *MultiplayerGameService) StartMultiplayerGame(matchID string, player1I
 		CountdownStarted:       false,
 	}
 	
-	// Set initial player positions in game state
-	gameState.SetPlayerPosition(player1Pos.Row, player1Pos.Col)
-	gameState.SetPlayer2Position(player2Pos.Row, player2Pos.Col)
-	
-	// Update game map to show both players
-	gameMap := gameState.GetGameMap()
-	utils.Debug("gameMap length: %d, player1Pos: %+v, player2Pos: %+v", len(gameMap), player1Pos, player2Pos)
-	
-	// Safety check before accessing gameMap
-	if len(gameMap) == 0 {
-		utils.Error("gameMap is empty! mapContent length was: %d", len(mapContent))
-		return nil, fmt.Errorf("game map is empty after initialization")
-	}
-	
-	// Check if positions are within bounds
-	if player1Pos.Row >= len(gameMap) || player1Pos.Row < 0 {
-		utils.Error("player1Pos row out of bounds: %+v, gameMap size: %d", player1Pos, len(gameMap))
-		return nil, fmt.Errorf("player1 position row out of bounds")
-	}
-	
-	if len(gameMap[player1Pos.Row]) == 0 || player1Pos.Col >= len(gameMap[player1Pos.Row]) || player1Pos.Col < 0 {
-		utils.Error("player1Pos col out of bounds: %+v, row length: %d", player1Pos, len(gameMap[player1Pos.Row]))
-		return nil, fmt.Errorf("player1 position col out of bounds")
-	}
-	
-	if player2Pos.Row >= len(gameMap) || player2Pos.Row < 0 {
-		utils.Error("player2Pos row out of bounds: %+v, gameMap size: %d", player2Pos, len(gameMap))
-		return nil, fmt.Errorf("player2 position row out of bounds")
-	}
-	
-	if len(gameMap[player2Pos.Row]) == 0 || player2Pos.Col >= len(gameMap[player2Pos.Row]) || player2Pos.Col < 0 {
-		utils.Error("player2Pos col out of bounds: %+v, row length: %d", player2Pos, len(gameMap[player2Pos.Row]))
-		return nil, fmt.Errorf("player2 position col out of bounds")
-	}
-	
-	gameMap[player1Pos.Row][player1Pos.Col] = 1 // Player 1
-	gameMap[player2Pos.Row][player2Pos.Col] = 2 // Player 2 (different value)
+	// Let the rule set ready the board; the game isn't shared yet
+	mpGame.rules().Setup(mpGame)
 	
 	// Add to active games and match mapping
 	mgs.gamesMutex.Lock()
 	mgs.activeGames[gameID] = mpGame
-	mgs.matchToGame[matchID] = gameID
 	mgs.gamesMutex.Unlock()
 	
+	// Let the other instances find the game
+	mgs.publishGame(mpGame)
+	mgs.startBots(mpGame)
+	
 	// Cache game state in Redis for faster access
 	if mgs.cache != nil && mgs.cache.IsAvailable() {
 		gameStateData := mgs.getGameStateData(mpGame)
@@ -359,9 +334,9 @@ func (mgs *MultiplayerGameService) StartMultiplayerGame(matchID string, player1I
 	case mgs.dbWorkerPool <- func() {
 		dbGame := &models.GameSession{
 			SessionToken:     sessionToken,
-			PlayerID:         &player1ID,
+			PlayerID:         &participants[0].PlayerID,
 			MapID:            selectedMap.ID,
-			SelectedCharacter: player1Character,
+			SelectedCharacter: participants[0].Character,
 			IsActive:         true,
 			IsCompleted:      false,
 			IsMultiplayer:    true,
@@ -376,9 +351,9 @@ func (mgs *MultiplayerGameService) StartMultiplayerGame(matchID string, player1I
 		// If worker pool is full, do it synchronously
 		dbGame := &models.GameSession{
 			SessionToken:     sessionToken,
-			PlayerID:         &player1ID,
+			PlayerID:         &participants[0].PlayerID,
 			MapID:            selectedMap.ID,
-			SelectedCharacter: player1Character,
+			SelectedCharacter: participants[0].Character,
 			IsActive:         true,
 			IsCompleted:      false,
 			IsMultiplayer:    true,
@@ -396,19 +371,21 @@ func (mgs *MultiplayerGameService) StartMultiplayerGame(matchID string, player1I
 	// Don't start countdown automatically - wait for players to connect via WebSocket
 	// Countdown will be triggered when the first player connects
 	
-	utils.Info("Multiplayer game %s started between %s and %s", gameID, player1Username, player2Username)
+	utils.Info("Multiplayer game %s (%s) started with %d players", gameID, mode, len(players))
 	utils.Info("Match ID %s mapped to game ID %s", matchID, gameID)
 	return mpGame, nil
 }
 
 // ProcessMove processes a move in a multiplayer game
 func (mgs *MultiplayerGameService) ProcessMove(gameID string, playerID uint, direction string, count int, hasExplicitCount bool) (map[string]interface{}, error) {
-	// Get game reference with read lock
-	mgs.gamesMutex.RLock()
-	mpGame, exists := mgs.activeGames[gameID]
-	mgs.gamesMutex.RUnlock()
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		var result map[string]interface{}
+		err := mgs.node.Call(owner, clusterMethodMove, gameCall{GameID: gameID, PlayerID: playerID, Direction: direction, Count: count, HasExplicitCount: hasExplicitCount}, &result)
+		return result, err
+	}
 	
-	if !exists {
+	if mpGame == nil {
 		return map[string]interface{}{
 			"success": false,
 			"error":   "Game not found",
@@ -418,6 +395,7 @@ func (mgs *MultiplayerGameService) ProcessMove(gameID string, playerID uint, dir
 	// Check if countdown is active - block movement during countdown
 	if mgs.IsCountdownActive(gameID) {
 		utils.Debug("🚫 Movement blocked for player %d in game %s - countdown active", playerID, gameID)
+		mgs.traceCountdownMove(mpGame, playerID)
 		return map[string]interface{}{
 			"success": false,
 			"error":   "Movement blocked during countdown",
@@ -429,20 +407,35 @@ func (mgs *MultiplayerGameService) ProcessMove(gameID string, playerID uint, dir
 	mpGame.mutex.Lock()
 	defer mpGame.mutex.Unlock()
 	
+	return mgs.applyMove(mpGame, playerID, direction, count, hasExplicitCount), nil
+}
+
+// applyMove moves a player and returns the move response. The caller must hold the game lock.
+func (mgs *MultiplayerGameService) applyMove(mpGame *MultiplayerGame, playerID uint, direction string, count int, hasExplicitCount bool) map[string]interface{} {
 	// Check if game is completed
 	if mpGame.IsCompleted {
 		return map[string]interface{}{
 			"success": false,
 			"error":   "Game already completed",
-		}, nil
+		}
 	}
 	
 	// Check if countdown is active - block moves during countdown
 	if mpGame.CountdownActive {
+		mgs.traceCountdownMove(mpGame, playerID)
 		return map[string]interface{}{
 			"success": false,
 			"error":   "Game is starting, please wait for countdown to finish",
-		}, nil
+		}
+	}
+	
+	// Block moves while a player is reconnecting
+	if mpGame.Paused {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Game is paused while a player reconnects",
+			"paused":  true,
+		}
 	}
 	
 	// Update last activity
@@ -454,96 +447,85 @@ func (mgs *MultiplayerGameService) ProcessMove(gameID string, playerID uint, dir
 		return map[string]interface{}{
 			"success": false,
 			"error":   err.Error(),
-		}, nil
+		}
 	}
 	
 	// Determine which player is moving
-	var currentPos *Position
-	var currentScore *int
-	var currentPreferredColumn *int
-	isPlayer1 := playerID == mpGame.Player1ID
-	
-	if isPlayer1 {
-		currentPos = &mpGame.Player1Position
-		currentScore = &mpGame.Player1Score
-		currentPreferredColumn = &mpGame.Player1PreferredColumn
-	} else if playerID == mpGame.Player2ID {
-		currentPos = &mpGame.Player2Position
-		currentScore = &mpGame.Player2Score
-		currentPreferredColumn = &mpGame.Player2PreferredColumn
-	} else {
+	player := mpGame.Player(playerID)
+	if player == nil {
 		return map[string]interface{}{
 			"success": false,
 			"error":   "Player not in this game",
-		}, nil
+		}
 	}
+	if player.Left {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Player left this game",
+		}
+	}
+	if player.Eliminated {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Player was eliminated",
+		}
+	}
+	
+	// Note what the anti-cheat checks judge the move against before it changes the board
+	oldPreferredColumn := player.PreferredColumn
+	target, hasTarget := mpGame.rules().Target(mpGame, player)
+	oldPearl := mpGame.GameState.GetPearlPosition()
+	oldMap := mpGame.GameState.GetGameMap()
 	
 	// Process the move using the existing game logic
-	oldRow, oldCol := currentPos.Row, currentPos.Col
-	newRow, newCol, newPreferredColumn, moveScore := game.ProcessMove(mpGame.GameState, oldRow, oldCol, validatedDirection, count, hasExplicitCount, *currentPreferredColumn)
+	oldRow, oldCol := player.Position.Row, player.Position.Col
+	newRow, newCol, newPreferredColumn, pearlScore := game.ProcessMove(mpGame.GameState, oldRow, oldCol, validatedDirection, count, hasExplicitCount, player.PreferredColumn, player.Options)
 	
 	// Only update if the move was valid (position changed or stayed same for valid reasons)
-	if newRow != oldRow || newCol != oldCol || moveScore > 0 {
-		// Update player position, preferred column, and score
-		currentPos.Row = newRow
-		currentPos.Col = newCol
-		*currentPreferredColumn = newPreferredColumn
-		*currentScore += moveScore
+	if newRow != oldRow || newCol != oldCol || pearlScore > 0 {
+		// Update player position and preferred column
+		player.Position = Position{Row: newRow, Col: newCol}
+		player.PreferredColumn = newPreferredColumn
 		
 		// Update game state
-		if isPlayer1 {
-			mpGame.GameState.SetPlayerPosition(newRow, newCol)
-		} else {
-			mpGame.GameState.SetPlayer2Position(newRow, newCol)
-		}
+		mpGame.GameState.SetPlayerPosition(player.Seat, newRow, newCol)
 		
-		// Update game map to show both players correctly
-		gameMap := mpGame.GameState.GetGameMap()
-		// Clear old positions
-		for row := 0; row < len(gameMap); row++ {
-			for col := 0; col < len(gameMap[row]); col++ {
-				if gameMap[row][col] == 1 || gameMap[row][col] == 2 {
-					gameMap[row][col] = 0
-				}
-			}
-		}
-		// Set new positions
-		gameMap[mpGame.Player1Position.Row][mpGame.Player1Position.Col] = 1 // Player 1
-		gameMap[mpGame.Player2Position.Row][mpGame.Player2Position.Col] = 2 // Player 2
-		// Pearl position is handled separately by the game state
+		// Score the move and check the win condition with the game's rules - do this before
+		// sending updates. Points count for the whole team in a team game.
+		rules := mpGame.rules()
+		moveScore := rules.ScoreMove(mpGame, player, pearlScore)
+		player.Score += moveScore
+		gameCompleted := mpGame.decide(rules)
 		
-		// Check win condition (1300 points) - do this before sending updates
-		gameCompleted := *currentScore >= 1300
-		if gameCompleted {
-			mpGame.IsCompleted = true
-			mpGame.Winner = &playerID
+		newPearl := mpGame.GameState.GetPearlPosition()
+		mgs.traceMove(mpGame, player, tracedMove{
+			at:              mpGame.LastActivity,
+			direction:       validatedDirection,
+			count:           count,
+			from:            Position{Row: oldRow, Col: oldCol},
+			preferredColumn: oldPreferredColumn,
+			to:              player.Position,
+			target:          target,
+			hasTarget:       hasTarget,
+			options:         player.Options,
+		}, Position{Row: oldPearl.Row, Col: oldPearl.Col}, Position{Row: newPearl.Row, Col: newPearl.Col})
+		
+		// Clients already hold the text and the map, so only the cells the move changed are sent
+		// with the map's version before and after the move. A client whose version isn't the base
+		// version missed a change and resyncs.
+		mapChanges, _ := game.DiffGameMap(oldMap, mpGame.GameState.GetGameMap())
+		baseMapVersion := mpGame.mapVersion
+		if len(mapChanges) > 0 {
+			mpGame.mapVersion++
 		}
 		
 		// Create response data
 		responseData := map[string]interface{}{
 			"success": true,
 			"game_id": mpGame.ID,
-			"map": map[string]interface{}{
-				"id":      mpGame.MapID,
-				"content": mpGame.GameMap.TextPattern,
-				"name":    mpGame.GameMap.Name,
-			},
-			"text_grid": mpGame.GameState.GetTextGrid(),
-			"game_map":  mpGame.GameState.GetGameMap(),
-			"player1": map[string]interface{}{
-				"id":        mpGame.Player1ID,
-				"username":  mpGame.Player1Username,
-				"character": mpGame.Player1Character,
-				"position":  mpGame.Player1Position,
-				"score":     mpGame.Player1Score,
-			},
-			"player2": map[string]interface{}{
-				"id":        mpGame.Player2ID,
-				"username":  mpGame.Player2Username,
-				"character": mpGame.Player2Character,
-				"position":  mpGame.Player2Position,
-				"score":     mpGame.Player2Score,
-			},
+			"map_version":      mpGame.mapVersion,
+			"base_map_version": baseMapVersion,
+			"map_changes":      mapChanges,
 			"pearl_position": mpGame.GameState.GetPearlPosition(),
 			"is_completed":   mpGame.IsCompleted,
 			"winner":         mpGame.Winner,
@@ -551,42 +533,44 @@ func (mgs *MultiplayerGameService) ProcessMove(gameID string, playerID uint, dir
 			"move_score":     moveScore,
 			"completed":      mpGame.IsCompleted,
 		}
+		addPlayersData(responseData, mpGame)
+		addGameRulesData(responseData, mpGame)
 		
-		// Send updates to both players using worker pool to prevent goroutine explosion
+		// Send updates to every player using worker pool to prevent goroutine explosion
 		select {
 		case mgs.updateWorkerPool <- func() {
 			mgs.sendGameUpdate(mpGame)
 		}:
 		default:
-			// If worker pool is full, do it synchronously to avoid blocking
-			mgs.sendGameUpdate(mpGame)
+			// If worker pool is full, send from a goroutine since the caller holds the game lock
+			go mgs.sendGameUpdate(mpGame)
 		}
 		
 		// Handle game completion using worker pool
 		if gameCompleted {
 			select {
 			case mgs.dbWorkerPool <- func() {
-				mgs.handleGameCompletion(mpGame)
+				mgs.handleGameCompletion(mpGame, "normal")
 			}:
 			default:
 				// If worker pool is full, do it synchronously
-				go mgs.handleGameCompletion(mpGame)
+				go mgs.handleGameCompletion(mpGame, "normal")
 			}
 		}
 		
-		return responseData, nil
+		return responseData
 	} else {
 		// Invalid move - return error
 		return map[string]interface{}{
 			"success": false,
 			"error":   "Invalid move",
-			"game_map": mpGame.GameState.GetGameMap(),
+			"map_version": mpGame.mapVersion,
 			"player_pos": map[string]int{
 				"row": oldRow,
 				"col": oldCol,
 			},
-			"score": *currentScore,
-		}, nil
+			"score": player.Score,
+		}
 	}
 }
 
@@ -610,6 +594,7 @@ func (mgs *MultiplayerGameService) GetGameState(gameID string, playerID uint) (m
 					"position": cachedData.Player2Position,
 					"score":    cachedData.Player2Score,
 				},
+				"players":        cachedData.Players,
 				"pearl_position": cachedData.PearlPosition,
 				"game_state":     cachedData.GameState,
 				"current_player": playerID,
@@ -617,12 +602,15 @@ func (mgs *MultiplayerGameService) GetGameState(gameID string, playerID uint) (m
 		}
 	}
 	
-	// Fallback to database/memory lookup
-	mgs.gamesMutex.RLock()
-	mpGame, exists := mgs.activeGames[gameID]
-	mgs.gamesMutex.RUnlock()
+	// Fallback to the instance running the game
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		var result map[string]interface{}
+		err := mgs.node.Call(owner, clusterMethodGameState, gameCall{GameID: gameID, PlayerID: playerID}, &result)
+		return result, err
+	}
 	
-	if !exists {
+	if mpGame == nil {
 		return map[string]interface{}{
 			"success": false,
 			"error":   "Game not found",
@@ -633,16 +621,22 @@ func (mgs *MultiplayerGameService) GetGameState(gameID string, playerID uint) (m
 	defer mpGame.mutex.RUnlock()
 	
 	// Verify player is in this game
-	if playerID != mpGame.Player1ID && playerID != mpGame.Player2ID {
+	if mpGame.Player(playerID) == nil {
 		return map[string]interface{}{
 			"success": false,
 			"error":   "Player not in this game",
 		}, nil
 	}
 	
-	return map[string]interface{}{
+	return mgs.gameStateResponse(mpGame, playerID), nil
+}
+
+// gameStateResponse returns the full state of a game as seen by one of its players, enough for a
+// reconnecting client to rebuild the game. The caller must hold the game lock.
+func (mgs *MultiplayerGameService) gameStateResponse(mpGame *MultiplayerGame, playerID uint) map[string]interface{} {
+	response := map[string]interface{}{
 		"success": true,
-		"game_id": gameID,
+		"game_id": mpGame.ID,
 		"map": map[string]interface{}{
 			"id":      mpGame.MapID,
 			"content": mpGame.GameMap.TextPattern,
@@ -650,35 +644,36 @@ func (mgs *MultiplayerGameService) GetGameState(gameID string, playerID uint) (m
 		},
 		"text_grid": mpGame.GameState.GetTextGrid(),
 		"game_map":  mpGame.GameState.GetGameMap(),
-		"player1": map[string]interface{}{
-			"id":        mpGame.Player1ID,
-			"username":  mpGame.Player1Username,
-			"character": mpGame.Player1Character,
-			"position":  mpGame.Player1Position,
-			"score":     mpGame.Player1Score,
-		},
-		"player2": map[string]interface{}{
-			"id":        mpGame.Player2ID,
-			"username":  mpGame.Player2Username,
-			"character": mpGame.Player2Character,
-			"position":  mpGame.Player2Position,
-			"score":     mpGame.Player2Score,
-		},
 		"pearl_position": mpGame.GameState.GetPearlPosition(),
 		"is_completed":   mpGame.IsCompleted,
 		"winner":         mpGame.Winner,
 		"current_player": playerID,
-	}, nil
+		"countdown_active": mpGame.CountdownActive,
+		"paused":           mpGame.Paused,
+	}
+	if player := mpGame.Player(playerID); player != nil {
+		response["move_seq"] = player.MoveSeq
+	}
+	
+	// Teammates follow each other's cursors to split up the map
+	if teammates := mpGame.Teammates(playerID); len(teammates) > 0 {
+		teammateIDs := make([]uint, len(teammates))
+		for i, teammate := range teammates {
+			teammateIDs[i] = teammate.ID
+		}
+		response["teammate_ids"] = teammateIDs
+	}
+	addPlayersData(response, mpGame)
+	addGameRulesData(response, mpGame)
+	
+	return response
 }
 
 // GetGameByMatchID returns the game state using match ID
 func (mgs *MultiplayerGameService) GetGameByMatchID(matchID string, playerID uint) (map[string]interface{}, error) {
-	mgs.gamesMutex.RLock()
-	gameID, exists := mgs.matchToGame[matchID]
-	mgs.gamesMutex.RUnlock()
+	gameID, exists := mgs.gameForMatch(matchID)
 	
 	utils.Debug("Looking for match ID %s, found: %v, game ID: %s", matchID, exists, gameID)
-	utils.Debug("Current match mappings: %v", mgs.matchToGame)
 	
 	if !exists {
 		return map[string]interface{}{
@@ -691,7 +686,7 @@ func (mgs *MultiplayerGameService) GetGameByMatchID(matchID string, playerID uin
 	return mgs.GetGameState(gameID, playerID)
 }
 
-// sendGameStartNotifications sends game start notifications to both players
+// sendGameStartNotifications sends game start notifications to every player
 func (mgs *MultiplayerGameService) sendGameStartNotifications(mpGame *MultiplayerGame) {
 	message := MultiplayerGameMessage{
 		Type:      "game_start",
@@ -699,14 +694,14 @@ func (mgs *MultiplayerGameService) sendGameStartNotifications(mpGame *Multiplaye
 		Timestamp: time.Now(),
 	}
 	
-	mgs.wsManager.SendMessage(mpGame.Player1ID, message)
-	mgs.wsManager.SendMessage(mpGame.Player2ID, message)
+	mgs.wsManager.BroadcastToPlayers(mpGame.PlayerIDs(), message)
 }
 
 // startCountdown starts the 3-2-1 countdown sequence
 func (mgs *MultiplayerGameService) startCountdown(mpGame *MultiplayerGame) {
+	playerIDs := mpGame.PlayerIDs()
 	utils.Debug("🎯 Starting countdown for game %s", mpGame.ID)
-	utils.Debug("🎯 Sending countdown to players %d and %d", mpGame.Player1ID, mpGame.Player2ID)
+	utils.Debug("🎯 Sending countdown to players %v", playerIDs)
 	
 	// Send initial countdown message
 	for countdown := 3; countdown > 0; countdown-- {
@@ -723,19 +718,8 @@ func (mgs *MultiplayerGameService) startCountdown(mpGame *MultiplayerGame) {
 			Timestamp: time.Now(),
 		}
 		
-		utils.Debug("🔥 Sending countdown message %d to player %d", countdown, mpGame.Player1ID)
-		err1 := mgs.wsManager.SendMessage(mpGame.Player1ID, message)
-		if err1 != nil {
-			utils.Error("❌ Failed to send countdown to player1 %d: %v", mpGame.Player1ID, err1)
-		}
-		
-		utils.Debug("🔥 Sending countdown message %d to player %d", countdown, mpGame.Player2ID)
-		err2 := mgs.wsManager.SendMessage(mpGame.Player2ID, message)
-		if err2 != nil {
-			utils.Error("❌ Failed to send countdown to player2 %d: %v", mpGame.Player2ID, err2)
-		}
-		
-		utils.Debug("✅ Sent countdown %d for game %s (errors: p1=%v, p2=%v)", countdown, mpGame.ID, err1, err2)
+		mgs.wsManager.BroadcastToPlayers(playerIDs, message)
+		utils.Debug("✅ Sent countdown %d for game %s to %d players", countdown, mpGame.ID, len(playerIDs))
 		
 		// Wait 1 second before next countdown
 		time.Sleep(1 * time.Second)
@@ -757,10 +741,12 @@ func (mgs *MultiplayerGameService) startCountdown(mpGame *MultiplayerGame) {
 	}
 	
 	utils.Debug("🏁 Sending GO message to players")
-	err1 := mgs.wsManager.SendMessage(mpGame.Player1ID, message)
-	err2 := mgs.wsManager.SendMessage(mpGame.Player2ID, message)
+	mgs.wsManager.BroadcastToPlayers(playerIDs, message)
+	mgs.startTimeLimit(mpGame)
+	mgs.startRuleTicks(mpGame)
+	mgs.saveSnapshot(mpGame)
 	
-	utils.Debug("🏁 Countdown finished for game %s - game is now active (errors: p1=%v, p2=%v)", mpGame.ID, err1, err2)
+	utils.Debug("🏁 Countdown finished for game %s - game is now active", mpGame.ID)
 }
 
 // TriggerCountdownForGame triggers countdown for a specific game (called when players connect)
@@ -793,89 +779,114 @@ func (mgs *MultiplayerGameService) TriggerCountdownForGame(gameID string) {
 	}
 }
 
-// sendGameUpdate sends game state updates to both players
-func (mgs *MultiplayerGameService) sendGameUpdate(mpGame *MultiplayerGame) {
-	// Take a read lock to safely access game data
-	mpGame.mutex.RLock()
+// RequestCountdown starts the countdown of a game once every player is connected, wherever they
+// are connected. Players ask for it when their game WebSocket opens.
+func (mgs *MultiplayerGameService) RequestCountdown(gameID string) {
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		if err := mgs.node.Send(owner, clusterMethodCountdown, gameCall{GameID: gameID}); err != nil {
+			utils.Error("Failed to request the countdown of game %s: %v", gameID, err)
+		}
+		return
+	}
+	if mpGame == nil {
+		return
+	}
 	
+	if mgs.AreAllPlayersConnected(gameID) {
+		utils.Info("All players connected, starting countdown for game %s", gameID)
+		go mgs.TriggerCountdownForGame(gameID)
+	} else {
+		utils.Debug("Waiting for all players to connect to game %s", gameID)
+	}
+}
+
+// sendGameUpdate sends game state updates to every player still in the game
+func (mgs *MultiplayerGameService) sendGameUpdate(mpGame *MultiplayerGame) {
 	// Get reusable data from pool
 	gameStateData := mgs.gameStatePool.Get().(*GameUpdateData)
 	defer func() {
 		// Reset and return to pool
 		*gameStateData = GameUpdateData{}
 		mgs.gameStatePool.Put(gameStateData)
-		mpGame.mutex.RUnlock()
 	}()
 	
-	// Populate game state data efficiently
-	*gameStateData = mgs.getGameStateData(mpGame)
-	
-	message := MultiplayerGameMessage{
-		Type:      "game_update",
-		Data:      *gameStateData,
-		Timestamp: time.Now(),
-	}
+	// Encode once and fan out to every connection, as a delta to players following versions
+	mgs.sendStateUpdate(mpGame, gameStateData)
 	
 	// Update cache if available
 	if mgs.cache != nil && mgs.cache.IsAvailable() {
 		cacheKey := cache.GetActiveGameKey(mpGame.ID)
+		cachedData := *gameStateData
 		// Non-blocking cache update
 		go func() {
-			if err := mgs.cache.Set(cacheKey, *gameStateData, 10*time.Minute); err != nil {
+			if err := mgs.cache.Set(cacheKey, cachedData, 10*time.Minute); err != nil {
 				utils.Error("Failed to update game cache: %v", err)
 			}
 		}()
 	}
 	
-	// Batch message sending to reduce WebSocket overhead
-	players := []uint{mpGame.Player1ID, mpGame.Player2ID}
-	
-	// Send messages concurrently but with controlled concurrency
-	var wg sync.WaitGroup
-	for _, playerID := range players {
-		wg.Add(1)
-		go func(pID uint) {
-			defer wg.Done()
-			if err := mgs.wsManager.SendMessage(pID, message); err != nil {
-				utils.Error("Failed to send game update to player %d: %v", pID, err)
-			}
-		}(playerID)
-	}
-	wg.Wait()
+	mgs.saveSnapshotThrottled(mpGame)
 }
 
-// getGameStateData returns the current game state data
+// getGameStateData returns the current game state data. The caller must hold the game lock.
 func (mgs *MultiplayerGameService) getGameStateData(mpGame *MultiplayerGame) GameUpdateData {
+	players := make([]PlayerUpdateData, len(mpGame.Players))
+	for i, player := range mpGame.Players {
+		players[i] = PlayerUpdateData{
+			ID:       player.ID,
+			Position: player.Position,
+			Score:    player.Score,
+			Team:     player.Team,
+			Left:     player.Left,
+			Disconnected: player.Disconnected,
+			Eliminated:   player.Eliminated,
+		}
+	}
+	
+	gameState := "active"
+	if mpGame.Paused {
+		gameState = "paused"
+	}
+	
+	pearlPosition := mpGame.GameState.GetPearlPosition()
 	return GameUpdateData{
-		Player1Position: mpGame.Player1Position,
-		Player1Score:    mpGame.Player1Score,
-		Player2Position: mpGame.Player2Position,
-		Player2Score:    mpGame.Player2Score,
-		PearlPosition:   Position{Row: mpGame.GameState.GetPearlPosition().Row, Col: mpGame.GameState.GetPearlPosition().Col},
-		GameState:       "active",
+		Player1Position: players[0].Position,
+		Player1Score:    players[0].Score,
+		Player2Position: players[1].Position,
+		Player2Score:    players[1].Score,
+		Players:         players,
+		Teams:           mpGame.teamsData(),
+		PearlPosition:   Position{Row: pearlPosition.Row, Col: pearlPosition.Col},
+		GameState:       gameState,
+		SpectatorCount:  mpGame.SpectatorCount(),
+		RuleSet:         mpGame.rules().Name(),
+		Rules:           mpGame.ruleStateData(),
 	}
 }
 
 // handleGameCompletion handles when a game is completed
-func (mgs *MultiplayerGameService) handleGameCompletion(mpGame *MultiplayerGame) {
-	// Take a read lock to safely access game data
-	mpGame.mutex.RLock()
+func (mgs *MultiplayerGameService) handleGameCompletion(mpGame *MultiplayerGame, completionType string) {
+	// Take the lock to safely access game data and score the game toward its series
+	mpGame.mutex.Lock()
 	gameID := mpGame.ID
-	player1ID := mpGame.Player1ID
-	player1Username := mpGame.Player1Username
-	player1Character := mpGame.Player1Character
-	player2ID := mpGame.Player2ID
-	player2Username := mpGame.Player2Username
-	player2Character := mpGame.Player2Character
+	mode := mpGame.Mode
+	settings := mpGame.Settings
+	players := mpGame.snapshotPlayers()
+	playerIDs := mpGame.ActivePlayerIDs()
 	winner := mpGame.Winner
+	winningTeam := mpGame.WinningTeam
 	winnerScore := mgs.getWinnerScore(mpGame)
-	player1Score := mpGame.Player1Score
-	player2Score := mpGame.Player2Score
+	teams := mpGame.teamsData()
 	createdAt := mpGame.CreatedAt
 	mapID := mpGame.MapID
-	mpGame.mutex.RUnlock()
+	completedAt := time.Now()
+	mpGame.CompletedAt = &completedAt
+	series := mpGame.scoreSeries(winner, false)
+	mpGame.mutex.Unlock()
 	
 	duration := time.Since(createdAt)
+	placements := rankParticipants(mode, players, winner, winningTeam)
 	
 	// Send completion notification
 	message := MultiplayerGameMessage{
@@ -883,19 +894,25 @@ func (mgs *MultiplayerGameService) handleGameCompletion(mpGame *MultiplayerGame)
 		Data: map[string]interface{}{
 			"winner":        winner,
 			"winner_score":  winnerScore,
-			"player1_score": player1Score,
-			"player2_score": player2Score,
+			"player1_score": players[0].Score,
+			"player2_score": players[1].Score,
+			"placements":    placements,
+			"winning_team":  winningTeam,
+			"teams":         teams,
+			"completion":    completionType,
+			"ranked":        settings.Ranked,
 			"duration":      duration,
+			"series":        series,
 		},
 		Timestamp: time.Now(),
 	}
 	
-	mgs.wsManager.SendMessage(player1ID, message)
-	mgs.wsManager.SendMessage(player2ID, message)
+	mgs.wsManager.BroadcastToPlayers(playerIDs, message)
+	mgs.broadcastToSpectators(mpGame, message)
+	mgs.saveSnapshot(mpGame)
 	
 	// Record game result in leaderboard
-	mgs.recordGameResult(gameID, player1ID, player1Username, player1Character, player1Score,
-		player2ID, player2Username, player2Character, player2Score, winner, uint(mapID), duration, "normal")
+	mgs.recordGameResult(gameID, mode, settings, placements, winner, winningTeam, uint(mapID), duration, completionType)
 	
 	// Update database
 	mgs.db.Model(&models.GameSession{}).
@@ -905,58 +922,131 @@ func (mgs *MultiplayerGameService) handleGameCompletion(mpGame *MultiplayerGame)
 			"is_active":    false,
 		})
 	
+	mgs.continueSeries(mpGame, series)
+	
 	// Schedule cleanup
 	time.AfterFunc(5*time.Minute, func() {
 		mgs.cleanupGame(gameID)
 	})
 }
 
-// getWinnerScore returns the winner's score
+// getWinnerScore returns the winner's score, or the winning team's score in a team game
 func (mgs *MultiplayerGameService) getWinnerScore(mpGame *MultiplayerGame) int {
+	if mpGame.WinningTeam != 0 {
+		return mpGame.TeamScore(mpGame.WinningTeam)
+	}
 	if mpGame.Winner == nil {
 		return 0
 	}
 	
-	if *mpGame.Winner == mpGame.Player1ID {
-		return mpGame.Player1Score
+	if winner := mpGame.Player(*mpGame.Winner); winner != nil {
+		return winner.Score
 	}
-	return mpGame.Player2Score
+	return 0
 }
 
-// recordGameResult records a multiplayer game result in the database
-func (mgs *MultiplayerGameService) recordGameResult(gameSessionID string, player1ID uint, player1Username, player1Character string, player1Score int, player2ID uint, player2Username, player2Character string, player2Score int, winnerID *uint, mapID uint, duration time.Duration, completionType string) {
+// recordGameResult records a multiplayer game result with every participant's placement and the map
+// veto in the database. Unranked results are kept for history but don't change player stats.
+func (mgs *MultiplayerGameService) recordGameResult(gameSessionID string, mode string, settings MultiplayerGameSettings, placements []PlayerPlacement, winnerID *uint, winningTeam int, mapID uint, duration time.Duration, completionType string) {
+	// Practice games against bots are kept out of the results
+	if settings.Practice {
+		utils.Debug("Not recording practice game %s", gameSessionID)
+		return
+	}
+	
+	// Screen the players' play; a ranked result with flagged play is held out of stats until an
+	// admin reviews it
+	reviews := mgs.screenGame(gameSessionID)
+	held := len(reviews) > 0 && settings.Ranked && mgs.cfg.HoldFlaggedResults
+	
 	// Create leaderboard service
 	leaderboardService := NewMultiplayerLeaderboardService(mgs.db)
 	
-	// Get character levels from database
-	player1CharacterLevel := mgs.getCharacterLevel(player1ID, player1Character)
-	player2CharacterLevel := mgs.getCharacterLevel(player2ID, player2Character)
+	// Get character levels from database and index participants by seat
+	participants := make([]models.MultiplayerGameParticipant, len(placements))
+	bySeat := make(map[int]*models.MultiplayerGameParticipant, len(placements))
+	for i, placement := range placements {
+		participants[i] = models.MultiplayerGameParticipant{
+			PlayerID:       placement.PlayerID,
+			Username:       placement.Username,
+			Character:      placement.Character,
+			CharacterLevel: mgs.getCharacterLevel(placement.PlayerID, placement.Character),
+			Seat:           placement.Seat,
+			FinalScore:     placement.Score,
+			Placement:      placement.Placement,
+			LeftGame:       placement.Left,
+			Team:           placement.Team,
+			TeamScore:      placement.TeamScore,
+		}
+		bySeat[placement.Seat] = &participants[i]
+	}
+	
+	mapVeto := make([]models.MultiplayerMapVetoAction, len(settings.MapVeto))
+	for i, action := range settings.MapVeto {
+		mapVeto[i] = models.MultiplayerMapVetoAction{
+			Step:     action.Step,
+			PlayerID: action.PlayerID,
+			Username: action.Username,
+			Action:   action.Action,
+			MapID:    action.MapID,
+			MapName:  action.MapName,
+			Auto:     action.Auto,
+		}
+	}
 	
-	// Create game result record
+	ruleSet := settings.RuleSet
+	if ruleSet == "" {
+		ruleSet = DefaultRuleSet
+	}
+	
+	player1, player2 := bySeat[0], bySeat[1]
+	if player1 == nil || player2 == nil {
+		utils.Error("Cannot record multiplayer game %s without its first two seats", gameSessionID)
+		return
+	}
+	
+	// Create game result record; the player columns hold the first two seats
 	gameResult := &models.MultiplayerGameResult{
 		GameSessionID:     gameSessionID,
 		MatchID:           gameSessionID, // Use game session ID as match ID for now
-		Player1ID:         player1ID,
-		Player1Username:   player1Username,
-		Player1Character:  player1Character,
-		Player1CharacterLevel: player1CharacterLevel,
-		Player1FinalScore: player1Score,
-		Player2ID:         player2ID,
-		Player2Username:   player2Username,
-		Player2Character:  player2Character,
-		Player2CharacterLevel: player2CharacterLevel,
-		Player2FinalScore: player2Score,
+		Player1ID:         player1.PlayerID,
+		Player1Username:   player1.Username,
+		Player1Character:  player1.Character,
+		Player1CharacterLevel: player1.CharacterLevel,
+		Player1FinalScore: player1.FinalScore,
+		Player2ID:         player2.PlayerID,
+		Player2Username:   player2.Username,
+		Player2Character:  player2.Character,
+		Player2CharacterLevel: player2.CharacterLevel,
+		Player2FinalScore: player2.FinalScore,
 		WinnerID:          winnerID,
+		WinningTeam:       winningTeam,
 		GameDuration:      int(duration.Seconds()),
 		CompletionType:    completionType,
 		MapID:             mapID,
+		Mode:              mode,
+		RuleSet:           ruleSet,
+		PlayerCount:       len(participants),
+		Unranked:          !settings.Ranked,
+		Participants:      participants,
+		MapVeto:           mapVeto,
+		SeriesID:          settings.SeriesID,
+		SeriesGame:        settings.SeriesGame,
+		HeldForReview:     held,
 	}
 	
 	// Record the result
 	if err := leaderboardService.RecordGameResult(gameResult); err != nil {
 		utils.Error("Error recording multiplayer game result: %v", err)
-	} else {
-		utils.Info("Recorded multiplayer game result for game %s", gameSessionID)
+		if len(reviews) > 0 {
+			mgs.saveMatchReviews(reviews, nil, false)
+		}
+		return
+	}
+	utils.Info("Recorded multiplayer game result for game %s", gameSessionID)
+	
+	if len(reviews) > 0 {
+		mgs.saveMatchReviews(reviews, &gameResult.ID, held)
 	}
 }
 
@@ -982,16 +1072,34 @@ func (mgs *MultiplayerGameService) cleanupGame(gameID string) {
 	defer mgs.gamesMutex.Unlock()
 	
 	if game, exists := mgs.activeGames[gameID]; exists {
+		// Remove the game from the cluster first so no other instance adopts it
+		mgs.unpublishGame(game)
 		delete(mgs.activeGames, gameID)
 		
-		// Also remove from match mapping
-		for matchID, gID := range mgs.matchToGame {
-			if gID == gameID {
-				delete(mgs.matchToGame, matchID)
-				break
-			}
+		// Stop the game clock of games cleaned up before their time ran out
+		game.mutex.Lock()
+		if game.timeLimitTimer != nil {
+			game.timeLimitTimer.Stop()
+		}
+		game.stopForfeitTimers()
+		if game.rematchOffer != nil {
+			game.rematchOffer.timer.Stop()
+			game.rematchOffer = nil
+		}
+		var abandoned *GameSeries
+		if !game.IsCompleted {
+			abandoned = game.abandonSeries()
+		}
+		game.mutex.Unlock()
+		
+		// A series whose game was never finished ends with it
+		if abandoned != nil {
+			go mgs.continueSeries(game, abandoned)
 		}
 		
+		// Spectators have nothing left to watch
+		mgs.closeSpectators(game)
+		
 		utils.Debug("Cleaned up multiplayer game %s", gameID)
 		
 		// Send disconnect notifications if game was not completed
@@ -1002,77 +1110,146 @@ func (mgs *MultiplayerGameService) cleanupGame(gameID string) {
 				Timestamp: time.Now(),
 			}
 			
-			mgs.wsManager.SendMessage(game.Player1ID, message)
-			mgs.wsManager.SendMessage(game.Player2ID, message)
+			mgs.wsManager.BroadcastToPlayers(game.PlayerIDs(), message)
 		}
 	}
 }
 
-// HandlePlayerDisconnect handles when a player disconnects
+// HandlePlayerDisconnect handles when a player leaves their game on purpose. The player forfeits
+// right away; a dropped connection goes through HandleConnectionLost instead.
 func (mgs *MultiplayerGameService) HandlePlayerDisconnect(playerID uint) {
-	mgs.gamesMutex.RLock()
-	var gameToCleanup *MultiplayerGame
+	gameID, exists := mgs.gameForPlayer(playerID)
+	if !exists {
+		return
+	}
 	
-	for _, game := range mgs.activeGames {
-		if (game.Player1ID == playerID || game.Player2ID == playerID) && !game.IsCompleted {
-			gameToCleanup = game
-			break
+	gameToUpdate, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		if err := mgs.node.Call(owner, clusterMethodDisconnect, gameCall{GameID: gameID, PlayerID: playerID}, nil); err != nil {
+			utils.Error("Failed to disconnect player %d from game %s: %v", playerID, gameID, err)
 		}
+		return
 	}
-	mgs.gamesMutex.RUnlock()
 	
-	if gameToCleanup != nil {
-		gameToCleanup.mutex.Lock()
-		gameToCleanup.IsCompleted = true
-		
-		// Record the disconnection result - other player wins by default
-		gameID := gameToCleanup.ID
-		player1ID := gameToCleanup.Player1ID
-		player1Username := gameToCleanup.Player1Username
-		player1Character := gameToCleanup.Player1Character
-		player1Score := gameToCleanup.Player1Score
-		player2ID := gameToCleanup.Player2ID
-		player2Username := gameToCleanup.Player2Username
-		player2Character := gameToCleanup.Player2Character
-		player2Score := gameToCleanup.Player2Score
-		createdAt := gameToCleanup.CreatedAt
-		mapID := gameToCleanup.MapID
-		
-		// Determine winner (the player who didn't disconnect)
-		var winnerID *uint
-		if playerID == player1ID {
-			winnerID = &player2ID
-		} else {
-			winnerID = &player1ID
-		}
-		
-		gameToCleanup.Winner = winnerID
-		gameToCleanup.mutex.Unlock()
-		
-		// Record game result
-		duration := time.Since(createdAt)
-		mgs.recordGameResult(gameID, player1ID, player1Username, player1Character, player1Score,
-			player2ID, player2Username, player2Character, player2Score, winnerID, uint(mapID), duration, "disconnection")
-		
-		// Notify the other player
-		otherPlayerID := gameToCleanup.Player1ID
-		if playerID == gameToCleanup.Player1ID {
-			otherPlayerID = gameToCleanup.Player2ID
-		}
+	if gameToUpdate == nil {
+		return
+	}
+	
+	gameToUpdate.mutex.RLock()
+	player := gameToUpdate.Player(playerID)
+	inGame := player != nil && !player.Left && !gameToUpdate.IsCompleted
+	gameToUpdate.mutex.RUnlock()
+	
+	if inGame {
+		mgs.forfeitPlayer(gameToUpdate, playerID, false)
+	}
+}
+
+// forfeitPlayer makes a player leave their game. Once fewer than two players remain the game ends
+// and the remaining player wins by default; a team game ends once a single team remains, which
+// wins by default. With onlyIfDisconnected, a player who reconnected in the meantime stays in the
+// game.
+func (mgs *MultiplayerGameService) forfeitPlayer(gameToUpdate *MultiplayerGame, playerID uint, onlyIfDisconnected bool) {
+	gameToUpdate.mutex.Lock()
+	leaving := gameToUpdate.Player(playerID)
+	if gameToUpdate.IsCompleted || leaving == nil || leaving.Left || (onlyIfDisconnected && !leaving.Disconnected) {
+		gameToUpdate.mutex.Unlock()
+		return
+	}
+	
+	if leaving.forfeitTimer != nil {
+		leaving.forfeitTimer.Stop()
+		leaving.forfeitTimer = nil
+	}
+	leaving.Left = true
+	leaving.Disconnected = false
+	leaving.ReconnectDeadline = nil
+	remainingIDs := gameToUpdate.ActivePlayerIDs()
+	remainingTeams := gameToUpdate.ActiveTeams()
+	
+	// A free-for-all continues while at least two players remain, and a team game while both
+	// teams have a player left
+	continues := len(remainingIDs) > 1
+	if gameToUpdate.IsTeamGame() {
+		continues = len(remainingTeams) > 1
+	}
+	if continues {
+		gameToUpdate.resume()
+		gameToUpdate.mutex.Unlock()
 		
 		message := MultiplayerGameMessage{
-			Type:      "player_disconnected",
-			Data:      map[string]string{"reason": "Opponent disconnected"},
+			Type:     "player_left",
+			PlayerID: playerID,
+			Data: map[string]interface{}{
+				"username":          leaving.Username,
+				"remaining_players": len(remainingIDs),
+			},
 			Timestamp: time.Now(),
 		}
 		
-		mgs.wsManager.SendMessage(otherPlayerID, message)
-		
-		// Schedule cleanup
-		time.AfterFunc(5*time.Minute, func() {
-			mgs.cleanupGame(gameToCleanup.ID)
-		})
+		mgs.wsManager.BroadcastToPlayers(remainingIDs, message)
+		mgs.broadcastToSpectators(gameToUpdate, message)
+		mgs.saveSnapshot(gameToUpdate)
+		utils.Info("Player %d left multiplayer game %s, %d players remain", playerID, gameToUpdate.ID, len(remainingIDs))
+		return
+	}
+	
+	gameToUpdate.IsCompleted = true
+	gameToUpdate.stopForfeitTimers()
+	
+	// Record the disconnection result - the remaining player wins by default
+	gameID := gameToUpdate.ID
+	mode := gameToUpdate.Mode
+	settings := gameToUpdate.Settings
+	createdAt := gameToUpdate.CreatedAt
+	mapID := gameToUpdate.MapID
+	
+	var winnerID *uint
+	winningTeam := 0
+	if gameToUpdate.IsTeamGame() {
+		if len(remainingTeams) == 1 {
+			winningTeam = remainingTeams[0]
+		}
+	} else if len(remainingIDs) == 1 {
+		winnerID = &remainingIDs[0]
+	}
+	
+	gameToUpdate.Winner = winnerID
+	gameToUpdate.WinningTeam = winningTeam
+	completedAt := time.Now()
+	gameToUpdate.CompletedAt = &completedAt
+	series := gameToUpdate.scoreSeries(winnerID, true)
+	players := gameToUpdate.snapshotPlayers()
+	gameToUpdate.mutex.Unlock()
+	
+	// Record game result
+	duration := time.Since(createdAt)
+	mgs.recordGameResult(gameID, mode, settings, rankParticipants(mode, players, winnerID, winningTeam), winnerID, winningTeam, uint(mapID), duration, "disconnection")
+	
+	// Notify the remaining players
+	reason := "Opponent disconnected"
+	switch mode {
+	case matchmaking.GameModeTeams:
+		reason = "The other team disconnected"
+	case matchmaking.GameModeFreeForAll:
+		reason = "All other players disconnected"
 	}
+	
+	message := MultiplayerGameMessage{
+		Type:      "player_disconnected",
+		Data:      map[string]string{"reason": reason},
+		Timestamp: time.Now(),
+	}
+	
+	mgs.wsManager.BroadcastToPlayers(remainingIDs, message)
+	mgs.broadcastToSpectators(gameToUpdate, message)
+	mgs.saveSnapshot(gameToUpdate)
+	mgs.continueSeries(gameToUpdate, series)
+	
+	// Schedule cleanup
+	time.AfterFunc(5*time.Minute, func() {
+		mgs.cleanupGame(gameID)
+	})
 }
 
 // periodicGameStateBroadcast sends game state updates at regular intervals for real-time feel
@@ -1136,17 +1313,10 @@ func (mgs *MultiplayerGameService) cleanupExpiredGames() {
 			for gameID, game := range mgs.activeGames {
 				// Clean up games inactive for more than 8 minutes (user requirement)
 				if now.Sub(game.LastActivity) > 8*time.Minute {
+					mgs.unpublishGame(game)
 					delete(mgs.activeGames, gameID)
 					utils.Debug("Cleaned up expired multiplayer game %s after 8 minutes of inactivity", gameID)
 					
-					// Remove from match mapping
-					for matchID, gID := range mgs.matchToGame {
-						if gID == gameID {
-							delete(mgs.matchToGame, matchID)
-							break
-						}
-					}
-					
 					// Notify players
 					message := MultiplayerGameMessage{
 						Type:      "game_expired",
@@ -1154,8 +1324,7 @@ func (mgs *MultiplayerGameService) cleanupExpiredGames() {
 						Timestamp: time.Now(),
 					}
 					
-					mgs.wsManager.SendMessage(game.Player1ID, message)
-					mgs.wsManager.SendMessage(game.Player2ID, message)
+					mgs.wsManager.BroadcastToPlayers(game.PlayerIDs(), message)
 				}
 			}
 			
@@ -1172,10 +1341,15 @@ func (mgs *MultiplayerGameService) RegisterWebSocketConnection(playerID uint, co
 	}
 }
 
-// UnregisterWebSocketConnection removes a WebSocket connection
-func (mgs *MultiplayerGameService) UnregisterWebSocketConnection(playerID uint) {
-	mgs.wsManager.RemoveConnection(playerID)
+// UnregisterWebSocketConnection removes a WebSocket connection unless the player already replaced it
+// with a newer one. It reports whether the connection was the player's current one.
+func (mgs *MultiplayerGameService) UnregisterWebSocketConnection(playerID uint, conn interface{}) bool {
+	wsConn, ok := conn.(*websocket.Conn)
+	if !ok || !mgs.wsManager.RemoveConnectionIfCurrent(playerID, wsConn) {
+		return false
+	}
 	utils.Debug("🔌 Unregistered WebSocket connection for player %d", playerID)
+	return true
 }
 
 // GetGameByID returns a game by its ID
@@ -1186,8 +1360,8 @@ func (mgs *MultiplayerGameService) GetGameByID(gameID string) *MultiplayerGame {
 	return mgs.activeGames[gameID]
 }
 
-// AreBothPlayersConnected checks if both players of a game are connected via WebSocket
-func (mgs *MultiplayerGameService) AreBothPlayersConnected(gameID string) bool {
+// AreAllPlayersConnected checks if every player of a game is connected via WebSocket
+func (mgs *MultiplayerGameService) AreAllPlayersConnected(gameID string) bool {
 	mgs.gamesMutex.RLock()
 	game, exists := mgs.activeGames[gameID]
 	mgs.gamesMutex.RUnlock()
@@ -1196,14 +1370,17 @@ func (mgs *MultiplayerGameService) AreBothPlayersConnected(gameID string) bool {
 		return false
 	}
 	
-	// Check if both players are connected to the WebSocket manager
-	player1Connected := mgs.wsManager.IsPlayerConnected(game.Player1ID)
-	player2Connected := mgs.wsManager.IsPlayerConnected(game.Player2ID)
+	// Check if every player is connected to the WebSocket manager; bots are always there
+	connected := 0
+	for _, playerID := range game.PlayerIDs() {
+		if IsBotPlayer(playerID) || mgs.wsManager.IsPlayerConnected(playerID) {
+			connected++
+		}
+	}
 	
-	utils.Debug("🔍 Connection status for game %s: P1(%d)=%v, P2(%d)=%v", 
-		gameID, game.Player1ID, player1Connected, game.Player2ID, player2Connected)
+	utils.Debug("🔍 Connection status for game %s: %d/%d players connected", gameID, connected, len(game.Players))
 	
-	return player1Connected && player2Connected
+	return connected == len(game.Players)
 }
 
 // StartCountdownDirectly starts countdown and sends messages directly through the WebSocket manager
@@ -1228,6 +1405,7 @@ func (mgs *MultiplayerGameService) StartCountdownDirectly(gameID string) {
 	game.mutex.Unlock()
 	
 	utils.Debug("🎯 Starting direct countdown for game %s", gameID)
+	playerIDs := game.PlayerIDs()
 	
 	// Send 3-2-1 countdown
 	for countdown := 3; countdown > 0; countdown-- {
@@ -1241,17 +1419,9 @@ func (mgs *MultiplayerGameService) StartCountdownDirectly(gameID string) {
 		}
 		
 		utils.Debug("🔥 Sending countdown message %d to players in game %s", countdown, gameID)
-		err1 := mgs.wsManager.SendMessage(game.Player1ID, message)
-		err2 := mgs.wsManager.SendMessage(game.Player2ID, message)
-		
-		if err1 != nil {
-			utils.Error("❌ Failed to send countdown to player1 %d: %v", game.Player1ID, err1)
-		}
-		if err2 != nil {
-			utils.Error("❌ Failed to send countdown to player2 %d: %v", game.Player2ID, err2)
-		}
+		mgs.wsManager.BroadcastToPlayers(playerIDs, message)
 		
-		utils.Debug("✅ Sent countdown %d for game %s (errors: p1=%v, p2=%v)", countdown, gameID, err1, err2)
+		utils.Debug("✅ Sent countdown %d for game %s to %d players", countdown, gameID, len(playerIDs))
 		time.Sleep(1 * time.Second)
 	}
 	
@@ -1266,17 +1436,9 @@ func (mgs *MultiplayerGameService) StartCountdownDirectly(gameID string) {
 	}
 	
 	utils.Debug("🏁 Sending GO message to players in game %s", gameID)
-	err1 := mgs.wsManager.SendMessage(game.Player1ID, message)
-	err2 := mgs.wsManager.SendMessage(game.Player2ID, message)
+	mgs.wsManager.BroadcastToPlayers(playerIDs, message)
 	
-	if err1 != nil {
-		utils.Error("❌ Failed to send GO to player1 %d: %v", game.Player1ID, err1)
-	}
-	if err2 != nil {
-		utils.Error("❌ Failed to send GO to player2 %d: %v", game.Player2ID, err2)
-	}
-	
-	utils.Debug("🏁 Countdown finished for game %s - game is now active (errors: p1=%v, p2=%v)", gameID, err1, err2)
+	utils.Debug("🏁 Countdown finished for game %s - game is now active", gameID)
 }
 
 // SetCountdownState sets the countdown state for a game
@@ -1301,6 +1463,12 @@ func (mgs *MultiplayerGameService) SetCountdownState(gameID string, started, act
 	game.CountdownActive = active
 	
 	utils.Debug("🔄 Updated countdown state for game %s: started=%v, active=%v", gameID, started, active)
+	
+	// The game clock starts when the countdown finishes
+	if started && !active {
+		go mgs.startTimeLimit(game)
+		go mgs.startRuleTicks(game)
+	}
 	return true
 }
 
@@ -1333,58 +1501,41 @@ func (mgs *MultiplayerGameService) GetActiveGameCount() int {
 func (mgs *MultiplayerGameService) Cleanup() {
 	mgs.cancel()
 	
+	// The other instances adopt the games still running here once this one is gone
+	mgs.handOffGames()
+	
 	mgs.gamesMutex.Lock()
 	defer mgs.gamesMutex.Unlock()
 	
 	// Clean up all active games
-	for gameID := range mgs.activeGames {
+	for gameID, game := range mgs.activeGames {
+		game.mutex.Lock()
+		if game.timeLimitTimer != nil {
+			game.timeLimitTimer.Stop()
+		}
+		game.stopForfeitTimers()
+		game.mutex.Unlock()
+		
+		mgs.closeSpectators(game)
 		delete(mgs.activeGames, gameID)
 	}
 	
-	// Clean up match mappings
-	for matchID := range mgs.matchToGame {
-		delete(mgs.matchToGame, matchID)
-	}
-	
 	utils.Info("Multiplayer game service cleaned up")
 }
 
-// validateDirection validates and converts direction input (same as single-player)
-func (mgs *MultiplayerGameService) validateDirection(direction string) (string, error) {
-	// Check for empty direction string
-	if direction == "" {
-		return "", fmt.Errorf("empty direction string")
-	}
-	
-	// Check if this is a character search direction
-	if (len(direction) > 17 && direction[:17] == "find_char_forward") ||
-		(len(direction) > 18 && direction[:18] == "find_char_backward") ||
-		(len(direction) > 17 && direction[:17] == "till_char_forward") ||
-		(len(direction) > 18 && direction[:18] == "till_char_backward") {
-		// For character search, use the direction string directly
-		return direction, nil
-	}
-
-	// Strip number prefix if present (e.g., "5j" -> "j", "123B" -> "B")
-	baseDirection := direction
-	for i, char := range direction {
-		if char < '0' || char > '9' {
-			if i < len(direction) {
-				baseDirection = direction[i:]
-			}
-			break
-		}
-	}
-	
-	// Additional safety check for empty result
-	if baseDirection == "" {
-		return "", fmt.Errorf("empty direction after processing: %s", direction)
+// playerMovementOptions loads a player's saved vim options for a multiplayer game.
+// Multiplayer clients don't report a text width, so display-line motions follow logical lines.
+func (mgs *MultiplayerGameService) playerMovementOptions(playerID uint) game.MovementOptions {
+	vimOptions, err := NewVimOptionsService(mgs.db).GetPlayerOptions(playerID)
+	if err != nil {
+		utils.Error("Failed to load vim options for player %d: %v", playerID, err)
+		vimOptions = models.DefaultVimOptions()
 	}
+	return MovementOptions(vimOptions, false, 0)
+}
 
-	// For normal movement, look up in MovementKeys map
-	directionInfo, exists := game.MovementKeys[baseDirection]
-	if !exists {
-		return "", fmt.Errorf("invalid movement key: %s", baseDirection)
-	}
-	return directionInfo["direction"].(string), nil
+// validateDirection validates and converts direction input (same as single-player)
+func (mgs *MultiplayerGameService) validateDirection(direction string) (string, error) {
+	// Keys, count prefixes and character search directions are resolved by the motion registry
+	return game.ParseMovementKey(direction)
 }
\ No newline at end of file
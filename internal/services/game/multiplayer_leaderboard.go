@@ -3,11 +3,11 @@ package game
 import (
 	"errors"
 	"fmt"
-	"math"
 	"time"
 
 	"boba-vim/internal/cache"
 	"boba-vim/internal/models"
+	"boba-vim/internal/rating"
 	"boba-vim/internal/utils"
 	"gorm.io/gorm"
 )
@@ -33,73 +33,151 @@ type LeaderboardEntry struct {
 	SelectedCharacter string  `json:"selected_character"`
 	CharacterLevel   *int    `json:"character_level"`
 	TotalGamesPlayed int     `json:"total_games_played"`
+	Rating           float64 `json:"rating"`
+	RatingDeviation  float64 `json:"rating_deviation"`
 	TotalWins        int     `json:"total_wins"`
 	TotalLosses      int     `json:"total_losses"`
 	WinRate          float64 `json:"win_rate"`
 	AverageScore     float64 `json:"average_score"`
 	HighestScore     int     `json:"highest_score"`
+	AveragePlacement float64 `json:"average_placement"`
+	TeamGamesPlayed  int     `json:"team_games_played"`
+	TeamWinRate      float64 `json:"team_win_rate"`
 	LeaderboardScore float64 `json:"leaderboard_score"`
 	Rank             int     `json:"rank"`
 	IsConfirmed      bool    `json:"is_confirmed"`
 }
 
-// RecordGameResult records a multiplayer game result and updates player stats
+// RecordGameResult records a multiplayer game result and updates the stats of every participant
 func (mls *MultiplayerLeaderboardService) RecordGameResult(gameResult *models.MultiplayerGameResult) error {
 	return mls.db.Transaction(func(tx *gorm.DB) error {
-		// Save the game result
+		// Ranked games count toward the season being played
+		if !gameResult.Unranked {
+			var season models.RankedSeason
+			if err := tx.Where("status = ?", models.SeasonStatusActive).First(&season).Error; err == nil {
+				gameResult.SeasonID = &season.ID
+			}
+		}
+
+		// Save the game result along with its participants
 		if err := tx.Create(gameResult).Error; err != nil {
 			return fmt.Errorf("failed to save game result: %w", err)
 		}
 
-		// Update player 1 stats
-		if err := mls.updatePlayerStats(tx, gameResult.Player1ID, gameResult.Player1Username, gameResult.Player1Character, gameResult.Player1CharacterLevel, gameResult.Player1FinalScore, gameResult.WinnerID, gameResult.GameDuration); err != nil {
-			return fmt.Errorf("failed to update player 1 stats: %w", err)
+		// Unranked games are kept out of the stats, and flagged games until they are reviewed
+		if gameResult.Unranked || gameResult.HeldForReview {
+			return nil
 		}
 
-		// Update player 2 stats
-		if err := mls.updatePlayerStats(tx, gameResult.Player2ID, gameResult.Player2Username, gameResult.Player2Character, gameResult.Player2CharacterLevel, gameResult.Player2FinalScore, gameResult.WinnerID, gameResult.GameDuration); err != nil {
-			return fmt.Errorf("failed to update player 2 stats: %w", err)
+		return mls.applyGameResult(tx, gameResult)
+	})
+}
+
+// ReleaseGameResult counts a result held for review toward the stats of its participants, once
+// every review of its game cleared the players
+func (mls *MultiplayerLeaderboardService) ReleaseGameResult(resultID uint) error {
+	return mls.db.Transaction(func(tx *gorm.DB) error {
+		released := tx.Model(&models.MultiplayerGameResult{}).
+			Where("id = ? AND held_for_review = ?", resultID, true).
+			Update("held_for_review", false)
+		if released.Error != nil {
+			return released.Error
+		}
+		if released.RowsAffected == 0 {
+			return nil // Already released
 		}
 
-		return nil
+		var gameResult models.MultiplayerGameResult
+		if err := tx.Preload("Participants").First(&gameResult, resultID).Error; err != nil {
+			return err
+		}
+		if gameResult.Unranked {
+			return nil
+		}
+		return mls.applyGameResult(tx, &gameResult)
 	})
 }
 
-// updatePlayerStats updates or creates player statistics
-func (mls *MultiplayerLeaderboardService) updatePlayerStats(tx *gorm.DB, playerID uint, username string, character string, characterLevel *int, finalScore int, winnerID *uint, gameDuration int) error {
+// applyGameResult updates the stats and ratings of every participant of a ranked game
+func (mls *MultiplayerLeaderboardService) applyGameResult(tx *gorm.DB, gameResult *models.MultiplayerGameResult) error {
+	// Load every participant's stats first so ratings are updated against pre-game ratings
+	playerStats := make([]*models.MultiplayerPlayerStats, len(gameResult.Participants))
+	for i, participant := range gameResult.Participants {
+		stats, err := mls.loadPlayerStats(tx, participant)
+		if err != nil {
+			return fmt.Errorf("failed to load stats for player %d: %w", participant.PlayerID, err)
+		}
+		playerStats[i] = stats
+	}
+
+	updateRatings(playerStats, gameResult.Participants, time.Now())
+
+	// Update every participant's stats
+	for i, participant := range gameResult.Participants {
+		if err := mls.updatePlayerStats(tx, playerStats[i], participant, gameResult.WinnerID, gameResult.WinningTeam, gameResult.GameDuration); err != nil {
+			return fmt.Errorf("failed to update stats for player %d: %w", participant.PlayerID, err)
+		}
+	}
+
+	return nil
+}
+
+// loadPlayerStats finds a participant's stats, or starts new stats with the default rating
+func (mls *MultiplayerLeaderboardService) loadPlayerStats(tx *gorm.DB, participant models.MultiplayerGameParticipant) (*models.MultiplayerPlayerStats, error) {
 	var stats models.MultiplayerPlayerStats
 	
-	// Find existing stats or create new
-	result := tx.Where("player_id = ?", playerID).First(&stats)
+	result := tx.Where("player_id = ?", participant.PlayerID).First(&stats)
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			// Create new stats record
-			stats = models.MultiplayerPlayerStats{
-				PlayerID: playerID,
-				Username: username,
-				SelectedCharacter: character,
-				CharacterLevel: characterLevel,
-			}
-		} else {
-			return result.Error
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, result.Error
 		}
-	} else {
-		// Update character information (player might have changed character)
-		stats.SelectedCharacter = character
-		stats.CharacterLevel = characterLevel
+		
+		// Create new stats record
+		stats = models.MultiplayerPlayerStats{
+			PlayerID: participant.PlayerID,
+			Username: participant.Username,
+		}
+		setStatsRating(&stats, rating.Default())
 	}
+	
+	return &stats, nil
+}
+
+// updatePlayerStats updates a participant's statistics with a game and saves them. In a team game
+// the players of the winning team win.
+func (mls *MultiplayerLeaderboardService) updatePlayerStats(tx *gorm.DB, stats *models.MultiplayerPlayerStats, participant models.MultiplayerGameParticipant, winnerID *uint, winningTeam int, gameDuration int) error {
+	playerID := participant.PlayerID
+	
+	// Update character information (player might have changed character)
+	stats.SelectedCharacter = participant.Character
+	stats.CharacterLevel = participant.CharacterLevel
 
 	// Update stats
 	stats.TotalGamesPlayed++
-	stats.TotalScore += finalScore
+	stats.SeasonGamesPlayed++
+	stats.TotalScore += participant.FinalScore
 	stats.TotalGameTimeSeconds += gameDuration
+	stats.PlacedGames++
+	stats.TotalPlacement += participant.Placement
 	
-	if finalScore > stats.HighestScore {
-		stats.HighestScore = finalScore
+	if participant.FinalScore > stats.HighestScore {
+		stats.HighestScore = participant.FinalScore
 	}
 
 	// Update win/loss/tie counts
-	if winnerID != nil {
+	if participant.Team != 0 {
+		stats.TeamGamesPlayed++
+		stats.TotalTeamScore += participant.TeamScore
+		switch {
+		case winningTeam == 0:
+			stats.TotalTies++
+		case winningTeam == participant.Team:
+			stats.TotalWins++
+			stats.TeamWins++
+		default:
+			stats.TotalLosses++
+		}
+	} else if winnerID != nil {
 		if *winnerID == playerID {
 			stats.TotalWins++
 		} else {
@@ -111,14 +189,16 @@ func (mls *MultiplayerLeaderboardService) updatePlayerStats(tx *gorm.DB, playerI
 
 	// Calculate derived statistics
 	stats.CalculateWinRate()
+	stats.CalculateTeamWinRate()
 	stats.CalculateAverageScore()
+	stats.CalculateAveragePlacement()
 	stats.CalculateAverageGameTime()
 	
 	now := time.Now()
 	stats.LastPlayedAt = &now
 
 	// Save or update
-	return tx.Save(&stats).Error
+	return tx.Save(stats).Error
 }
 
 // GetMultiplayerLeaderboard retrieves the multiplayer leaderboard with proper scoring
@@ -135,7 +215,7 @@ func (mls *MultiplayerLeaderboardService) GetMultiplayerLeaderboard(limit int, i
 
 	var stats []models.MultiplayerPlayerStats
 	
-	query := mls.db.Preload("Player").Order("total_games_played DESC, win_rate DESC, average_score DESC")
+	query := mls.db.Preload("Player").Order("conservative_rating DESC, total_games_played DESC")
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -158,16 +238,21 @@ func (mls *MultiplayerLeaderboardService) GetMultiplayerLeaderboard(limit int, i
 			SelectedCharacter: stat.SelectedCharacter,
 			CharacterLevel:   stat.CharacterLevel,
 			TotalGamesPlayed: stat.TotalGamesPlayed,
+			Rating:           stat.Rating,
+			RatingDeviation:  stat.RatingDeviation,
 			TotalWins:        stat.TotalWins,
 			TotalLosses:      stat.TotalLosses,
 			WinRate:          stat.WinRate,
 			AverageScore:     stat.AverageScore,
 			HighestScore:     stat.HighestScore,
+			AveragePlacement: stat.AveragePlacement,
+			TeamGamesPlayed:  stat.TeamGamesPlayed,
+			TeamWinRate:      stat.TeamWinRate,
 			IsConfirmed:      stat.Player.EmailConfirmed,
 		}
 
-		// Calculate composite leaderboard score
-		entry.LeaderboardScore = mls.calculateLeaderboardScore(stat)
+		// Players are ranked by conservative rating, so a few lucky games don't top the board
+		entry.LeaderboardScore = stat.ConservativeRating
 		
 		// Include based on confirmation status
 		if includeUnconfirmed || entry.IsConfirmed {
@@ -175,15 +260,6 @@ func (mls *MultiplayerLeaderboardService) GetMultiplayerLeaderboard(limit int, i
 		}
 	}
 
-	// Sort by composite score (descending)
-	for i := 0; i < len(entries)-1; i++ {
-		for j := i + 1; j < len(entries); j++ {
-			if entries[j].LeaderboardScore > entries[i].LeaderboardScore {
-				entries[i], entries[j] = entries[j], entries[i]
-			}
-		}
-	}
-
 	// Assign ranks
 	for i := range entries {
 		entries[i].Rank = i + 1
@@ -201,32 +277,6 @@ func (mls *MultiplayerLeaderboardService) GetMultiplayerLeaderboard(limit int, i
 	return entries, nil
 }
 
-// calculateLeaderboardScore calculates a composite score for leaderboard ranking
-// This balances win rate with game volume to prevent low-game-count players from dominating
-func (mls *MultiplayerLeaderboardService) calculateLeaderboardScore(stats models.MultiplayerPlayerStats) float64 {
-	if stats.TotalGamesPlayed == 0 {
-		return 0
-	}
-
-	// Base score components
-	winRate := stats.WinRate / 100.0 // Convert percentage to decimal
-	avgScore := stats.AverageScore / 1300.0 // Normalize to winning score
-	
-	// Game volume factor - reduces impact of low game counts
-	// Uses a logarithmic scale to prevent excessive punishment
-	minGames := 5.0
-	gameVolumeFactor := math.Min(1.0, math.Log(float64(stats.TotalGamesPlayed)+1)/math.Log(minGames+1))
-	
-	// Weighted composite score
-	// 60% win rate, 30% average score, 10% game volume
-	compositeScore := (winRate * 0.6) + (avgScore * 0.3) + (gameVolumeFactor * 0.1)
-	
-	// Apply game volume dampening to prevent low-game players from ranking too high
-	dampedScore := compositeScore * gameVolumeFactor
-	
-	return dampedScore
-}
-
 // GetPlayerMultiplayerRank gets a specific player's rank in the multiplayer leaderboard
 func (mls *MultiplayerLeaderboardService) GetPlayerMultiplayerRank(playerID uint) (int, *LeaderboardEntry, error) {
 	// Get full leaderboard (excluding unconfirmed to match displayed leaderboard)
@@ -263,8 +313,17 @@ func (mls *MultiplayerLeaderboardService) GetPlayerMultiplayerStats(playerID uin
 func (mls *MultiplayerLeaderboardService) GetRecentMultiplayerGames(playerID uint, limit int) ([]models.MultiplayerGameResult, error) {
 	var games []models.MultiplayerGameResult
 	
+	// Games recorded before participants existed only have the two player columns
+	participantGames := mls.db.Model(&models.MultiplayerGameParticipant{}).Select("game_result_id").Where("player_id = ?", playerID)
+	
 	query := mls.db.Preload("Player1").Preload("Player2").Preload("Winner").
-		Where("player1_id = ? OR player2_id = ?", playerID, playerID).
+		Preload("Participants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("placement ASC, seat ASC")
+		}).
+		Preload("MapVeto", func(db *gorm.DB) *gorm.DB {
+			return db.Order("step ASC")
+		}).
+		Where("player1_id = ? OR player2_id = ? OR id IN (?)", playerID, playerID, participantGames).
 		Order("completed_at DESC")
 	
 	if limit > 0 {
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"time"
+
+	"boba-vim/internal/utils"
+)
+
+// MaxWebSocketMoveCount caps the count prefix of moves sent over the game WebSocket
+const MaxWebSocketMoveCount = 1000
+
+// MoveAck acknowledges a move sent over the game WebSocket with the server's authoritative state.
+// Clients drop the predictions up to Seq and replay the ones after it from Position.
+type MoveAck struct {
+	Seq      uint64 `json:"seq"`
+	Accepted bool   `json:"accepted"`
+	// Duplicate marks a sequence number that was already processed (retransmit or out of order)
+	Duplicate bool `json:"duplicate,omitempty"`
+	// Conflict marks a client prediction that differs from the authoritative position
+	Conflict        bool     `json:"conflict"`
+	Error           string   `json:"error,omitempty"`
+	Position        Position `json:"position"`
+	PreferredColumn int      `json:"preferred_column"`
+	Score           int      `json:"score"`
+	MoveScore       int      `json:"move_score"`
+	PearlPosition   Position `json:"pearl_position"`
+	IsCompleted     bool     `json:"is_completed"`
+	Winner          *uint    `json:"winner"`
+	TeamScore       int      `json:"team_score,omitempty"` // The player's team's score in a team game
+	WinningTeam     int      `json:"winning_team,omitempty"`
+}
+
+// ProcessSequencedMove processes a move sent over the game WebSocket. Moves are applied in the
+// order the server receives them under the game lock, so when both players reach the pearl at the
+// same moment the first move processed collects it and the other player's ack reports the conflict.
+// predicted is the position the client expects after the move, or nil when it doesn't predict.
+func (mgs *MultiplayerGameService) ProcessSequencedMove(gameID string, playerID uint, seq uint64, direction string, count int, hasExplicitCount bool, predicted *Position) (*MoveAck, error) {
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		ack := &MoveAck{}
+		err := mgs.node.Call(owner, clusterMethodSequencedMove, gameCall{GameID: gameID, PlayerID: playerID, Seq: seq, Direction: direction, Count: count, HasExplicitCount: hasExplicitCount, Predicted: predicted}, ack)
+		return ack, err
+	}
+
+	ack := &MoveAck{Seq: seq}
+	if mpGame == nil {
+		ack.Error = "Game not found"
+		return ack, nil
+	}
+
+	// Check if countdown is active - block movement during countdown
+	if mgs.IsCountdownActive(gameID) {
+		ack.Error = "Movement blocked during countdown"
+		mgs.traceCountdownMove(mpGame, playerID)
+		mpGame.mutex.RLock()
+		mgs.fillMoveAck(mpGame, playerID, ack, predicted)
+		mpGame.mutex.RUnlock()
+		return ack, nil
+	}
+
+	mpGame.mutex.Lock()
+	defer mpGame.mutex.Unlock()
+
+	player := mpGame.Player(playerID)
+	if player == nil {
+		ack.Error = "Player not in this game"
+		return ack, nil
+	}
+
+	// Sequence numbers must increase; anything else was already handled
+	if seq <= player.MoveSeq {
+		utils.Debug("Ignoring move seq %d from player %d in game %s (last seq %d)", seq, playerID, gameID, player.MoveSeq)
+		ack.Duplicate = true
+		mgs.fillMoveAck(mpGame, playerID, ack, predicted)
+		return ack, nil
+	}
+	player.MoveSeq = seq
+
+	if count < 1 || count > MaxWebSocketMoveCount {
+		ack.Error = "Invalid count"
+		mgs.fillMoveAck(mpGame, playerID, ack, predicted)
+		return ack, nil
+	}
+
+	result := mgs.applyMove(mpGame, playerID, direction, count, hasExplicitCount)
+	if success, _ := result["success"].(bool); success {
+		ack.Accepted = true
+		ack.MoveScore, _ = result["move_score"].(int)
+	} else if errorMessage, ok := result["error"].(string); ok {
+		ack.Error = errorMessage
+	}
+
+	mgs.fillMoveAck(mpGame, playerID, ack, predicted)
+	return ack, nil
+}
+
+// fillMoveAck copies the player's authoritative state into the ack. The caller must hold the game lock.
+func (mgs *MultiplayerGameService) fillMoveAck(mpGame *MultiplayerGame, playerID uint, ack *MoveAck, predicted *Position) {
+	if player := mpGame.Player(playerID); player != nil {
+		ack.Position = player.Position
+		ack.PreferredColumn = player.PreferredColumn
+		ack.Score = player.Score
+		if player.Team != 0 {
+			ack.TeamScore = mpGame.TeamScore(player.Team)
+		}
+	}
+
+	pearl := mpGame.GameState.GetPearlPosition()
+	ack.PearlPosition = Position{Row: pearl.Row, Col: pearl.Col}
+	ack.IsCompleted = mpGame.IsCompleted
+	ack.Winner = mpGame.Winner
+	ack.WinningTeam = mpGame.WinningTeam
+	ack.Conflict = predicted != nil && *predicted != ack.Position
+}
+
+// SendMessageToPlayer sends a game message to a single player over their game WebSocket
+func (mgs *MultiplayerGameService) SendMessageToPlayer(playerID uint, messageType string, data interface{}) error {
+	message := MultiplayerGameMessage{
+		Type:      messageType,
+		PlayerID:  playerID,
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+	return mgs.wsManager.SendMessage(playerID, message)
+}
@@ -0,0 +1,242 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"boba-vim/internal/game"
+	"boba-vim/internal/services/matchmaking"
+)
+
+// MultiplayerPlayer is one participant of a multiplayer game
+type MultiplayerPlayer struct {
+	ID                  uint
+	Username            string
+	Character           string
+	Seat                int // Index in MultiplayerGame.Players and in the game state
+	Position            Position
+	PreferredColumn     int
+	Options             game.MovementOptions
+	Score               int
+	MoveSeq             uint64     // Last move sequence number received over the WebSocket
+	Left                bool       // Disconnected before the game completed
+	Disconnected        bool       // Lost their connection and may still reconnect
+	ReconnectDeadline   *time.Time // When a disconnected player forfeits
+	Bot                 string     // Difficulty of a server-side bot; empty for a player
+	Team                int        // TeamOne or TeamTwo in a team game; 0 when playing alone
+	Eliminated          bool       // Knocked out by the game's rules; still connected but can't move
+	EliminatedAt        *time.Time
+	StealProtectedUntil *time.Time // Pearl steal: can't be stolen from until then
+	forfeitTimer        *time.Timer
+}
+
+// PlayerUpdateData is one participant's entry in a game state update
+type PlayerUpdateData struct {
+	ID           uint     `json:"id"`
+	Position     Position `json:"position"`
+	Score        int      `json:"score"`
+	Team         int      `json:"team,omitempty"`
+	Left         bool     `json:"left,omitempty"`
+	Disconnected bool     `json:"disconnected,omitempty"`
+	Eliminated   bool     `json:"eliminated,omitempty"`
+}
+
+// PlayerPlacement is a participant's finishing position in a completed game
+type PlayerPlacement struct {
+	PlayerID   uint   `json:"player_id"`
+	Username   string `json:"username"`
+	Character  string `json:"character"`
+	Seat       int    `json:"seat"`
+	Score      int    `json:"score"`
+	Placement  int    `json:"placement"`
+	Left       bool   `json:"left,omitempty"`
+	Eliminated bool   `json:"eliminated,omitempty"`
+	Team       int    `json:"team,omitempty"`
+	TeamScore  int    `json:"team_score,omitempty"`
+}
+
+// Player returns the participant with the given ID, or nil if they aren't in the game
+func (mpGame *MultiplayerGame) Player(playerID uint) *MultiplayerPlayer {
+	for _, player := range mpGame.Players {
+		if player.ID == playerID {
+			return player
+		}
+	}
+	return nil
+}
+
+// PlayerIDs returns the IDs of every participant in seat order
+func (mpGame *MultiplayerGame) PlayerIDs() []uint {
+	playerIDs := make([]uint, len(mpGame.Players))
+	for i, player := range mpGame.Players {
+		playerIDs[i] = player.ID
+	}
+	return playerIDs
+}
+
+// ActivePlayerIDs returns the IDs of the participants who haven't left the game
+func (mpGame *MultiplayerGame) ActivePlayerIDs() []uint {
+	playerIDs := make([]uint, 0, len(mpGame.Players))
+	for _, player := range mpGame.Players {
+		if !player.Left {
+			playerIDs = append(playerIDs, player.ID)
+		}
+	}
+	return playerIDs
+}
+
+// snapshotPlayers copies every participant so they can be used after the game lock is released
+func (mpGame *MultiplayerGame) snapshotPlayers() []MultiplayerPlayer {
+	players := make([]MultiplayerPlayer, len(mpGame.Players))
+	for i, player := range mpGame.Players {
+		players[i] = *player
+	}
+	return players
+}
+
+// validateParticipants checks that a mode is known and that the participants fit its room size
+func validateParticipants(mode string, participants []matchmaking.MatchParticipant) error {
+	switch mode {
+	case matchmaking.GameModeDuel:
+		if len(participants) != 2 {
+			return fmt.Errorf("a duel needs 2 players, got %d", len(participants))
+		}
+	case matchmaking.GameModeFreeForAll:
+		if len(participants) < matchmaking.MinFreeForAllRoomSize || len(participants) > matchmaking.MaxFreeForAllRoomSize {
+			return fmt.Errorf("a free-for-all needs %d to %d players, got %d",
+				matchmaking.MinFreeForAllRoomSize, matchmaking.MaxFreeForAllRoomSize, len(participants))
+		}
+	case matchmaking.GameModeTeams:
+		if len(participants) != matchmaking.TeamRoomSize || !validateTeams(participants) {
+			return fmt.Errorf("a team game needs two teams of %d players", matchmaking.TeamSize)
+		}
+	default:
+		return fmt.Errorf("unknown game mode %q", mode)
+	}
+
+	seen := make(map[uint]bool, len(participants))
+	for _, participant := range participants {
+		if seen[participant.PlayerID] {
+			return fmt.Errorf("player %d is seated twice", participant.PlayerID)
+		}
+		seen[participant.PlayerID] = true
+	}
+	return nil
+}
+
+// spawnPositions spreads count starting positions evenly over the map cells in reading order and
+// hands them out in random order. The first and last positions are the top-left and bottom-right
+// corners, so a duel keeps its corner starts.
+func spawnPositions(mapContent [][]string, count int) ([]Position, error) {
+	var cells []Position
+	for row, line := range mapContent {
+		for col := range line {
+			cells = append(cells, Position{Row: row, Col: col})
+		}
+	}
+
+	if len(cells) < count {
+		return nil, fmt.Errorf("map has %d cells, not enough for %d players", len(cells), count)
+	}
+
+	positions := make([]Position, count)
+	for i := range positions {
+		index := 0
+		if count > 1 {
+			index = i * (len(cells) - 1) / (count - 1)
+		}
+		positions[i] = cells[index]
+	}
+
+	rand.Shuffle(len(positions), func(i, j int) {
+		positions[i], positions[j] = positions[j], positions[i]
+	})
+	return positions, nil
+}
+
+// rankPlayers orders participants into placements: the winner first, then players still in the
+// game by score, then eliminated players with the last to fall first, then players who left by
+// score. Players with equal standing share a placement.
+func rankPlayers(players []MultiplayerPlayer, winnerID *uint) []PlayerPlacement {
+	isWinner := func(player MultiplayerPlayer) bool {
+		return winnerID != nil && player.ID == *winnerID
+	}
+
+	ranked := make([]MultiplayerPlayer, len(players))
+	copy(ranked, players)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if isWinner(ranked[i]) != isWinner(ranked[j]) {
+			return isWinner(ranked[i])
+		}
+		if ranked[i].Left != ranked[j].Left {
+			return !ranked[i].Left
+		}
+		if ranked[i].Eliminated != ranked[j].Eliminated {
+			return !ranked[i].Eliminated
+		}
+		if ranked[i].Eliminated && !ranked[i].EliminatedAt.Equal(*ranked[j].EliminatedAt) {
+			return ranked[i].EliminatedAt.After(*ranked[j].EliminatedAt)
+		}
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	placements := make([]PlayerPlacement, len(ranked))
+	for i, player := range ranked {
+		placement := i + 1
+		if i > 0 {
+			previous := ranked[i-1]
+			if !isWinner(previous) && previous.Left == player.Left && previous.Eliminated == player.Eliminated &&
+				previous.Score == player.Score && (!player.Eliminated || previous.EliminatedAt.Equal(*player.EliminatedAt)) {
+				placement = placements[i-1].Placement
+			}
+		}
+
+		placements[i] = PlayerPlacement{
+			PlayerID:   player.ID,
+			Username:   player.Username,
+			Character:  player.Character,
+			Seat:       player.Seat,
+			Score:      player.Score,
+			Placement:  placement,
+			Left:       player.Left,
+			Eliminated: player.Eliminated,
+		}
+	}
+	return placements
+}
+
+// playerData returns the public view of a participant used in game responses
+func playerData(player *MultiplayerPlayer) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         player.ID,
+		"username":   player.Username,
+		"character":  player.Character,
+		"position":   player.Position,
+		"score":      player.Score,
+		"seat":       player.Seat,
+		"team":       player.Team,
+		"left":       player.Left,
+		"eliminated": player.Eliminated,
+	}
+}
+
+// addPlayersData adds every participant to a game response under "players", and the first two
+// seats under "player1" and "player2" for duel clients. Team games add the teams' scores and the
+// winning team. The caller must hold the game lock.
+func addPlayersData(response map[string]interface{}, mpGame *MultiplayerGame) {
+	players := make([]map[string]interface{}, len(mpGame.Players))
+	for i, player := range mpGame.Players {
+		players[i] = playerData(player)
+	}
+
+	response["mode"] = mpGame.Mode
+	response["players"] = players
+	response["player1"] = players[0]
+	response["player2"] = players[1]
+	if mpGame.IsTeamGame() {
+		response["teams"] = mpGame.teamsData()
+		response["winning_team"] = mpGame.WinningTeam
+	}
+}
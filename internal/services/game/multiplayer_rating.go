@@ -0,0 +1,63 @@
+package game
+
+import (
+	"time"
+
+	"boba-vim/internal/models"
+	"boba-vim/internal/rating"
+)
+
+// RatingPeriod is how long a player can go without playing before their rating deviation grows
+const RatingPeriod = 24 * time.Hour
+
+// statsRating returns the Glicko-2 rating stored in a player's stats
+func statsRating(stats *models.MultiplayerPlayerStats) rating.Rating {
+	return rating.Rating{
+		Rating:     stats.Rating,
+		Deviation:  stats.RatingDeviation,
+		Volatility: stats.RatingVolatility,
+	}
+}
+
+// setStatsRating stores a Glicko-2 rating in a player's stats
+func setStatsRating(stats *models.MultiplayerPlayerStats, newRating rating.Rating) {
+	stats.Rating = newRating.Rating
+	stats.RatingDeviation = newRating.Deviation
+	stats.RatingVolatility = newRating.Volatility
+	stats.ConservativeRating = newRating.Conservative()
+}
+
+// updateRatings rates a completed game. Every participant plays one game against each other
+// participant: a better placement is a win and an equal placement a draw. Teammates aren't rated
+// against each other. Ratings of players who sat out rating periods lose certainty before the
+// game is rated.
+func updateRatings(playerStats []*models.MultiplayerPlayerStats, participants []models.MultiplayerGameParticipant, playedAt time.Time) {
+	ratings := make([]rating.Rating, len(playerStats))
+	for i, stats := range playerStats {
+		ratings[i] = statsRating(stats)
+		if stats.LastPlayedAt != nil {
+			ratings[i] = ratings[i].Decay(int(playedAt.Sub(*stats.LastPlayedAt) / RatingPeriod))
+		}
+	}
+
+	for i, stats := range playerStats {
+		outcomes := make([]rating.Outcome, 0, len(participants)-1)
+		for j, opponent := range participants {
+			if i == j || (opponent.Team != 0 && opponent.Team == participants[i].Team) {
+				continue
+			}
+
+			score := rating.Draw
+			switch {
+			case participants[i].Placement < opponent.Placement:
+				score = rating.Win
+			case participants[i].Placement > opponent.Placement:
+				score = rating.Loss
+			}
+			outcomes = append(outcomes, rating.Outcome{Opponent: ratings[j], Score: score})
+		}
+
+		setStatsRating(stats, ratings[i].Update(outcomes))
+		stats.RatedGames++
+	}
+}
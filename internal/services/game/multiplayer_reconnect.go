@@ -0,0 +1,193 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+)
+
+// Reconnect errors
+var (
+	ErrReconnectGameNotFound = errors.New("game not found")
+	ErrReconnectNotInGame    = errors.New("player not in this game")
+	ErrPlayerForfeited       = errors.New("player already forfeited this game")
+)
+
+// HandleConnectionLost gives a player whose game WebSocket dropped the reconnect grace period to
+// come back before they forfeit. A duel is paused while its player is away; a free-for-all plays on.
+func (mgs *MultiplayerGameService) HandleConnectionLost(gameID string, playerID uint) {
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		if err := mgs.node.Send(owner, clusterMethodConnectionLost, gameCall{GameID: gameID, PlayerID: playerID}); err != nil {
+			utils.Error("Failed to report the lost connection of player %d to game %s: %v", playerID, gameID, err)
+		}
+		return
+	}
+	if mpGame == nil {
+		return
+	}
+
+	// A player who already reconnected, maybe to another instance, isn't away
+	if mgs.wsManager.IsPlayerConnected(playerID) {
+		return
+	}
+
+	grace := mgs.cfg.ReconnectGrace
+	if grace <= 0 {
+		mgs.forfeitPlayer(mpGame, playerID, false)
+		return
+	}
+
+	mpGame.mutex.Lock()
+	player := mpGame.Player(playerID)
+	if player == nil || player.Left || player.Disconnected || mpGame.IsCompleted {
+		mpGame.mutex.Unlock()
+		return
+	}
+
+	deadline := time.Now().Add(grace)
+	player.Disconnected = true
+	player.ReconnectDeadline = &deadline
+	player.forfeitTimer = time.AfterFunc(grace, func() {
+		mgs.forfeitPlayer(mpGame, playerID, true)
+	})
+
+	if mpGame.Mode == matchmaking.GameModeDuel {
+		mpGame.pause()
+	}
+	paused := mpGame.Paused
+	username := player.Username
+	playerIDs := mpGame.ActivePlayerIDs()
+	mpGame.mutex.Unlock()
+
+	message := MultiplayerGameMessage{
+		Type:     "player_connection_lost",
+		PlayerID: playerID,
+		Data: map[string]interface{}{
+			"username":           username,
+			"reconnect_deadline": deadline,
+			"grace_seconds":      int(grace.Seconds()),
+			"paused":             paused,
+		},
+		Timestamp: time.Now(),
+	}
+
+	mgs.wsManager.BroadcastToPlayers(playerIDs, message)
+	mgs.broadcastToSpectators(mpGame, message)
+	mgs.saveSnapshot(mpGame)
+	utils.Info("Player %d lost connection to game %s, forfeits at %s unless they reconnect", playerID, gameID, deadline.Format(time.RFC3339))
+}
+
+// HandlePlayerReconnect attaches a player's new game WebSocket. A player coming back within the
+// grace period is restored and the game resumes. It returns the full game state, and whether the
+// player should resync from it because the game was already underway.
+func (mgs *MultiplayerGameService) HandlePlayerReconnect(gameID string, playerID uint) (map[string]interface{}, bool, error) {
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		var reply reconnectReply
+		if err := mgs.node.Call(owner, clusterMethodReconnect, gameCall{GameID: gameID, PlayerID: playerID}, &reply); err != nil {
+			return nil, false, err
+		}
+		return reply.State, reply.Resync, nil
+	}
+	if mpGame == nil {
+		return nil, false, ErrReconnectGameNotFound
+	}
+
+	mpGame.mutex.Lock()
+	player := mpGame.Player(playerID)
+	if player == nil {
+		mpGame.mutex.Unlock()
+		return nil, false, ErrReconnectNotInGame
+	}
+	if player.Left {
+		mpGame.mutex.Unlock()
+		return nil, false, ErrPlayerForfeited
+	}
+
+	reconnected := player.Disconnected
+	if reconnected {
+		player.forfeitTimer.Stop()
+		player.forfeitTimer = nil
+		player.Disconnected = false
+		player.ReconnectDeadline = nil
+		mpGame.resume()
+	}
+
+	state := mgs.gameStateResponse(mpGame, playerID)
+	resync := reconnected || mpGame.CountdownStarted
+	paused := mpGame.Paused
+	username := player.Username
+	playerIDs := mpGame.ActivePlayerIDs()
+	mpGame.mutex.Unlock()
+
+	// The new connection may not follow versions until it asks for a resync
+	mgs.stopDeltas(mpGame, playerID)
+
+	if reconnected {
+		message := MultiplayerGameMessage{
+			Type:     "player_reconnected",
+			PlayerID: playerID,
+			Data: map[string]interface{}{
+				"username": username,
+				"paused":   paused,
+			},
+			Timestamp: time.Now(),
+		}
+
+		mgs.wsManager.BroadcastToPlayers(playerIDs, message)
+		mgs.broadcastToSpectators(mpGame, message)
+		mgs.saveSnapshot(mpGame)
+		utils.Info("Player %d reconnected to game %s", playerID, gameID)
+	}
+
+	return state, resync, nil
+}
+
+// pause stops a game until every player is back. The caller must hold the game lock.
+func (mpGame *MultiplayerGame) pause() {
+	if mpGame.Paused {
+		return
+	}
+
+	mpGame.Paused = true
+	if mpGame.timeLimitTimer != nil && mpGame.EndsAt != nil {
+		mpGame.timeLimitTimer.Stop()
+		mpGame.timeRemaining = time.Until(*mpGame.EndsAt)
+	}
+}
+
+// resume restarts a paused game once no player is away, with the time that was left on its clock.
+// The caller must hold the game lock.
+func (mpGame *MultiplayerGame) resume() {
+	if !mpGame.Paused {
+		return
+	}
+	for _, player := range mpGame.Players {
+		if player.Disconnected && !player.Left {
+			return
+		}
+	}
+
+	mpGame.Paused = false
+	mpGame.LastActivity = time.Now()
+	if mpGame.timeLimitTimer != nil && mpGame.EndsAt != nil {
+		endsAt := time.Now().Add(mpGame.timeRemaining)
+		mpGame.EndsAt = &endsAt
+		mpGame.timeLimitTimer.Reset(mpGame.timeRemaining)
+		mpGame.timeRemaining = 0
+	}
+}
+
+// stopForfeitTimers stops the grace periods of players who are away. The caller must hold the
+// game lock.
+func (mpGame *MultiplayerGame) stopForfeitTimers() {
+	for _, player := range mpGame.Players {
+		if player.forfeitTimer != nil {
+			player.forfeitTimer.Stop()
+			player.forfeitTimer = nil
+		}
+	}
+}
@@ -0,0 +1,544 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"boba-vim/internal/constant"
+	"boba-vim/internal/models"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Constants for rematches and series
+const (
+	RematchWindow       = 60 * time.Second // How long after a game ends its players can ask for a rematch
+	RematchOfferTimeout = 30 * time.Second // How long an offer waits for the opponent's answer
+	SeriesNextGameDelay = 10 * time.Second // Breather between the games of a series
+
+	// Tied games of a series are replayed, up to this many times the series length
+	maxSeriesGamesFactor = 2
+
+	// rematchPending marks a game whose rematch is being started
+	rematchPending = "pending"
+)
+
+// Rematch actions players send over the game WebSocket
+const (
+	RematchActionOffer   = "offer"
+	RematchActionAccept  = "accept"
+	RematchActionDecline = "decline"
+)
+
+// Ways a series ends
+const (
+	SeriesCompletionNormal    = "normal"
+	SeriesCompletionForfeit   = "forfeit"   // A player left one of its games
+	SeriesCompletionAbandoned = "abandoned" // A game could not be started or was never played
+)
+
+// Rematch errors
+var (
+	ErrRematchGameNotFound    = errors.New("game not found")
+	ErrRematchNotInGame       = errors.New("player not in this game")
+	ErrUnknownRematchAction   = errors.New("unknown rematch action")
+	ErrRematchUnavailable     = errors.New("rematches are only available after a duel between two players")
+	ErrGameNotFinished        = errors.New("the game isn't over yet")
+	ErrRematchExpired         = errors.New("it's too late to ask for a rematch")
+	ErrOpponentLeft           = errors.New("your opponent left the game")
+	ErrRematchStarted         = errors.New("the rematch has already started")
+	ErrSeriesInProgress       = errors.New("the next game of the series starts automatically")
+	ErrInvalidSeriesLength    = errors.New("a rematch is best of 1, 3 or 5")
+	ErrNoRematchOffer         = errors.New("there is no rematch offer to answer")
+	ErrOwnRematchOffer        = errors.New("you can't accept your own rematch offer")
+	ErrRematchPlayerBusy      = errors.New("a player is already in another game")
+	errUnexpectedRematchStart = errors.New("unexpected game type for rematch")
+)
+
+// GameSeries is a best-of series of games between two players
+type GameSeries struct {
+	ID          string    `json:"id"`
+	BestOf      int       `json:"best_of"`
+	PlayerIDs   []uint    `json:"player_ids"` // Seat order of the series' first game
+	Usernames   []string  `json:"usernames"`
+	Wins        []int     `json:"wins"`         // Games won, in PlayerIDs order
+	GamesPlayed int       `json:"games_played"` // Including tied games
+	WinnerID    *uint     `json:"winner_id,omitempty"`
+	Completion  string    `json:"completion,omitempty"` // How the series ended; empty while it goes on
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// rematchOffer is a player's pending offer of a rematch
+type rematchOffer struct {
+	PlayerID  uint
+	BestOf    int
+	ExpiresAt time.Time
+	timer     *time.Timer
+}
+
+// RematchOfferData is sent to both players when a rematch is offered
+type RematchOfferData struct {
+	PlayerID  uint      `json:"player_id"`
+	Username  string    `json:"username"`
+	BestOf    int       `json:"best_of"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HandleRematch answers a player's rematch offer, acceptance or decline after their game ended.
+// An accepted rematch starts a new game between the same players on another map; rematches of
+// more than one game are played as a series.
+func (mgs *MultiplayerGameService) HandleRematch(gameID string, playerID uint, action string, bestOf int) error {
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		return mgs.node.Call(owner, clusterMethodRematch, gameCall{GameID: gameID, PlayerID: playerID, Rematch: action, BestOf: bestOf}, nil)
+	}
+	if mpGame == nil {
+		return ErrRematchGameNotFound
+	}
+
+	switch action {
+	case RematchActionOffer:
+		return mgs.offerRematch(mpGame, playerID, bestOf)
+	case RematchActionAccept:
+		return mgs.acceptRematch(mpGame, playerID)
+	case RematchActionDecline:
+		return mgs.declineRematch(mpGame, playerID)
+	default:
+		return ErrUnknownRematchAction
+	}
+}
+
+// offerRematch offers the opponent a rematch. Offering while the opponent's offer is pending
+// accepts it.
+func (mgs *MultiplayerGameService) offerRematch(mpGame *MultiplayerGame, playerID uint, bestOf int) error {
+	if bestOf == 0 {
+		bestOf = 1
+	}
+	if bestOf != 1 && bestOf != 3 && bestOf != 5 {
+		return ErrInvalidSeriesLength
+	}
+
+	mpGame.mutex.Lock()
+	if err := mpGame.checkRematch(playerID); err != nil {
+		mpGame.mutex.Unlock()
+		return err
+	}
+
+	if pending := mpGame.rematchOffer; pending != nil {
+		if pending.PlayerID != playerID {
+			mpGame.mutex.Unlock()
+			return mgs.acceptRematch(mpGame, playerID)
+		}
+		pending.timer.Stop()
+	}
+
+	offer := &rematchOffer{
+		PlayerID:  playerID,
+		BestOf:    bestOf,
+		ExpiresAt: time.Now().Add(RematchOfferTimeout),
+	}
+	offer.timer = time.AfterFunc(RematchOfferTimeout, func() {
+		mgs.expireRematchOffer(mpGame, offer)
+	})
+	mpGame.rematchOffer = offer
+
+	data := RematchOfferData{
+		PlayerID:  playerID,
+		Username:  mpGame.Player(playerID).Username,
+		BestOf:    bestOf,
+		ExpiresAt: offer.ExpiresAt,
+	}
+	playerIDs := mpGame.PlayerIDs()
+	mpGame.mutex.Unlock()
+
+	mgs.broadcastRematchMessage(playerIDs, "rematch_offered", data)
+	utils.Info("Player %d offered a best of %d rematch of game %s", playerID, bestOf, mpGame.ID)
+	return nil
+}
+
+// acceptRematch accepts the opponent's pending offer and starts the rematch
+func (mgs *MultiplayerGameService) acceptRematch(mpGame *MultiplayerGame, playerID uint) error {
+	mpGame.mutex.Lock()
+	if err := mpGame.checkRematch(playerID); err != nil {
+		mpGame.mutex.Unlock()
+		return err
+	}
+
+	offer := mpGame.rematchOffer
+	if offer == nil {
+		mpGame.mutex.Unlock()
+		return ErrNoRematchOffer
+	}
+	if offer.PlayerID == playerID {
+		mpGame.mutex.Unlock()
+		return ErrOwnRematchOffer
+	}
+	if mgs.playersBusy(mpGame) {
+		mpGame.mutex.Unlock()
+		return ErrRematchPlayerBusy
+	}
+
+	offer.timer.Stop()
+	mpGame.rematchOffer = nil
+	mpGame.RematchGameID = rematchPending
+
+	var series *GameSeries
+	if offer.BestOf > 1 {
+		series = mpGame.newSeries(offer.BestOf)
+	}
+	mpGame.mutex.Unlock()
+
+	if _, err := mgs.startRematchGame(mpGame, series); err != nil {
+		mpGame.mutex.Lock()
+		mpGame.RematchGameID = ""
+		mpGame.mutex.Unlock()
+		utils.Error("Failed to start rematch of game %s: %v", mpGame.ID, err)
+		return err
+	}
+	return nil
+}
+
+// declineRematch turns down the pending offer, or takes back the player's own offer
+func (mgs *MultiplayerGameService) declineRematch(mpGame *MultiplayerGame, playerID uint) error {
+	mpGame.mutex.Lock()
+	if mpGame.Player(playerID) == nil {
+		mpGame.mutex.Unlock()
+		return ErrRematchNotInGame
+	}
+
+	offer := mpGame.rematchOffer
+	if offer == nil {
+		mpGame.mutex.Unlock()
+		return ErrNoRematchOffer
+	}
+	offer.timer.Stop()
+	mpGame.rematchOffer = nil
+	playerIDs := mpGame.PlayerIDs()
+	mpGame.mutex.Unlock()
+
+	mgs.broadcastRematchMessage(playerIDs, "rematch_declined", map[string]interface{}{"player_id": playerID})
+	return nil
+}
+
+// expireRematchOffer withdraws an offer nobody answered
+func (mgs *MultiplayerGameService) expireRematchOffer(mpGame *MultiplayerGame, offer *rematchOffer) {
+	mpGame.mutex.Lock()
+	if mpGame.rematchOffer != offer {
+		mpGame.mutex.Unlock()
+		return
+	}
+	mpGame.rematchOffer = nil
+	playerIDs := mpGame.PlayerIDs()
+	mpGame.mutex.Unlock()
+
+	mgs.broadcastRematchMessage(playerIDs, "rematch_expired", map[string]interface{}{"player_id": offer.PlayerID})
+}
+
+// startRematchGame starts a game between the players of a finished game on another map, with
+// the same rules. The game is the next game of series, when it is given.
+func (mgs *MultiplayerGameService) startRematchGame(previous *MultiplayerGame, series *GameSeries) (*MultiplayerGame, error) {
+	previous.mutex.RLock()
+	mode := previous.Mode
+	settings := previous.Settings
+	previousMapID := previous.MapID
+	playerIDs := previous.PlayerIDs()
+	participants := make([]matchmaking.MatchParticipant, len(previous.Players))
+	for i, player := range previous.Players {
+		participants[i] = matchmaking.MatchParticipant{
+			PlayerID:  player.ID,
+			Username:  player.Username,
+			Character: player.Character,
+		}
+	}
+	previous.mutex.RUnlock()
+
+	settings.MapID = rematchMapID(previousMapID)
+	settings.MapVeto = nil
+	settings.SeriesID, settings.SeriesGame = "", 0
+	if series != nil {
+		settings.SeriesID = series.ID
+		settings.SeriesGame = series.GamesPlayed + 1
+	}
+
+	matchID := uuid.New().String()
+	started, err := mgs.StartMultiplayerGameWithSettings(matchID, mode, participants, settings)
+	if err != nil {
+		return nil, err
+	}
+	rematch, ok := started.(*MultiplayerGame)
+	if !ok {
+		return nil, errUnexpectedRematchStart
+	}
+
+	if series != nil {
+		rematch.mutex.Lock()
+		rematch.Series = series.clone()
+		rematch.mutex.Unlock()
+		mgs.saveSnapshot(rematch)
+	}
+
+	previous.mutex.Lock()
+	previous.RematchGameID = rematch.ID
+	previous.mutex.Unlock()
+	mgs.saveSnapshot(previous)
+
+	mgs.broadcastRematchMessage(playerIDs, "rematch_started", map[string]interface{}{
+		"game_id":  rematch.ID,
+		"match_id": matchID,
+		"map_id":   rematch.MapID,
+		"series":   series,
+	})
+
+	utils.Info("Rematch %s of game %s started", rematch.ID, previous.ID)
+	return rematch, nil
+}
+
+// continueSeries moves a series on after one of its games ended: a decided series is recorded,
+// and otherwise its next game starts after a breather
+func (mgs *MultiplayerGameService) continueSeries(mpGame *MultiplayerGame, series *GameSeries) {
+	if series == nil {
+		return
+	}
+
+	if series.Completion != "" {
+		mpGame.mutex.RLock()
+		ranked := mpGame.Settings.Ranked
+		mpGame.mutex.RUnlock()
+
+		mgs.recordSeriesResult(series, ranked)
+		mgs.broadcastRematchMessage(series.PlayerIDs, "series_complete", series)
+		utils.Info("Series %s ended %v", series.ID, series.Wins)
+		return
+	}
+
+	mgs.broadcastRematchMessage(series.PlayerIDs, "series_update", map[string]interface{}{
+		"series":          series,
+		"next_game_in_ms": SeriesNextGameDelay.Milliseconds(),
+	})
+	time.AfterFunc(SeriesNextGameDelay, func() {
+		mgs.startNextSeriesGame(mpGame, series)
+	})
+}
+
+// startNextSeriesGame starts the next game of a series, or abandons the series if it can't be
+// played
+func (mgs *MultiplayerGameService) startNextSeriesGame(previous *MultiplayerGame, series *GameSeries) {
+	previous.mutex.Lock()
+	if previous.RematchGameID != "" {
+		previous.mutex.Unlock()
+		return
+	}
+	previous.RematchGameID = rematchPending
+	busy := mgs.playersBusy(previous)
+	previous.mutex.Unlock()
+
+	if !busy {
+		if _, err := mgs.startRematchGame(previous, series); err == nil {
+			return
+		} else {
+			utils.Error("Failed to start the next game of series %s: %v", series.ID, err)
+		}
+	}
+
+	abandoned := series.clone()
+	abandoned.finish(nil, SeriesCompletionAbandoned)
+	mgs.continueSeries(previous, abandoned)
+}
+
+// recordSeriesResult records a series that ended
+func (mgs *MultiplayerGameService) recordSeriesResult(series *GameSeries, ranked bool) {
+	if len(series.PlayerIDs) != 2 {
+		return
+	}
+
+	result := &models.MultiplayerSeriesResult{
+		SeriesID:        series.ID,
+		BestOf:          series.BestOf,
+		Player1ID:       series.PlayerIDs[0],
+		Player1Username: series.Usernames[0],
+		Player1Wins:     series.Wins[0],
+		Player2ID:       series.PlayerIDs[1],
+		Player2Username: series.Usernames[1],
+		Player2Wins:     series.Wins[1],
+		GamesPlayed:     series.GamesPlayed,
+		WinnerID:        series.WinnerID,
+		CompletionType:  series.Completion,
+		Ranked:          ranked,
+		StartedAt:       series.StartedAt,
+		CompletedAt:     time.Now(),
+	}
+	if err := mgs.db.Create(result).Error; err != nil {
+		utils.Error("Failed to record series %s: %v", series.ID, err)
+	}
+}
+
+// broadcastRematchMessage sends a rematch or series message to players over the game WebSocket
+func (mgs *MultiplayerGameService) broadcastRematchMessage(playerIDs []uint, messageType string, data interface{}) {
+	mgs.wsManager.BroadcastToPlayers(playerIDs, MultiplayerGameMessage{
+		Type:      messageType,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
+
+// playersBusy reports whether a player of a finished game has since joined another game. The
+// caller must hold the game lock.
+func (mgs *MultiplayerGameService) playersBusy(mpGame *MultiplayerGame) bool {
+	for _, playerID := range mpGame.PlayerIDs() {
+		if gameID, exists := mgs.gameForPlayer(playerID); exists && gameID != mpGame.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRematch checks that a player can ask for or accept a rematch of the game. The caller must
+// hold the game lock.
+func (mpGame *MultiplayerGame) checkRematch(playerID uint) error {
+	if mpGame.Player(playerID) == nil {
+		return ErrRematchNotInGame
+	}
+	if !mpGame.IsCompleted {
+		return ErrGameNotFinished
+	}
+	if mpGame.Mode != matchmaking.GameModeDuel || mpGame.Settings.Practice {
+		return ErrRematchUnavailable
+	}
+	for _, player := range mpGame.Players {
+		if player.Bot != "" {
+			return ErrRematchUnavailable
+		}
+		if player.Left {
+			return ErrOpponentLeft
+		}
+	}
+	if mpGame.RematchGameID != "" {
+		return ErrRematchStarted
+	}
+	if mpGame.Series != nil && mpGame.Series.Completion == "" {
+		return ErrSeriesInProgress
+	}
+	if mpGame.CompletedAt == nil || time.Since(*mpGame.CompletedAt) > RematchWindow {
+		return ErrRematchExpired
+	}
+	return nil
+}
+
+// newSeries starts a series between the game's players. The caller must hold the game lock.
+func (mpGame *MultiplayerGame) newSeries(bestOf int) *GameSeries {
+	series := &GameSeries{
+		ID:        uuid.New().String(),
+		BestOf:    bestOf,
+		PlayerIDs: make([]uint, len(mpGame.Players)),
+		Usernames: make([]string, len(mpGame.Players)),
+		Wins:      make([]int, len(mpGame.Players)),
+		StartedAt: time.Now(),
+	}
+	for i, player := range mpGame.Players {
+		series.PlayerIDs[i] = player.ID
+		series.Usernames[i] = player.Username
+	}
+	return series
+}
+
+// scoreSeries counts the game's result toward its series. It returns a copy of the series
+// afterwards, or nil for a game outside a series. The caller must hold the game lock.
+func (mpGame *MultiplayerGame) scoreSeries(winnerID *uint, forfeit bool) *GameSeries {
+	if mpGame.Series == nil || mpGame.Series.Completion != "" {
+		return nil
+	}
+	mpGame.Series.score(winnerID, forfeit)
+	return mpGame.Series.clone()
+}
+
+// abandonSeries ends the series of a game that was never finished. It returns a copy of the
+// series, or nil for a game outside a series. The caller must hold the game lock.
+func (mpGame *MultiplayerGame) abandonSeries() *GameSeries {
+	if mpGame.Series == nil || mpGame.Series.Completion != "" {
+		return nil
+	}
+	mpGame.Series.finish(nil, SeriesCompletionAbandoned)
+	return mpGame.Series.clone()
+}
+
+// score counts a finished game. A player who reaches a majority of the series' games wins it,
+// and a player who wins by forfeit wins the series. A series that keeps being tied ends after
+// maxSeriesGamesFactor times its length, won by the player ahead if any.
+func (series *GameSeries) score(winnerID *uint, forfeit bool) {
+	series.GamesPlayed++
+	leader := -1
+	for i, playerID := range series.PlayerIDs {
+		if winnerID != nil && playerID == *winnerID {
+			series.Wins[i]++
+		}
+		if leader < 0 || series.Wins[i] > series.Wins[leader] {
+			leader = i
+		}
+	}
+
+	if forfeit {
+		series.finish(winnerID, SeriesCompletionForfeit)
+		return
+	}
+
+	if series.Wins[leader] >= series.BestOf/2+1 {
+		series.finish(&series.PlayerIDs[leader], SeriesCompletionNormal)
+		return
+	}
+
+	if series.GamesPlayed >= series.BestOf*maxSeriesGamesFactor {
+		var winner *uint
+		tied := 0
+		for _, wins := range series.Wins {
+			if wins == series.Wins[leader] {
+				tied++
+			}
+		}
+		if tied == 1 {
+			winner = &series.PlayerIDs[leader]
+		}
+		series.finish(winner, SeriesCompletionNormal)
+	}
+}
+
+// finish ends the series
+func (series *GameSeries) finish(winnerID *uint, completion string) {
+	if winnerID != nil {
+		id := *winnerID
+		series.WinnerID = &id
+	}
+	series.Completion = completion
+}
+
+// clone returns a copy of the series that shares nothing with it
+func (series *GameSeries) clone() *GameSeries {
+	if series == nil {
+		return nil
+	}
+	cloned := *series
+	cloned.PlayerIDs = append([]uint(nil), series.PlayerIDs...)
+	cloned.Usernames = append([]string(nil), series.Usernames...)
+	cloned.Wins = append([]int(nil), series.Wins...)
+	if series.WinnerID != nil {
+		winnerID := *series.WinnerID
+		cloned.WinnerID = &winnerID
+	}
+	return &cloned
+}
+
+// rematchMapID picks a random map outside the tutorials other than the one just played
+func rematchMapID(previousMapID int) int {
+	var candidates []int
+	for _, gameMap := range constant.GAME_MAPS {
+		if gameMap.Category != "tutorial" && gameMap.ID != previousMapID {
+			candidates = append(candidates, gameMap.ID)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
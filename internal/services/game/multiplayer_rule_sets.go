@@ -0,0 +1,399 @@
+package game
+
+import (
+	"math/rand"
+	"time"
+
+	"boba-vim/internal/game"
+)
+
+// Names of the rule sets players can pick
+const (
+	RuleSetFirstTo       = "first_to"
+	RuleSetTimed         = "timed"
+	RuleSetKingOfTheHill = "king_of_the_hill"
+	RuleSetPearlSteal    = "pearl_steal"
+	RuleSetElimination   = "elimination"
+
+	// DefaultRuleSet is the pearl race every matchmade game is played with
+	DefaultRuleSet = RuleSetFirstTo
+)
+
+// Constants for the rule sets
+const (
+	DefaultTimedLimit = 3 * time.Minute // Time limit of timed games created without one
+
+	HillTickInterval  = time.Second      // How often the hill scores
+	HillPointsPerTick = 20               // Points the hill's holder earns per tick
+	HillZoneLifetime  = 30 * time.Second // How long the hill stays before moving
+	HillMinCells      = 3                // Shortest word the hill can be on
+	HillMaxCells      = 6                // Most cells the hill covers
+
+	PearlStealPercent    = 25              // Share of the victim's points a steal takes
+	PearlStealProtection = 5 * time.Second // How long a victim can't be stolen from again
+
+	DefaultEliminationLimit = 5 * time.Minute  // Time limit of elimination games created without one
+	MoldStepInterval        = time.Second      // How often the molds move
+	MoldSpawnInterval       = 20 * time.Second // How often another mold appears
+	MoldSpawnDistance       = 4                // Closest a new mold appears to a player
+)
+
+func init() {
+	RegisterRuleSet(firstToRules{})
+	RegisterRuleSet(timedRules{})
+	RegisterRuleSet(kingOfTheHillRules{})
+	RegisterRuleSet(pearlStealRules{})
+	RegisterRuleSet(eliminationRules{})
+}
+
+// firstToRules is the pearl race: the first player or team to reach the target score wins. With a
+// time limit, the most points win when time runs out.
+type firstToRules struct{}
+
+func (firstToRules) Name() string { return RuleSetFirstTo }
+
+func (firstToRules) Description() string {
+	return "Collect pearls; the first to the target score wins"
+}
+
+func (firstToRules) Prepare(settings *MultiplayerGameSettings) error { return nil }
+
+func (firstToRules) Setup(mpGame *MultiplayerGame) {}
+
+func (firstToRules) ScoreMove(mpGame *MultiplayerGame, player *MultiplayerPlayer, pearlScore int) int {
+	return pearlScore
+}
+
+func (firstToRules) TickInterval() time.Duration { return 0 }
+
+func (firstToRules) Tick(mpGame *MultiplayerGame, now time.Time) bool { return false }
+
+// Decide ends the game once a player, or a team in a team game, reaches the target score
+func (firstToRules) Decide(mpGame *MultiplayerGame) (bool, *uint, int) {
+	if mpGame.IsTeamGame() {
+		for _, team := range []int{TeamOne, TeamTwo} {
+			if mpGame.TeamScore(team) >= mpGame.Settings.TargetScore {
+				return true, nil, team
+			}
+		}
+		return false, nil, 0
+	}
+
+	for _, player := range mpGame.Players {
+		if inGame(player) && player.Score >= mpGame.Settings.TargetScore {
+			winnerID := player.ID
+			return true, &winnerID, 0
+		}
+	}
+	return false, nil, 0
+}
+
+func (firstToRules) TimeUp(mpGame *MultiplayerGame) (*uint, int) {
+	return leaderByScore(mpGame, inGame)
+}
+
+// Target heads for the pearl
+func (firstToRules) Target(mpGame *MultiplayerGame, player *MultiplayerPlayer) (Position, bool) {
+	pearl := mpGame.GameState.GetPearlPosition()
+	return Position{Row: pearl.Row, Col: pearl.Col}, pearl.Row >= 0
+}
+
+// timedRules has no target score: the most points when time runs out win
+type timedRules struct {
+	firstToRules
+}
+
+func (timedRules) Name() string { return RuleSetTimed }
+
+func (timedRules) Description() string {
+	return "Collect pearls; the most points when time runs out win"
+}
+
+// Prepare gives games without a time limit the default one
+func (timedRules) Prepare(settings *MultiplayerGameSettings) error {
+	if settings.TimeLimit <= 0 {
+		settings.TimeLimit = DefaultTimedLimit
+	}
+	return nil
+}
+
+func (timedRules) Decide(mpGame *MultiplayerGame) (bool, *uint, int) {
+	return false, nil, 0
+}
+
+// kingOfTheHillRules replaces the pearl with a hill: a few cells of a word that move every so
+// often. Every tick, a player alone on the hill, or players of a single team, score; a hill
+// shared by rivals scores nobody. The first to the target score wins.
+type kingOfTheHillRules struct {
+	firstToRules
+}
+
+func (kingOfTheHillRules) Name() string { return RuleSetKingOfTheHill }
+
+func (kingOfTheHillRules) Description() string {
+	return "Hold the hill, a stretch of text that keeps moving; the first to the target score wins"
+}
+
+func (kingOfTheHillRules) Setup(mpGame *MultiplayerGame) {
+	mpGame.GameState.RemovePearl()
+	mpGame.RuleState.Zone = pickHill(mpGame.GameState.GetTextGrid(), nil)
+}
+
+func (kingOfTheHillRules) ScoreMove(mpGame *MultiplayerGame, player *MultiplayerPlayer, pearlScore int) int {
+	return 0
+}
+
+func (kingOfTheHillRules) TickInterval() time.Duration { return HillTickInterval }
+
+// Tick moves the hill when its time is up and scores its holders
+func (kingOfTheHillRules) Tick(mpGame *MultiplayerGame, now time.Time) bool {
+	state := &mpGame.RuleState
+	changed := false
+	if state.ZoneMovesAt == nil || now.After(*state.ZoneMovesAt) {
+		if state.ZoneMovesAt != nil {
+			state.Zone = pickHill(mpGame.GameState.GetTextGrid(), state.Zone)
+		}
+		movesAt := now.Add(HillZoneLifetime)
+		state.ZoneMovesAt = &movesAt
+		changed = true
+	}
+
+	var holders []*MultiplayerPlayer
+	for _, player := range mpGame.Players {
+		if inGame(player) && onCells(player.Position, state.Zone) {
+			holders = append(holders, player)
+		}
+	}
+
+	contested := false
+	for _, holder := range holders[min(1, len(holders)):] {
+		if rivals(mpGame, holders[0], holder) {
+			contested = true
+			break
+		}
+	}
+
+	holder, holderTeam := uint(0), 0
+	if len(holders) > 0 && !contested {
+		holder, holderTeam = holders[0].ID, holders[0].Team
+
+		// Holding teammates share the hill's points
+		share := HillPointsPerTick / len(holders)
+		for _, player := range holders {
+			player.Score += share
+		}
+		holders[0].Score += HillPointsPerTick - share*len(holders)
+		changed = true
+	}
+
+	if state.ZoneHolder != holder || state.ZoneHolderTeam != holderTeam || state.ZoneContested != contested {
+		state.ZoneHolder, state.ZoneHolderTeam, state.ZoneContested = holder, holderTeam, contested
+		changed = true
+	}
+	return changed
+}
+
+// Target heads for the nearest cell of the hill
+func (kingOfTheHillRules) Target(mpGame *MultiplayerGame, player *MultiplayerPlayer) (Position, bool) {
+	zone := mpGame.RuleState.Zone
+	if len(zone) == 0 {
+		return Position{}, false
+	}
+	nearest := zone[0]
+	for _, cell := range zone[1:] {
+		if distance(player.Position, cell) < distance(player.Position, nearest) {
+			nearest = cell
+		}
+	}
+	return nearest, true
+}
+
+// pearlStealRules is the pearl race where landing on a rival takes a share of their points.
+// A player who was stolen from can't be stolen from again for a few seconds.
+type pearlStealRules struct {
+	firstToRules
+}
+
+func (pearlStealRules) Name() string { return RuleSetPearlSteal }
+
+func (pearlStealRules) Description() string {
+	return "Collect pearls and land on rivals to steal a quarter of their points; the first to the target score wins"
+}
+
+func (pearlStealRules) ScoreMove(mpGame *MultiplayerGame, player *MultiplayerPlayer, pearlScore int) int {
+	points := pearlScore
+	now := time.Now()
+	for _, victim := range mpGame.Players {
+		if !rivals(mpGame, player, victim) || !inGame(victim) || victim.Position != player.Position {
+			continue
+		}
+		if victim.StealProtectedUntil != nil && now.Before(*victim.StealProtectedUntil) {
+			continue
+		}
+
+		stolen := victim.Score * PearlStealPercent / 100
+		if stolen <= 0 {
+			continue
+		}
+		victim.Score -= stolen
+		points += stolen
+
+		protectedUntil := now.Add(PearlStealProtection)
+		victim.StealProtectedUntil = &protectedUntil
+		mpGame.RuleState.LastSteal = &PearlSteal{
+			ThiefID:  player.ID,
+			VictimID: victim.ID,
+			Points:   stolen,
+			At:       now,
+		}
+	}
+	return points
+}
+
+// eliminationRules sets molds roaming the map, with another appearing every so often. A player
+// who touches a mold is out, and the last player or team standing wins. When time runs out, the
+// most points among the players still standing win.
+type eliminationRules struct {
+	firstToRules
+}
+
+func (eliminationRules) Name() string { return RuleSetElimination }
+
+func (eliminationRules) Description() string {
+	return "Dodge the roaming molds; the last one standing wins"
+}
+
+// Prepare gives games without a time limit the default one, so games always end
+func (eliminationRules) Prepare(settings *MultiplayerGameSettings) error {
+	if settings.TimeLimit <= 0 {
+		settings.TimeLimit = DefaultEliminationLimit
+	}
+	return nil
+}
+
+func (eliminationRules) Setup(mpGame *MultiplayerGame) {
+	molds := mpGame.GameState.SpawnMolds(len(mpGame.Players), MoldSpawnDistance)
+	mpGame.RuleState.Molds = boardPositions(molds)
+}
+
+func (eliminationRules) ScoreMove(mpGame *MultiplayerGame, player *MultiplayerPlayer, pearlScore int) int {
+	if mpGame.GameState.MoldAt(player.Position.Row, player.Position.Col) {
+		eliminate(player, time.Now())
+	}
+	return pearlScore
+}
+
+func (eliminationRules) TickInterval() time.Duration { return MoldStepInterval }
+
+// Tick adds a mold when one is due, moves every mold and knocks out the players they reach
+func (eliminationRules) Tick(mpGame *MultiplayerGame, now time.Time) bool {
+	state := &mpGame.RuleState
+	if state.NextMoldAt == nil || now.After(*state.NextMoldAt) {
+		if state.NextMoldAt != nil {
+			mpGame.GameState.SpawnMolds(1, MoldSpawnDistance)
+		}
+		nextMoldAt := now.Add(MoldSpawnInterval)
+		state.NextMoldAt = &nextMoldAt
+	}
+
+	state.Molds = boardPositions(mpGame.GameState.MoveMolds())
+	for _, player := range mpGame.Players {
+		if inGame(player) && onCells(player.Position, state.Molds) {
+			eliminate(player, now)
+		}
+	}
+	return true
+}
+
+// Decide ends the game once at most one player, or one team, is standing. A game where the last
+// players fall together has no winner.
+func (eliminationRules) Decide(mpGame *MultiplayerGame) (bool, *uint, int) {
+	var standing []*MultiplayerPlayer
+	for _, player := range mpGame.Players {
+		if inGame(player) {
+			standing = append(standing, player)
+		}
+	}
+
+	if mpGame.IsTeamGame() {
+		for _, player := range standing[min(1, len(standing)):] {
+			if player.Team != standing[0].Team {
+				return false, nil, 0
+			}
+		}
+		if len(standing) == 0 {
+			return true, nil, 0
+		}
+		return true, nil, standing[0].Team
+	}
+
+	switch len(standing) {
+	case 0:
+		return true, nil, 0
+	case 1:
+		winnerID := standing[0].ID
+		return true, &winnerID, 0
+	default:
+		return false, nil, 0
+	}
+}
+
+// eliminate knocks a player out of the game
+func eliminate(player *MultiplayerPlayer, now time.Time) {
+	player.Eliminated = true
+	player.EliminatedAt = &now
+}
+
+// pickHill picks the cells of a random word as the hill, other than the current hill when there
+// is another word to pick. Long words only give up HillMaxCells of their cells.
+func pickHill(textGrid [][]string, current []Position) []Position {
+	var words [][]Position
+	for row, line := range textGrid {
+		var word []Position
+		for col := 0; col <= len(line); col++ {
+			if col < len(line) && line[col] != " " && line[col] != "\t" {
+				word = append(word, Position{Row: row, Col: col})
+				continue
+			}
+			if len(word) >= HillMinCells {
+				words = append(words, word)
+			}
+			word = nil
+		}
+	}
+	if len(words) == 0 {
+		return current
+	}
+
+	for attempts := 0; attempts < 10; attempts++ {
+		word := words[rand.Intn(len(words))]
+		start := 0
+		if len(word) > HillMaxCells {
+			start = rand.Intn(len(word) - HillMaxCells + 1)
+		}
+		hill := word[start:min(len(word), start+HillMaxCells)]
+		if len(current) == 0 || hill[0] != current[0] || len(words) == 1 {
+			return append([]Position(nil), hill...)
+		}
+	}
+	return current
+}
+
+// onCells reports whether a position is one of the cells
+func onCells(position Position, cells []Position) bool {
+	for _, cell := range cells {
+		if cell == position {
+			return true
+		}
+	}
+	return false
+}
+
+// boardPositions converts positions on the game board into game positions
+func boardPositions(positions []game.Position) []Position {
+	converted := make([]Position, len(positions))
+	for i, position := range positions {
+		converted[i] = Position{Row: position.Row, Col: position.Col}
+	}
+	return converted
+}
@@ -0,0 +1,272 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"boba-vim/internal/utils"
+)
+
+// Rule set errors
+var (
+	ErrUnknownRuleSet = errors.New("unknown rule set")
+)
+
+// RuleSet decides how a multiplayer game is scored and won. Rule sets are registered by name with
+// RegisterRuleSet and picked with MultiplayerGameSettings.RuleSet; every method that takes a game
+// is called with the game lock held.
+type RuleSet interface {
+	// Name identifies the rule set in game settings and results
+	Name() string
+	// Description explains the rule set to players
+	Description() string
+	// Prepare checks the settings of a game about to be played with the rule set and fills in
+	// what the rule set needs, such as a time limit
+	Prepare(settings *MultiplayerGameSettings) error
+	// Setup readies the board of a new game
+	Setup(mpGame *MultiplayerGame)
+	// ScoreMove applies the effects of a player's move to where they stand now and returns the
+	// points the move earns them. pearlScore is the value of the pearl the move collected, if any.
+	ScoreMove(mpGame *MultiplayerGame, player *MultiplayerPlayer, pearlScore int) int
+	// TickInterval is how often Tick runs while the game is played; 0 means never
+	TickInterval() time.Duration
+	// Tick advances the game with time and reports whether it changed anything players can see
+	Tick(mpGame *MultiplayerGame, now time.Time) bool
+	// Decide reports whether the game is over after a move or tick, with its winner or winning team
+	Decide(mpGame *MultiplayerGame) (bool, *uint, int)
+	// TimeUp picks the winner or winning team of a game whose time ran out; neither for a tie
+	TimeUp(mpGame *MultiplayerGame) (*uint, int)
+	// Target is the cell a bot playing the game heads for
+	Target(mpGame *MultiplayerGame, player *MultiplayerPlayer) (Position, bool)
+}
+
+// RuleState is what rule sets keep about a game between moves. It's sent to players with every
+// game update.
+type RuleState struct {
+	Zone           []Position  `json:"zone,omitempty"`             // Cells of the hill in king of the hill
+	ZoneHolder     uint        `json:"zone_holder,omitempty"`      // Player scoring on the hill; 0 when nobody is
+	ZoneHolderTeam int         `json:"zone_holder_team,omitempty"` // Team scoring on the hill in a team game
+	ZoneContested  bool        `json:"zone_contested,omitempty"`   // Rival players share the hill and nobody scores
+	ZoneMovesAt    *time.Time  `json:"zone_moves_at,omitempty"`
+	Molds          []Position  `json:"molds,omitempty"` // Molds roaming the map in elimination
+	NextMoldAt     *time.Time  `json:"next_mold_at,omitempty"`
+	LastSteal      *PearlSteal `json:"last_steal,omitempty"` // Latest steal in pearl steal
+}
+
+// PearlSteal is a player taking points from an opponent by landing on them
+type PearlSteal struct {
+	ThiefID  uint      `json:"thief_id"`
+	VictimID uint      `json:"victim_id"`
+	Points   int       `json:"points"`
+	At       time.Time `json:"at"`
+}
+
+// RuleSetSummary describes a rule set to players
+type RuleSetSummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var (
+	ruleSets     = map[string]RuleSet{}
+	ruleSetOrder []string
+)
+
+// RegisterRuleSet adds a rule set players can pick. Like movement.Register it is meant to be
+// called from init functions, and it panics when a name is registered twice.
+func RegisterRuleSet(rules RuleSet) {
+	name := rules.Name()
+	if _, exists := ruleSets[name]; exists {
+		panic(fmt.Sprintf("game: rule set %q registered twice", name))
+	}
+	ruleSets[name] = rules
+	ruleSetOrder = append(ruleSetOrder, name)
+}
+
+// GetRuleSet returns the rule set registered under a name. An empty name is the default rule set.
+func GetRuleSet(name string) (RuleSet, bool) {
+	if name == "" {
+		name = DefaultRuleSet
+	}
+	rules, exists := ruleSets[name]
+	return rules, exists
+}
+
+// RuleSets describes every registered rule set in registration order
+func RuleSets() []RuleSetSummary {
+	summaries := make([]RuleSetSummary, len(ruleSetOrder))
+	for i, name := range ruleSetOrder {
+		summaries[i] = RuleSetSummary{Name: name, Description: ruleSets[name].Description()}
+	}
+	return summaries
+}
+
+// PrepareRuleSet checks settings against their rule set and fills in what the rule set needs.
+// An empty rule set becomes the default.
+func PrepareRuleSet(settings *MultiplayerGameSettings) error {
+	if settings.RuleSet == "" {
+		settings.RuleSet = DefaultRuleSet
+	}
+	rules, exists := GetRuleSet(settings.RuleSet)
+	if !exists {
+		return ErrUnknownRuleSet
+	}
+	return rules.Prepare(settings)
+}
+
+// rules returns the rule set the game is played with. Games restored with a rule set this
+// instance doesn't know are played with the default rules.
+func (mpGame *MultiplayerGame) rules() RuleSet {
+	if rules, exists := GetRuleSet(mpGame.Settings.RuleSet); exists {
+		return rules
+	}
+	rules, _ := GetRuleSet(DefaultRuleSet)
+	return rules
+}
+
+// decide ends the game if its rules say it's over. The caller must hold the game lock.
+func (mpGame *MultiplayerGame) decide(rules RuleSet) bool {
+	done, winner, winningTeam := rules.Decide(mpGame)
+	if !done {
+		return false
+	}
+	mpGame.IsCompleted = true
+	mpGame.Winner = winner
+	mpGame.WinningTeam = winningTeam
+	return true
+}
+
+// ruleStateData returns a copy of the game's rule state that can be sent after the game lock is
+// released. The caller must hold the game lock.
+func (mpGame *MultiplayerGame) ruleStateData() *RuleState {
+	state := mpGame.RuleState
+	state.Zone = append([]Position(nil), state.Zone...)
+	state.Molds = append([]Position(nil), state.Molds...)
+	if state.LastSteal != nil {
+		steal := *state.LastSteal
+		state.LastSteal = &steal
+	}
+	return &state
+}
+
+// startRuleTicks starts ticking the rules of a game whose countdown has finished, for rule sets
+// that change the game with time. Games already ticking are left alone.
+func (mgs *MultiplayerGameService) startRuleTicks(mpGame *MultiplayerGame) {
+	mpGame.mutex.Lock()
+	rules := mpGame.rules()
+	interval := rules.TickInterval()
+	if interval <= 0 || mpGame.ruleTicking || mpGame.IsCompleted {
+		mpGame.mutex.Unlock()
+		return
+	}
+	mpGame.ruleTicking = true
+	mpGame.mutex.Unlock()
+
+	go mgs.runRuleTicks(mpGame, rules, interval)
+}
+
+// runRuleTicks ticks a game's rules until it ends or leaves this instance. Paused games don't tick.
+func (mgs *MultiplayerGameService) runRuleTicks(mpGame *MultiplayerGame, rules RuleSet, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-mgs.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if mgs.GetGameByID(mpGame.ID) != mpGame {
+				return
+			}
+
+			mpGame.mutex.Lock()
+			if mpGame.IsCompleted {
+				mpGame.mutex.Unlock()
+				return
+			}
+			if mpGame.CountdownActive || mpGame.Paused {
+				mpGame.mutex.Unlock()
+				continue
+			}
+			changed := rules.Tick(mpGame, now)
+			completed := changed && mpGame.decide(rules)
+			mpGame.mutex.Unlock()
+
+			if changed {
+				mgs.sendGameUpdate(mpGame)
+			}
+			if completed {
+				utils.Info("Multiplayer game %s was decided by its %s rules", mpGame.ID, rules.Name())
+				mgs.handleGameCompletion(mpGame, "normal")
+				return
+			}
+		}
+	}
+}
+
+// leaderByScore returns the winner by points among the players who can still win, or the
+// winning team by points among teams with such a player in a team game. A shared top score
+// returns neither. The caller must hold the game lock.
+func leaderByScore(mpGame *MultiplayerGame, contending func(player *MultiplayerPlayer) bool) (*uint, int) {
+	if mpGame.IsTeamGame() {
+		leadingTeam, leadingScore, tied := 0, 0, false
+		for _, team := range []int{TeamOne, TeamTwo} {
+			inGame := false
+			for _, player := range mpGame.Players {
+				if player.Team == team && contending(player) {
+					inGame = true
+					break
+				}
+			}
+			if !inGame {
+				continue
+			}
+
+			score := mpGame.TeamScore(team)
+			switch {
+			case leadingTeam == 0 || score > leadingScore:
+				leadingTeam, leadingScore, tied = team, score, false
+			case score == leadingScore:
+				tied = true
+			}
+		}
+		if tied {
+			return nil, 0
+		}
+		return nil, leadingTeam
+	}
+
+	var leader *MultiplayerPlayer
+	tied := false
+	for _, player := range mpGame.Players {
+		if !contending(player) {
+			continue
+		}
+		switch {
+		case leader == nil || player.Score > leader.Score:
+			leader = player
+			tied = false
+		case player.Score == leader.Score:
+			tied = true
+		}
+	}
+	if leader == nil || tied {
+		return nil, 0
+	}
+	winnerID := leader.ID
+	return &winnerID, 0
+}
+
+// inGame reports whether a player is still playing: they haven't left or been eliminated
+func inGame(player *MultiplayerPlayer) bool {
+	return !player.Left && !player.Eliminated
+}
+
+// rivals reports whether two players play against each other
+func rivals(mpGame *MultiplayerGame, a, b *MultiplayerPlayer) bool {
+	if a.ID == b.ID {
+		return false
+	}
+	return !mpGame.IsTeamGame() || a.Team != b.Team
+}
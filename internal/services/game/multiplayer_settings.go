@@ -0,0 +1,101 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"boba-vim/internal/constant"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+)
+
+// DefaultMultiplayerTargetScore is the score that wins a matchmade multiplayer game
+const DefaultMultiplayerTargetScore = 1300
+
+// MultiplayerGameSettings are the rules a multiplayer game is played with
+type MultiplayerGameSettings struct {
+	MapID       int                         // 0 picks a random map
+	TargetScore int                         // Score that wins the game
+	TimeLimit   time.Duration               // 0 means no time limit
+	Ranked      bool                        // Whether the result counts toward multiplayer stats
+	Unlisted    bool                        // Kept out of the live game list; spectators need the game ID
+	Practice    bool                        // Played against server-side bots; the result isn't recorded
+	MapVeto     []matchmaking.MapVetoAction // Bans and picks that chose the map, recorded with the result
+	SeriesID    string                      // Series the game belongs to; empty for a single game
+	SeriesGame  int                         // Number of the game within its series
+	RuleSet     string                      // Registered rule set deciding how the game is scored and won
+}
+
+// DefaultMultiplayerGameSettings returns the rules used by matchmade games
+func DefaultMultiplayerGameSettings() MultiplayerGameSettings {
+	return MultiplayerGameSettings{
+		TargetScore: DefaultMultiplayerTargetScore,
+		Ranked:      true,
+	}
+}
+
+// selectMap returns the map chosen in the settings, or a random map when none was chosen
+func (settings MultiplayerGameSettings) selectMap() (constant.Map, error) {
+	if settings.MapID == 0 {
+		return constant.GAME_MAPS[rand.Intn(len(constant.GAME_MAPS))], nil
+	}
+
+	selectedMap := constant.GetMapByID(settings.MapID)
+	if selectedMap == nil {
+		return constant.Map{}, fmt.Errorf("map %d not found", settings.MapID)
+	}
+	return *selectedMap, nil
+}
+
+// addGameRulesData adds the game's rules to a game response. The caller must hold the game lock.
+func addGameRulesData(response map[string]interface{}, mpGame *MultiplayerGame) {
+	response["target_score"] = mpGame.Settings.TargetScore
+	response["time_limit_seconds"] = int(mpGame.Settings.TimeLimit.Seconds())
+	response["ranked"] = mpGame.Settings.Ranked
+	response["rule_set"] = mpGame.rules().Name()
+	response["rules"] = mpGame.ruleStateData()
+	if mpGame.EndsAt != nil {
+		response["ends_at"] = mpGame.EndsAt
+	}
+	if mpGame.Series != nil {
+		response["series"] = mpGame.Series.clone()
+	}
+}
+
+// startTimeLimit starts the game clock once the countdown has finished.
+// Games without a time limit, or whose clock is already running, are left alone.
+func (mgs *MultiplayerGameService) startTimeLimit(mpGame *MultiplayerGame) {
+	mpGame.mutex.Lock()
+	defer mpGame.mutex.Unlock()
+
+	if mpGame.Settings.TimeLimit <= 0 || mpGame.timeLimitTimer != nil || mpGame.IsCompleted {
+		return
+	}
+
+	endsAt := time.Now().Add(mpGame.Settings.TimeLimit)
+	mpGame.EndsAt = &endsAt
+	mpGame.timeLimitTimer = time.AfterFunc(mpGame.Settings.TimeLimit, func() {
+		mgs.endGameOnTimeLimit(mpGame)
+	})
+
+	utils.Debug("Game %s ends at %s", mpGame.ID, endsAt.Format(time.RFC3339))
+}
+
+// endGameOnTimeLimit completes a game whose time ran out, won by whoever its rule set picks. Most
+// rule sets pick the highest scoring player still in the game, or the highest scoring team in a
+// team game; a shared top score ends the game without a winner.
+func (mgs *MultiplayerGameService) endGameOnTimeLimit(mpGame *MultiplayerGame) {
+	mpGame.mutex.Lock()
+	if mpGame.IsCompleted {
+		mpGame.mutex.Unlock()
+		return
+	}
+
+	mpGame.IsCompleted = true
+	mpGame.Winner, mpGame.WinningTeam = mpGame.rules().TimeUp(mpGame)
+	mpGame.mutex.Unlock()
+
+	utils.Info("Multiplayer game %s reached its time limit", mpGame.ID)
+	mgs.handleGameCompletion(mpGame, "timeout")
+}
@@ -0,0 +1,382 @@
+package game
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"boba-vim/internal/utils"
+	"boba-vim/internal/wsproto"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Constants for spectating
+const (
+	MaxSpectatorsPerGame  = 100
+	MaxSpectatorDelay     = 30 * time.Second
+	spectatorQueueSize    = 64   // Frames buffered per spectator; a slow spectator drops frames rather than slowing the game
+	maxPendingFrames      = 1024 // Frames held back by a spectator's delay
+	spectatorPingInterval = 30 * time.Second
+	spectatorWriteTimeout = 5 * time.Second
+)
+
+// Spectator errors
+var (
+	ErrSpectateGameNotFound  = errors.New("game not found")
+	ErrSpectateGameCompleted = errors.New("game is already completed")
+	ErrSpectateOwnGame       = errors.New("players cannot spectate their own game")
+	ErrSpectatorsFull        = errors.New("too many spectators in this game")
+	ErrInvalidSpectatorDelay = errors.New("spectator delay must be between 0 and 30 seconds")
+)
+
+// Spectator is a read-only viewer of a multiplayer game. The service owns all writes to its
+// connection; the caller only reads from it to notice when the viewer goes away.
+type Spectator struct {
+	ID        string
+	ViewerID  uint // 0 for guests
+	delay     time.Duration
+	conn      *websocket.Conn
+	protocol  wsproto.Protocol // Encoding and schema version the viewer negotiated
+	frames    chan spectatorFrame
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// spectatorFrame is a message waiting to be sent to a spectator. It is encoded once per encoding
+// for all the spectators it goes to.
+type spectatorFrame struct {
+	message *wsproto.Message
+	queued  time.Time
+}
+
+// LiveGameSummary describes a running game that can be watched
+type LiveGameSummary struct {
+	GameID         string           `json:"game_id"`
+	Mode           string           `json:"mode"`
+	MapID          int              `json:"map_id"`
+	MapName        string           `json:"map_name"`
+	Players        []LiveGamePlayer `json:"players"`
+	SpectatorCount int              `json:"spectator_count"`
+	TargetScore    int              `json:"target_score"`
+	RuleSet        string           `json:"rule_set"`
+	Ranked         bool             `json:"ranked"`
+	StartedAt      time.Time        `json:"started_at"`
+	EndsAt         *time.Time       `json:"ends_at,omitempty"`
+}
+
+// LiveGamePlayer is a participant shown in the live game list
+type LiveGamePlayer struct {
+	Username  string `json:"username"`
+	Character string `json:"character"`
+	Score     int    `json:"score"`
+	Left      bool   `json:"left,omitempty"`
+}
+
+// GetLiveGames returns the running games listed for spectators, most watched first
+func (mgs *MultiplayerGameService) GetLiveGames() []LiveGameSummary {
+	mgs.gamesMutex.RLock()
+	games := make([]*MultiplayerGame, 0, len(mgs.activeGames))
+	for _, mpGame := range mgs.activeGames {
+		games = append(games, mpGame)
+	}
+	mgs.gamesMutex.RUnlock()
+
+	liveGames := make([]LiveGameSummary, 0, len(games))
+	for _, mpGame := range games {
+		mpGame.mutex.RLock()
+		if mpGame.IsCompleted || mpGame.Settings.Unlisted {
+			mpGame.mutex.RUnlock()
+			continue
+		}
+
+		players := make([]LiveGamePlayer, len(mpGame.Players))
+		for i, player := range mpGame.Players {
+			players[i] = LiveGamePlayer{
+				Username:  player.Username,
+				Character: player.Character,
+				Score:     player.Score,
+				Left:      player.Left,
+			}
+		}
+
+		liveGames = append(liveGames, LiveGameSummary{
+			GameID:         mpGame.ID,
+			Mode:           mpGame.Mode,
+			MapID:          mpGame.MapID,
+			MapName:        mpGame.GameMap.Name,
+			Players:        players,
+			SpectatorCount: mpGame.SpectatorCount(),
+			TargetScore:    mpGame.Settings.TargetScore,
+			RuleSet:        mpGame.rules().Name(),
+			Ranked:         mpGame.Settings.Ranked,
+			StartedAt:      mpGame.CreatedAt,
+			EndsAt:         mpGame.EndsAt,
+		})
+		mpGame.mutex.RUnlock()
+	}
+
+	sort.Slice(liveGames, func(i, j int) bool {
+		if liveGames[i].SpectatorCount != liveGames[j].SpectatorCount {
+			return liveGames[i].SpectatorCount > liveGames[j].SpectatorCount
+		}
+		return liveGames[i].StartedAt.After(liveGames[j].StartedAt)
+	})
+	return liveGames
+}
+
+// AddSpectator attaches a read-only viewer to a running game. Every message the spectator receives
+// is held back by delay. viewerID is 0 for guests.
+func (mgs *MultiplayerGameService) AddSpectator(gameID string, viewerID uint, conn *websocket.Conn, delay time.Duration) (*Spectator, error) {
+	if delay < 0 || delay > MaxSpectatorDelay {
+		return nil, ErrInvalidSpectatorDelay
+	}
+
+	mgs.gamesMutex.RLock()
+	mpGame, exists := mgs.activeGames[gameID]
+	mgs.gamesMutex.RUnlock()
+
+	if !exists {
+		return nil, ErrSpectateGameNotFound
+	}
+
+	mpGame.mutex.RLock()
+	completed := mpGame.IsCompleted
+	isPlayer := viewerID != 0 && mpGame.Player(viewerID) != nil
+	mpGame.mutex.RUnlock()
+
+	if completed {
+		return nil, ErrSpectateGameCompleted
+	}
+	if isPlayer {
+		return nil, ErrSpectateOwnGame
+	}
+
+	spectator := &Spectator{
+		ID:       uuid.New().String(),
+		ViewerID: viewerID,
+		delay:    delay,
+		conn:     conn,
+		protocol: wsproto.Negotiated(conn),
+		frames:   make(chan spectatorFrame, spectatorQueueSize),
+		done:     make(chan struct{}),
+	}
+
+	mpGame.spectatorsMutex.Lock()
+	if len(mpGame.spectators) >= MaxSpectatorsPerGame {
+		mpGame.spectatorsMutex.Unlock()
+		return nil, ErrSpectatorsFull
+	}
+	if mpGame.spectators == nil {
+		mpGame.spectators = make(map[string]*Spectator)
+	}
+	mpGame.spectators[spectator.ID] = spectator
+	mpGame.spectatorsMutex.Unlock()
+
+	go spectator.writeLoop()
+
+	// The first frame is the full game state, delayed like everything that follows
+	if state, err := mgs.getSpectatorState(mpGame); err == nil {
+		spectator.enqueue(MultiplayerGameMessage{
+			Type:      "spectate_start",
+			Data:      state,
+			Timestamp: time.Now(),
+		})
+	}
+
+	mgs.notifySpectatorCount(mpGame)
+	utils.Info("Spectator %s joined game %s (delay %s)", spectator.ID, gameID, delay)
+	return spectator, nil
+}
+
+// RemoveSpectator detaches a viewer from a game and stops writing to their connection
+func (mgs *MultiplayerGameService) RemoveSpectator(gameID string, spectator *Spectator) {
+	spectator.close()
+
+	mgs.gamesMutex.RLock()
+	mpGame, exists := mgs.activeGames[gameID]
+	mgs.gamesMutex.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	mpGame.spectatorsMutex.Lock()
+	_, watching := mpGame.spectators[spectator.ID]
+	delete(mpGame.spectators, spectator.ID)
+	mpGame.spectatorsMutex.Unlock()
+
+	if watching {
+		mgs.notifySpectatorCount(mpGame)
+		utils.Info("Spectator %s left game %s", spectator.ID, gameID)
+	}
+}
+
+// SpectatorCount returns how many viewers are watching the game
+func (mpGame *MultiplayerGame) SpectatorCount() int {
+	mpGame.spectatorsMutex.Lock()
+	defer mpGame.spectatorsMutex.Unlock()
+	return len(mpGame.spectators)
+}
+
+// getSpectatorState returns the full game state as seen by a spectator
+func (mgs *MultiplayerGameService) getSpectatorState(mpGame *MultiplayerGame) (map[string]interface{}, error) {
+	mpGame.mutex.RLock()
+	defer mpGame.mutex.RUnlock()
+
+	if mpGame.IsCompleted {
+		return nil, ErrSpectateGameCompleted
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"game_id": mpGame.ID,
+		"map": map[string]interface{}{
+			"id":      mpGame.MapID,
+			"content": mpGame.GameMap.TextPattern,
+			"name":    mpGame.GameMap.Name,
+		},
+		"text_grid":       mpGame.GameState.GetTextGrid(),
+		"game_map":        mpGame.GameState.GetGameMap(),
+		"pearl_position":  mpGame.GameState.GetPearlPosition(),
+		"is_completed":    mpGame.IsCompleted,
+		"winner":          mpGame.Winner,
+		"spectating":      true,
+		"spectator_count": mpGame.SpectatorCount(),
+	}
+	addPlayersData(response, mpGame)
+	addGameRulesData(response, mpGame)
+
+	return response, nil
+}
+
+// broadcastToSpectators encodes a message once and queues it for every spectator of the game
+func (mgs *MultiplayerGameService) broadcastToSpectators(mpGame *MultiplayerGame, message interface{}) {
+	mpGame.spectatorsMutex.Lock()
+	spectators := make([]*Spectator, 0, len(mpGame.spectators))
+	for _, spectator := range mpGame.spectators {
+		spectators = append(spectators, spectator)
+	}
+	mpGame.spectatorsMutex.Unlock()
+
+	if len(spectators) == 0 {
+		return
+	}
+
+	frame := newSpectatorFrame(message)
+	for _, spectator := range spectators {
+		spectator.push(frame)
+	}
+}
+
+// notifySpectatorCount tells players and spectators how many viewers are watching
+func (mgs *MultiplayerGameService) notifySpectatorCount(mpGame *MultiplayerGame) {
+	mpGame.mutex.RLock()
+	playerIDs := mpGame.ActivePlayerIDs()
+	mpGame.mutex.RUnlock()
+
+	message := MultiplayerGameMessage{
+		Type:      "spectator_count",
+		Data:      map[string]int{"count": mpGame.SpectatorCount()},
+		Timestamp: time.Now(),
+	}
+
+	mgs.wsManager.BroadcastToPlayers(playerIDs, message)
+	mgs.broadcastToSpectators(mpGame, message)
+}
+
+// closeSpectators disconnects every spectator of a game that is going away
+func (mgs *MultiplayerGameService) closeSpectators(mpGame *MultiplayerGame) {
+	mpGame.spectatorsMutex.Lock()
+	spectators := mpGame.spectators
+	mpGame.spectators = nil
+	mpGame.spectatorsMutex.Unlock()
+
+	for _, spectator := range spectators {
+		spectator.close()
+	}
+}
+
+// newSpectatorFrame wraps a message for spectators
+func newSpectatorFrame(message interface{}) spectatorFrame {
+	return spectatorFrame{message: wsproto.NewMessage(message), queued: time.Now()}
+}
+
+// enqueue queues a message for this spectator only
+func (s *Spectator) enqueue(message interface{}) {
+	s.push(newSpectatorFrame(message))
+}
+
+// push queues a frame without blocking. Game updates carry the full state, so a dropped frame is
+// made up for by the next one.
+func (s *Spectator) push(frame spectatorFrame) {
+	select {
+	case <-s.done:
+	case s.frames <- frame:
+	default:
+		utils.Debug("Spectator %s is falling behind, dropping a frame", s.ID)
+	}
+}
+
+// close stops the write loop. The connection itself is closed by whoever accepted it.
+func (s *Spectator) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// Done is closed once the spectator stops receiving messages
+func (s *Spectator) Done() <-chan struct{} {
+	return s.done
+}
+
+// writeLoop sends queued frames once their delay has passed and keeps the connection alive with pings
+func (s *Spectator) writeLoop() {
+	pingTicker := time.NewTicker(spectatorPingInterval)
+	defer pingTicker.Stop()
+
+	var pending []spectatorFrame
+	var release <-chan time.Time
+
+	for {
+		if release == nil && len(pending) > 0 {
+			release = time.After(time.Until(pending[0].queued.Add(s.delay)))
+		}
+
+		select {
+		case <-s.done:
+			return
+		case frame := <-s.frames:
+			if len(pending) >= maxPendingFrames {
+				utils.Debug("Spectator %s has too many delayed frames, dropping a frame", s.ID)
+				continue
+			}
+			pending = append(pending, frame)
+		case <-release:
+			release = nil
+			frame := pending[0]
+			pending = pending[1:]
+
+			prepared, err := frame.message.Prepared(s.protocol)
+			if err != nil {
+				utils.Error("Failed to encode message for spectator %s: %v", s.ID, err)
+				continue
+			}
+
+			s.conn.SetWriteDeadline(time.Now().Add(spectatorWriteTimeout))
+			if err := s.conn.WritePreparedMessage(prepared); err != nil {
+				utils.Debug("Failed to write to spectator %s: %v", s.ID, err)
+				s.close()
+				return
+			}
+		case <-pingTicker.C:
+			s.conn.SetWriteDeadline(time.Now().Add(spectatorWriteTimeout))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				s.close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// State sync errors
+var (
+	ErrResyncGameNotFound = errors.New("game not found")
+	ErrResyncNotInGame    = errors.New("player not in this game")
+)
+
+// Game state updates are versioned. Every update that changes what players see gets the next
+// version; players who asked for a resync are then sent only what changed as "game_delta"
+// messages, while other players keep getting the whole state as "game_update". A delta whose
+// base version isn't the one a client holds tells it an update was missed, so it asks for a
+// resync and is sent the whole state again as "game_resync".
+
+// GameStateDelta is what changed in a game's state from one version to the next. Fields left out
+// are unchanged; players are listed only when something about them changed. A delta whose
+// version equals its base version changes nothing and only confirms the version is current.
+type GameStateDelta struct {
+	Version        uint64             `json:"version"`
+	BaseVersion    uint64             `json:"base_version"`
+	Players        []PlayerUpdateData `json:"players,omitempty"`
+	Teams          []TeamUpdateData   `json:"teams,omitempty"`
+	PearlPosition  *Position          `json:"pearl_position,omitempty"`
+	GameState      string             `json:"game_state,omitempty"`
+	SpectatorCount *int               `json:"spectator_count,omitempty"`
+	Rules          *RuleState         `json:"rules,omitempty"`
+}
+
+// gameStateSync keeps the version of a game's state and the state last sent. Its lock is held
+// while an update is worked out and sent so players get versions in order; it is taken before the
+// game lock.
+type gameStateSync struct {
+	mutex        sync.Mutex
+	version      uint64
+	last         *GameUpdateData
+	deltaPlayers map[uint]bool // Players sent deltas rather than the whole state
+}
+
+// next works out the delta from the state last sent to the current one, moving to the next
+// version when anything changed. The caller must hold the sync lock.
+func (stateSync *gameStateSync) next(current GameUpdateData) GameStateDelta {
+	delta := GameStateDelta{BaseVersion: stateSync.version}
+	last := stateSync.last
+	changed := last == nil
+
+	for i, player := range current.Players {
+		if last == nil || i >= len(last.Players) || last.Players[i] != player {
+			delta.Players = append(delta.Players, player)
+			changed = true
+		}
+	}
+	if last == nil || !reflect.DeepEqual(last.Teams, current.Teams) {
+		delta.Teams = current.Teams
+		changed = true
+	}
+	if last == nil || last.PearlPosition != current.PearlPosition {
+		pearlPosition := current.PearlPosition
+		delta.PearlPosition = &pearlPosition
+		changed = true
+	}
+	if last == nil || last.GameState != current.GameState {
+		delta.GameState = current.GameState
+		changed = true
+	}
+	if last == nil || last.SpectatorCount != current.SpectatorCount {
+		spectatorCount := current.SpectatorCount
+		delta.SpectatorCount = &spectatorCount
+		changed = true
+	}
+	if last == nil || !reflect.DeepEqual(last.Rules, current.Rules) {
+		delta.Rules = current.Rules
+		changed = true
+	}
+
+	if changed {
+		stateSync.version++
+	}
+	delta.Version = stateSync.version
+	stateSync.last = &current
+	return delta
+}
+
+// ResyncGameState sends a player the whole state of a game with its version, and from then on
+// only what changes. Clients ask for it once connected, and again whenever they miss a version.
+func (mgs *MultiplayerGameService) ResyncGameState(gameID string, playerID uint) error {
+	mpGame, owner := mgs.locateGame(gameID)
+	if owner != "" {
+		return mgs.node.Call(owner, clusterMethodResync, gameCall{GameID: gameID, PlayerID: playerID}, nil)
+	}
+	if mpGame == nil {
+		return ErrResyncGameNotFound
+	}
+
+	stateSync := &mpGame.stateSync
+	stateSync.mutex.Lock()
+	defer stateSync.mutex.Unlock()
+
+	mpGame.mutex.RLock()
+	if mpGame.Player(playerID) == nil {
+		mpGame.mutex.RUnlock()
+		return ErrResyncNotInGame
+	}
+	state := mgs.gameStateResponse(mpGame, playerID)
+	mpGame.mutex.RUnlock()
+
+	// The whole state is at least as new as the version last sent, so the deltas that follow
+	// bring it up to date
+	state["state_version"] = stateSync.version
+	if stateSync.deltaPlayers == nil {
+		stateSync.deltaPlayers = make(map[uint]bool)
+	}
+	stateSync.deltaPlayers[playerID] = true
+
+	return mgs.SendMessageToPlayer(playerID, "game_resync", state)
+}
+
+// stopDeltas sends a player the whole state again with every update, such as when they connect
+// from a new tab that may not follow versions
+func (mgs *MultiplayerGameService) stopDeltas(mpGame *MultiplayerGame, playerID uint) {
+	stateSync := &mpGame.stateSync
+	stateSync.mutex.Lock()
+	defer stateSync.mutex.Unlock()
+
+	delete(stateSync.deltaPlayers, playerID)
+}
+
+// sendStateUpdate sends the current state of a game to its players and spectators: the next
+// delta to the players following versions, and the whole state to everyone else
+func (mgs *MultiplayerGameService) sendStateUpdate(mpGame *MultiplayerGame, gameStateData *GameUpdateData) {
+	stateSync := &mpGame.stateSync
+	stateSync.mutex.Lock()
+	defer stateSync.mutex.Unlock()
+
+	mpGame.mutex.RLock()
+	*gameStateData = mgs.getGameStateData(mpGame)
+	playerIDs := mpGame.ActivePlayerIDs()
+	mpGame.mutex.RUnlock()
+
+	delta := stateSync.next(*gameStateData)
+	gameStateData.Version = delta.Version
+
+	var deltaIDs, fullIDs []uint
+	for _, playerID := range playerIDs {
+		if stateSync.deltaPlayers[playerID] {
+			deltaIDs = append(deltaIDs, playerID)
+		} else {
+			fullIDs = append(fullIDs, playerID)
+		}
+	}
+
+	now := time.Now()
+	if len(deltaIDs) > 0 {
+		mgs.wsManager.BroadcastToPlayers(deltaIDs, MultiplayerGameMessage{
+			Type:      "game_delta",
+			Data:      delta,
+			Timestamp: now,
+		})
+	}
+
+	message := MultiplayerGameMessage{
+		Type:      "game_update",
+		Data:      *gameStateData,
+		Timestamp: now,
+	}
+	if len(fullIDs) > 0 {
+		mgs.wsManager.BroadcastToPlayers(fullIDs, message)
+	}
+	mgs.broadcastToSpectators(mpGame, message)
+}
@@ -0,0 +1,171 @@
+package game
+
+import (
+	"sort"
+
+	"boba-vim/internal/services/matchmaking"
+)
+
+// Teams of a team game
+const (
+	TeamOne = 1
+	TeamTwo = 2
+)
+
+// TeamUpdateData is one team's entry in a game state update
+type TeamUpdateData struct {
+	Team      int    `json:"team"`
+	Score     int    `json:"score"`
+	PlayerIDs []uint `json:"player_ids"`
+}
+
+// IsTeamGame reports whether the game is played by teams
+func (mpGame *MultiplayerGame) IsTeamGame() bool {
+	return mpGame.Mode == matchmaking.GameModeTeams
+}
+
+// TeamScore returns the combined score of a team's players, including players who left
+func (mpGame *MultiplayerGame) TeamScore(team int) int {
+	score := 0
+	for _, player := range mpGame.Players {
+		if player.Team == team {
+			score += player.Score
+		}
+	}
+	return score
+}
+
+// Teammates returns the other players on a player's team
+func (mpGame *MultiplayerGame) Teammates(playerID uint) []*MultiplayerPlayer {
+	player := mpGame.Player(playerID)
+	if player == nil || player.Team == 0 {
+		return nil
+	}
+
+	var teammates []*MultiplayerPlayer
+	for _, other := range mpGame.Players {
+		if other.ID != playerID && other.Team == player.Team {
+			teammates = append(teammates, other)
+		}
+	}
+	return teammates
+}
+
+// ActiveTeams returns the teams that still have a player in the game, in team order
+func (mpGame *MultiplayerGame) ActiveTeams() []int {
+	var teams []int
+	for _, team := range []int{TeamOne, TeamTwo} {
+		for _, player := range mpGame.Players {
+			if player.Team == team && !player.Left {
+				teams = append(teams, team)
+				break
+			}
+		}
+	}
+	return teams
+}
+
+// teamsData returns every team's score and players. The caller must hold the game lock.
+func (mpGame *MultiplayerGame) teamsData() []TeamUpdateData {
+	if !mpGame.IsTeamGame() {
+		return nil
+	}
+
+	teams := []TeamUpdateData{{Team: TeamOne}, {Team: TeamTwo}}
+	for _, player := range mpGame.Players {
+		if player.Team < TeamOne || player.Team > TeamTwo {
+			continue
+		}
+		team := &teams[player.Team-1]
+		team.Score += player.Score
+		team.PlayerIDs = append(team.PlayerIDs, player.ID)
+	}
+	return teams
+}
+
+// validateTeams checks that the participants of a team game form two full teams
+func validateTeams(participants []matchmaking.MatchParticipant) bool {
+	sizes := make(map[int]int, 2)
+	for _, participant := range participants {
+		if participant.Team != TeamOne && participant.Team != TeamTwo {
+			return false
+		}
+		sizes[participant.Team]++
+	}
+	return sizes[TeamOne] == matchmaking.TeamSize && sizes[TeamTwo] == matchmaking.TeamSize
+}
+
+// rankParticipants orders the participants of a completed game into placements, by team in a
+// team game
+func rankParticipants(mode string, players []MultiplayerPlayer, winnerID *uint, winningTeam int) []PlayerPlacement {
+	if mode == matchmaking.GameModeTeams {
+		return rankTeams(players, winningTeam)
+	}
+	return rankPlayers(players, winnerID)
+}
+
+// rankTeams orders the participants of a team game into placements. Teammates share their team's
+// placement: the winning team first, then teams with a player still in the game by team score,
+// then teams whose players all left. Teams with equal standing share a placement.
+func rankTeams(players []MultiplayerPlayer, winningTeam int) []PlayerPlacement {
+	scores := make(map[int]int)
+	present := make(map[int]bool)
+	for _, player := range players {
+		scores[player.Team] += player.Score
+		if !player.Left {
+			present[player.Team] = true
+		}
+	}
+
+	ahead := func(a, b int) bool {
+		if (a == winningTeam) != (b == winningTeam) {
+			return a == winningTeam
+		}
+		if present[a] != present[b] {
+			return present[a]
+		}
+		return scores[a] > scores[b]
+	}
+
+	teams := []int{TeamOne, TeamTwo}
+	sort.SliceStable(teams, func(i, j int) bool {
+		return ahead(teams[i], teams[j])
+	})
+
+	teamPlacements := make(map[int]int, len(teams))
+	for i, team := range teams {
+		teamPlacements[team] = i + 1
+		if i > 0 && !ahead(teams[i-1], team) {
+			teamPlacements[team] = teamPlacements[teams[i-1]]
+		}
+	}
+
+	ranked := make([]MultiplayerPlayer, len(players))
+	copy(ranked, players)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if teamPlacements[a.Team] != teamPlacements[b.Team] {
+			return teamPlacements[a.Team] < teamPlacements[b.Team]
+		}
+		if a.Team != b.Team {
+			return a.Team < b.Team
+		}
+		return a.Score > b.Score
+	})
+
+	placements := make([]PlayerPlacement, len(ranked))
+	for i, player := range ranked {
+		placements[i] = PlayerPlacement{
+			PlayerID:  player.ID,
+			Username:  player.Username,
+			Character: player.Character,
+			Seat:      player.Seat,
+			Score:     player.Score,
+			Placement: teamPlacements[player.Team],
+			Left:      player.Left,
+			Team:      player.Team,
+			TeamScore: scores[player.Team],
+		}
+	}
+	return placements
+}
@@ -10,6 +10,7 @@ type GameService struct {
 	Session     *SessionService
 	Movement    *MovementService
 	Leaderboard *LeaderboardService
+	VimOptions  *VimOptionsService
 	db          *gorm.DB
 }
 
@@ -19,13 +20,14 @@ func NewGameService(db *gorm.DB, cfg *config.Config, pearlMoldService *PearlMold
 		Session:     NewSessionService(db, cfg),
 		Movement:    NewMovementService(db, cfg, pearlMoldService),
 		Leaderboard: NewLeaderboardService(db, cfg),
+		VimOptions:  NewVimOptionsService(db),
 		db:          db,
 	}
 }
 
 // ProcessMove processes a move with full concurrency control
-func (gs *GameService) ProcessMove(sessionToken, direction string, count int, hasExplicitCount bool) (map[string]interface{}, error) {
-	return gs.Movement.ProcessMove(sessionToken, direction, count, hasExplicitCount)
+func (gs *GameService) ProcessMove(sessionToken, direction string, count int, hasExplicitCount bool, knownVersion int64) (map[string]interface{}, error) {
+	return gs.Movement.ProcessMove(sessionToken, direction, count, hasExplicitCount, knownVersion)
 }
 
 // GetGameState returns current game state
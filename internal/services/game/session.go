@@ -85,9 +85,10 @@ func (ss *SessionService) buildGameStateResponse(gameSession *models.GameSession
 	}
 
 	result := map[string]interface{}{
-		"success":   true,
-		"text_grid": gameSession.GetTextGrid(),
-		"game_map":  gameSession.GetGameMap(),
+		"success":       true,
+		"text_grid":     gameSession.GetTextGrid(),
+		"game_map":      gameSession.GetGameMap(),
+		"state_version": gameSession.StateVersion,
 		"player_pos": map[string]int{
 			"row": gameSession.CurrentRow,
 			"col": gameSession.CurrentCol,
@@ -100,6 +101,9 @@ func (ss *SessionService) buildGameStateResponse(gameSession *models.GameSession
 		"pearls_collected":   gameSession.PearlsCollected,
 		"total_moves":        gameSession.TotalMoves,
 		"map_id":             gameSession.MapID,
+		"wrap":               gameSession.WrapLines,
+		"text_width":         gameSession.TextWidth,
+		"vim_options":        gameSession.VimOptions,
 	}
 
 	// Add map information if found
@@ -140,6 +144,32 @@ func (ss *SessionService) StartGameWithMap(username interface{}, selectedCharact
 	// Initialize game data with specific map
 	gameData := game.InitializeGameSessionWithMap(mapID)
 
+	return ss.startGame(username, selectedCharacter, mapID, gameData, nil)
+}
+
+// StartChallengeGame starts a player's game of a challenge. The game is laid out from the
+// challenge's pearl seed, so both players of the challenge get the same pearls.
+func (ss *SessionService) StartChallengeGame(username, selectedCharacter string, mapID int, seed int64, challengeID uint) (map[string]interface{}, error) {
+	if selectedCharacter == "" {
+		selectedCharacter = "boba"
+	}
+
+	gameData := game.InitializeSeededGameSession(mapID, seed)
+
+	result, err := ss.startGame(username, selectedCharacter, mapID, gameData, func(gameSession *models.GameSession) {
+		gameSession.ChallengeID = &challengeID
+		gameSession.PearlSeed = &seed
+	})
+	if err != nil {
+		return nil, err
+	}
+	result["challenge_id"] = challengeID
+	return result, nil
+}
+
+// startGame creates the session of a new game from its initial game data. prepare, when set,
+// adjusts the session before it is saved.
+func (ss *SessionService) startGame(username interface{}, selectedCharacter string, mapID int, gameData map[string]interface{}, prepare func(gameSession *models.GameSession)) (map[string]interface{}, error) {
 	var gameSession *models.GameSession
 
 	// Convert username to string if it's not nil
@@ -160,6 +190,10 @@ func (ss *SessionService) StartGameWithMap(username interface{}, selectedCharact
 		}
 	}
 
+	if prepare != nil {
+		prepare(gameSession)
+	}
+
 	if err := ss.db.Create(gameSession).Error; err != nil {
 		return nil, err
 	}
@@ -177,6 +211,7 @@ func (ss *SessionService) StartGameWithMap(username interface{}, selectedCharact
 		"game_data": map[string]interface{}{
 			"text_grid":          gameData["text_grid"],
 			"game_map":           gameSession.GetGameMap(),
+			"state_version":      gameSession.StateVersion,
 			"player_pos":         map[string]int{"row": gameSession.CurrentRow, "col": gameSession.CurrentCol},
 			"score":              gameSession.CurrentScore,
 			"is_completed":       gameSession.IsCompleted,
@@ -243,10 +278,17 @@ func (ss *SessionService) createRegisteredUserSessionWithMap(username, selectedC
 			"end_time":  &now,
 		})
 
+	// Carry the player's saved vim options into the new session
+	vimOptions, err := NewVimOptionsService(ss.db).GetPlayerOptions(player.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create new game session for registered user
 	gameSession := &models.GameSession{
 		PlayerID:          &player.ID,
 		SelectedCharacter: selectedCharacter,
+		VimOptions:        vimOptions,
 		MapID:             mapID,
 		CurrentScore:      0,
 		CurrentRow:        gameData["player_pos"].(map[string]int)["row"],
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"errors"
+
+	"boba-vim/internal/game"
+	"boba-vim/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// VimOptionsService manages the per-player and per-session vim options used by the movement engine
+type VimOptionsService struct {
+	db *gorm.DB
+}
+
+// NewVimOptionsService creates a new vim options service
+func NewVimOptionsService(db *gorm.DB) *VimOptionsService {
+	return &VimOptionsService{db: db}
+}
+
+// GetPlayerOptions returns the player's saved vim options, or vim's defaults when none are saved
+func (vs *VimOptionsService) GetPlayerOptions(playerID uint) (models.VimOptions, error) {
+	var preferences models.PlayerVimOptions
+	if err := vs.db.Where("player_id = ?", playerID).First(&preferences).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DefaultVimOptions(), nil
+		}
+		return models.VimOptions{}, err
+	}
+	return preferences.VimOptions, nil
+}
+
+// SavePlayerOptions stores the player's vim options and applies them to the player's active session
+func (vs *VimOptionsService) SavePlayerOptions(playerID uint, options models.VimOptions) error {
+	if err := ValidateVimOptions(options); err != nil {
+		return err
+	}
+
+	return vs.db.Transaction(func(tx *gorm.DB) error {
+		var preferences models.PlayerVimOptions
+		err := tx.Where("player_id = ?", playerID).First(&preferences).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		preferences.PlayerID = playerID
+		preferences.VimOptions = options
+		if err := tx.Save(&preferences).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.GameSession{}).
+			Where("player_id = ? AND is_active = ?", playerID, true).
+			Updates(vimOptionColumns(options)).Error
+	})
+}
+
+// UpdateSessionOptions changes the vim options of a single game session
+func (vs *VimOptionsService) UpdateSessionOptions(sessionToken string, options models.VimOptions) error {
+	if err := ValidateVimOptions(options); err != nil {
+		return err
+	}
+
+	return vs.db.Model(&models.GameSession{}).
+		Where("session_token = ? AND is_active = ?", sessionToken, true).
+		Updates(vimOptionColumns(options)).Error
+}
+
+// ValidateVimOptions checks that the options hold values vim would accept
+func ValidateVimOptions(options models.VimOptions) error {
+	return MovementOptions(options, false, 0).Validate()
+}
+
+// MovementOptions converts stored vim options and display settings into movement engine options
+func MovementOptions(options models.VimOptions, wrap bool, textWidth int) game.MovementOptions {
+	// Sessions created before vim options existed behave like vim's defaults
+	if !options.IsSet() {
+		options = models.DefaultVimOptions()
+	}
+
+	return game.MovementOptions{
+		Wrap:        wrap,
+		TextWidth:   textWidth,
+		WhichWrap:   options.WhichWrap,
+		StartOfLine: options.StartOfLine,
+		VirtualEdit: options.VirtualEdit,
+		IsKeyword:   options.IsKeyword,
+		WrapScan:    options.WrapScan,
+		TabStop:     options.TabStop,
+	}
+}
+
+// vimOptionColumns maps vim options to the embedded game session columns, including false values
+func vimOptionColumns(options models.VimOptions) map[string]interface{} {
+	return map[string]interface{}{
+		"vim_which_wrap":    options.WhichWrap,
+		"vim_start_of_line": options.StartOfLine,
+		"vim_virtual_edit":  options.VirtualEdit,
+		"vim_is_keyword":    options.IsKeyword,
+		"vim_wrap_scan":     options.WrapScan,
+		"vim_tab_stop":      options.TabStop,
+	}
+}
@@ -0,0 +1,576 @@
+package lobby
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"boba-vim/internal/constant"
+	gameService "boba-vim/internal/services/game"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Lobby is a private room that players join with its code before the host starts the match
+type Lobby struct {
+	Code         string
+	HostID       uint
+	Members      []*LobbyMember // In join order; the order is the seat order of the match
+	Settings     LobbySettings
+	Status       LobbyStatus
+	MatchID      string
+	CreatedAt    time.Time
+	LastActivity time.Time
+}
+
+// LobbyService manages private lobbies. Lobbies only live in memory; the match they start is
+// recorded like any other multiplayer game.
+type LobbyService struct {
+	baseURL     string
+	gameStarter GameStarter
+	notifier    Notifier
+	lobbies     map[string]*Lobby
+	playerLobby map[uint]string // Code of the open lobby each player is in
+	mu          sync.Mutex
+	stopCleanup chan struct{}
+	cleanupOnce sync.Once
+}
+
+// lobbyNotification is a message queued while the service lock is held and sent after it is released
+type lobbyNotification struct {
+	playerIDs []uint
+	message   matchmaking.WebSocketMessage
+}
+
+// NewLobbyService creates a new lobby service
+func NewLobbyService(baseURL string, gameStarter GameStarter, notifier Notifier) *LobbyService {
+	ls := &LobbyService{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		gameStarter: gameStarter,
+		notifier:    notifier,
+		lobbies:     make(map[string]*Lobby),
+		playerLobby: make(map[uint]string),
+		stopCleanup: make(chan struct{}),
+	}
+
+	go ls.cleanupRoutine()
+
+	return ls
+}
+
+// CreateLobby opens a new lobby hosted by the player
+func (ls *LobbyService) CreateLobby(hostID uint, username, character string, update LobbySettingsUpdate) (*LobbyData, error) {
+	settings, err := applySettingsUpdate(DefaultLobbySettings(), update, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if _, inLobby := ls.playerLobby[hostID]; inLobby {
+		return nil, ErrAlreadyInLobby
+	}
+
+	code, err := ls.generateCode()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lobby := &Lobby{
+		Code:   code,
+		HostID: hostID,
+		Members: []*LobbyMember{{
+			PlayerID:  hostID,
+			Username:  username,
+			Character: character,
+			JoinedAt:  now,
+		}},
+		Settings:     settings,
+		Status:       StatusOpen,
+		CreatedAt:    now,
+		LastActivity: now,
+	}
+
+	ls.lobbies[code] = lobby
+	ls.playerLobby[hostID] = code
+
+	utils.Info("Lobby %s created by %s", code, username)
+	return ls.lobbyData(lobby), nil
+}
+
+// GetLobby returns a lobby by its code
+func (ls *LobbyService) GetLobby(code string) (*LobbyData, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	lobby, exists := ls.lobbies[normalizeCode(code)]
+	if !exists {
+		return nil, ErrLobbyNotFound
+	}
+	return ls.lobbyData(lobby), nil
+}
+
+// JoinLobby adds a player to an open lobby
+func (ls *LobbyService) JoinLobby(code string, playerID uint, username, character string) (*LobbyData, error) {
+	ls.mu.Lock()
+
+	lobby, exists := ls.lobbies[normalizeCode(code)]
+	if !exists {
+		ls.mu.Unlock()
+		return nil, ErrLobbyNotFound
+	}
+	if lobby.Status != StatusOpen {
+		ls.mu.Unlock()
+		return nil, ErrLobbyNotOpen
+	}
+
+	// Joining the lobby the player is already in is a no-op
+	if lobbyCode, inLobby := ls.playerLobby[playerID]; inLobby {
+		ls.mu.Unlock()
+		if lobbyCode == lobby.Code {
+			return ls.GetLobby(lobby.Code)
+		}
+		return nil, ErrAlreadyInLobby
+	}
+
+	if len(lobby.Members) >= lobby.Settings.MaxPlayers {
+		ls.mu.Unlock()
+		return nil, ErrLobbyFull
+	}
+
+	lobby.Members = append(lobby.Members, &LobbyMember{
+		PlayerID:  playerID,
+		Username:  username,
+		Character: character,
+		JoinedAt:  time.Now(),
+	})
+	lobby.LastActivity = time.Now()
+	ls.playerLobby[playerID] = lobby.Code
+
+	data := ls.lobbyData(lobby)
+	notification := ls.updatedNotification(lobby, username+" joined the lobby")
+	ls.mu.Unlock()
+
+	ls.send(notification)
+	utils.Info("%s joined lobby %s", username, lobby.Code)
+	return data, nil
+}
+
+// LeaveLobby removes a player from a lobby. When the host leaves, the next player to have joined
+// becomes host; the lobby closes once it is empty.
+func (ls *LobbyService) LeaveLobby(code string, playerID uint) error {
+	ls.mu.Lock()
+
+	lobby, exists := ls.lobbies[normalizeCode(code)]
+	if !exists {
+		ls.mu.Unlock()
+		return ErrLobbyNotFound
+	}
+
+	member := ls.removeMember(lobby, playerID)
+	if member == nil {
+		ls.mu.Unlock()
+		return ErrNotLobbyMember
+	}
+
+	if len(lobby.Members) == 0 {
+		ls.closeLobby(lobby)
+		ls.mu.Unlock()
+		utils.Info("Lobby %s closed after its last player left", lobby.Code)
+		return nil
+	}
+
+	if lobby.HostID == playerID {
+		lobby.HostID = lobby.Members[0].PlayerID
+		utils.Info("Lobby %s host passed to %s", lobby.Code, lobby.Members[0].Username)
+	}
+
+	notification := ls.updatedNotification(lobby, member.Username+" left the lobby")
+	ls.mu.Unlock()
+
+	ls.send(notification)
+	return nil
+}
+
+// UpdateSettings changes the lobby's rules. Only the host can change them.
+func (ls *LobbyService) UpdateSettings(code string, playerID uint, update LobbySettingsUpdate) (*LobbyData, error) {
+	ls.mu.Lock()
+
+	lobby, err := ls.hostedOpenLobby(code, playerID)
+	if err != nil {
+		ls.mu.Unlock()
+		return nil, err
+	}
+
+	settings, err := applySettingsUpdate(lobby.Settings, update, len(lobby.Members))
+	if err != nil {
+		ls.mu.Unlock()
+		return nil, err
+	}
+
+	lobby.Settings = settings
+	lobby.LastActivity = time.Now()
+
+	data := ls.lobbyData(lobby)
+	notification := ls.updatedNotification(lobby, "Lobby settings updated")
+	ls.mu.Unlock()
+
+	ls.send(notification)
+	return data, nil
+}
+
+// KickMember removes a player from the lobby. Only the host can kick players.
+func (ls *LobbyService) KickMember(code string, hostID, memberID uint) (*LobbyData, error) {
+	if hostID == memberID {
+		return nil, ErrCannotKickHost
+	}
+
+	ls.mu.Lock()
+
+	lobby, err := ls.hostedOpenLobby(code, hostID)
+	if err != nil {
+		ls.mu.Unlock()
+		return nil, err
+	}
+
+	member := ls.removeMember(lobby, memberID)
+	if member == nil {
+		ls.mu.Unlock()
+		return nil, ErrNotLobbyMember
+	}
+
+	data := ls.lobbyData(lobby)
+	notifications := []lobbyNotification{
+		{
+			playerIDs: []uint{memberID},
+			message: matchmaking.WebSocketMessage{
+				Type:      MsgTypeLobbyKicked,
+				Message:   "You were removed from the lobby",
+				Data:      data,
+				Timestamp: time.Now(),
+			},
+		},
+		ls.updatedNotification(lobby, member.Username+" was removed from the lobby"),
+	}
+	ls.mu.Unlock()
+
+	ls.send(notifications...)
+	utils.Info("%s was kicked from lobby %s", member.Username, lobby.Code)
+	return data, nil
+}
+
+// StartLobby starts the lobby's match with its settings. Only the host can start it.
+func (ls *LobbyService) StartLobby(code string, hostID uint) (*LobbyData, error) {
+	ls.mu.Lock()
+
+	lobby, err := ls.hostedOpenLobby(code, hostID)
+	if err != nil {
+		ls.mu.Unlock()
+		return nil, err
+	}
+	if len(lobby.Members) < MinPlayers {
+		ls.mu.Unlock()
+		return nil, ErrNotEnoughPlayers
+	}
+
+	matchID := uuid.New().String()
+	mode := lobbyMode(len(lobby.Members))
+	participants := make([]matchmaking.MatchParticipant, len(lobby.Members))
+	usernames := make([]string, len(lobby.Members))
+	for i, member := range lobby.Members {
+		participants[i] = matchmaking.MatchParticipant{
+			PlayerID:  member.PlayerID,
+			Username:  member.Username,
+			Character: member.Character,
+		}
+		usernames[i] = member.Username
+	}
+
+	if _, err := ls.gameStarter.StartMultiplayerGameWithSettings(matchID, mode, participants, lobby.Settings.gameSettings()); err != nil {
+		ls.mu.Unlock()
+		utils.Error("Failed to start game for lobby %s: %v", lobby.Code, err)
+		return nil, err
+	}
+
+	// The lobby stays around so late clients can still find the match, but its players are free
+	// to join other lobbies
+	lobby.Status = StatusStarted
+	lobby.MatchID = matchID
+	lobby.LastActivity = time.Now()
+	playerIDs := lobby.playerIDs()
+	for _, playerID := range playerIDs {
+		delete(ls.playerLobby, playerID)
+	}
+
+	data := ls.lobbyData(lobby)
+	notification := lobbyNotification{
+		playerIDs: playerIDs,
+		message: matchmaking.WebSocketMessage{
+			Type:    matchmaking.MsgTypeMatchStarted,
+			Message: "Match started! Redirecting to game...",
+			Data: matchmaking.MatchStartData{
+				MatchID:         matchID,
+				GameSessionID:   uuid.New().String(),
+				Map:             "default", // Will be determined by the game service
+				GameServerURL:   "/play",   // Redirect to game page
+				Player1Username: usernames[0],
+				Player2Username: usernames[1],
+				Mode:            mode,
+				Usernames:       usernames,
+			},
+			Timestamp: time.Now(),
+		},
+	}
+	ls.mu.Unlock()
+
+	ls.send(notification)
+	utils.Info("Lobby %s started match %s between %s", lobby.Code, matchID, strings.Join(usernames, ", "))
+	return data, nil
+}
+
+// Cleanup stops the lobby service
+func (ls *LobbyService) Cleanup() {
+	ls.cleanupOnce.Do(func() {
+		close(ls.stopCleanup)
+	})
+}
+
+// cleanupRoutine periodically closes idle lobbies
+func (ls *LobbyService) cleanupRoutine() {
+	ticker := time.NewTicker(CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ls.cleanupIdleLobbies()
+		case <-ls.stopCleanup:
+			return
+		}
+	}
+}
+
+// cleanupIdleLobbies closes lobbies nobody has touched for IdleTimeout
+func (ls *LobbyService) cleanupIdleLobbies() {
+	ls.mu.Lock()
+
+	var notifications []lobbyNotification
+	now := time.Now()
+	for _, lobby := range ls.lobbies {
+		if now.Sub(lobby.LastActivity) < IdleTimeout {
+			continue
+		}
+
+		if lobby.Status == StatusOpen {
+			notifications = append(notifications, lobbyNotification{
+				playerIDs: lobby.playerIDs(),
+				message: matchmaking.WebSocketMessage{
+					Type:      MsgTypeLobbyClosed,
+					Message:   "Lobby closed after being idle",
+					Timestamp: now,
+				},
+			})
+		}
+		ls.closeLobby(lobby)
+		utils.Debug("Closed idle lobby %s", lobby.Code)
+	}
+	ls.mu.Unlock()
+
+	ls.send(notifications...)
+}
+
+// hostedOpenLobby returns an open lobby the player hosts. The caller must hold the service lock.
+func (ls *LobbyService) hostedOpenLobby(code string, playerID uint) (*Lobby, error) {
+	lobby, exists := ls.lobbies[normalizeCode(code)]
+	if !exists {
+		return nil, ErrLobbyNotFound
+	}
+	if lobby.HostID != playerID {
+		return nil, ErrNotLobbyHost
+	}
+	if lobby.Status != StatusOpen {
+		return nil, ErrLobbyNotOpen
+	}
+	return lobby, nil
+}
+
+// removeMember takes a player out of an open lobby and returns them, or nil if they weren't in it.
+// The caller must hold the service lock.
+func (ls *LobbyService) removeMember(lobby *Lobby, playerID uint) *LobbyMember {
+	if lobby.Status != StatusOpen {
+		return nil
+	}
+
+	for i, member := range lobby.Members {
+		if member.PlayerID == playerID {
+			lobby.Members = append(lobby.Members[:i], lobby.Members[i+1:]...)
+			lobby.LastActivity = time.Now()
+			delete(ls.playerLobby, playerID)
+			return member
+		}
+	}
+	return nil
+}
+
+// closeLobby removes a lobby and frees its players. The caller must hold the service lock.
+func (ls *LobbyService) closeLobby(lobby *Lobby) {
+	lobby.Status = StatusClosed
+	delete(ls.lobbies, lobby.Code)
+	for _, member := range lobby.Members {
+		if ls.playerLobby[member.PlayerID] == lobby.Code {
+			delete(ls.playerLobby, member.PlayerID)
+		}
+	}
+}
+
+// generateCode returns a lobby code that isn't in use. The caller must hold the service lock.
+func (ls *LobbyService) generateCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(CodeAlphabet)))
+	for {
+		var code strings.Builder
+		for i := 0; i < CodeLength; i++ {
+			index, err := rand.Int(rand.Reader, alphabetSize)
+			if err != nil {
+				return "", err
+			}
+			code.WriteByte(CodeAlphabet[index.Int64()])
+		}
+
+		if _, taken := ls.lobbies[code.String()]; !taken {
+			return code.String(), nil
+		}
+	}
+}
+
+// updatedNotification builds the lobby_updated message for every member. The caller must hold the service lock.
+func (ls *LobbyService) updatedNotification(lobby *Lobby, message string) lobbyNotification {
+	return lobbyNotification{
+		playerIDs: lobby.playerIDs(),
+		message: matchmaking.WebSocketMessage{
+			Type:      MsgTypeLobbyUpdated,
+			Message:   message,
+			Data:      ls.lobbyData(lobby),
+			Timestamp: time.Now(),
+		},
+	}
+}
+
+// send delivers queued notifications. Players without a matchmaking WebSocket simply miss them
+// and see the change the next time they fetch the lobby.
+func (ls *LobbyService) send(notifications ...lobbyNotification) {
+	if ls.notifier == nil {
+		return
+	}
+
+	for _, notification := range notifications {
+		for _, playerID := range notification.playerIDs {
+			if err := ls.notifier.SendMessage(playerID, notification.message); err != nil {
+				utils.Debug("Could not send %s to player %d: %v", notification.message.Type, playerID, err)
+			}
+		}
+	}
+}
+
+// lobbyData returns the public view of a lobby. The caller must hold the service lock.
+func (ls *LobbyService) lobbyData(lobby *Lobby) *LobbyData {
+	members := make([]LobbyMember, len(lobby.Members))
+	for i, member := range lobby.Members {
+		members[i] = *member
+	}
+
+	return &LobbyData{
+		Code:       lobby.Code,
+		InviteLink: ls.baseURL + "/play?lobby=" + lobby.Code,
+		HostID:     lobby.HostID,
+		Status:     lobby.Status,
+		Mode:       lobbyMode(len(lobby.Members)),
+		Settings:   lobby.Settings,
+		Members:    members,
+		MatchID:    lobby.MatchID,
+		CreatedAt:  lobby.CreatedAt,
+	}
+}
+
+// playerIDs returns the IDs of the lobby's members in join order
+func (lobby *Lobby) playerIDs() []uint {
+	playerIDs := make([]uint, len(lobby.Members))
+	for i, member := range lobby.Members {
+		playerIDs[i] = member.PlayerID
+	}
+	return playerIDs
+}
+
+// gameSettings converts the lobby settings into the rules of the multiplayer game.
+// Private games stay out of the public live game list.
+func (settings LobbySettings) gameSettings() gameService.MultiplayerGameSettings {
+	return gameService.MultiplayerGameSettings{
+		MapID:       settings.MapID,
+		TargetScore: settings.TargetScore,
+		TimeLimit:   time.Duration(settings.TimeLimitSeconds) * time.Second,
+		Ranked:      settings.Ranked,
+		Unlisted:    true,
+		RuleSet:     settings.RuleSet,
+	}
+}
+
+// applySettingsUpdate returns the settings with the update applied, after checking the result
+// is valid for a lobby holding memberCount players
+func applySettingsUpdate(settings LobbySettings, update LobbySettingsUpdate, memberCount int) (LobbySettings, error) {
+	if update.MapID != nil {
+		settings.MapID = *update.MapID
+	}
+	if update.TargetScore != nil {
+		settings.TargetScore = *update.TargetScore
+	}
+	if update.TimeLimitSeconds != nil {
+		settings.TimeLimitSeconds = *update.TimeLimitSeconds
+	}
+	if update.Ranked != nil {
+		settings.Ranked = *update.Ranked
+	}
+	if update.MaxPlayers != nil {
+		settings.MaxPlayers = *update.MaxPlayers
+	}
+	if update.RuleSet != nil {
+		settings.RuleSet = *update.RuleSet
+	}
+
+	if settings.MapID != 0 && constant.GetMapByID(settings.MapID) == nil {
+		return settings, ErrInvalidMap
+	}
+	if settings.TargetScore < MinTargetScore || settings.TargetScore > MaxTargetScore {
+		return settings, ErrInvalidTargetScore
+	}
+	if settings.TimeLimitSeconds != 0 && (settings.TimeLimitSeconds < MinTimeLimitSeconds || settings.TimeLimitSeconds > MaxTimeLimitSeconds) {
+		return settings, ErrInvalidTimeLimit
+	}
+	if settings.MaxPlayers < MinPlayers || settings.MaxPlayers > MaxPlayers {
+		return settings, ErrInvalidMaxPlayers
+	}
+	if settings.MaxPlayers < memberCount {
+		return settings, ErrMaxPlayersTooSmall
+	}
+	if _, exists := gameService.GetRuleSet(settings.RuleSet); !exists {
+		return settings, ErrInvalidRuleSet
+	}
+	return settings, nil
+}
+
+// lobbyMode returns the game mode a lobby with playerCount players plays
+func lobbyMode(playerCount int) string {
+	if playerCount <= 2 {
+		return matchmaking.GameModeDuel
+	}
+	return matchmaking.GameModeFreeForAll
+}
+
+// normalizeCode makes codes typed by players match regardless of case and surrounding spaces
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,134 @@
+package lobby
+
+import (
+	"errors"
+	"time"
+
+	gameService "boba-vim/internal/services/game"
+	"boba-vim/internal/services/matchmaking"
+)
+
+// Error definitions
+var (
+	ErrLobbyNotFound      = errors.New("lobby not found")
+	ErrLobbyFull          = errors.New("lobby is full")
+	ErrLobbyNotOpen       = errors.New("lobby is no longer open")
+	ErrNotLobbyHost       = errors.New("only the host can do that")
+	ErrNotLobbyMember     = errors.New("player is not in this lobby")
+	ErrAlreadyInLobby     = errors.New("player is already in a lobby")
+	ErrNotEnoughPlayers   = errors.New("a lobby needs at least 2 players to start")
+	ErrCannotKickHost     = errors.New("the host cannot kick themselves")
+	ErrInvalidMap         = errors.New("map not found")
+	ErrInvalidTargetScore = errors.New("pearl target must be between 100 and 10000")
+	ErrInvalidTimeLimit   = errors.New("time limit must be 0 or between 60 and 3600 seconds")
+	ErrInvalidRuleSet     = errors.New("unknown rule set")
+	ErrInvalidMaxPlayers  = errors.New("a lobby holds 2 to 8 players")
+	ErrMaxPlayersTooSmall = errors.New("max players is below the number of players in the lobby")
+)
+
+// LobbyStatus represents the current status of a lobby
+type LobbyStatus string
+
+const (
+	StatusOpen    LobbyStatus = "open"
+	StatusStarted LobbyStatus = "started"
+	StatusClosed  LobbyStatus = "closed"
+)
+
+// Message types sent to lobby members over the matchmaking WebSocket
+const (
+	MsgTypeLobbyUpdated matchmaking.MessageType = "lobby_updated"
+	MsgTypeLobbyClosed  matchmaking.MessageType = "lobby_closed"
+	MsgTypeLobbyKicked  matchmaking.MessageType = "lobby_kicked"
+)
+
+// Constants for codes, limits and timeouts
+const (
+	CodeLength          = 6
+	CodeAlphabet        = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // No 0/O or 1/I to keep codes easy to read out
+	MinPlayers          = 2
+	MaxPlayers          = matchmaking.MaxFreeForAllRoomSize
+	MinTargetScore      = 100
+	MaxTargetScore      = 10000
+	MinTimeLimitSeconds = 60
+	MaxTimeLimitSeconds = 3600
+	IdleTimeout         = 30 * time.Minute // Lobbies nobody touched for this long are closed
+	CleanupInterval     = time.Minute
+)
+
+// GameStarter starts the game a lobby plays
+type GameStarter interface {
+	StartMultiplayerGameWithSettings(matchID string, mode string, participants []matchmaking.MatchParticipant, settings gameService.MultiplayerGameSettings) (interface{}, error)
+}
+
+// Notifier sends messages to players over the matchmaking WebSocket
+type Notifier interface {
+	SendMessage(playerID uint, message interface{}) error
+}
+
+// LobbySettings are the rules the host picks for the lobby's match
+type LobbySettings struct {
+	MapID            int    `json:"map_id"` // 0 picks a random map
+	TargetScore      int    `json:"target_score"`
+	TimeLimitSeconds int    `json:"time_limit_seconds"` // 0 means no time limit
+	Ranked           bool   `json:"ranked"`
+	MaxPlayers       int    `json:"max_players"`
+	RuleSet          string `json:"rule_set"` // How the game is scored and won
+}
+
+// DefaultLobbySettings returns the settings a new lobby starts with
+func DefaultLobbySettings() LobbySettings {
+	return LobbySettings{
+		TargetScore: gameService.DefaultMultiplayerTargetScore,
+		Ranked:      true,
+		MaxPlayers:  MaxPlayers,
+		RuleSet:     gameService.DefaultRuleSet,
+	}
+}
+
+// LobbySettingsUpdate changes some of a lobby's settings. Fields left out keep their value.
+type LobbySettingsUpdate struct {
+	MapID            *int    `json:"map_id"`
+	TargetScore      *int    `json:"target_score"`
+	TimeLimitSeconds *int    `json:"time_limit_seconds"`
+	Ranked           *bool   `json:"ranked"`
+	MaxPlayers       *int    `json:"max_players"`
+	RuleSet          *string `json:"rule_set"`
+}
+
+// LobbyCreateRequest represents a request to create a lobby
+type LobbyCreateRequest struct {
+	SelectedCharacter string `json:"selected_character"`
+	LobbySettingsUpdate
+}
+
+// LobbyJoinRequest represents a request to join a lobby
+type LobbyJoinRequest struct {
+	SelectedCharacter string `json:"selected_character"`
+}
+
+// LobbyKickRequest represents a request to remove a member from a lobby
+type LobbyKickRequest struct {
+	PlayerID uint `json:"player_id"`
+}
+
+// LobbyMember is a player waiting in a lobby
+type LobbyMember struct {
+	PlayerID  uint      `json:"player_id"`
+	Username  string    `json:"username"`
+	Character string    `json:"character"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// LobbyData is the public view of a lobby sent to clients
+type LobbyData struct {
+	Code       string        `json:"code"`
+	InviteLink string        `json:"invite_link"`
+	HostID     uint          `json:"host_id"`
+	Status     LobbyStatus   `json:"status"`
+	Mode       string        `json:"mode"`
+	Settings   LobbySettings `json:"settings"`
+	Members    []LobbyMember `json:"members"`
+	MatchID    string        `json:"match_id,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
@@ -2,12 +2,33 @@ package matchmaking
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"boba-vim/internal/services/cluster"
 	"boba-vim/internal/services/matchmaking/matchmaking_modules"
 	"gorm.io/gorm"
 )
 
+// Cluster methods of the matchmaking manager
+const (
+	clusterMethodAcceptMatch = "matchmaking.accept_match"
+	clusterMethodRejectMatch = "matchmaking.reject_match"
+	clusterMethodMapVeto     = "matchmaking.map_veto"
+	
+	// Only the node holding this lease matches queued players, so nobody is matched twice
+	matcherLease    = "matchmaking:matcher"
+	matcherLeaseTTL = 6 * time.Second
+)
+
+// matchAction is a player's response to a match forwarded to the node running it
+type matchAction struct {
+	MatchID  string `json:"match_id"`
+	PlayerID uint   `json:"player_id"`
+	Action   string `json:"action,omitempty"`
+	MapID    int    `json:"map_id,omitempty"`
+}
+
 // Type aliases to ensure compatibility
 type mmWebSocketManager struct {
 	wsManager *WebSocketManager
@@ -24,6 +45,7 @@ func (w *mmWebSocketManager) SendMessage(playerID uint, message interface{}) err
 // MatchmakingManager handles the core matchmaking logic
 type MatchmakingManager struct {
 	db             *gorm.DB
+	node           *cluster.Node
 	wsManager      *WebSocketManager
 	gameStarter    matchmaking_modules.MultiplayerGameStarter
 	ctx            context.Context
@@ -33,6 +55,7 @@ type MatchmakingManager struct {
 	queueManager      *matchmaking_modules.QueueManager
 	matchCreator      *matchmaking_modules.MatchCreator
 	matchLifecycle    *matchmaking_modules.MatchLifecycleManager
+	mapPool           *matchmaking_modules.MapPoolManager
 	cleanupManager    *matchmaking_modules.CleanupManager
 	statusManager     *matchmaking_modules.StatusManager
 	activeMatches     *matchmaking_modules.ActiveMatchesManager
@@ -42,13 +65,15 @@ type MatchmakingManager struct {
 }
 
 // NewMatchmakingManager creates a new matchmaking manager
-func NewMatchmakingManager(db *gorm.DB, wsManager *WebSocketManager, gameStarter matchmaking_modules.MultiplayerGameStarter) *MatchmakingManager {
+func NewMatchmakingManager(db *gorm.DB, node *cluster.Node, wsManager *WebSocketManager, gameStarter matchmaking_modules.MultiplayerGameStarter) *MatchmakingManager {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	wsWrapper := &mmWebSocketManager{wsManager: wsManager}
+	mapPool := matchmaking_modules.NewMapPoolManager(db)
 	
 	mm := &MatchmakingManager{
 		db:          db,
+		node:        node,
 		wsManager:   wsManager,
 		gameStarter: gameStarter,
 		ctx:         ctx,
@@ -56,14 +81,38 @@ func NewMatchmakingManager(db *gorm.DB, wsManager *WebSocketManager, gameStarter
 		wsWrapper:   wsWrapper,
 		
 		// Initialize module components
-		queueManager:   matchmaking_modules.NewQueueManager(db),
+		queueManager:   matchmaking_modules.NewQueueManager(db, node.Store),
 		matchCreator:   matchmaking_modules.NewMatchCreator(db),
-		matchLifecycle: matchmaking_modules.NewMatchLifecycleManager(db, gameStarter),
+		matchLifecycle: matchmaking_modules.NewMatchLifecycleManager(db, node, gameStarter, mapPool),
+		mapPool:        mapPool,
 		cleanupManager: matchmaking_modules.NewCleanupManager(db),
-		statusManager:  matchmaking_modules.NewStatusManager(),
-		activeMatches:  matchmaking_modules.NewActiveMatchesManager(),
+		statusManager:  matchmaking_modules.NewStatusManager(node.Store),
+		activeMatches:  matchmaking_modules.NewActiveMatchesManager(node),
 	}
 	
+	// Responses to matches this node runs may reach other nodes
+	node.Handle(clusterMethodAcceptMatch, func(body json.RawMessage) (interface{}, error) {
+		var action matchAction
+		if err := json.Unmarshal(body, &action); err != nil {
+			return nil, err
+		}
+		return nil, mm.matchLifecycle.AcceptMatch(action.MatchID, action.PlayerID, mm.activeMatches, mm.wsWrapper, mm.statusManager)
+	})
+	node.Handle(clusterMethodRejectMatch, func(body json.RawMessage) (interface{}, error) {
+		var action matchAction
+		if err := json.Unmarshal(body, &action); err != nil {
+			return nil, err
+		}
+		return nil, mm.matchLifecycle.RejectMatch(action.MatchID, action.PlayerID, mm.activeMatches, mm.wsWrapper, mm.statusManager)
+	})
+	node.Handle(clusterMethodMapVeto, func(body json.RawMessage) (interface{}, error) {
+		var action matchAction
+		if err := json.Unmarshal(body, &action); err != nil {
+			return nil, err
+		}
+		return nil, mm.matchLifecycle.SubmitMapVeto(action.MatchID, action.PlayerID, action.Action, action.MapID, mm.wsWrapper)
+	})
+	
 	// Start background processes
 	go mm.processQueue()
 	mm.cleanupManager.StartCleanupProcess(mm.activeMatches, mm.wsWrapper, mm.statusManager)
@@ -71,9 +120,10 @@ func NewMatchmakingManager(db *gorm.DB, wsManager *WebSocketManager, gameStarter
 	return mm
 }
 
-// JoinQueue adds a player to the matchmaking queue
-func (mm *MatchmakingManager) JoinQueue(playerID uint, username, selectedCharacter string) error {
-	err := mm.queueManager.JoinQueue(playerID, username, selectedCharacter, mm.wsWrapper)
+// JoinQueue adds a player to the matchmaking queue for a game mode and room size, with the
+// partner they want as their teammate in a team game
+func (mm *MatchmakingManager) JoinQueue(playerID uint, username, selectedCharacter, mode string, roomSize int, partner string) error {
+	err := mm.queueManager.JoinQueue(playerID, username, selectedCharacter, mode, roomSize, partner, mm.wsWrapper)
 	if err != nil {
 		return err
 	}
@@ -93,16 +143,41 @@ func (mm *MatchmakingManager) LeaveQueue(playerID uint) error {
 	return nil
 }
 
-// AcceptMatch handles a player accepting a match
+// AcceptMatch handles a player accepting a match, on the node running it
 func (mm *MatchmakingManager) AcceptMatch(matchID string, playerID uint) error {
+	if owner := matchmaking_modules.MatchOwner(mm.node, matchID); owner != "" {
+		return mm.node.Call(owner, clusterMethodAcceptMatch, matchAction{MatchID: matchID, PlayerID: playerID}, nil)
+	}
 	return mm.matchLifecycle.AcceptMatch(matchID, playerID, mm.activeMatches, mm.wsWrapper, mm.statusManager)
 }
 
-// RejectMatch handles a player rejecting a match
+// ChallengePlayer starts a duel between a player and the friend they challenged, skipping the
+// queue. The match runs on this node.
+func (mm *MatchmakingManager) ChallengePlayer(challenger, opponent MatchParticipant) (string, error) {
+	return mm.matchLifecycle.StartDirectMatch(challenger, opponent, mm.activeMatches, mm.wsWrapper, mm.statusManager)
+}
+
+// RejectMatch handles a player rejecting a match, on the node running it
 func (mm *MatchmakingManager) RejectMatch(matchID string, playerID uint) error {
+	if owner := matchmaking_modules.MatchOwner(mm.node, matchID); owner != "" {
+		return mm.node.Call(owner, clusterMethodRejectMatch, matchAction{MatchID: matchID, PlayerID: playerID}, nil)
+	}
 	return mm.matchLifecycle.RejectMatch(matchID, playerID, mm.activeMatches, mm.wsWrapper, mm.statusManager)
 }
 
+// SubmitMapVeto handles a player banning or picking a map during a map veto, on the node running it
+func (mm *MatchmakingManager) SubmitMapVeto(matchID string, playerID uint, action string, mapID int) error {
+	if owner := matchmaking_modules.MatchOwner(mm.node, matchID); owner != "" {
+		return mm.node.Call(owner, clusterMethodMapVeto, matchAction{MatchID: matchID, PlayerID: playerID, Action: action, MapID: mapID}, nil)
+	}
+	return mm.matchLifecycle.SubmitMapVeto(matchID, playerID, action, mapID, mm.wsWrapper)
+}
+
+// MapPool returns the manager of the ranked map pool
+func (mm *MatchmakingManager) MapPool() *matchmaking_modules.MapPoolManager {
+	return mm.mapPool
+}
+
 // GetPlayerStatus returns the current status of a player
 func (mm *MatchmakingManager) GetPlayerStatus(playerID uint) MatchmakingStatus {
 	return MatchmakingStatus(mm.statusManager.GetPlayerStatus(playerID))
@@ -123,6 +198,9 @@ func (mm *MatchmakingManager) processQueue() {
 		case <-mm.ctx.Done():
 			return
 		case <-ticker.C:
+			if !mm.node.HoldLease(matcherLease, matcherLeaseTTL) {
+				continue // Another node is matching the shared queue
+			}
 			mm.tryCreateMatches()
 			mm.queueManager.CheckQueueTimeouts(mm.wsWrapper)
 		}
@@ -139,7 +217,8 @@ func (mm *MatchmakingManager) tryCreateMatches() {
 func (mm *MatchmakingManager) Cleanup() {
 	mm.cancel()
 	mm.cleanupManager.Cleanup()
-	mm.queueManager.Cleanup()
+	mm.matchLifecycle.Cleanup()
 	mm.activeMatches.Cleanup()
+	mm.node.Store.ReleaseLease(matcherLease, mm.node.ID)
 	mm.statusManager.Cleanup()
 }
\ No newline at end of file
@@ -3,17 +3,21 @@ package matchmaking_modules
 import (
 	"sync"
 	"time"
+
+	"boba-vim/internal/services/cluster"
 )
 
-// ActiveMatchesManager handles active match storage and retrieval
+// ActiveMatchesManager handles storage and retrieval of the matches this node runs
 type ActiveMatchesManager struct {
+	node          *cluster.Node
 	activeMatches map[string]*ActiveMatch
 	matchMutex    sync.RWMutex
 }
 
 // NewActiveMatchesManager creates a new active matches manager
-func NewActiveMatchesManager() *ActiveMatchesManager {
+func NewActiveMatchesManager(node *cluster.Node) *ActiveMatchesManager {
 	return &ActiveMatchesManager{
+		node:          node,
 		activeMatches: make(map[string]*ActiveMatch),
 	}
 }
@@ -24,6 +28,7 @@ func (amm *ActiveMatchesManager) AddMatch(matchID string, match *ActiveMatch) {
 	defer amm.matchMutex.Unlock()
 	
 	amm.activeMatches[matchID] = match
+	claimMatch(amm.node, matchID)
 }
 
 // GetMatch retrieves a match by ID
@@ -40,7 +45,10 @@ func (amm *ActiveMatchesManager) RemoveMatch(matchID string) {
 	amm.matchMutex.Lock()
 	defer amm.matchMutex.Unlock()
 	
-	delete(amm.activeMatches, matchID)
+	if _, exists := amm.activeMatches[matchID]; exists {
+		delete(amm.activeMatches, matchID)
+		releaseMatch(amm.node, matchID)
+	}
 }
 
 // GetExpiredMatches returns all matches that have expired
@@ -63,5 +71,8 @@ func (amm *ActiveMatchesManager) Cleanup() {
 	amm.matchMutex.Lock()
 	defer amm.matchMutex.Unlock()
 	
+	for matchID := range amm.activeMatches {
+		releaseMatch(amm.node, matchID)
+	}
 	amm.activeMatches = make(map[string]*ActiveMatch)
 }
\ No newline at end of file
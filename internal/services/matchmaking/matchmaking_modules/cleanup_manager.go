@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"boba-vim/internal/models/model_modules"
 	"boba-vim/internal/utils"
 	"gorm.io/gorm"
 )
@@ -58,26 +57,26 @@ func (cm *CleanupManager) processExpiredMatches(activeMatches ActiveMatchesInter
 		activeMatches.RemoveMatch(match.ID)
 		
 		// Update player statuses
-		statusManager.SetPlayerStatus(match.Player1ID, StatusIdle)
-		statusManager.SetPlayerStatus(match.Player2ID, StatusIdle)
+		for _, playerID := range match.PlayerIDs() {
+			statusManager.SetPlayerStatus(playerID, StatusIdle)
+		}
 		
 		// Remove from database
-		cm.db.Where("(player1_id = ? AND player2_id = ?) OR (player1_id = ? AND player2_id = ?)", 
-			match.Player1ID, match.Player2ID, match.Player2ID, match.Player1ID).
-			Delete(&model_modules.OnlineMatch{})
+		deleteMatchRecord(cm.db, match)
 		
 		// Send timeout messages
-		wsManager.SendMessage(match.Player1ID, WebSocketMessage{
-			Type:      MsgTypeMatchCancelled,
-			Message:   "Match expired. No response from opponent.",
-			Timestamp: time.Now(),
-		})
+		message := "Match expired. No response from opponent."
+		if match.Mode != GameModeDuel {
+			message = "Match expired. Not every player responded."
+		}
 		
-		wsManager.SendMessage(match.Player2ID, WebSocketMessage{
-			Type:      MsgTypeMatchCancelled,
-			Message:   "Match expired. No response from opponent.",
-			Timestamp: time.Now(),
-		})
+		for _, playerID := range match.PlayerIDs() {
+			wsManager.SendMessage(playerID, WebSocketMessage{
+				Type:      MsgTypeMatchCancelled,
+				Message:   message,
+				Timestamp: time.Now(),
+			})
+		}
 		
 		utils.Info("Match %s expired", match.ID)
 	}
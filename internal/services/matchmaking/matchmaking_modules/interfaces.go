@@ -18,32 +18,94 @@ type QueuePlayer struct {
 	PlayerID          uint      `json:"player_id"`
 	Username          string    `json:"username"`
 	SelectedCharacter string    `json:"selected_character"`
+	Mode              string    `json:"mode"`
+	RoomSize          int       `json:"room_size"`
+	Partner           string    `json:"partner,omitempty"` // Username of the teammate the player queued with
+	Rating            float64   `json:"rating"`
 	QueuedAt          time.Time `json:"queued_at"`
 }
 
+// MatchParticipant is a player taking part in a match, in seat order
+type MatchParticipant struct {
+	PlayerID  uint
+	Username  string
+	Character string
+	Bot       string // Difficulty of a server-side bot; empty for a player
+	Team      int    // Team of the player in a team game, 1 or 2; 0 when everyone plays alone
+}
+
+// MatchPlayer is a participant of an active match and their response to it
+type MatchPlayer struct {
+	MatchParticipant
+	Accepted  bool
+	Responded bool
+}
+
 type ActiveMatch struct {
-	ID                string
-	Player1ID         uint
-	Player1Username   string
-	Player1Character  string
-	Player2ID         uint
-	Player2Username   string
-	Player2Character  string
-	CreatedAt         time.Time
-	ExpiresAt         time.Time
-	Player1Accepted   bool
-	Player2Accepted   bool
-	Player1Responded  bool
-	Player2Responded  bool
+	ID        string
+	Mode      string
+	Players   []*MatchPlayer
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Player returns the match player with the given ID, or nil if they aren't in the match
+func (am *ActiveMatch) Player(playerID uint) *MatchPlayer {
+	for _, player := range am.Players {
+		if player.PlayerID == playerID {
+			return player
+		}
+	}
+	return nil
+}
+
+// PlayerIDs returns the IDs of every player in the match
+func (am *ActiveMatch) PlayerIDs() []uint {
+	playerIDs := make([]uint, len(am.Players))
+	for i, player := range am.Players {
+		playerIDs[i] = player.PlayerID
+	}
+	return playerIDs
+}
+
+// Participants returns the players of the match in seat order
+func (am *ActiveMatch) Participants() []MatchParticipant {
+	participants := make([]MatchParticipant, len(am.Players))
+	for i, player := range am.Players {
+		participants[i] = player.MatchParticipant
+	}
+	return participants
+}
+
+// AllAccepted reports whether every player accepted the match
+func (am *ActiveMatch) AllAccepted() bool {
+	for _, player := range am.Players {
+		if !player.Accepted {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchFoundPlayer describes one player of a found match
+type MatchFoundPlayer struct {
+	Username  string `json:"username"`
+	Character string `json:"character"`
+	Team      int    `json:"team,omitempty"`
 }
 
 type MatchFoundData struct {
-	MatchID           string `json:"match_id"`
-	PlayerCharacter   string `json:"player_character"`
-	PlayerUsername    string `json:"player_username"`
-	OpponentCharacter string `json:"opponent_character"`
-	OpponentUsername  string `json:"opponent_username"`
-	AcceptTimeoutMs   int64  `json:"accept_timeout_ms"`
+	MatchID           string             `json:"match_id"`
+	Mode              string             `json:"mode"`
+	RoomSize          int                `json:"room_size"`
+	PlayerCharacter   string             `json:"player_character"`
+	PlayerUsername    string             `json:"player_username"`
+	OpponentCharacter string             `json:"opponent_character"`
+	OpponentUsername  string             `json:"opponent_username"`
+	Players           []MatchFoundPlayer `json:"players"`
+	Team              int                `json:"team,omitempty"` // The player's team in a team game
+	Direct            bool               `json:"direct,omitempty"` // A friend's challenge rather than a match found in the queue
+	AcceptTimeoutMs   int64              `json:"accept_timeout_ms"`
 }
 
 type MatchStartData struct {
@@ -51,8 +113,10 @@ type MatchStartData struct {
 	GameSessionID   string `json:"game_session_id"`
 	Map             string `json:"map"`
 	GameServerURL   string `json:"game_server_url"`
-	Player1Username string `json:"player1_username"`
-	Player2Username string `json:"player2_username"`
+	Player1Username string   `json:"player1_username"`
+	Player2Username string   `json:"player2_username"`
+	Mode            string   `json:"mode"`
+	Usernames       []string `json:"usernames"`
 }
 
 // Constants
@@ -61,6 +125,7 @@ const (
 	StatusSearching      MatchmakingStatus = "searching"
 	StatusMatchFound     MatchmakingStatus = "match_found"
 	StatusWaitingAccept  MatchmakingStatus = "waiting_accept"
+	StatusMapVeto        MatchmakingStatus = "map_veto"
 	StatusMatchAccepted  MatchmakingStatus = "match_accepted"
 	StatusMatchRejected  MatchmakingStatus = "match_rejected"
 	StatusInGame         MatchmakingStatus = "in_game"
@@ -75,11 +140,30 @@ const (
 	MsgTypeOpponentRejected  MessageType = "opponent_rejected"
 	MsgTypeMatchStarted      MessageType = "match_started"
 	MsgTypeMatchCancelled    MessageType = "match_cancelled"
+	MsgTypeMapVeto           MessageType = "map_veto"
+	MsgTypeMapBan            MessageType = "map_ban"  // Sent by players during a map veto
+	MsgTypeMapPick           MessageType = "map_pick" // Sent by players during a map veto
 	MsgTypeError             MessageType = "error"
 	
+	GameModeDuel          = "duel"
+	GameModeFreeForAll    = "free_for_all"
+	GameModeTeams         = "teams"
+	DuelRoomSize          = 2
+	MinFreeForAllRoomSize = 3
+	MaxFreeForAllRoomSize = 8
+	TeamRoomSize          = 4 // Two teams of TeamSize players
+	TeamSize              = 2
+	
 	QueueTimeoutDuration  = 45 * time.Second
 	AcceptTimeoutDuration = 30 * time.Second
+	DirectMatchTimeout    = 60 * time.Second // Time a challenged friend has to accept
 	MaxQueueSize          = 1000
+	
+	// Players are matched within a rating window that widens the longer they wait
+	InitialRatingWindow         = 100.0
+	RatingWindowGrowthPerSecond = 25.0
+	MaxRatingWindow             = 1000.0
+	DefaultQueueRating          = 1500.0
 )
 
 // Error definitions (re-exported)
@@ -99,6 +183,19 @@ var (
 	ErrMatchAlreadyAccepted  = MatchmakingError{"match already accepted"}
 	ErrMatchExpired          = MatchmakingError{"match expired"}
 	ErrInvalidMatchAction    = MatchmakingError{"invalid match action"}
+	ErrInvalidGameMode       = MatchmakingError{"invalid game mode"}
+	ErrInvalidRoomSize       = MatchmakingError{"free-for-all rooms hold 3 to 8 players"}
+	ErrInvalidTeamRoomSize   = MatchmakingError{"team games hold 4 players"}
+	ErrPartnerNotAllowed     = MatchmakingError{"only team games can be queued with a partner"}
+	ErrInvalidPartner        = MatchmakingError{"you can't queue as your own partner"}
+	ErrMapVetoNotFound       = MatchmakingError{"map veto not found"}
+	ErrNotYourVetoTurn       = MatchmakingError{"it is not your turn to ban or pick"}
+	ErrInvalidVetoAction     = MatchmakingError{"invalid map veto action"}
+	ErrMapNotInVeto          = MatchmakingError{"map is not available in this veto"}
+	ErrUnknownMap            = MatchmakingError{"map not found"}
+	ErrMapAlreadyInPool      = MatchmakingError{"map is already in the ranked pool"}
+	ErrMapNotInPool          = MatchmakingError{"map is not in the ranked pool"}
+	ErrPlayerBusy            = MatchmakingError{"player is already queued or in a match"}
 )
 
 // Interfaces
@@ -108,7 +205,7 @@ type WebSocketManager interface {
 }
 
 type MultiplayerGameStarter interface {
-	StartMultiplayerGame(matchID string, player1ID uint, player1Username, player1Character string, player2ID uint, player2Username, player2Character string) error
+	StartMultiplayerGame(matchID string, mode string, participants []MatchParticipant, mapSelection MapSelection) error
 }
 
 type ActiveMatchesInterface interface {
@@ -0,0 +1,124 @@
+package matchmaking_modules
+
+import (
+	"errors"
+	"math/rand"
+
+	"boba-vim/internal/constant"
+	"boba-vim/internal/models/model_modules"
+	"boba-vim/internal/utils"
+	"gorm.io/gorm"
+)
+
+// MapSummary describes a map without its text
+type MapSummary struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Difficulty string `json:"difficulty"`
+	Category   string `json:"category"`
+}
+
+// MapPoolManager manages the ranked map pool matchmade games are played on
+type MapPoolManager struct {
+	db *gorm.DB
+}
+
+// NewMapPoolManager creates a new map pool manager
+func NewMapPoolManager(db *gorm.DB) *MapPoolManager {
+	return &MapPoolManager{
+		db: db,
+	}
+}
+
+// RankedMaps returns the maps of the ranked pool. Until admins add maps to the pool, every map
+// outside the tutorials is ranked.
+func (mpm *MapPoolManager) RankedMaps() []MapSummary {
+	maps, _ := mpm.Pool()
+	return maps
+}
+
+// Pool returns the maps of the ranked pool and whether it is the default pool
+func (mpm *MapPoolManager) Pool() ([]MapSummary, bool) {
+	var entries []model_modules.RankedMapPoolEntry
+	if err := mpm.db.Order("map_id ASC").Find(&entries).Error; err != nil {
+		utils.Error("Failed to load ranked map pool: %v", err)
+		return DefaultRankedMaps(), true
+	}
+
+	maps := make([]MapSummary, 0, len(entries))
+	for _, entry := range entries {
+		if gameMap := constant.GetMapByID(entry.MapID); gameMap != nil {
+			maps = append(maps, summarizeMap(*gameMap))
+		}
+	}
+	if len(maps) == 0 {
+		return DefaultRankedMaps(), true
+	}
+	return maps, false
+}
+
+// RandomRankedMap returns a random map of the ranked pool
+func (mpm *MapPoolManager) RandomRankedMap() MapSummary {
+	maps := mpm.RankedMaps()
+	return maps[rand.Intn(len(maps))]
+}
+
+// AddMap puts a map in the ranked pool
+func (mpm *MapPoolManager) AddMap(mapID int) (*MapSummary, error) {
+	gameMap := constant.GetMapByID(mapID)
+	if gameMap == nil {
+		return nil, ErrUnknownMap
+	}
+
+	var existing model_modules.RankedMapPoolEntry
+	err := mpm.db.Where("map_id = ?", mapID).First(&existing).Error
+	if err == nil {
+		return nil, ErrMapAlreadyInPool
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := mpm.db.Create(&model_modules.RankedMapPoolEntry{MapID: mapID}).Error; err != nil {
+		return nil, err
+	}
+
+	summary := summarizeMap(*gameMap)
+	utils.Info("Map %d (%s) added to the ranked pool", mapID, gameMap.Name)
+	return &summary, nil
+}
+
+// RemoveMap takes a map out of the ranked pool
+func (mpm *MapPoolManager) RemoveMap(mapID int) error {
+	result := mpm.db.Where("map_id = ?", mapID).Delete(&model_modules.RankedMapPoolEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMapNotInPool
+	}
+
+	utils.Info("Map %d removed from the ranked pool", mapID)
+	return nil
+}
+
+// DefaultRankedMaps returns every map outside the tutorials
+func DefaultRankedMaps() []MapSummary {
+	var maps []MapSummary
+	for _, gameMap := range constant.GAME_MAPS {
+		if gameMap.Category != "tutorial" {
+			maps = append(maps, summarizeMap(gameMap))
+		}
+	}
+	return maps
+}
+
+// summarizeMap returns the summary of a map
+func summarizeMap(gameMap constant.Map) MapSummary {
+	return MapSummary{
+		ID:         gameMap.ID,
+		Name:       gameMap.Name,
+		Difficulty: gameMap.Difficulty,
+		Category:   gameMap.Category,
+	}
+}
@@ -0,0 +1,312 @@
+package matchmaking_modules
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"boba-vim/internal/services/cluster"
+	"boba-vim/internal/utils"
+)
+
+// Map veto settings
+const (
+	MapVetoPoolSize    = 5                // Maps drawn from the ranked pool for a veto
+	MapVetoTurnTimeout = 20 * time.Second // Time a player has to ban or pick
+
+	MapVetoActionBan     = "ban"
+	MapVetoActionPick    = "pick"
+	MapVetoActionDecider = "decider" // Only one map could be played
+)
+
+// MapVetoAction is one ban or pick of a map veto
+type MapVetoAction struct {
+	Step     int    `json:"step"`
+	PlayerID uint   `json:"player_id"`
+	Username string `json:"username"`
+	Action   string `json:"action"`
+	MapID    int    `json:"map_id"`
+	MapName  string `json:"map_name"`
+	Auto     bool   `json:"auto"` // Made by the server when the player ran out of time
+}
+
+// MapSelection is the map a match is played on and the veto that chose it
+type MapSelection struct {
+	MapID   int
+	MapName string
+	Veto    []MapVetoAction
+}
+
+// MapVetoData is the state of a map veto sent to its players after every action
+type MapVetoData struct {
+	MatchID      string          `json:"match_id"`
+	Maps         []MapSummary    `json:"maps"`
+	Remaining    []int           `json:"remaining"` // IDs of the maps still in play
+	History      []MapVetoAction `json:"history"`
+	TurnPlayerID uint            `json:"turn_player_id,omitempty"`
+	TurnUsername string          `json:"turn_username,omitempty"`
+	TurnAction   string          `json:"turn_action,omitempty"`
+	TurnEndsAt   *time.Time      `json:"turn_ends_at,omitempty"`
+	YourTurn     bool            `json:"your_turn"`              // The player it is sent to acts next
+	SelectedMap  *MapSummary     `json:"selected_map,omitempty"` // Set once the veto is complete
+}
+
+// mapVeto is a running veto. Players take turns banning maps until two are left, then the
+// player whose turn it is picks one of them.
+type mapVeto struct {
+	matchID    string
+	players    []MatchParticipant
+	maps       []MapSummary
+	remaining  []MapSummary
+	history    []MapVetoAction
+	turnEndsAt time.Time
+	turnTimer  *time.Timer
+	onComplete func(MapSelection)
+}
+
+// turnPlayer returns the player who acts next
+func (mv *mapVeto) turnPlayer() MatchParticipant {
+	return mv.players[len(mv.history)%len(mv.players)]
+}
+
+// turnAction returns what the next action must be
+func (mv *mapVeto) turnAction() string {
+	if len(mv.remaining) > 2 {
+		return MapVetoActionBan
+	}
+	return MapVetoActionPick
+}
+
+// hasPlayer reports whether a player takes part in the veto
+func (mv *mapVeto) hasPlayer(playerID uint) bool {
+	for _, player := range mv.players {
+		if player.PlayerID == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// data returns the state of the veto to send to its players
+func (mv *mapVeto) data() MapVetoData {
+	remaining := make([]int, len(mv.remaining))
+	for i, gameMap := range mv.remaining {
+		remaining[i] = gameMap.ID
+	}
+
+	data := MapVetoData{
+		MatchID:   mv.matchID,
+		Maps:      mv.maps,
+		Remaining: remaining,
+		History:   append([]MapVetoAction(nil), mv.history...),
+	}
+
+	if len(mv.remaining) == 1 {
+		selected := mv.remaining[0]
+		data.SelectedMap = &selected
+		return data
+	}
+
+	turnPlayer := mv.turnPlayer()
+	turnEndsAt := mv.turnEndsAt
+	data.TurnPlayerID = turnPlayer.PlayerID
+	data.TurnUsername = turnPlayer.Username
+	data.TurnAction = mv.turnAction()
+	data.TurnEndsAt = &turnEndsAt
+	return data
+}
+
+// selection returns the chosen map and the veto history. The veto must be complete.
+func (mv *mapVeto) selection() MapSelection {
+	return MapSelection{
+		MapID:   mv.remaining[0].ID,
+		MapName: mv.remaining[0].Name,
+		Veto:    mv.history,
+	}
+}
+
+// MapVetoManager runs the map vetoes of the duels this node runs
+type MapVetoManager struct {
+	node   *cluster.Node
+	vetoes map[string]*mapVeto
+	mutex  sync.Mutex
+}
+
+// NewMapVetoManager creates a new map veto manager
+func NewMapVetoManager(node *cluster.Node) *MapVetoManager {
+	return &MapVetoManager{
+		node:   node,
+		vetoes: make(map[string]*mapVeto),
+	}
+}
+
+// Begin starts the veto of a match over maps drawn from the pool. onComplete is called with the
+// chosen map once the veto ends; right away when the pool holds a single map.
+func (mvm *MapVetoManager) Begin(matchID string, players []MatchParticipant, pool []MapSummary, wsManager WebSocketManager, onComplete func(MapSelection)) {
+	maps := append([]MapSummary(nil), pool...)
+	rand.Shuffle(len(maps), func(i, j int) {
+		maps[i], maps[j] = maps[j], maps[i]
+	})
+	if len(maps) > MapVetoPoolSize {
+		maps = maps[:MapVetoPoolSize]
+	}
+
+	if len(maps) == 1 {
+		onComplete(MapSelection{
+			MapID:   maps[0].ID,
+			MapName: maps[0].Name,
+			Veto: []MapVetoAction{{
+				Step:    1,
+				Action:  MapVetoActionDecider,
+				MapID:   maps[0].ID,
+				MapName: maps[0].Name,
+			}},
+		})
+		return
+	}
+
+	veto := &mapVeto{
+		matchID:    matchID,
+		players:    players,
+		maps:       maps,
+		remaining:  append([]MapSummary(nil), maps...),
+		onComplete: onComplete,
+	}
+
+	mvm.mutex.Lock()
+	mvm.vetoes[matchID] = veto
+	claimMatch(mvm.node, matchID)
+	mvm.startTurn(veto, wsManager)
+	data := veto.data()
+	mvm.mutex.Unlock()
+
+	mvm.broadcast(veto.players, "Ban and pick the map for your match", data, wsManager)
+	utils.Info("Map veto started for match %s over %d maps", matchID, len(maps))
+}
+
+// Act applies a player's ban or pick
+func (mvm *MapVetoManager) Act(matchID string, playerID uint, action string, mapID int, wsManager WebSocketManager) error {
+	mvm.mutex.Lock()
+
+	veto, exists := mvm.vetoes[matchID]
+	if !exists {
+		mvm.mutex.Unlock()
+		return ErrMapVetoNotFound
+	}
+	if !veto.hasPlayer(playerID) {
+		mvm.mutex.Unlock()
+		return ErrInvalidMatchAction
+	}
+	if veto.turnPlayer().PlayerID != playerID {
+		mvm.mutex.Unlock()
+		return ErrNotYourVetoTurn
+	}
+	if action != veto.turnAction() {
+		mvm.mutex.Unlock()
+		return ErrInvalidVetoAction
+	}
+
+	index := -1
+	for i, gameMap := range veto.remaining {
+		if gameMap.ID == mapID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		mvm.mutex.Unlock()
+		return ErrMapNotInVeto
+	}
+
+	mvm.apply(veto, index, false, wsManager)
+	return nil
+}
+
+// autoAct bans or picks a random map for a player who ran out of time
+func (mvm *MapVetoManager) autoAct(matchID string, step int, wsManager WebSocketManager) {
+	mvm.mutex.Lock()
+
+	veto, exists := mvm.vetoes[matchID]
+	if !exists || len(veto.history) != step {
+		mvm.mutex.Unlock()
+		return // The player acted in time
+	}
+
+	utils.Debug("Player %d ran out of time in the map veto of match %s", veto.turnPlayer().PlayerID, matchID)
+	mvm.apply(veto, rand.Intn(len(veto.remaining)), true, wsManager)
+}
+
+// apply records the turn's action on a remaining map, then starts the next turn or completes the
+// veto. The caller must hold the lock; apply releases it before notifying players.
+func (mvm *MapVetoManager) apply(veto *mapVeto, index int, auto bool, wsManager WebSocketManager) {
+	veto.turnTimer.Stop()
+
+	player := veto.turnPlayer()
+	action := veto.turnAction()
+	chosen := veto.remaining[index]
+
+	veto.history = append(veto.history, MapVetoAction{
+		Step:     len(veto.history) + 1,
+		PlayerID: player.PlayerID,
+		Username: player.Username,
+		Action:   action,
+		MapID:    chosen.ID,
+		MapName:  chosen.Name,
+		Auto:     auto,
+	})
+
+	if action == MapVetoActionBan {
+		veto.remaining = append(veto.remaining[:index:index], veto.remaining[index+1:]...)
+		mvm.startTurn(veto, wsManager)
+		data := veto.data()
+		mvm.mutex.Unlock()
+
+		mvm.broadcast(veto.players, player.Username+" banned "+chosen.Name, data, wsManager)
+		return
+	}
+
+	veto.remaining = []MapSummary{chosen}
+	delete(mvm.vetoes, veto.matchID)
+	releaseMatch(mvm.node, veto.matchID)
+	data := veto.data()
+	selection := veto.selection()
+	mvm.mutex.Unlock()
+
+	mvm.broadcast(veto.players, player.Username+" picked "+chosen.Name, data, wsManager)
+	utils.Info("Map veto of match %s chose %s", veto.matchID, chosen.Name)
+	veto.onComplete(selection)
+}
+
+// startTurn starts the clock of the next turn. The caller must hold the lock.
+func (mvm *MapVetoManager) startTurn(veto *mapVeto, wsManager WebSocketManager) {
+	step := len(veto.history)
+	veto.turnEndsAt = time.Now().Add(MapVetoTurnTimeout)
+	veto.turnTimer = time.AfterFunc(MapVetoTurnTimeout, func() {
+		mvm.autoAct(veto.matchID, step, wsManager)
+	})
+}
+
+// broadcast sends the state of a veto to its players
+func (mvm *MapVetoManager) broadcast(players []MatchParticipant, message string, data MapVetoData, wsManager WebSocketManager) {
+	for _, player := range players {
+		data.YourTurn = data.SelectedMap == nil && player.PlayerID == data.TurnPlayerID
+		wsManager.SendMessage(player.PlayerID, WebSocketMessage{
+			Type:      MsgTypeMapVeto,
+			Message:   message,
+			Data:      data,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// Cleanup stops every running veto
+func (mvm *MapVetoManager) Cleanup() {
+	mvm.mutex.Lock()
+	defer mvm.mutex.Unlock()
+
+	for matchID, veto := range mvm.vetoes {
+		veto.turnTimer.Stop()
+		releaseMatch(mvm.node, matchID)
+	}
+	mvm.vetoes = make(map[string]*mapVeto)
+}
@@ -1,6 +1,9 @@
 package matchmaking_modules
 
 import (
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"boba-vim/internal/models/model_modules"
@@ -21,7 +24,16 @@ func NewMatchCreator(db *gorm.DB) *MatchCreator {
 	}
 }
 
-// TryCreateMatches attempts to create matches from queued players
+// roomKey groups queued players who can be matched together
+type roomKey struct {
+	mode     string
+	roomSize int
+}
+
+// TryCreateMatches attempts to create matches from queued players.
+// Players are grouped by mode and room size, and each room is filled around the longest-waiting
+// player with the players closest to their rating, within a window that widens with wait time.
+// Team games are filled the same way with parties, so partners are matched together.
 func (mc *MatchCreator) TryCreateMatches(players []*QueuePlayer, activeMatches ActiveMatchesInterface, wsManager WebSocketManager, statusManager StatusInterface, queueManager *QueueManager) {
 	// Need at least 2 players to create a match
 	if len(players) < 2 {
@@ -29,107 +41,217 @@ func (mc *MatchCreator) TryCreateMatches(players []*QueuePlayer, activeMatches A
 	}
 	
 	// Create a defensive copy to prevent race conditions
-	playersCopy := make([]*QueuePlayer, len(players))
-	copy(playersCopy, players)
-	
-	// Match players in pairs with bounds checking
-	for i := 0; i < len(playersCopy)-1; i += 2 {
-		// Double-check bounds to prevent index out of range panic
-		if i+1 >= len(playersCopy) {
-			utils.Info("Bounds check failed: trying to access index %d in slice of length %d", i+1, len(playersCopy))
-			break
+	playersCopy := make([]*QueuePlayer, 0, len(players))
+	for _, player := range players {
+		// Skip players that disappeared while the queue was read
+		if player == nil {
+			continue
+		}
+		playersCopy = append(playersCopy, player)
+	}
+	
+	sort.Slice(playersCopy, func(i, j int) bool {
+		return playersCopy[i].QueuedAt.Before(playersCopy[j].QueuedAt)
+	})
+	
+	// Group players by the room they are waiting for
+	rooms := make(map[roomKey][]*QueuePlayer)
+	var roomOrder []roomKey
+	for _, player := range playersCopy {
+		key := roomKey{mode: player.Mode, roomSize: player.RoomSize}
+		if _, exists := rooms[key]; !exists {
+			roomOrder = append(roomOrder, key)
+		}
+		rooms[key] = append(rooms[key], player)
+	}
+	
+	// Fill rooms of the requested size
+	now := time.Now()
+	for _, key := range roomOrder {
+		waiting := rooms[key]
+		if key.roomSize < DuelRoomSize {
+			continue
 		}
 		
-		player1 := playersCopy[i]
-		player2 := playersCopy[i+1]
+		for len(waiting) >= key.roomSize {
+			var room, remaining []*QueuePlayer
+			if key.mode == GameModeTeams {
+				room, remaining = pickTeamRoom(waiting, now)
+			} else {
+				room, remaining = pickRatedRoom(waiting, key.roomSize, now)
+			}
+			if room == nil {
+				break
+			}
+			waiting = remaining
+			
+			if err := mc.createMatch(key.mode, room, activeMatches, wsManager, statusManager, queueManager); err != nil {
+				utils.Info("Failed to create %s match for %d players: %v", key.mode, len(room), err)
+			}
+		}
+	}
+}
+
+// RatingWindow returns how far apart in rating a player accepts opponents after waiting for wait
+func RatingWindow(wait time.Duration) float64 {
+	return math.Min(MaxRatingWindow, InitialRatingWindow+wait.Seconds()*RatingWindowGrowthPerSecond)
+}
+
+// pickRatedRoom picks roomSize players from waiting, which is ordered by queue time. Each player in
+// turn, longest waiting first, is tried as the anchor of a room with the players closest to their
+// rating. A player fits when the rating gap is within either player's window. It returns nil when
+// no room can be filled yet, along with the players left waiting.
+func pickRatedRoom(waiting []*QueuePlayer, roomSize int, now time.Time) ([]*QueuePlayer, []*QueuePlayer) {
+	for _, anchor := range waiting {
+		anchorWindow := RatingWindow(now.Sub(anchor.QueuedAt))
 		
-		// Validate that both players still exist and are valid
-		if player1 == nil || player2 == nil {
-			utils.Info("Null player encountered at indices %d or %d, skipping match creation", i, i+1)
+		candidates := make([]*QueuePlayer, 0, len(waiting)-1)
+		for _, player := range waiting {
+			if player == anchor {
+				continue
+			}
+			window := math.Max(anchorWindow, RatingWindow(now.Sub(player.QueuedAt)))
+			if math.Abs(player.Rating-anchor.Rating) <= window {
+				candidates = append(candidates, player)
+			}
+		}
+		if len(candidates) < roomSize-1 {
 			continue
 		}
 		
-		// Create match
-		if err := mc.createMatch(player1, player2, activeMatches, wsManager, statusManager, queueManager); err != nil {
-			utils.Info("Failed to create match between %d and %d: %v", 
-				player1.PlayerID, player2.PlayerID, err)
+		// Closest ratings first; candidates are already in queue order for equal gaps
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return math.Abs(candidates[i].Rating-anchor.Rating) < math.Abs(candidates[j].Rating-anchor.Rating)
+		})
+		
+		room := append([]*QueuePlayer{anchor}, candidates[:roomSize-1]...)
+		picked := make(map[*QueuePlayer]bool, len(room))
+		for _, player := range room {
+			picked[player] = true
+		}
+		
+		remaining := make([]*QueuePlayer, 0, len(waiting)-len(room))
+		for _, player := range waiting {
+			if !picked[player] {
+				remaining = append(remaining, player)
+			}
 		}
+		return room, remaining
 	}
+	
+	return nil, waiting
 }
 
-// createMatch creates a new match between two players
-func (mc *MatchCreator) createMatch(player1, player2 *QueuePlayer, activeMatches ActiveMatchesInterface, wsManager WebSocketManager, statusManager StatusInterface, queueManager *QueueManager) error {
+// createMatch creates a new match between the players of a room. The players of a team game are
+// seated team by team.
+func (mc *MatchCreator) createMatch(mode string, room []*QueuePlayer, activeMatches ActiveMatchesInterface, wsManager WebSocketManager, statusManager StatusInterface, queueManager *QueueManager) error {
 	matchID := uuid.New().String()
 	
+	playerIDs := make([]uint, len(room))
+	usernames := make([]string, len(room))
+	foundPlayers := make([]MatchFoundPlayer, len(room))
+	matchPlayers := make([]*MatchPlayer, len(room))
+	teams := make([]int, len(room))
+	for i, player := range room {
+		if mode == GameModeTeams {
+			teams[i] = i/TeamSize + 1
+		}
+		playerIDs[i] = player.PlayerID
+		usernames[i] = player.Username
+		foundPlayers[i] = MatchFoundPlayer{Username: player.Username, Character: player.SelectedCharacter, Team: teams[i]}
+		matchPlayers[i] = &MatchPlayer{MatchParticipant: MatchParticipant{
+			PlayerID:  player.PlayerID,
+			Username:  player.Username,
+			Character: player.SelectedCharacter,
+			Team:      teams[i],
+		}}
+	}
+	
 	// Remove players from queue
-	queueManager.RemovePlayersFromQueue([]uint{player1.PlayerID, player2.PlayerID})
+	queueManager.RemovePlayersFromQueue(playerIDs)
 	
 	// Create active match
 	activeMatch := &ActiveMatch{
-		ID:               matchID,
-		Player1ID:        player1.PlayerID,
-		Player1Username:  player1.Username,
-		Player1Character: player1.SelectedCharacter,
-		Player2ID:        player2.PlayerID,
-		Player2Username:  player2.Username,
-		Player2Character: player2.SelectedCharacter,
-		CreatedAt:        time.Now(),
-		ExpiresAt:        time.Now().Add(AcceptTimeoutDuration),
+		ID:        matchID,
+		Mode:      mode,
+		Players:   matchPlayers,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(AcceptTimeoutDuration),
 	}
 	
 	activeMatches.AddMatch(matchID, activeMatch)
 	
 	// Update player statuses
-	statusManager.SetPlayerStatus(player1.PlayerID, StatusMatchFound)
-	statusManager.SetPlayerStatus(player2.PlayerID, StatusMatchFound)
+	for _, playerID := range playerIDs {
+		statusManager.SetPlayerStatus(playerID, StatusMatchFound)
+	}
 	
 	// Create database record
+	createMatchRecord(mc.db, activeMatch)
+	
+	// Send match found messages to every player
+	for i, player := range room {
+		// The opponent fields name the next seat so duel clients keep working; in a team game
+		// that is the first player of the other team
+		opponent := room[(i+1)%len(room)]
+		if mode == GameModeTeams {
+			opponent = room[(i/TeamSize+1)%2*TeamSize]
+		}
+		
+		matchData := MatchFoundData{
+			MatchID:           matchID,
+			Mode:              mode,
+			RoomSize:          len(room),
+			PlayerCharacter:   player.SelectedCharacter,
+			PlayerUsername:    player.Username,
+			OpponentCharacter: opponent.SelectedCharacter,
+			OpponentUsername:  opponent.Username,
+			Players:           foundPlayers,
+			Team:              teams[i],
+			AcceptTimeoutMs:   int64(AcceptTimeoutDuration.Milliseconds()),
+		}
+		
+		wsManager.SendMessage(player.PlayerID, WebSocketMessage{
+			Type:      MsgTypeMatchFound,
+			Message:   "Match found! Do you accept?",
+			Data:      matchData,
+			Timestamp: time.Now(),
+		})
+	}
+	
+	utils.Info("Match %s (%s) created between %s", matchID, mode, strings.Join(usernames, ", "))
+	return nil
+}
+
+// createMatchRecord records a duel in the database (online matches record pairs, so only duels get one)
+func createMatchRecord(db *gorm.DB, match *ActiveMatch) {
+	if match.Mode != GameModeDuel || len(match.Players) != DuelRoomSize {
+		return
+	}
+	
+	player1, player2 := match.Players[0], match.Players[1]
 	dbMatch := &model_modules.OnlineMatch{
 		Player1ID:        player1.PlayerID,
 		Player1Username:  player1.Username,
-		Player1Character: player1.SelectedCharacter,
+		Player1Character: player1.Character,
 		Player2ID:        player2.PlayerID,
 		Player2Username:  player2.Username,
-		Player2Character: player2.SelectedCharacter,
+		Player2Character: player2.Character,
 	}
 	
-	if err := mc.db.Create(dbMatch).Error; err != nil {
+	if err := db.Create(dbMatch).Error; err != nil {
 		utils.Info("Failed to create database match record: %v", err)
 	}
-	
-	// Send match found messages to both players
-	matchData1 := MatchFoundData{
-		MatchID:           matchID,
-		PlayerCharacter:   player1.SelectedCharacter,
-		PlayerUsername:    player1.Username,
-		OpponentCharacter: player2.SelectedCharacter,
-		OpponentUsername:  player2.Username,
-		AcceptTimeoutMs:   int64(AcceptTimeoutDuration.Milliseconds()),
-	}
-	
-	matchData2 := MatchFoundData{
-		MatchID:           matchID,
-		PlayerCharacter:   player2.SelectedCharacter,
-		PlayerUsername:    player2.Username,
-		OpponentCharacter: player1.SelectedCharacter,
-		OpponentUsername:  player1.Username,
-		AcceptTimeoutMs:   int64(AcceptTimeoutDuration.Milliseconds()),
+}
+
+// deleteMatchRecord removes the database record of a duel that did not start
+func deleteMatchRecord(db *gorm.DB, match *ActiveMatch) {
+	if match.Mode != GameModeDuel || len(match.Players) != DuelRoomSize {
+		return
 	}
 	
-	wsManager.SendMessage(player1.PlayerID, WebSocketMessage{
-		Type:      MsgTypeMatchFound,
-		Message:   "Match found! Do you accept?",
-		Data:      matchData1,
-		Timestamp: time.Now(),
-	})
-	
-	wsManager.SendMessage(player2.PlayerID, WebSocketMessage{
-		Type:      MsgTypeMatchFound,
-		Message:   "Match found! Do you accept?",
-		Data:      matchData2,
-		Timestamp: time.Now(),
-	})
-	
-	utils.Info("Match %s created between %s and %s", matchID, player1.Username, player2.Username)
-	return nil
-}
\ No newline at end of file
+	player1ID, player2ID := match.Players[0].PlayerID, match.Players[1].PlayerID
+	db.Where("(player1_id = ? AND player2_id = ?) OR (player1_id = ? AND player2_id = ?)", 
+		player1ID, player2ID, player2ID, player1ID).
+		Delete(&model_modules.OnlineMatch{})
+}
@@ -1,9 +1,11 @@
 package matchmaking_modules
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
-	"boba-vim/internal/models/model_modules"
+	"boba-vim/internal/services/cluster"
 	"boba-vim/internal/utils"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -13,13 +15,17 @@ import (
 type MatchLifecycleManager struct {
 	db          *gorm.DB
 	gameStarter MultiplayerGameStarter
+	mapPool     *MapPoolManager
+	mapVetoes   *MapVetoManager
 }
 
 // NewMatchLifecycleManager creates a new match lifecycle manager
-func NewMatchLifecycleManager(db *gorm.DB, gameStarter MultiplayerGameStarter) *MatchLifecycleManager {
+func NewMatchLifecycleManager(db *gorm.DB, node *cluster.Node, gameStarter MultiplayerGameStarter, mapPool *MapPoolManager) *MatchLifecycleManager {
 	return &MatchLifecycleManager{
 		db:          db,
 		gameStarter: gameStarter,
+		mapPool:     mapPool,
+		mapVetoes:   NewMapVetoManager(node),
 	}
 }
 
@@ -36,53 +42,113 @@ func (mlm *MatchLifecycleManager) AcceptMatch(matchID string, playerID uint, act
 	}
 	
 	// Mark player as accepted
-	if match.Player1ID == playerID {
-		if match.Player1Responded {
-			return ErrMatchAlreadyAccepted
-		}
-		match.Player1Accepted = true
-		match.Player1Responded = true
-	} else if match.Player2ID == playerID {
-		if match.Player2Responded {
-			return ErrMatchAlreadyAccepted
-		}
-		match.Player2Accepted = true
-		match.Player2Responded = true
-	} else {
+	player := match.Player(playerID)
+	if player == nil {
 		return ErrInvalidMatchAction
 	}
+	if player.Responded {
+		return ErrMatchAlreadyAccepted
+	}
+	player.Accepted = true
+	player.Responded = true
 	
 	// Update player status
 	statusManager.SetPlayerStatus(playerID, StatusWaitingAccept)
 	
-	// Check if both players accepted
-	if match.Player1Accepted && match.Player2Accepted {
+	// Check if every player accepted
+	if match.AllAccepted() {
 		return mlm.startMatch(match, activeMatches, wsManager, statusManager)
 	}
 	
-	// If only one player accepted, wait for the other
+	// If only some players accepted, wait for the others
 	// Send confirmation to the accepting player
+	acceptedMessage := "Match accepted. Waiting for opponent..."
+	opponentMessage := "Your opponent has accepted the match!"
+	if match.Mode != GameModeDuel {
+		acceptedMessage = "Match accepted. Waiting for the other players..."
+		opponentMessage = fmt.Sprintf("%s has accepted the match!", player.Username)
+	}
+	
 	wsManager.SendMessage(playerID, WebSocketMessage{
 		Type:      MsgTypeMatchAccepted,
-		Message:   "Match accepted. Waiting for opponent...",
+		Message:   acceptedMessage,
 		Timestamp: time.Now(),
 	})
 	
-	// Notify the other player that their opponent has accepted
-	var otherPlayerID uint
-	if match.Player1ID == playerID {
-		otherPlayerID = match.Player2ID
-	} else {
-		otherPlayerID = match.Player1ID
+	// Notify the other players that this player has accepted
+	for _, otherPlayerID := range match.PlayerIDs() {
+		if otherPlayerID == playerID {
+			continue
+		}
+		
+		wsManager.SendMessage(otherPlayerID, WebSocketMessage{
+			Type:      MsgTypeOpponentAccepted,
+			Message:   opponentMessage,
+			Timestamp: time.Now(),
+		})
+	}
+	
+	return nil
+}
+
+// StartDirectMatch starts a duel between two players without the queue: a player challenging a
+// friend. The challenger has already accepted, so the match starts like any other once the
+// challenged player accepts it.
+func (mlm *MatchLifecycleManager) StartDirectMatch(challenger, opponent MatchParticipant, activeMatches ActiveMatchesInterface, wsManager WebSocketManager, statusManager StatusInterface) (string, error) {
+	if !wsManager.IsPlayerConnected(opponent.PlayerID) {
+		return "", ErrPlayerNotConnected
+	}
+	for _, playerID := range []uint{challenger.PlayerID, opponent.PlayerID} {
+		switch statusManager.GetPlayerStatus(playerID) {
+		case StatusSearching, StatusMatchFound, StatusWaitingAccept, StatusMapVeto:
+			return "", ErrPlayerBusy
+		}
 	}
 	
-	wsManager.SendMessage(otherPlayerID, WebSocketMessage{
-		Type:      MsgTypeOpponentAccepted,
-		Message:   "Your opponent has accepted the match!",
+	match := &ActiveMatch{
+		ID:   uuid.New().String(),
+		Mode: GameModeDuel,
+		Players: []*MatchPlayer{
+			{MatchParticipant: challenger, Accepted: true, Responded: true},
+			{MatchParticipant: opponent},
+		},
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(DirectMatchTimeout),
+	}
+	
+	activeMatches.AddMatch(match.ID, match)
+	statusManager.SetPlayerStatus(challenger.PlayerID, StatusWaitingAccept)
+	statusManager.SetPlayerStatus(opponent.PlayerID, StatusMatchFound)
+	createMatchRecord(mlm.db, match)
+	
+	wsManager.SendMessage(challenger.PlayerID, WebSocketMessage{
+		Type:      MsgTypeMatchAccepted,
+		Message:   fmt.Sprintf("Challenge sent to %s. Waiting for them to accept...", opponent.Username),
+		Timestamp: time.Now(),
+	})
+	wsManager.SendMessage(opponent.PlayerID, WebSocketMessage{
+		Type:    MsgTypeMatchFound,
+		Message: fmt.Sprintf("%s challenged you to a duel! Do you accept?", challenger.Username),
+		Data: MatchFoundData{
+			MatchID:           match.ID,
+			Mode:              GameModeDuel,
+			RoomSize:          DuelRoomSize,
+			PlayerCharacter:   opponent.Character,
+			PlayerUsername:    opponent.Username,
+			OpponentCharacter: challenger.Character,
+			OpponentUsername:  challenger.Username,
+			Players: []MatchFoundPlayer{
+				{Username: challenger.Username, Character: challenger.Character},
+				{Username: opponent.Username, Character: opponent.Character},
+			},
+			Direct:          true,
+			AcceptTimeoutMs: DirectMatchTimeout.Milliseconds(),
+		},
 		Timestamp: time.Now(),
 	})
 	
-	return nil
+	utils.Info("Match %s created by %s challenging %s", match.ID, challenger.Username, opponent.Username)
+	return match.ID, nil
 }
 
 // RejectMatch handles a player rejecting a match
@@ -93,15 +159,12 @@ func (mlm *MatchLifecycleManager) RejectMatch(matchID string, playerID uint, act
 	}
 	
 	// Mark player as rejected
-	if match.Player1ID == playerID {
-		match.Player1Accepted = false
-		match.Player1Responded = true
-	} else if match.Player2ID == playerID {
-		match.Player2Accepted = false
-		match.Player2Responded = true
-	} else {
+	player := match.Player(playerID)
+	if player == nil {
 		return ErrInvalidMatchAction
 	}
+	player.Accepted = false
+	player.Responded = true
 	
 	// Cancel the match
 	return mlm.cancelMatch(match, playerID, activeMatches, wsManager, statusManager)
@@ -113,35 +176,32 @@ func (mlm *MatchLifecycleManager) cancelMatch(match *ActiveMatch, rejectingPlaye
 	activeMatches.RemoveMatch(match.ID)
 	
 	// Update player statuses
-	statusManager.SetPlayerStatus(match.Player1ID, StatusIdle)
-	statusManager.SetPlayerStatus(match.Player2ID, StatusIdle)
+	for _, playerID := range match.PlayerIDs() {
+		statusManager.SetPlayerStatus(playerID, StatusIdle)
+	}
 	
 	// Remove from database
-	mlm.db.Where("(player1_id = ? AND player2_id = ?) OR (player1_id = ? AND player2_id = ?)", 
-		match.Player1ID, match.Player2ID, match.Player2ID, match.Player1ID).
-		Delete(&model_modules.OnlineMatch{})
+	deleteMatchRecord(mlm.db, match)
 	
 	// Send rejection messages
-	if rejectingPlayerID == match.Player1ID {
-		wsManager.SendMessage(match.Player1ID, WebSocketMessage{
-			Type:      MsgTypeMatchRejected,
-			Message:   "You rejected the match",
-			Timestamp: time.Now(),
-		})
-		wsManager.SendMessage(match.Player2ID, WebSocketMessage{
-			Type:      MsgTypeOpponentRejected,
-			Message:   "Your opponent rejected the match",
-			Timestamp: time.Now(),
-		})
-	} else {
-		wsManager.SendMessage(match.Player2ID, WebSocketMessage{
-			Type:      MsgTypeMatchRejected,
-			Message:   "You rejected the match",
-			Timestamp: time.Now(),
-		})
-		wsManager.SendMessage(match.Player1ID, WebSocketMessage{
+	opponentMessage := "Your opponent rejected the match"
+	if match.Mode != GameModeDuel {
+		opponentMessage = "A player rejected the match"
+	}
+	
+	for _, playerID := range match.PlayerIDs() {
+		if playerID == rejectingPlayerID {
+			wsManager.SendMessage(playerID, WebSocketMessage{
+				Type:      MsgTypeMatchRejected,
+				Message:   "You rejected the match",
+				Timestamp: time.Now(),
+			})
+			continue
+		}
+		
+		wsManager.SendMessage(playerID, WebSocketMessage{
 			Type:      MsgTypeOpponentRejected,
-			Message:   "Your opponent rejected the match",
+			Message:   opponentMessage,
 			Timestamp: time.Now(),
 		})
 	}
@@ -150,39 +210,59 @@ func (mlm *MatchLifecycleManager) cancelMatch(match *ActiveMatch, rejectingPlaye
 	return nil
 }
 
-// startMatch starts a match when both players accept
+// SubmitMapVeto handles a player banning or picking a map during a map veto
+func (mlm *MatchLifecycleManager) SubmitMapVeto(matchID string, playerID uint, action string, mapID int, wsManager WebSocketManager) error {
+	return mlm.mapVetoes.Act(matchID, playerID, action, mapID, wsManager)
+}
+
+// startMatch starts a match when every player accepts. Duels first ban and pick their map from
+// the ranked pool; larger rooms play a random map of the pool.
 func (mlm *MatchLifecycleManager) startMatch(match *ActiveMatch, activeMatches ActiveMatchesInterface, wsManager WebSocketManager, statusManager StatusInterface) error {
 	// Remove from active matches
 	activeMatches.RemoveMatch(match.ID)
 	
+	if match.Mode != GameModeDuel {
+		randomMap := mlm.mapPool.RandomRankedMap()
+		return mlm.launchMatch(match, MapSelection{MapID: randomMap.ID, MapName: randomMap.Name}, wsManager, statusManager)
+	}
+	
+	for _, playerID := range match.PlayerIDs() {
+		statusManager.SetPlayerStatus(playerID, StatusMapVeto)
+	}
+	
+	mlm.mapVetoes.Begin(match.ID, match.Participants(), mlm.mapPool.RankedMaps(), wsManager, func(selection MapSelection) {
+		if err := mlm.launchMatch(match, selection, wsManager, statusManager); err != nil {
+			utils.Error("Failed to launch match %s after its map veto: %v", match.ID, err)
+		}
+	})
+	return nil
+}
+
+// launchMatch starts the game of a match on the selected map
+func (mlm *MatchLifecycleManager) launchMatch(match *ActiveMatch, selection MapSelection, wsManager WebSocketManager, statusManager StatusInterface) error {
 	// Update player statuses
-	statusManager.SetPlayerStatus(match.Player1ID, StatusMatchAccepted)
-	statusManager.SetPlayerStatus(match.Player2ID, StatusMatchAccepted)
+	playerIDs := match.PlayerIDs()
+	for _, playerID := range playerIDs {
+		statusManager.SetPlayerStatus(playerID, StatusMatchAccepted)
+	}
 	
 	// Start the multiplayer game
 	if mlm.gameStarter != nil {
-		err := mlm.gameStarter.StartMultiplayerGame(
-			match.ID,
-			match.Player1ID,
-			match.Player1Username,
-			match.Player1Character,
-			match.Player2ID,
-			match.Player2Username,
-			match.Player2Character,
-		)
+		err := mlm.gameStarter.StartMultiplayerGame(match.ID, match.Mode, match.Participants(), selection)
 		
 		if err != nil {
 			utils.Info("Failed to start multiplayer game: %v", err)
 			
-			// Send error messages to both players
+			// Send error messages to every player
 			errorMessage := WebSocketMessage{
 				Type:      MsgTypeError,
 				Message:   "Failed to start game. Please try again.",
 				Timestamp: time.Now(),
 			}
 			
-			wsManager.SendMessage(match.Player1ID, errorMessage)
-			wsManager.SendMessage(match.Player2ID, errorMessage)
+			for _, playerID := range playerIDs {
+				wsManager.SendMessage(playerID, errorMessage)
+			}
 			
 			return err
 		}
@@ -191,29 +271,36 @@ func (mlm *MatchLifecycleManager) startMatch(match *ActiveMatch, activeMatches A
 	// Generate game session ID for redirection
 	gameSessionID := uuid.New().String()
 	
+	usernames := make([]string, len(match.Players))
+	for i, player := range match.Players {
+		usernames[i] = player.Username
+	}
+	
 	startData := MatchStartData{
 		MatchID:         match.ID,
 		GameSessionID:   gameSessionID,
-		Map:             "default", // Will be determined by the game service
+		Map:             selection.MapName,
 		GameServerURL:   "/play",   // Redirect to game page
-		Player1Username: match.Player1Username,
-		Player2Username: match.Player2Username,
+		Player1Username: usernames[0],
+		Player2Username: usernames[1],
+		Mode:            match.Mode,
+		Usernames:       usernames,
 	}
 	
-	wsManager.SendMessage(match.Player1ID, WebSocketMessage{
-		Type:      MsgTypeMatchStarted,
-		Message:   "Match started! Redirecting to game...",
-		Data:      startData,
-		Timestamp: time.Now(),
-	})
-	
-	wsManager.SendMessage(match.Player2ID, WebSocketMessage{
-		Type:      MsgTypeMatchStarted,
-		Message:   "Match started! Redirecting to game...",
-		Data:      startData,
-		Timestamp: time.Now(),
-	})
+	for _, playerID := range playerIDs {
+		wsManager.SendMessage(playerID, WebSocketMessage{
+			Type:      MsgTypeMatchStarted,
+			Message:   "Match started! Redirecting to game...",
+			Data:      startData,
+			Timestamp: time.Now(),
+		})
+	}
 	
-	utils.Info("Match %s started between %s and %s", match.ID, match.Player1Username, match.Player2Username)
+	utils.Info("Match %s started on %s between %s", match.ID, selection.MapName, strings.Join(usernames, ", "))
 	return nil
-}
\ No newline at end of file
+}
+
+// Cleanup stops the running map vetoes
+func (mlm *MatchLifecycleManager) Cleanup() {
+	mlm.mapVetoes.Cleanup()
+}
@@ -0,0 +1,42 @@
+package matchmaking_modules
+
+import (
+	"boba-vim/internal/services/cluster"
+	"boba-vim/internal/utils"
+)
+
+// matchOwnersCollection is the cluster collection recording which node runs each pending match.
+// A match is run by the node that created it until its game starts; responses to it and its map
+// veto are handled there.
+const matchOwnersCollection = "matchmaking:match_owners"
+
+// claimMatch records that this node runs a match
+func claimMatch(node *cluster.Node, matchID string) {
+	if err := node.Store.Put(matchOwnersCollection, matchID, []byte(node.ID)); err != nil {
+		utils.Error("Failed to record the node of match %s: %v", matchID, err)
+	}
+}
+
+// releaseMatch forgets that this node runs a match
+func releaseMatch(node *cluster.Node, matchID string) {
+	if _, err := node.Store.DeleteIf(matchOwnersCollection, matchID, []byte(node.ID)); err != nil {
+		utils.Error("Failed to forget the node of match %s: %v", matchID, err)
+	}
+}
+
+// MatchOwner returns the other running node that runs a match, or "" when this node should
+// handle it
+func MatchOwner(node *cluster.Node, matchID string) string {
+	owner, err := node.Store.Get(matchOwnersCollection, matchID)
+	if err != nil {
+		if err != cluster.ErrNotFound {
+			utils.Error("Failed to look up the node of match %s: %v", matchID, err)
+		}
+		return ""
+	}
+
+	if string(owner) == node.ID || !node.IsAlive(string(owner)) {
+		return ""
+	}
+	return string(owner)
+}
@@ -1,47 +1,94 @@
 package matchmaking_modules
 
 import (
+	"encoding/json"
 	"fmt"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
 	"boba-vim/internal/models/model_modules"
+	"boba-vim/internal/services/cluster"
 	"boba-vim/internal/utils"
 	"gorm.io/gorm"
 )
 
-// QueueManager handles queue operations
+// queueCollection is the cluster collection holding the queued players of every instance
+const queueCollection = "matchmaking:queue"
+
+// QueueManager handles queue operations. The queue is shared by every instance of the server, so
+// players queued on different instances are matched with each other.
 type QueueManager struct {
-	db         *gorm.DB
-	queue      map[uint]*QueuePlayer
-	queueMutex sync.RWMutex
+	db    *gorm.DB
+	store cluster.Store
 }
 
 // NewQueueManager creates a new queue manager
-func NewQueueManager(db *gorm.DB) *QueueManager {
+func NewQueueManager(db *gorm.DB, store cluster.Store) *QueueManager {
 	return &QueueManager{
 		db:    db,
-		queue: make(map[uint]*QueuePlayer),
+		store: store,
 	}
 }
 
-// JoinQueue adds a player to the matchmaking queue
-func (qm *QueueManager) JoinQueue(playerID uint, username, selectedCharacter string, wsManager WebSocketManager) error {
-	qm.queueMutex.Lock()
-	defer qm.queueMutex.Unlock()
-	
-	// Check if player is already in queue
-	if _, exists := qm.queue[playerID]; exists {
-		return ErrPlayerAlreadyInQueue
+// playerKey returns the cluster key of a player
+func playerKey(playerID uint) string {
+	return strconv.FormatUint(uint64(playerID), 10)
+}
+
+// NormalizeQueueMode validates a requested game mode and room size and fills in defaults.
+// Duels always hold two players and team games four; free-for-all rooms default to the smallest size.
+func NormalizeQueueMode(mode string, roomSize int) (string, int, error) {
+	switch mode {
+	case "", GameModeDuel:
+		if roomSize != 0 && roomSize != DuelRoomSize {
+			return "", 0, ErrInvalidRoomSize
+		}
+		return GameModeDuel, DuelRoomSize, nil
+	case GameModeFreeForAll:
+		if roomSize == 0 {
+			roomSize = MinFreeForAllRoomSize
+		}
+		if roomSize < MinFreeForAllRoomSize || roomSize > MaxFreeForAllRoomSize {
+			return "", 0, ErrInvalidRoomSize
+		}
+		return GameModeFreeForAll, roomSize, nil
+	case GameModeTeams:
+		if roomSize != 0 && roomSize != TeamRoomSize {
+			return "", 0, ErrInvalidTeamRoomSize
+		}
+		return GameModeTeams, TeamRoomSize, nil
+	default:
+		return "", 0, ErrInvalidGameMode
+	}
+}
+
+// JoinQueue adds a player to the matchmaking queue for the given mode and room size. A player
+// queuing for a team game may name a partner; the two are matched as a team once the partner
+// queues naming them back.
+func (qm *QueueManager) JoinQueue(playerID uint, username, selectedCharacter, mode string, roomSize int, partner string, wsManager WebSocketManager) error {
+	mode, roomSize, err := NormalizeQueueMode(mode, roomSize)
+	if err != nil {
+		return err
+	}
+	if partner != "" {
+		if mode != GameModeTeams {
+			return ErrPartnerNotAllowed
+		}
+		if strings.EqualFold(partner, username) {
+			return ErrInvalidPartner
+		}
 	}
 	
+	playerRating := qm.playerRating(playerID)
+	
 	// Check if player is connected via WebSocket
 	if !wsManager.IsPlayerConnected(playerID) {
 		return ErrPlayerNotConnected
 	}
 	
 	// Check queue size limit
-	if len(qm.queue) >= MaxQueueSize {
+	if qm.GetQueueSize() >= MaxQueueSize {
 		return fmt.Errorf("queue is full (max %d players)", MaxQueueSize)
 	}
 	
@@ -50,21 +97,40 @@ func (qm *QueueManager) JoinQueue(playerID uint, username, selectedCharacter str
 		PlayerID:          playerID,
 		Username:          username,
 		SelectedCharacter: selectedCharacter,
+		Mode:              mode,
+		RoomSize:          roomSize,
+		Partner:           partner,
+		Rating:            playerRating,
 		QueuedAt:          time.Now(),
 	}
 	
-	qm.queue[playerID] = queuePlayer
+	data, err := json.Marshal(queuePlayer)
+	if err != nil {
+		return err
+	}
+	
+	// Check if player is already in queue
+	added, err := qm.store.PutIfAbsent(queueCollection, playerKey(playerID), data)
+	if err != nil {
+		return fmt.Errorf("failed to join queue: %v", err)
+	}
+	if !added {
+		return ErrPlayerAlreadyInQueue
+	}
 	
 	// Add to database
 	dbQueue := &model_modules.MatchmakingQueue{
 		PlayerID:          playerID,
 		Username:          username,
 		SelectedCharacter: selectedCharacter,
+		Mode:              mode,
+		RoomSize:          roomSize,
+		Partner:           partner,
 	}
 	
 	if err := qm.db.Create(dbQueue).Error; err != nil {
-		// Remove from memory queue if DB insert fails
-		delete(qm.queue, playerID)
+		// Remove from the shared queue if DB insert fails
+		qm.store.Delete(queueCollection, playerKey(playerID))
 		return fmt.Errorf("failed to add to database queue: %v", err)
 	}
 	
@@ -75,23 +141,30 @@ func (qm *QueueManager) JoinQueue(playerID uint, username, selectedCharacter str
 		Timestamp: time.Now(),
 	})
 	
-	utils.Info("Player %d (%s) joined matchmaking queue", playerID, username)
+	utils.Info("Player %d (%s) joined matchmaking queue (%s, %d players, rating %.0f)", playerID, username, mode, roomSize, playerRating)
 	return nil
 }
 
+// playerRating returns the player's multiplayer rating, or the starting rating if they haven't played
+func (qm *QueueManager) playerRating(playerID uint) float64 {
+	var stats model_modules.MultiplayerPlayerStats
+	if err := qm.db.Select("rating").Where("player_id = ?", playerID).First(&stats).Error; err != nil {
+		return DefaultQueueRating
+	}
+	return stats.Rating
+}
+
 // LeaveQueue removes a player from the matchmaking queue
 func (qm *QueueManager) LeaveQueue(playerID uint, wsManager WebSocketManager) error {
-	qm.queueMutex.Lock()
-	defer qm.queueMutex.Unlock()
-	
-	// Check if player is in queue
-	if _, exists := qm.queue[playerID]; !exists {
+	// Remove from the shared queue
+	removed, err := qm.store.Delete(queueCollection, playerKey(playerID))
+	if err != nil {
+		return fmt.Errorf("failed to leave queue: %v", err)
+	}
+	if !removed {
 		return ErrPlayerNotInQueue
 	}
 	
-	// Remove from memory queue
-	delete(qm.queue, playerID)
-	
 	// Remove from database
 	qm.db.Where("player_id = ?", playerID).Delete(&model_modules.MatchmakingQueue{})
 	
@@ -106,13 +179,32 @@ func (qm *QueueManager) LeaveQueue(playerID uint, wsManager WebSocketManager) er
 	return nil
 }
 
+// queuedPlayers returns every player in the shared queue
+func (qm *QueueManager) queuedPlayers() []*QueuePlayer {
+	entries, err := qm.store.List(queueCollection)
+	if err != nil {
+		utils.Error("Failed to read matchmaking queue: %v", err)
+		return nil
+	}
+	
+	players := make([]*QueuePlayer, 0, len(entries))
+	for key, data := range entries {
+		var player QueuePlayer
+		if err := json.Unmarshal(data, &player); err != nil {
+			utils.Error("Invalid matchmaking queue entry %s: %v", key, err)
+			continue
+		}
+		players = append(players, &player)
+	}
+	return players
+}
+
 // GetQueuedPlayers returns queued players for matching
 func (qm *QueueManager) GetQueuedPlayers(wsManager WebSocketManager) []*QueuePlayer {
-	qm.queueMutex.RLock()
-	defer qm.queueMutex.RUnlock()
+	queued := qm.queuedPlayers()
 	
-	players := make([]*QueuePlayer, 0, len(qm.queue))
-	for _, player := range qm.queue {
+	players := make([]*QueuePlayer, 0, len(queued))
+	for _, player := range queued {
 		// Only include players who are still connected
 		if wsManager.IsPlayerConnected(player.PlayerID) {
 			players = append(players, player)
@@ -124,11 +216,10 @@ func (qm *QueueManager) GetQueuedPlayers(wsManager WebSocketManager) []*QueuePla
 
 // RemovePlayersFromQueue removes multiple players from queue
 func (qm *QueueManager) RemovePlayersFromQueue(playerIDs []uint) {
-	qm.queueMutex.Lock()
-	defer qm.queueMutex.Unlock()
-	
 	for _, playerID := range playerIDs {
-		delete(qm.queue, playerID)
+		if _, err := qm.store.Delete(queueCollection, playerKey(playerID)); err != nil {
+			utils.Error("Failed to remove player %d from matchmaking queue: %v", playerID, err)
+		}
 	}
 	
 	// Remove from database
@@ -139,22 +230,27 @@ func (qm *QueueManager) RemovePlayersFromQueue(playerIDs []uint) {
 
 // CheckQueueTimeouts removes players who have been in queue too long
 func (qm *QueueManager) CheckQueueTimeouts(wsManager WebSocketManager) {
-	qm.queueMutex.Lock()
-	defer qm.queueMutex.Unlock()
-	
 	now := time.Now()
-	for playerID, queuePlayer := range qm.queue {
+	for _, queuePlayer := range qm.queuedPlayers() {
+		playerID := queuePlayer.PlayerID
 		if now.Sub(queuePlayer.QueuedAt) > QueueTimeoutDuration {
-			// Remove from queue
-			delete(qm.queue, playerID)
+			// Remove from queue, unless the player left it in the meantime
+			if removed, err := qm.store.Delete(queueCollection, playerKey(playerID)); err != nil || !removed {
+				continue
+			}
 			
 			// Remove from database
 			qm.db.Where("player_id = ?", playerID).Delete(&model_modules.MatchmakingQueue{})
 			
-			// Send timeout message
+			// Send timeout message, offering a practice game against bots in the same mode instead
 			wsManager.SendMessage(playerID, WebSocketMessage{
-				Type:      MsgTypeQueueTimeout,
-				Message:   "Matchmaking timeout. No opponent found.",
+				Type:    MsgTypeQueueTimeout,
+				Message: "Matchmaking timeout. No opponent found.",
+				Data: map[string]interface{}{
+					"practice_offered": true,
+					"mode":             queuePlayer.Mode,
+					"room_size":        queuePlayer.RoomSize,
+				},
 				Timestamp: time.Now(),
 			})
 			
@@ -165,16 +261,10 @@ func (qm *QueueManager) CheckQueueTimeouts(wsManager WebSocketManager) {
 
 // GetQueueSize returns the current size of the matchmaking queue
 func (qm *QueueManager) GetQueueSize() int {
-	qm.queueMutex.RLock()
-	defer qm.queueMutex.RUnlock()
-	
-	return len(qm.queue)
-}
-
-// Cleanup clears the queue
-func (qm *QueueManager) Cleanup() {
-	qm.queueMutex.Lock()
-	defer qm.queueMutex.Unlock()
-	
-	qm.queue = make(map[uint]*QueuePlayer)
+	size, err := qm.store.Count(queueCollection)
+	if err != nil {
+		utils.Error("Failed to read matchmaking queue size: %v", err)
+		return 0
+	}
+	return size
 }
\ No newline at end of file
@@ -1,45 +1,50 @@
 package matchmaking_modules
 
 import (
-	"sync"
+	"boba-vim/internal/services/cluster"
+	"boba-vim/internal/utils"
 )
 
-// StatusManager handles player status tracking
+// statusCollection is the cluster collection holding the matchmaking status of every player
+const statusCollection = "matchmaking:status"
+
+// StatusManager handles player status tracking. Statuses are shared by every instance of the
+// server, since a player's requests may reach any of them.
 type StatusManager struct {
-	playerStatus map[uint]MatchmakingStatus
-	statusMutex  sync.RWMutex
+	store cluster.Store
 }
 
 // NewStatusManager creates a new status manager
-func NewStatusManager() *StatusManager {
+func NewStatusManager(store cluster.Store) *StatusManager {
 	return &StatusManager{
-		playerStatus: make(map[uint]MatchmakingStatus),
+		store: store,
 	}
 }
 
 // SetPlayerStatus sets the status of a player
 func (sm *StatusManager) SetPlayerStatus(playerID uint, status MatchmakingStatus) {
-	sm.statusMutex.Lock()
-	defer sm.statusMutex.Unlock()
-	
-	sm.playerStatus[playerID] = status
+	var err error
+	if status == StatusIdle {
+		_, err = sm.store.Delete(statusCollection, playerKey(playerID))
+	} else {
+		err = sm.store.Put(statusCollection, playerKey(playerID), []byte(status))
+	}
+	if err != nil {
+		utils.Error("Failed to set matchmaking status of player %d: %v", playerID, err)
+	}
 }
 
 // GetPlayerStatus returns the current status of a player
 func (sm *StatusManager) GetPlayerStatus(playerID uint) MatchmakingStatus {
-	sm.statusMutex.RLock()
-	defer sm.statusMutex.RUnlock()
-	
-	if status, exists := sm.playerStatus[playerID]; exists {
-		return status
+	status, err := sm.store.Get(statusCollection, playerKey(playerID))
+	if err != nil {
+		if err != cluster.ErrNotFound {
+			utils.Error("Failed to get matchmaking status of player %d: %v", playerID, err)
+		}
+		return StatusIdle
 	}
-	return StatusIdle
+	return MatchmakingStatus(status)
 }
 
-// Cleanup clears all player statuses
-func (sm *StatusManager) Cleanup() {
-	sm.statusMutex.Lock()
-	defer sm.statusMutex.Unlock()
-	
-	sm.playerStatus = make(map[uint]MatchmakingStatus)
-}
\ No newline at end of file
+// Cleanup leaves the shared statuses to the other instances
+func (sm *StatusManager) Cleanup() {}
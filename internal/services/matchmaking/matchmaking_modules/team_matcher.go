@@ -0,0 +1,150 @@
+package matchmaking_modules
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// queueParty is a group of queued players who must be matched together: a player queuing alone,
+// or two partners who named each other
+type queueParty struct {
+	players  []*QueuePlayer
+	rating   float64   // Average rating of the players
+	queuedAt time.Time // When the first of the players queued
+}
+
+// queueParties groups players waiting for a team game into parties, ordered by queue time.
+// Players whose partner hasn't queued naming them back wait until they do.
+func queueParties(waiting []*QueuePlayer) []*queueParty {
+	byUsername := make(map[string]*QueuePlayer, len(waiting))
+	for _, player := range waiting {
+		byUsername[strings.ToLower(player.Username)] = player
+	}
+
+	grouped := make(map[*QueuePlayer]bool, len(waiting))
+	var parties []*queueParty
+	for _, player := range waiting {
+		if grouped[player] {
+			continue
+		}
+
+		party := &queueParty{players: []*QueuePlayer{player}, queuedAt: player.QueuedAt}
+		if player.Partner != "" {
+			partner := byUsername[strings.ToLower(player.Partner)]
+			if partner == nil || grouped[partner] || !strings.EqualFold(partner.Partner, player.Username) {
+				continue
+			}
+			party.players = append(party.players, partner)
+			grouped[partner] = true
+		}
+		grouped[player] = true
+
+		total := 0.0
+		for _, member := range party.players {
+			total += member.Rating
+		}
+		party.rating = total / float64(len(party.players))
+		parties = append(parties, party)
+	}
+
+	// waiting is in queue order, so parties already are, ordered by their first player
+	return parties
+}
+
+// pickTeamRoom picks the players of a team game from waiting, which is ordered by queue time.
+// Parties are tried in turn, longest waiting first, as the anchor of a room filled with the
+// parties closest to their rating, the same way pickRatedRoom fills other rooms. The room is
+// returned in seat order, the first team's players first. It returns nil when no room can be
+// filled yet, along with the players left waiting.
+func pickTeamRoom(waiting []*QueuePlayer, now time.Time) ([]*QueuePlayer, []*QueuePlayer) {
+	parties := queueParties(waiting)
+
+	for _, anchor := range parties {
+		anchorWindow := RatingWindow(now.Sub(anchor.queuedAt))
+
+		candidates := make([]*queueParty, 0, len(parties)-1)
+		for _, party := range parties {
+			if party == anchor {
+				continue
+			}
+			window := math.Max(anchorWindow, RatingWindow(now.Sub(party.queuedAt)))
+			if math.Abs(party.rating-anchor.rating) <= window {
+				candidates = append(candidates, party)
+			}
+		}
+
+		// Closest ratings first; candidates are already in queue order for equal gaps
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return math.Abs(candidates[i].rating-anchor.rating) < math.Abs(candidates[j].rating-anchor.rating)
+		})
+
+		room := []*queueParty{anchor}
+		size := len(anchor.players)
+		for _, party := range candidates {
+			if size+len(party.players) > TeamRoomSize {
+				continue
+			}
+			room = append(room, party)
+			size += len(party.players)
+		}
+		if size < TeamRoomSize {
+			continue
+		}
+
+		seated := splitTeams(room)
+		picked := make(map[*QueuePlayer]bool, len(seated))
+		for _, player := range seated {
+			picked[player] = true
+		}
+
+		remaining := make([]*QueuePlayer, 0, len(waiting)-len(seated))
+		for _, player := range waiting {
+			if !picked[player] {
+				remaining = append(remaining, player)
+			}
+		}
+		return seated, remaining
+	}
+
+	return nil, waiting
+}
+
+// splitTeams seats the parties of a full team room in two teams. Partners always share a team;
+// players queuing alone fill the remaining places so the teams' ratings are as even as possible.
+func splitTeams(room []*queueParty) []*QueuePlayer {
+	var teams [2][]*QueuePlayer
+	var solos []*QueuePlayer
+	for _, party := range room {
+		if len(party.players) == TeamSize {
+			if len(teams[0]) == 0 {
+				teams[0] = party.players
+			} else {
+				teams[1] = party.players
+			}
+			continue
+		}
+		solos = append(solos, party.players...)
+	}
+
+	// Four players alone: the best and worst rated play the middle two
+	if len(solos) == TeamRoomSize {
+		sort.SliceStable(solos, func(i, j int) bool {
+			return solos[i].Rating > solos[j].Rating
+		})
+		teams[0] = []*QueuePlayer{solos[0], solos[3]}
+		teams[1] = []*QueuePlayer{solos[1], solos[2]}
+		solos = nil
+	}
+
+	for _, player := range solos {
+		if len(teams[0]) < TeamSize {
+			teams[0] = append(teams[0], player)
+		} else {
+			teams[1] = append(teams[1], player)
+		}
+	}
+
+	return append(append([]*QueuePlayer{}, teams[0]...), teams[1]...)
+}
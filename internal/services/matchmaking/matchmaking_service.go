@@ -3,9 +3,12 @@ package matchmaking
 import (
 	"net/http"
 	"sync"
+	"time"
 
 	"boba-vim/internal/cache"
 	"boba-vim/internal/models"
+	"boba-vim/internal/services/cluster"
+	"boba-vim/internal/services/matchmaking/matchmaking_modules"
 	"boba-vim/internal/utils"
 
 	"github.com/gin-contrib/sessions"
@@ -25,13 +28,13 @@ type MatchmakingService struct {
 
 // MultiplayerGameService interface for game operations
 type MultiplayerGameService interface {
-	StartMultiplayerGame(matchID string, player1ID uint, player1Username, player1Character string, player2ID uint, player2Username, player2Character string) (interface{}, error)
+	StartMultiplayerGame(matchID string, mode string, participants []MatchParticipant, mapSelection MapSelection) (interface{}, error)
 	HandlePlayerDisconnect(playerID uint)
 }
 
-// NewMatchmakingService creates a new matchmaking service
-func NewMatchmakingService(db *gorm.DB, multiplayerGame MultiplayerGameService) *MatchmakingService {
-	wsManager := NewWebSocketManager()
+// NewMatchmakingService creates a new matchmaking service on a cluster node
+func NewMatchmakingService(db *gorm.DB, node *cluster.Node, multiplayerGame MultiplayerGameService) *MatchmakingService {
+	wsManager := NewWebSocketManager(node, "matchmaking")
 	
 	ms := &MatchmakingService{
 		db:              db,
@@ -41,22 +44,54 @@ func NewMatchmakingService(db *gorm.DB, multiplayerGame MultiplayerGameService)
 	}
 	
 	// Create manager with game starter
-	manager := NewMatchmakingManager(db, wsManager, ms)
+	manager := NewMatchmakingManager(db, node, wsManager, ms)
 	ms.manager = manager
+	wsManager.SetMessageHandler(ms.handleClientMessage)
 	
 	return ms
 }
 
 // StartMultiplayerGame implements the MultiplayerGameStarter interface
-func (ms *MatchmakingService) StartMultiplayerGame(matchID string, player1ID uint, player1Username, player1Character string, player2ID uint, player2Username, player2Character string) error {
+func (ms *MatchmakingService) StartMultiplayerGame(matchID string, mode string, participants []MatchParticipant, mapSelection MapSelection) error {
 	if ms.multiplayerGame != nil {
-		_, err := ms.multiplayerGame.StartMultiplayerGame(matchID, player1ID, player1Username, player1Character, player2ID, player2Username, player2Character)
+		_, err := ms.multiplayerGame.StartMultiplayerGame(matchID, mode, participants, mapSelection)
 		return err
 	}
 	utils.Error("No multiplayer game service available")
 	return nil
 }
 
+// WebSocketManager returns the manager of the matchmaking WebSocket connections
+func (ms *MatchmakingService) WebSocketManager() *WebSocketManager {
+	return ms.wsManager
+}
+
+// MapPool returns the manager of the ranked map pool
+func (ms *MatchmakingService) MapPool() *MapPoolManager {
+	return ms.manager.MapPool()
+}
+
+// handleClientMessage handles a message a player sent over the matchmaking WebSocket
+func (ms *MatchmakingService) handleClientMessage(playerID uint, message ClientMessage) {
+	var err error
+	switch message.Type {
+	case MsgTypeMapBan:
+		err = ms.manager.SubmitMapVeto(message.MatchID, playerID, matchmaking_modules.MapVetoActionBan, message.MapID)
+	case MsgTypeMapPick:
+		err = ms.manager.SubmitMapVeto(message.MatchID, playerID, matchmaking_modules.MapVetoActionPick, message.MapID)
+	default:
+		return
+	}
+	
+	if err != nil {
+		ms.wsManager.SendMessage(playerID, WebSocketMessage{
+			Type:      MsgTypeError,
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
 // DB returns the database instance
 func (ms *MatchmakingService) DB() *gorm.DB {
 	return ms.db
@@ -100,7 +135,7 @@ func (ms *MatchmakingService) JoinQueue(c *gin.Context) {
 	}
 	
 	// Join queue
-	err = ms.manager.JoinQueue(playerID, username, request.SelectedCharacter)
+	err = ms.manager.JoinQueue(playerID, username, request.SelectedCharacter, request.Mode, request.RoomSize, request.Partner)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -133,6 +168,13 @@ func (ms *MatchmakingService) LeaveQueue(c *gin.Context) {
 	})
 }
 
+// RemoveFromQueue takes a player out of the matchmaking queue if they are in it
+func (ms *MatchmakingService) RemoveFromQueue(playerID uint) {
+	if err := ms.manager.LeaveQueue(playerID); err != nil && err != matchmaking_modules.ErrPlayerNotInQueue {
+		utils.Error("Failed to remove player %d from the queue: %v", playerID, err)
+	}
+}
+
 // AcceptMatch handles accepting a match
 func (ms *MatchmakingService) AcceptMatch(c *gin.Context) {
 	playerID, _, err := ValidatePlayerSession(c)
@@ -181,6 +223,22 @@ func (ms *MatchmakingService) GetPlayerStatus(c *gin.Context) {
 	})
 }
 
+// PlayerStatus returns where a player stands in matchmaking
+func (ms *MatchmakingService) PlayerStatus(playerID uint) MatchmakingStatus {
+	return ms.manager.GetPlayerStatus(playerID)
+}
+
+// IsPlayerConnected reports whether a player is connected to the matchmaking WebSocket
+func (ms *MatchmakingService) IsPlayerConnected(playerID uint) bool {
+	return ms.wsManager.IsPlayerConnected(playerID)
+}
+
+// ChallengePlayer starts a duel between a player and someone they challenged directly,
+// skipping the queue. It returns the ID of the match the challenged player is asked to accept.
+func (ms *MatchmakingService) ChallengePlayer(challenger, opponent MatchParticipant) (string, error) {
+	return ms.manager.ChallengePlayer(challenger, opponent)
+}
+
 // GetQueueStatus returns general queue information
 func (ms *MatchmakingService) GetQueueStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
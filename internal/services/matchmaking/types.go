@@ -3,6 +3,8 @@ package matchmaking
 import (
 	"errors"
 	"time"
+
+	"boba-vim/internal/services/matchmaking/matchmaking_modules"
 )
 
 // Error definitions
@@ -14,8 +16,42 @@ var (
 	ErrMatchAlreadyAccepted  = errors.New("match already accepted")
 	ErrMatchExpired          = errors.New("match expired")
 	ErrInvalidMatchAction    = errors.New("invalid match action")
+	
+	// Ranked map pool errors
+	ErrUnknownMap            = matchmaking_modules.ErrUnknownMap
+	ErrMapAlreadyInPool      = matchmaking_modules.ErrMapAlreadyInPool
+	ErrMapNotInPool          = matchmaking_modules.ErrMapNotInPool
+	
+	// Direct match errors
+	ErrPlayerBusy            = matchmaking_modules.ErrPlayerBusy
+)
+
+// Game modes a player can queue for
+const (
+	GameModeDuel          = matchmaking_modules.GameModeDuel
+	GameModeFreeForAll    = matchmaking_modules.GameModeFreeForAll
+	GameModeTeams         = matchmaking_modules.GameModeTeams
+	MinFreeForAllRoomSize = matchmaking_modules.MinFreeForAllRoomSize
+	MaxFreeForAllRoomSize = matchmaking_modules.MaxFreeForAllRoomSize
+	TeamRoomSize          = matchmaking_modules.TeamRoomSize
+	TeamSize              = matchmaking_modules.TeamSize
 )
 
+// MatchParticipant is a player taking part in a match, in seat order
+type MatchParticipant = matchmaking_modules.MatchParticipant
+
+// MapSelection is the map a match is played on and the veto that chose it
+type MapSelection = matchmaking_modules.MapSelection
+
+// MapVetoAction is one ban or pick of a map veto
+type MapVetoAction = matchmaking_modules.MapVetoAction
+
+// MapSummary describes a map without its text
+type MapSummary = matchmaking_modules.MapSummary
+
+// MapPoolManager manages the ranked map pool
+type MapPoolManager = matchmaking_modules.MapPoolManager
+
 // MatchmakingStatus represents the current status of a player
 type MatchmakingStatus string
 
@@ -24,6 +60,7 @@ const (
 	StatusSearching      MatchmakingStatus = "searching"
 	StatusMatchFound     MatchmakingStatus = "match_found"
 	StatusWaitingAccept  MatchmakingStatus = "waiting_accept"
+	StatusMapVeto        MatchmakingStatus = "map_veto"
 	StatusMatchAccepted  MatchmakingStatus = "match_accepted"
 	StatusMatchRejected  MatchmakingStatus = "match_rejected"
 	StatusInGame         MatchmakingStatus = "in_game"
@@ -43,6 +80,9 @@ const (
 	MsgTypeOpponentRejected  MessageType = "opponent_rejected"
 	MsgTypeMatchStarted      MessageType = "match_started"
 	MsgTypeMatchCancelled    MessageType = "match_cancelled"
+	MsgTypeMapVeto           MessageType = "map_veto"
+	MsgTypeMapBan            MessageType = "map_ban"
+	MsgTypeMapPick           MessageType = "map_pick"
 	MsgTypeError             MessageType = "error"
 	MsgTypeHeartbeat         MessageType = "heartbeat"
 )
@@ -60,17 +100,36 @@ type QueuePlayer struct {
 	PlayerID          uint      `json:"player_id"`
 	Username          string    `json:"username"`
 	SelectedCharacter string    `json:"selected_character"`
+	Mode              string    `json:"mode"`
+	RoomSize          int       `json:"room_size"`
+	Rating            float64   `json:"rating"`
 	QueuedAt          time.Time `json:"queued_at"`
 }
 
 // MatchFoundData represents the data sent when a match is found
 type MatchFoundData struct {
-	MatchID           string `json:"match_id"`
-	PlayerCharacter   string `json:"player_character"`
-	PlayerUsername    string `json:"player_username"`
-	OpponentCharacter string `json:"opponent_character"`
-	OpponentUsername  string `json:"opponent_username"`
-	AcceptTimeoutMs   int64  `json:"accept_timeout_ms"`
+	MatchID           string             `json:"match_id"`
+	Mode              string             `json:"mode"`
+	RoomSize          int                `json:"room_size"`
+	PlayerCharacter   string             `json:"player_character"`
+	PlayerUsername    string             `json:"player_username"`
+	OpponentCharacter string             `json:"opponent_character"`
+	OpponentUsername  string             `json:"opponent_username"`
+	Players           []MatchFoundPlayer `json:"players"`
+	AcceptTimeoutMs   int64              `json:"accept_timeout_ms"`
+}
+
+// MatchFoundPlayer describes one player of a found match
+type MatchFoundPlayer struct {
+	Username  string `json:"username"`
+	Character string `json:"character"`
+}
+
+// ClientMessage represents a message sent by a player over the matchmaking WebSocket
+type ClientMessage struct {
+	Type    MessageType `json:"type"`
+	MatchID string      `json:"match_id"`
+	MapID   int         `json:"map_id"`
 }
 
 // MatchAcceptanceData represents the data for match acceptance
@@ -86,13 +145,20 @@ type MatchStartData struct {
 	GameSessionID   string `json:"game_session_id"`
 	Map             string `json:"map"`
 	GameServerURL   string `json:"game_server_url"`
-	Player1Username string `json:"player1_username"`
-	Player2Username string `json:"player2_username"`
+	Player1Username string   `json:"player1_username"`
+	Player2Username string   `json:"player2_username"`
+	Mode            string   `json:"mode"`
+	Usernames       []string `json:"usernames"`
 }
 
-// QueueJoinRequest represents a request to join the matchmaking queue
+// QueueJoinRequest represents a request to join the matchmaking queue.
+// Mode defaults to a duel; free-for-all rooms take a room size of 3 to 8 players. Players queuing
+// for a team game together each name the other as their partner.
 type QueueJoinRequest struct {
 	SelectedCharacter string `json:"selected_character"`
+	Mode              string `json:"mode"`
+	RoomSize          int    `json:"room_size"`
+	Partner           string `json:"partner"`
 }
 
 // Constants for timeouts and limits
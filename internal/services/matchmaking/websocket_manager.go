@@ -3,10 +3,13 @@ package matchmaking
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"boba-vim/internal/services/cluster"
 	"boba-vim/internal/utils"
+	"boba-vim/internal/wsproto"
 
 	"github.com/gorilla/websocket"
 )
@@ -14,26 +17,117 @@ import (
 // WebSocketConnection wraps a WebSocket connection with write protection
 type WebSocketConnection struct {
 	conn      *websocket.Conn
+	protocol  wsproto.Protocol // Encoding and schema version the client negotiated
 	writeMu   sync.Mutex
 }
 
-// WebSocketManager manages WebSocket connections for matchmaking
+// WebSocketManager manages WebSocket connections for matchmaking. Players may be connected to any
+// instance of the server: the cluster records which node holds each connection, and messages for
+// players connected elsewhere are delivered through their node.
 type WebSocketManager struct {
-	connections map[uint]*WebSocketConnection
-	mu          sync.RWMutex
-	upgrader    websocket.Upgrader
+	name           string // Tells the managers of the same node apart in the cluster
+	node           *cluster.Node
+	connections    map[uint]*WebSocketConnection
+	mu             sync.RWMutex
+	upgrader       websocket.Upgrader
+	messageHandler func(playerID uint, message ClientMessage)
 }
 
-// NewWebSocketManager creates a new WebSocket manager
-func NewWebSocketManager() *WebSocketManager {
-	return &WebSocketManager{
+// remoteDelivery is a message for players connected to another node. It is sent as JSON; the node
+// holding the connections encodes it again for those speaking MessagePack, which most don't.
+type remoteDelivery struct {
+	PlayerIDs []uint          `json:"player_ids"`
+	Message   json.RawMessage `json:"message"`
+	MsgPack   []byte          `json:"msgpack,omitempty"` // Only sent by older nodes, which encoded both ways
+}
+
+// NewWebSocketManager creates a new WebSocket manager named name on a cluster node
+func NewWebSocketManager(node *cluster.Node, name string) *WebSocketManager {
+	wsm := &WebSocketManager{
+		name:        name,
+		node:        node,
 		connections: make(map[uint]*WebSocketConnection),
-		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true // Allow all origins for now
-			},
-		},
+		upgrader:    wsproto.NewUpgrader(),
+	}
+	
+	node.Handle(wsm.deliveryMethod(), wsm.handleRemoteDelivery)
+	return wsm
+}
+
+// connectionsCollection returns the cluster collection recording the node of every connection
+func (wsm *WebSocketManager) connectionsCollection() string {
+	return "connections:" + wsm.name
+}
+
+// deliveryMethod returns the cluster method delivering messages to this manager's connections
+func (wsm *WebSocketManager) deliveryMethod() string {
+	return "websocket.deliver:" + wsm.name
+}
+
+// playerKey returns the cluster key of a player
+func playerKey(playerID uint) string {
+	return strconv.FormatUint(uint64(playerID), 10)
+}
+
+// claimConnection records in the cluster that this node holds a player's connection
+func (wsm *WebSocketManager) claimConnection(playerID uint) {
+	if err := wsm.node.Store.Put(wsm.connectionsCollection(), playerKey(playerID), []byte(wsm.node.ID)); err != nil {
+		utils.Error("Failed to record the connection of player %d: %v", playerID, err)
+	}
+}
+
+// releaseConnection forgets that this node holds a player's connection, unless another node has
+// taken it over since
+func (wsm *WebSocketManager) releaseConnection(playerID uint) {
+	if _, err := wsm.node.Store.DeleteIf(wsm.connectionsCollection(), playerKey(playerID), []byte(wsm.node.ID)); err != nil {
+		utils.Error("Failed to forget the connection of player %d: %v", playerID, err)
+	}
+}
+
+// remoteNode returns the other node holding a player's connection, or "" when no running node does
+func (wsm *WebSocketManager) remoteNode(playerID uint) string {
+	nodeID, err := wsm.node.Store.Get(wsm.connectionsCollection(), playerKey(playerID))
+	if err != nil {
+		if err != cluster.ErrNotFound {
+			utils.Error("Failed to look up the connection of player %d: %v", playerID, err)
+		}
+		return ""
+	}
+	
+	if string(nodeID) == wsm.node.ID || !wsm.node.IsAlive(string(nodeID)) {
+		return ""
+	}
+	return string(nodeID)
+}
+
+// handleRemoteDelivery writes a message another node sent to players connected here
+func (wsm *WebSocketManager) handleRemoteDelivery(body json.RawMessage) (interface{}, error) {
+	var delivery remoteDelivery
+	if err := json.Unmarshal(body, &delivery); err != nil {
+		return nil, err
+	}
+	
+	encoded := map[string][]byte{wsproto.EncodingJSON: delivery.Message}
+	if len(delivery.MsgPack) > 0 {
+		encoded[wsproto.EncodingMsgPack] = delivery.MsgPack
 	}
+	wsm.writeToLocal(delivery.PlayerIDs, wsproto.NewEncodedMessage(encoded))
+	return nil, nil
+}
+
+// newRemoteDelivery encodes a message for players connected to another node
+func newRemoteDelivery(playerIDs []uint, message *wsproto.Message) (remoteDelivery, error) {
+	data, err := message.Bytes(wsproto.EncodingJSON)
+	if err != nil {
+		return remoteDelivery{}, err
+	}
+	return remoteDelivery{PlayerIDs: playerIDs, Message: data}, nil
+}
+
+// SetMessageHandler sets the handler of messages players send. It must be set before
+// connections are accepted.
+func (wsm *WebSocketManager) SetMessageHandler(handler func(playerID uint, message ClientMessage)) {
+	wsm.messageHandler = handler
 }
 
 // AddConnection adds a new WebSocket connection for a player
@@ -47,9 +141,11 @@ func (wsm *WebSocketManager) AddConnection(playerID uint, conn *websocket.Conn)
 	}
 	
 	wsm.connections[playerID] = &WebSocketConnection{
-		conn:    conn,
-		writeMu: sync.Mutex{},
+		conn:     conn,
+		protocol: wsproto.Negotiated(conn),
+		writeMu:  sync.Mutex{},
 	}
+	wsm.claimConnection(playerID)
 	utils.Debug("WebSocket connection added for player %d", playerID)
 }
 
@@ -61,18 +157,52 @@ func (wsm *WebSocketManager) RemoveConnection(playerID uint) {
 	if wsConn, exists := wsm.connections[playerID]; exists {
 		wsConn.conn.Close()
 		delete(wsm.connections, playerID)
+		wsm.releaseConnection(playerID)
 		utils.Debug("WebSocket connection removed for player %d", playerID)
 	}
 }
 
-// SendMessage sends a message to a specific player
+// RemoveConnectionIfCurrent removes a player's connection only if it is still the given one, so a
+// stale connection closing can't drop the player's newer connection. It reports whether it did.
+func (wsm *WebSocketManager) RemoveConnectionIfCurrent(playerID uint, conn *websocket.Conn) bool {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	
+	wsConn, exists := wsm.connections[playerID]
+	if !exists || wsConn.conn != conn {
+		return false
+	}
+	
+	wsConn.conn.Close()
+	delete(wsm.connections, playerID)
+	wsm.releaseConnection(playerID)
+	utils.Debug("WebSocket connection removed for player %d", playerID)
+	return true
+}
+
+// SendMessage sends a message to a specific player, through their node when they are connected
+// to another instance
 func (wsm *WebSocketManager) SendMessage(playerID uint, message interface{}) error {
 	wsm.mu.RLock()
 	wsConn, exists := wsm.connections[playerID]
 	wsm.mu.RUnlock()
 	
 	if !exists {
-		return ErrPlayerNotConnected
+		nodeID := wsm.remoteNode(playerID)
+		if nodeID == "" {
+			return ErrPlayerNotConnected
+		}
+		
+		delivery, err := newRemoteDelivery([]uint{playerID}, wsproto.NewMessage(message))
+		if err != nil {
+			return err
+		}
+		return wsm.node.Send(nodeID, wsm.deliveryMethod(), delivery)
+	}
+	
+	data, err := wsConn.protocol.Marshal(message)
+	if err != nil {
+		return err
 	}
 	
 	// Use write mutex to prevent concurrent writes to the same connection
@@ -80,11 +210,52 @@ func (wsm *WebSocketManager) SendMessage(playerID uint, message interface{}) err
 	defer wsConn.writeMu.Unlock()
 	
 	wsConn.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-	return wsConn.conn.WriteJSON(message)
+	return wsConn.conn.WriteMessage(wsConn.protocol.FrameType(), data)
 }
 
-// BroadcastToPlayers sends a message to multiple players with optimized concurrency
+// BroadcastToPlayers sends a message to multiple players with optimized concurrency.
+// The message is encoded once per encoding and the same prepared frame is written to every
+// connection speaking it; players connected to other instances get it through one message to each
+// of their nodes.
 func (wsm *WebSocketManager) BroadcastToPlayers(playerIDs []uint, message interface{}) {
+	broadcast := wsproto.NewMessage(message)
+	
+	var localIDs, elsewhereIDs []uint
+	wsm.mu.RLock()
+	for _, playerID := range playerIDs {
+		if _, exists := wsm.connections[playerID]; exists {
+			localIDs = append(localIDs, playerID)
+		} else {
+			elsewhereIDs = append(elsewhereIDs, playerID)
+		}
+	}
+	wsm.mu.RUnlock()
+	
+	// Group the players connected to other nodes by node
+	remoteIDs := make(map[string][]uint)
+	for _, playerID := range elsewhereIDs {
+		if nodeID := wsm.remoteNode(playerID); nodeID != "" {
+			remoteIDs[nodeID] = append(remoteIDs[nodeID], playerID)
+		}
+	}
+	
+	for nodeID, nodePlayerIDs := range remoteIDs {
+		delivery, err := newRemoteDelivery(nodePlayerIDs, broadcast)
+		if err != nil {
+			utils.Error("Failed to encode broadcast message: %v", err)
+			return
+		}
+		if err := wsm.node.Send(nodeID, wsm.deliveryMethod(), delivery); err != nil {
+			utils.Error("Failed to forward broadcast to node %s: %v", nodeID, err)
+		}
+	}
+	
+	wsm.writeToLocal(localIDs, broadcast)
+}
+
+// writeToLocal writes a message to the players connected to this node, in the encoding each
+// connection speaks
+func (wsm *WebSocketManager) writeToLocal(playerIDs []uint, message *wsproto.Message) {
 	wsm.mu.RLock()
 	
 	// Create a copy of connections to minimize lock time
@@ -110,12 +281,18 @@ func (wsm *WebSocketManager) BroadcastToPlayers(playerIDs []uint, message interf
 				wg.Done()
 			}()
 			
+			prepared, err := message.Prepared(conn.protocol)
+			if err != nil {
+				utils.Error("Failed to encode message for player %d: %v", pID, err)
+				return
+			}
+			
 			// Use write mutex to prevent concurrent writes
 			conn.writeMu.Lock()
 			defer conn.writeMu.Unlock()
 			
 			conn.conn.SetWriteDeadline(time.Now().Add(1 * time.Second)) // Reduced from 3s
-			if err := conn.conn.WriteJSON(message); err != nil {
+			if err := conn.conn.WritePreparedMessage(prepared); err != nil {
 				utils.Error("Failed to send message to player %d: %v", pID, err)
 			}
 		}(wsConn, playerID)
@@ -136,16 +313,16 @@ func (wsm *WebSocketManager) BroadcastToPlayers(playerIDs []uint, message interf
 	}
 }
 
-// IsPlayerConnected checks if a player has an active WebSocket connection
+// IsPlayerConnected checks if a player has an active WebSocket connection to any instance
 func (wsm *WebSocketManager) IsPlayerConnected(playerID uint) bool {
 	wsm.mu.RLock()
-	defer wsm.mu.RUnlock()
-	
 	_, exists := wsm.connections[playerID]
-	return exists
+	wsm.mu.RUnlock()
+	
+	return exists || wsm.remoteNode(playerID) != ""
 }
 
-// GetConnectedPlayers returns a list of all connected player IDs
+// GetConnectedPlayers returns a list of the player IDs connected to this instance
 func (wsm *WebSocketManager) GetConnectedPlayers() []uint {
 	wsm.mu.RLock()
 	defer wsm.mu.RUnlock()
@@ -216,17 +393,24 @@ func (wsm *WebSocketManager) handleConnectionMessages(playerID uint, conn *webso
 	
 	// Listen for messages
 	for {
-		var rawMsg json.RawMessage
-		if err := conn.ReadJSON(&rawMsg); err != nil {
+		frameType, data, err := conn.ReadMessage()
+		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				utils.Error("WebSocket error for player %d: %v", playerID, err)
 			}
 			break
 		}
 		
-		// For now, we'll just log incoming messages
-		// The actual matchmaking logic is handled via HTTP endpoints
-		utils.Debug("Received message from player %d: %s", playerID, string(rawMsg))
+		// Queueing and match responses go through HTTP endpoints; the socket carries map vetoes
+		utils.Debug("Received message from player %d (%d bytes)", playerID, len(data))
+		
+		var message ClientMessage
+		if err := wsproto.Unmarshal(frameType, data, &message); err != nil || message.Type == "" {
+			continue
+		}
+		if wsm.messageHandler != nil {
+			wsm.messageHandler(playerID, message)
+		}
 	}
 }
 
@@ -237,6 +421,7 @@ func (wsm *WebSocketManager) Cleanup() {
 	
 	for playerID, wsConn := range wsm.connections {
 		wsConn.conn.Close()
+		wsm.releaseConnection(playerID)
 		utils.Debug("Closed WebSocket connection for player %d", playerID)
 	}
 	
@@ -0,0 +1,467 @@
+package season
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"boba-vim/internal/models"
+	"boba-vim/internal/rating"
+	"boba-vim/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// Error definitions
+var (
+	ErrSeasonNotFound     = errors.New("season not found")
+	ErrInvalidSeasonDates = errors.New("a season must end after it starts, and in the future")
+	ErrSeasonOverlap      = errors.New("season dates overlap another season")
+	ErrSeasonArchived     = errors.New("season is already archived")
+	ErrSeasonStarted      = errors.New("the start date of a season that has started cannot change")
+	ErrSeasonNotActive    = errors.New("season is not active")
+)
+
+// Constants for resets, rewards and transitions
+const (
+	// SoftResetFactor is how much of a player's distance from the default rating carries into a new season
+	SoftResetFactor = 0.5
+	// SeasonStartDeviation is the minimum rating deviation at the start of a season, so ratings settle quickly
+	SeasonStartDeviation = 200.0
+	// RewardMinGames is how many ranked games a player needs in a season to earn a reward
+	RewardMinGames     = 10
+	TransitionInterval = time.Minute
+	standingsBatchSize = 100
+)
+
+// Reward tiers, from best to worst
+const (
+	RewardTierChampion    = "champion"    // Rank 1
+	RewardTierElite       = "elite"       // Top 10
+	RewardTierContender   = "contender"   // Top 100
+	RewardTierParticipant = "participant" // Played enough games
+)
+
+// SeasonService manages ranked seasons: starting them with a rating soft reset, and archiving
+// their standings and granting rewards when they end
+type SeasonService struct {
+	db       *gorm.DB
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSeasonService creates a new season service
+func NewSeasonService(db *gorm.DB) *SeasonService {
+	return &SeasonService{
+		db:   db,
+		stop: make(chan struct{}),
+	}
+}
+
+// StartPeriodicTransitions starts a goroutine that starts and ends seasons on their dates
+func (ss *SeasonService) StartPeriodicTransitions() {
+	ticker := time.NewTicker(TransitionInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		ss.ProcessTransitions(time.Now())
+		for {
+			select {
+			case <-ticker.C:
+				ss.ProcessTransitions(time.Now())
+			case <-ss.stop:
+				return
+			}
+		}
+	}()
+
+	utils.Info("Started periodic ranked season transitions")
+}
+
+// Stop stops the periodic season transitions
+func (ss *SeasonService) Stop() {
+	ss.stopOnce.Do(func() {
+		close(ss.stop)
+	})
+}
+
+// ProcessTransitions archives the active season once it has ended, then starts the next
+// scheduled season once its start date has passed
+func (ss *SeasonService) ProcessTransitions(now time.Time) {
+	var ended []models.RankedSeason
+	if err := ss.db.Where("status = ? AND ends_at <= ?", models.SeasonStatusActive, now).Find(&ended).Error; err != nil {
+		utils.Error("Failed to find ended seasons: %v", err)
+		return
+	}
+	for i := range ended {
+		if err := ss.archiveSeason(&ended[i], now); err != nil {
+			utils.Error("Failed to archive season %d: %v", ended[i].Number, err)
+		}
+	}
+
+	var active int64
+	if err := ss.db.Model(&models.RankedSeason{}).Where("status = ?", models.SeasonStatusActive).Count(&active).Error; err != nil || active > 0 {
+		return
+	}
+
+	var next models.RankedSeason
+	err := ss.db.Where("status = ? AND starts_at <= ? AND ends_at > ?", models.SeasonStatusScheduled, now, now).
+		Order("starts_at ASC").First(&next).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error("Failed to find the next season: %v", err)
+		}
+		return
+	}
+
+	if err := ss.startSeason(&next, now); err != nil {
+		utils.Error("Failed to start season %d: %v", next.Number, err)
+	}
+}
+
+// CreateSeason schedules a new season. An empty name becomes "Season <number>".
+func (ss *SeasonService) CreateSeason(name string, startsAt, endsAt time.Time) (*models.RankedSeason, error) {
+	if !endsAt.After(startsAt) || !endsAt.After(time.Now()) {
+		return nil, ErrInvalidSeasonDates
+	}
+
+	var season models.RankedSeason
+	err := ss.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkOverlap(tx, 0, startsAt, endsAt); err != nil {
+			return err
+		}
+
+		var lastNumber int
+		if err := tx.Model(&models.RankedSeason{}).Select("COALESCE(MAX(number), 0)").Scan(&lastNumber).Error; err != nil {
+			return err
+		}
+
+		season = models.RankedSeason{
+			Number:   lastNumber + 1,
+			Name:     strings.TrimSpace(name),
+			StartsAt: startsAt,
+			EndsAt:   endsAt,
+			Status:   models.SeasonStatusScheduled,
+		}
+		if season.Name == "" {
+			season.Name = fmt.Sprintf("Season %d", season.Number)
+		}
+
+		return tx.Create(&season).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Info("Scheduled ranked season %d (%s) from %s to %s", season.Number, season.Name, startsAt.Format(time.RFC3339), endsAt.Format(time.RFC3339))
+	return &season, nil
+}
+
+// UpdateSeason changes a season's name or dates. Nil fields keep their value. A season that has
+// started can still be renamed or have its end date moved.
+func (ss *SeasonService) UpdateSeason(id uint, name *string, startsAt, endsAt *time.Time) (*models.RankedSeason, error) {
+	var season models.RankedSeason
+	err := ss.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&season, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSeasonNotFound
+			}
+			return err
+		}
+
+		switch season.Status {
+		case models.SeasonStatusArchived:
+			return ErrSeasonArchived
+		case models.SeasonStatusActive:
+			if startsAt != nil && !startsAt.Equal(season.StartsAt) {
+				return ErrSeasonStarted
+			}
+		}
+
+		if name != nil && strings.TrimSpace(*name) != "" {
+			season.Name = strings.TrimSpace(*name)
+		}
+		if startsAt != nil {
+			season.StartsAt = *startsAt
+		}
+		if endsAt != nil {
+			season.EndsAt = *endsAt
+		}
+
+		if !season.EndsAt.After(season.StartsAt) || !season.EndsAt.After(time.Now()) {
+			return ErrInvalidSeasonDates
+		}
+		if err := checkOverlap(tx, season.ID, season.StartsAt, season.EndsAt); err != nil {
+			return err
+		}
+
+		return tx.Save(&season).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &season, nil
+}
+
+// EndSeason ends the active season now, archiving its standings
+func (ss *SeasonService) EndSeason(id uint) (*models.RankedSeason, error) {
+	var season models.RankedSeason
+	if err := ss.db.First(&season, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSeasonNotFound
+		}
+		return nil, err
+	}
+	if season.Status != models.SeasonStatusActive {
+		return nil, ErrSeasonNotActive
+	}
+
+	if err := ss.archiveSeason(&season, time.Now()); err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// ListSeasons returns every season, newest first
+func (ss *SeasonService) ListSeasons() ([]models.RankedSeason, error) {
+	var seasons []models.RankedSeason
+	if err := ss.db.Order("number DESC").Find(&seasons).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch seasons: %w", err)
+	}
+	return seasons, nil
+}
+
+// CurrentSeason returns the active season, or nil between seasons
+func (ss *SeasonService) CurrentSeason() (*models.RankedSeason, error) {
+	var season models.RankedSeason
+	if err := ss.db.Where("status = ?", models.SeasonStatusActive).First(&season).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch current season: %w", err)
+	}
+	return &season, nil
+}
+
+// GetSeason returns a season by ID
+func (ss *SeasonService) GetSeason(id uint) (*models.RankedSeason, error) {
+	var season models.RankedSeason
+	if err := ss.db.First(&season, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSeasonNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch season: %w", err)
+	}
+	return &season, nil
+}
+
+// GetStandings returns a page of a season's archived standings by rank, and how many there are
+func (ss *SeasonService) GetStandings(seasonID uint, limit, offset int) ([]models.SeasonStanding, int64, error) {
+	var total int64
+	if err := ss.db.Model(&models.SeasonStanding{}).Where("season_id = ?", seasonID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count standings: %w", err)
+	}
+
+	var standings []models.SeasonStanding
+	query := ss.db.Where("season_id = ?", seasonID).Order("rank ASC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&standings).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch standings: %w", err)
+	}
+
+	return standings, total, nil
+}
+
+// GetPlayerHistory returns a player's standing in every archived season they played, newest first
+func (ss *SeasonService) GetPlayerHistory(playerID uint) ([]models.SeasonStanding, error) {
+	var standings []models.SeasonStanding
+	err := ss.db.Joins("Season").
+		Where("season_standings.player_id = ?", playerID).
+		Order("\"Season\".number DESC").
+		Find(&standings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch season history: %w", err)
+	}
+	return standings, nil
+}
+
+// startSeason activates a season and soft-resets every rating toward the default
+func (ss *SeasonService) startSeason(season *models.RankedSeason, now time.Time) error {
+	err := ss.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RankedSeason{}).
+			Where("id = ? AND status = ?", season.ID, models.SeasonStatusScheduled).
+			Updates(map[string]interface{}{"status": models.SeasonStatusActive, "started_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSeasonNotFound // Started or removed by someone else
+		}
+
+		// Pull ratings halfway back to the default and make them less certain
+		err := tx.Model(&models.MultiplayerPlayerStats{}).Where("1 = 1").Updates(map[string]interface{}{
+			"rating":              gorm.Expr("? + (rating - ?) * ?", rating.DefaultRating, rating.DefaultRating, SoftResetFactor),
+			"rating_deviation":    gorm.Expr("CASE WHEN rating_deviation < ? THEN ? ELSE rating_deviation END", SeasonStartDeviation, SeasonStartDeviation),
+			"season_games_played": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.MultiplayerPlayerStats{}).Where("1 = 1").
+			Update("conservative_rating", gorm.Expr("rating - 2 * rating_deviation")).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	season.Status = models.SeasonStatusActive
+	season.StartedAt = &now
+	utils.Info("Ranked season %d (%s) started, ratings soft-reset", season.Number, season.Name)
+	return nil
+}
+
+// archiveSeason freezes the standings of everyone who played ranked games in the season,
+// grants their rewards and marks the season archived
+func (ss *SeasonService) archiveSeason(season *models.RankedSeason, now time.Time) error {
+	var standingsCount int
+	err := ss.db.Transaction(func(tx *gorm.DB) error {
+		update := map[string]interface{}{"status": models.SeasonStatusArchived, "archived_at": now}
+		if now.Before(season.EndsAt) {
+			update["ends_at"] = now // Ended early by an admin
+		}
+
+		result := tx.Model(&models.RankedSeason{}).
+			Where("id = ? AND status = ?", season.ID, models.SeasonStatusActive).
+			Updates(update)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSeasonNotActive // Archived by someone else
+		}
+
+		var stats []models.MultiplayerPlayerStats
+		if err := tx.Where("season_games_played > 0").
+			Order("conservative_rating DESC, season_games_played DESC, player_id ASC").
+			Find(&stats).Error; err != nil {
+			return err
+		}
+
+		standings := make([]models.SeasonStanding, len(stats))
+		for i, stat := range stats {
+			rank := i + 1
+			tier := rewardTier(rank, stat.SeasonGamesPlayed)
+
+			standings[i] = models.SeasonStanding{
+				SeasonID:           season.ID,
+				PlayerID:           stat.PlayerID,
+				Username:           stat.Username,
+				SelectedCharacter:  stat.SelectedCharacter,
+				Rank:               rank,
+				Rating:             stat.Rating,
+				RatingDeviation:    stat.RatingDeviation,
+				RatingVolatility:   stat.RatingVolatility,
+				ConservativeRating: stat.ConservativeRating,
+				SeasonGamesPlayed:  stat.SeasonGamesPlayed,
+				TotalGamesPlayed:   stat.TotalGamesPlayed,
+				TotalWins:          stat.TotalWins,
+				TotalLosses:        stat.TotalLosses,
+				TotalTies:          stat.TotalTies,
+				WinRate:            stat.WinRate,
+				AverageScore:       stat.AverageScore,
+				HighestScore:       stat.HighestScore,
+				AveragePlacement:   stat.AveragePlacement,
+				RewardTier:         tier,
+			}
+
+			if tier != "" {
+				standings[i].RewardCharacter = RewardCharacter(season.Number, tier)
+				if err := grantReward(tx, stat.PlayerID, standings[i].RewardCharacter); err != nil {
+					return fmt.Errorf("failed to grant reward to player %d: %w", stat.PlayerID, err)
+				}
+			}
+		}
+
+		if len(standings) > 0 {
+			if err := tx.CreateInBatches(standings, standingsBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		standingsCount = len(standings)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	season.Status = models.SeasonStatusArchived
+	season.ArchivedAt = &now
+	if now.Before(season.EndsAt) {
+		season.EndsAt = now
+	}
+	utils.Info("Ranked season %d (%s) archived with %d standings", season.Number, season.Name, standingsCount)
+	return nil
+}
+
+// rewardTier returns the reward a player earned with their final rank, or "" for none
+func rewardTier(rank, seasonGames int) string {
+	switch {
+	case seasonGames < RewardMinGames:
+		return ""
+	case rank == 1:
+		return RewardTierChampion
+	case rank <= 10:
+		return RewardTierElite
+	case rank <= 100:
+		return RewardTierContender
+	default:
+		return RewardTierParticipant
+	}
+}
+
+// RewardCharacter returns the name of the cosmetic granted for a reward tier of a season
+func RewardCharacter(seasonNumber int, tier string) string {
+	return fmt.Sprintf("season%d_%s", seasonNumber, tier)
+}
+
+// grantReward gives a player a season reward cosmetic unless they already own it
+func grantReward(tx *gorm.DB, playerID uint, characterName string) error {
+	var existing models.PlayerCharacterOwnership
+	err := tx.Where("player_id = ? AND character_name = ?", playerID, characterName).First(&existing).Error
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	ownership := models.PlayerCharacterOwnership{
+		PlayerID:      playerID,
+		CharacterName: characterName,
+		Level:         1,
+		UnlockMethod:  "season_reward",
+	}
+	return tx.Create(&ownership).Error
+}
+
+// checkOverlap fails when the dates overlap a season that isn't archived, other than excludeID
+func checkOverlap(tx *gorm.DB, excludeID uint, startsAt, endsAt time.Time) error {
+	var overlapping int64
+	err := tx.Model(&models.RankedSeason{}).
+		Where("id <> ? AND status <> ? AND starts_at < ? AND ends_at > ?", excludeID, models.SeasonStatusArchived, endsAt, startsAt).
+		Count(&overlapping).Error
+	if err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrSeasonOverlap
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package tournament
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"boba-vim/internal/models"
+	"boba-vim/internal/rating"
+
+	"gorm.io/gorm"
+)
+
+// plannedMatch is a match of a drawn bracket before it is saved. Sources refer to earlier planned
+// matches by index, or are -1 for a slot filled when the bracket is drawn.
+type plannedMatch struct {
+	match   models.TournamentMatch
+	source1 int
+	source2 int
+}
+
+// bracketPlan is every match of a drawn bracket, in an order where sources come first
+type bracketPlan struct {
+	matches       []plannedMatch
+	winnersRounds int
+}
+
+// add appends a match to the plan and returns its index
+func (plan *bracketPlan) add(bracket string, round, position int, player1, player2 *uint, source1 int, outcome1 string, source2 int, outcome2 string) int {
+	plan.matches = append(plan.matches, plannedMatch{
+		match: models.TournamentMatch{
+			Bracket:        bracket,
+			Round:          round,
+			Position:       position,
+			Status:         models.TournamentMatchPending,
+			Player1ID:      player1,
+			Player2ID:      player2,
+			Source1Outcome: outcome1,
+			Source2Outcome: outcome2,
+		},
+		source1: source1,
+		source2: source2,
+	})
+	return len(plan.matches) - 1
+}
+
+// save creates the planned matches of a tournament, linking every slot to the match feeding it
+func (plan *bracketPlan) save(tx *gorm.DB, tournamentID uint, bestOf []int) error {
+	ids := make([]uint, len(plan.matches))
+	for i := range plan.matches {
+		planned := &plan.matches[i]
+		planned.match.TournamentID = tournamentID
+		planned.match.BestOf = bestOfForRound(bestOf, plan.seriesRound(&planned.match))
+		if planned.source1 >= 0 {
+			planned.match.Source1MatchID = &ids[planned.source1]
+		}
+		if planned.source2 >= 0 {
+			planned.match.Source2MatchID = &ids[planned.source2]
+		}
+		if err := tx.Create(&planned.match).Error; err != nil {
+			return err
+		}
+		ids[i] = planned.match.ID
+	}
+	return nil
+}
+
+// seedEntrants orders a tournament's entrants by the seeding method and numbers their seeds,
+// best first. Players with equal standing are seeded by registration order.
+func seedEntrants(tx *gorm.DB, t *models.Tournament, entrants []models.TournamentEntrant) error {
+	for i := range entrants {
+		entrant := &entrants[i]
+		entrant.SeedValue = 0
+		if t.Seeding == models.TournamentSeedingRegistration {
+			continue
+		}
+
+		var stats models.MultiplayerPlayerStats
+		found := tx.Where("player_id = ?", entrant.PlayerID).First(&stats).Error == nil
+		switch t.Seeding {
+		case models.TournamentSeedingStats:
+			if found {
+				// Win rate decides; average score, well below a percentage point, breaks ties
+				entrant.SeedValue = stats.WinRate + stats.AverageScore/1e6
+			}
+		default:
+			entrant.SeedValue = rating.Default().Conservative()
+			if found {
+				entrant.SeedValue = stats.ConservativeRating
+			}
+		}
+	}
+
+	sort.SliceStable(entrants, func(i, j int) bool {
+		if entrants[i].SeedValue != entrants[j].SeedValue {
+			return entrants[i].SeedValue > entrants[j].SeedValue
+		}
+		return entrants[i].RegisteredAt.Before(entrants[j].RegisteredAt)
+	})
+
+	for i := range entrants {
+		entrants[i].Seed = i + 1
+		if err := tx.Model(&entrants[i]).Updates(map[string]interface{}{
+			"seed":       entrants[i].Seed,
+			"seed_value": entrants[i].SeedValue,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bracketSize returns the smallest power of two that holds count players
+func bracketSize(count int) int {
+	size := 2
+	for size < count {
+		size *= 2
+	}
+	return size
+}
+
+// seedOrder returns the seeds of a bracket of the given size from top to bottom, so the top two
+// seeds can only meet in the final: 1, 8, 4, 5, 2, 7, 3, 6 for eight players
+func seedOrder(size int) []int {
+	order := []int{1, 2}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// planWinnersBracket draws the winners bracket of seeded entrants. Seeds past the number of
+// entrants are byes, which go to the top seeds. It returns the indexes of each round's matches.
+func planWinnersBracket(plan *bracketPlan, entrants []models.TournamentEntrant) [][]int {
+	size := bracketSize(len(entrants))
+	order := seedOrder(size)
+
+	seat := func(seed int) *uint {
+		if seed > len(entrants) {
+			return nil
+		}
+		playerID := entrants[seed-1].PlayerID
+		return &playerID
+	}
+
+	var rounds [][]int
+	first := make([]int, size/2)
+	for i := range first {
+		first[i] = plan.add(models.TournamentBracketWinners, 1, i+1, seat(order[2*i]), seat(order[2*i+1]), -1, "", -1, "")
+	}
+	rounds = append(rounds, first)
+
+	for round := 2; len(rounds[len(rounds)-1]) > 1; round++ {
+		previous := rounds[len(rounds)-1]
+		current := make([]int, len(previous)/2)
+		for i := range current {
+			current[i] = plan.add(models.TournamentBracketWinners, round, i+1, nil, nil,
+				previous[2*i], models.TournamentOutcomeWinner, previous[2*i+1], models.TournamentOutcomeWinner)
+		}
+		rounds = append(rounds, current)
+	}
+	plan.winnersRounds = len(rounds)
+	return rounds
+}
+
+// planSingleElimination draws a single elimination bracket of seeded entrants
+func planSingleElimination(entrants []models.TournamentEntrant) *bracketPlan {
+	plan := &bracketPlan{}
+	planWinnersBracket(plan, entrants)
+	return plan
+}
+
+// planDoubleElimination draws a double elimination bracket of seeded entrants. Losers of the
+// winners bracket drop into the losers bracket, whose rounds alternate between its own players
+// and the players dropping in; drop-ins come in reversed on alternate rounds to avoid early
+// rematches. The grand final puts the two bracket champions against each other, and is reset if
+// the losers bracket champion wins it, so every player needs two losses to be eliminated.
+func planDoubleElimination(entrants []models.TournamentEntrant) *bracketPlan {
+	plan := &bracketPlan{}
+	winners := planWinnersBracket(plan, entrants)
+	winnersRounds := len(winners)
+
+	// With two players the losers bracket is just the loser of the only winners bracket match
+	championSource, championOutcome := winners[0][0], models.TournamentOutcomeLoser
+	if winnersRounds > 1 {
+		first := make([]int, len(winners[0])/2)
+		for i := range first {
+			first[i] = plan.add(models.TournamentBracketLosers, 1, i+1, nil, nil,
+				winners[0][2*i], models.TournamentOutcomeLoser, winners[0][2*i+1], models.TournamentOutcomeLoser)
+		}
+		previous := first
+		round := 2
+
+		for dropRound := 1; dropRound < winnersRounds; dropRound++ {
+			// Players of the losers bracket meet the players dropping from the winners bracket
+			drops := winners[dropRound]
+			current := make([]int, len(previous))
+			for i := range current {
+				drop := drops[i]
+				if dropRound%2 == 1 {
+					drop = drops[len(drops)-1-i]
+				}
+				current[i] = plan.add(models.TournamentBracketLosers, round, i+1, nil, nil,
+					previous[i], models.TournamentOutcomeWinner, drop, models.TournamentOutcomeLoser)
+			}
+			previous = current
+			round++
+
+			if len(previous) == 1 {
+				break
+			}
+
+			// The players left in the losers bracket play each other
+			halved := make([]int, len(previous)/2)
+			for i := range halved {
+				halved[i] = plan.add(models.TournamentBracketLosers, round, i+1, nil, nil,
+					previous[2*i], models.TournamentOutcomeWinner, previous[2*i+1], models.TournamentOutcomeWinner)
+			}
+			previous = halved
+			round++
+		}
+		championSource, championOutcome = previous[0], models.TournamentOutcomeWinner
+	}
+
+	final := winners[winnersRounds-1][0]
+	grandFinal := plan.add(models.TournamentBracketGrandFinal, 1, 1, nil, nil,
+		final, models.TournamentOutcomeWinner, championSource, championOutcome)
+	reset := plan.add(models.TournamentBracketGrandFinal, 2, 1, nil, nil,
+		grandFinal, models.TournamentOutcomeWinner, grandFinal, models.TournamentOutcomeLoser)
+	plan.matches[reset].match.Conditional = true
+	return plan
+}
+
+// parseBestOf reads a tournament's comma-separated games per series
+func parseBestOf(value string) []int {
+	var bestOf []int
+	for _, field := range strings.Split(value, ",") {
+		games, err := strconv.Atoi(strings.TrimSpace(field))
+		if err == nil && games > 0 {
+			bestOf = append(bestOf, games)
+		}
+	}
+	if len(bestOf) == 0 {
+		return []int{1}
+	}
+	return bestOf
+}
+
+// formatBestOf writes games per series in the form a tournament stores them
+func formatBestOf(bestOf []int) string {
+	fields := make([]string, len(bestOf))
+	for i, games := range bestOf {
+		fields[i] = strconv.Itoa(games)
+	}
+	return strings.Join(fields, ",")
+}
+
+// bestOfForRound returns the games per series of a round, counting from 1. Rounds past the list
+// use its last entry.
+func bestOfForRound(bestOf []int, round int) int {
+	if round < 1 {
+		round = 1
+	}
+	if round > len(bestOf) {
+		return bestOf[len(bestOf)-1]
+	}
+	return bestOf[round-1]
+}
+
+// seriesRound returns the round whose games per series a match plays. Losers bracket rounds play
+// like the winners bracket round whose losers they take in, and the grand final like the round
+// after the winners bracket final.
+func (plan *bracketPlan) seriesRound(match *models.TournamentMatch) int {
+	switch match.Bracket {
+	case models.TournamentBracketLosers:
+		return match.Round/2 + 1
+	case models.TournamentBracketGrandFinal:
+		return plan.winnersRounds + 1
+	default:
+		return match.Round
+	}
+}
@@ -0,0 +1,481 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"boba-vim/internal/models"
+	gameService "boba-vim/internal/services/game"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// advanceTournament moves a running tournament forward as far as it can: matches whose sources
+// have completed get their players, finished games are scored, series that need a game get one,
+// and the next Swiss round is paired or the tournament finished once every match is done
+func (ts *TournamentService) advanceTournament(t *models.Tournament, now time.Time) error {
+	for {
+		matches, err := loadMatches(ts.db, t.ID)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[uint]*models.TournamentMatch, len(matches))
+		for i := range matches {
+			byID[matches[i].ID] = &matches[i]
+		}
+
+		progressed := false
+		for i := range matches {
+			match := &matches[i]
+			var changed bool
+			switch {
+			case match.Status == models.TournamentMatchPending:
+				changed, err = ts.fillMatch(t, match, byID, now)
+			case match.Status == models.TournamentMatchInProgress && match.GameID != "":
+				changed, err = ts.scoreGame(t, match, now)
+			}
+			if err != nil {
+				return err
+			}
+			progressed = progressed || changed
+		}
+		if progressed {
+			continue // Completed matches may have filled later ones
+		}
+
+		entrants, err := loadEntrants(ts.db, t.ID)
+		if err != nil {
+			return err
+		}
+
+		unfinished := false
+		for i := range matches {
+			match := &matches[i]
+			if match.Status == models.TournamentMatchCompleted {
+				continue
+			}
+			unfinished = true
+			if match.Status == models.TournamentMatchInProgress && match.GameID == "" {
+				if err := ts.startGame(t, match, entrants, now); err != nil {
+					utils.Error("Failed to start a game of tournament %d match %d: %v", t.ID, match.ID, err)
+				}
+			}
+		}
+		if unfinished {
+			return nil
+		}
+
+		if t.Format == models.TournamentFormatSwiss && t.CurrentRound < t.SwissRounds {
+			return ts.db.Transaction(func(tx *gorm.DB) error {
+				t.CurrentRound++
+				if err := pairSwissRound(tx, t, entrants, matches, parseBestOf(t.BestOf)); err != nil {
+					return err
+				}
+				utils.Info("Tournament %d paired Swiss round %d", t.ID, t.CurrentRound)
+				return tx.Model(t).Update("current_round", t.CurrentRound).Error
+			})
+		}
+		return ts.finishTournament(t, entrants, matches, now)
+	}
+}
+
+// fillMatch gives a pending match its players once the matches feeding it have completed. A
+// match with both players starts its series; a match left with one player or none completes as
+// a bye. It reports whether the match changed.
+func (ts *TournamentService) fillMatch(t *models.Tournament, match *models.TournamentMatch, byID map[uint]*models.TournamentMatch, now time.Time) (bool, error) {
+	player1, ready1 := sourcePlayer(match.Player1ID, match.Source1MatchID, match.Source1Outcome, byID)
+	player2, ready2 := sourcePlayer(match.Player2ID, match.Source2MatchID, match.Source2Outcome, byID)
+	if !ready1 || !ready2 {
+		return false, nil
+	}
+
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		match.Player1ID, match.Player2ID = player1, player2
+
+		// The grand final reset is only played if the winners bracket champion lost the grand final
+		if match.Conditional {
+			grandFinal := byID[*match.Source1MatchID]
+			if samePlayer(grandFinal.WinnerID, grandFinal.Player1ID) {
+				return completeBye(tx, t, match, player1, player2, now)
+			}
+		}
+
+		switch {
+		case player1 != nil && player2 != nil:
+			match.Status = models.TournamentMatchInProgress
+			match.StartedAt = &now
+			return tx.Save(match).Error
+		case player1 != nil:
+			return completeBye(tx, t, match, player1, nil, now)
+		default:
+			return completeBye(tx, t, match, player2, nil, now)
+		}
+	})
+	return err == nil, err
+}
+
+// startGame starts the next game of a match's series once the players have had a breather
+// since the last one
+func (ts *TournamentService) startGame(t *models.Tournament, match *models.TournamentMatch, entrants []models.TournamentEntrant, now time.Time) error {
+	var last models.TournamentGame
+	number := 1
+	err := ts.db.Where("tournament_match_id = ?", match.ID).Order("number DESC").First(&last).Error
+	switch {
+	case err == nil:
+		if last.CompletedAt != nil && now.Sub(*last.CompletedAt) < NextGameDelay {
+			return nil
+		}
+		number = last.Number + 1
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	byPlayer := make(map[uint]*models.TournamentEntrant, len(entrants))
+	for i := range entrants {
+		byPlayer[entrants[i].PlayerID] = &entrants[i]
+	}
+	player1, player2 := byPlayer[*match.Player1ID], byPlayer[*match.Player2ID]
+	if player1 == nil || player2 == nil {
+		return fmt.Errorf("match %d has a player who is not an entrant", match.ID)
+	}
+
+	participants := []matchmaking.MatchParticipant{
+		{PlayerID: player1.PlayerID, Username: player1.Username, Character: player1.Character},
+		{PlayerID: player2.PlayerID, Username: player2.Username, Character: player2.Character},
+	}
+
+	matchID := uuid.New().String()
+	started, err := ts.starter.StartMultiplayerGameWithSettings(matchID, matchmaking.GameModeDuel, participants, gameSettings(t))
+	if err != nil {
+		return err
+	}
+	mpGame, ok := started.(*gameService.MultiplayerGame)
+	if !ok {
+		return fmt.Errorf("unexpected game type %T", started)
+	}
+
+	err = ts.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.TournamentGame{
+			TournamentMatchID: match.ID,
+			Number:            number,
+			GameSessionID:     mpGame.ID,
+		}).Error; err != nil {
+			return err
+		}
+
+		match.MatchID = matchID
+		match.GameID = mpGame.ID
+		return tx.Model(match).Updates(map[string]interface{}{
+			"match_id": matchID,
+			"game_id":  mpGame.ID,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	usernames := []string{player1.Username, player2.Username}
+	ts.notify([]uint{player1.PlayerID, player2.PlayerID}, matchmaking.MsgTypeMatchStarted,
+		fmt.Sprintf("%s: game %d of your match is starting!", t.Name, number),
+		matchmaking.MatchStartData{
+			MatchID:         matchID,
+			GameSessionID:   mpGame.ID,
+			Map:             "default", // Will be determined by the game service
+			GameServerURL:   "/play",   // Redirect to game page
+			Player1Username: player1.Username,
+			Player2Username: player2.Username,
+			Mode:            matchmaking.GameModeDuel,
+			Usernames:       usernames,
+		})
+
+	utils.Info("Tournament %d match %d game %d started between %s and %s", t.ID, match.ID, number, player1.Username, player2.Username)
+	return nil
+}
+
+// scoreGame records the result of a match's current game once the multiplayer game service has
+// recorded it. A tied game is replayed. It reports whether the match's series was decided.
+func (ts *TournamentService) scoreGame(t *models.Tournament, match *models.TournamentMatch, now time.Time) (bool, error) {
+	var result models.MultiplayerGameResult
+	if err := ts.db.Where("game_session_id = ?", match.GameID).First(&result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil // Still being played
+		}
+		return false, err
+	}
+
+	completed := false
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		completedAt := result.CompletedAt
+		if err := tx.Model(&models.TournamentGame{}).
+			Where("tournament_match_id = ? AND game_session_id = ?", match.ID, match.GameID).
+			Updates(map[string]interface{}{"winner_id": result.WinnerID, "completed_at": &completedAt}).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case samePlayer(result.WinnerID, match.Player1ID):
+			match.Player1Wins++
+		case samePlayer(result.WinnerID, match.Player2ID):
+			match.Player2Wins++
+		}
+
+		needed := match.BestOf/2 + 1
+		switch {
+		case match.Player1Wins >= needed:
+			completed = true
+			return completeMatch(tx, t, match, *match.Player1ID, *match.Player2ID, now)
+		case match.Player2Wins >= needed:
+			completed = true
+			return completeMatch(tx, t, match, *match.Player2ID, *match.Player1ID, now)
+		}
+
+		// The series goes on; the runner starts the next game
+		match.MatchID = ""
+		match.GameID = ""
+		return tx.Save(match).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	utils.Debug("Tournament %d match %d stands at %d-%d", t.ID, match.ID, match.Player1Wins, match.Player2Wins)
+	return completed, nil
+}
+
+// completeMatch records the winner and loser of a match's series and updates their records.
+// Outside Swiss, a loser whose loss doesn't send them to a later match is eliminated.
+func completeMatch(tx *gorm.DB, t *models.Tournament, match *models.TournamentMatch, winnerID, loserID uint, now time.Time) error {
+	match.Status = models.TournamentMatchCompleted
+	match.WinnerID = &winnerID
+	match.LoserID = &loserID
+	match.MatchID = ""
+	match.GameID = ""
+	match.CompletedAt = &now
+	if err := tx.Save(match).Error; err != nil {
+		return err
+	}
+
+	winnerUpdates := map[string]interface{}{"match_wins": gorm.Expr("match_wins + 1")}
+	if t.Format == models.TournamentFormatSwiss {
+		winnerUpdates["points"] = gorm.Expr("points + 1")
+	}
+	if err := updateEntrant(tx, t.ID, winnerID, winnerUpdates); err != nil {
+		return err
+	}
+
+	loserUpdates := map[string]interface{}{"match_losses": gorm.Expr("match_losses + 1")}
+	if t.Format != models.TournamentFormatSwiss {
+		feeds, err := lossFeedsMatch(tx, match.ID)
+		if err != nil {
+			return err
+		}
+		loserUpdates["eliminated"] = !feeds
+	}
+	return updateEntrant(tx, t.ID, loserID, loserUpdates)
+}
+
+// completeBye completes a match without playing it. The winner, if any, advances; a Swiss bye
+// is worth a win. A loser is only given when a skipped grand final reset eliminates them.
+func completeBye(tx *gorm.DB, t *models.Tournament, match *models.TournamentMatch, winnerID, loserID *uint, now time.Time) error {
+	match.Status = models.TournamentMatchCompleted
+	match.Bye = true
+	match.WinnerID = winnerID
+	match.LoserID = loserID
+	match.CompletedAt = &now
+	if err := tx.Save(match).Error; err != nil {
+		return err
+	}
+
+	if winnerID != nil && t.Format == models.TournamentFormatSwiss {
+		if err := updateEntrant(tx, t.ID, *winnerID, map[string]interface{}{
+			"points": gorm.Expr("points + 1"),
+			"byes":   gorm.Expr("byes + 1"),
+		}); err != nil {
+			return err
+		}
+	}
+	if loserID != nil {
+		return updateEntrant(tx, t.ID, *loserID, map[string]interface{}{"eliminated": true})
+	}
+	return nil
+}
+
+// finishTournament places the entrants once every match has completed, and announces the champion
+func (ts *TournamentService) finishTournament(t *models.Tournament, entrants []models.TournamentEntrant, matches []models.TournamentMatch, now time.Time) error {
+	var placements map[uint]int
+	if t.Format == models.TournamentFormatSwiss {
+		placements = swissPlacements(entrants, matches)
+	} else {
+		placements = eliminationPlacements(entrants, matches)
+	}
+
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		t.WinnerID = nil
+		for i := range entrants {
+			entrant := &entrants[i]
+			entrant.Placement = placements[entrant.PlayerID]
+			if entrant.Placement == 1 && t.WinnerID == nil {
+				winnerID := entrant.PlayerID
+				t.WinnerID = &winnerID
+			}
+			if err := tx.Model(entrant).Update("placement", entrant.Placement).Error; err != nil {
+				return err
+			}
+		}
+
+		t.Status = models.TournamentStatusCompleted
+		t.CompletedAt = &now
+		return tx.Model(t).Updates(map[string]interface{}{
+			"status":       t.Status,
+			"winner_id":    t.WinnerID,
+			"completed_at": t.CompletedAt,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	bracket, err := ts.GetBracket(t.ID)
+	if err != nil {
+		return err
+	}
+
+	champion := "nobody"
+	playerIDs := make([]uint, len(bracket.Entrants))
+	for i, entrant := range bracket.Entrants {
+		playerIDs[i] = entrant.PlayerID
+		if t.WinnerID != nil && entrant.PlayerID == *t.WinnerID {
+			champion = entrant.Username
+		}
+	}
+	ts.notify(playerIDs, MsgTypeTournamentCompleted, fmt.Sprintf("%s is over! Champion: %s", t.Name, champion), bracket)
+
+	utils.Info("Tournament %d (%s) completed; champion %s", t.ID, t.Name, champion)
+	return nil
+}
+
+// eliminationPlacements places the players of an elimination bracket by how far they got. The
+// champion is first; everyone else is placed by the match that eliminated them, later rounds
+// first and the grand final above the losers bracket. Players eliminated at the same stage share
+// a placement.
+func eliminationPlacements(entrants []models.TournamentEntrant, matches []models.TournamentMatch) map[uint]int {
+	// Outcomes that send a player on to a later match
+	feeds := make(map[uint]map[string]bool)
+	for _, match := range matches {
+		for _, source := range []struct {
+			id      *uint
+			outcome string
+		}{{match.Source1MatchID, match.Source1Outcome}, {match.Source2MatchID, match.Source2Outcome}} {
+			if source.id == nil {
+				continue
+			}
+			if feeds[*source.id] == nil {
+				feeds[*source.id] = make(map[string]bool, 2)
+			}
+			feeds[*source.id][source.outcome] = true
+		}
+	}
+
+	const championStage = 1 << 30
+	stages := make(map[uint]int, len(entrants))
+	for _, match := range matches {
+		if match.WinnerID != nil && !feeds[match.ID][models.TournamentOutcomeWinner] {
+			stages[*match.WinnerID] = championStage
+		}
+		if match.LoserID != nil && !feeds[match.ID][models.TournamentOutcomeLoser] {
+			stages[*match.LoserID] = match.Round
+			if match.Bracket == models.TournamentBracketGrandFinal {
+				stages[*match.LoserID] += championStage / 2
+			}
+		}
+	}
+
+	placements := make(map[uint]int, len(entrants))
+	for _, entrant := range entrants {
+		placement := 1
+		for _, other := range entrants {
+			if stages[other.PlayerID] > stages[entrant.PlayerID] {
+				placement++
+			}
+		}
+		placements[entrant.PlayerID] = placement
+	}
+	return placements
+}
+
+// lossFeedsMatch reports whether the loser of a match plays on in a later match
+func lossFeedsMatch(tx *gorm.DB, matchID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.TournamentMatch{}).
+		Where("(source1_match_id = ? AND source1_outcome = ?) OR (source2_match_id = ? AND source2_outcome = ?)",
+			matchID, models.TournamentOutcomeLoser, matchID, models.TournamentOutcomeLoser).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// sourcePlayer returns the player of a match slot: the player drawn into it, or the winner or
+// loser of the match feeding it once that match has completed. It reports whether the slot is
+// decided; a decided slot can still be empty when the match feeding it was a bye.
+func sourcePlayer(drawn *uint, sourceID *uint, outcome string, byID map[uint]*models.TournamentMatch) (*uint, bool) {
+	if sourceID == nil {
+		return drawn, true
+	}
+
+	source := byID[*sourceID]
+	if source == nil || source.Status != models.TournamentMatchCompleted {
+		return nil, false
+	}
+	if outcome == models.TournamentOutcomeLoser {
+		return source.LoserID, true
+	}
+	return source.WinnerID, true
+}
+
+// gameSettings returns the rules of a tournament's games
+func gameSettings(t *models.Tournament) gameService.MultiplayerGameSettings {
+	settings := gameService.DefaultMultiplayerGameSettings()
+	settings.MapID = t.MapID
+	if t.TargetScore > 0 {
+		settings.TargetScore = t.TargetScore
+	}
+	settings.TimeLimit = time.Duration(t.TimeLimitSeconds) * time.Second
+	settings.Ranked = t.Ranked
+	return settings
+}
+
+// updateEntrant updates a tournament entrant's record
+func updateEntrant(tx *gorm.DB, tournamentID, playerID uint, updates map[string]interface{}) error {
+	return tx.Model(&models.TournamentEntrant{}).
+		Where("tournament_id = ? AND player_id = ?", tournamentID, playerID).
+		Updates(updates).Error
+}
+
+// loadMatches returns a tournament's matches in the order they were created
+func loadMatches(db *gorm.DB, tournamentID uint) ([]models.TournamentMatch, error) {
+	var matches []models.TournamentMatch
+	err := db.Where("tournament_id = ?", tournamentID).Order("id ASC").Find(&matches).Error
+	return matches, err
+}
+
+// loadEntrants returns a tournament's entrants by seed
+func loadEntrants(db *gorm.DB, tournamentID uint) ([]models.TournamentEntrant, error) {
+	var entrants []models.TournamentEntrant
+	if err := db.Where("tournament_id = ?", tournamentID).Find(&entrants).Error; err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entrants, func(i, j int) bool {
+		return entrants[i].Seed < entrants[j].Seed
+	})
+	return entrants, nil
+}
+
+// samePlayer reports whether two player IDs are set and equal
+func samePlayer(a, b *uint) bool {
+	return a != nil && b != nil && *a == *b
+}
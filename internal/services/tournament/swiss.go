@@ -0,0 +1,175 @@
+package tournament
+
+import (
+	"sort"
+	"time"
+
+	"boba-vim/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// defaultSwissRounds returns enough Swiss rounds for a single player to be left undefeated
+func defaultSwissRounds(count int) int {
+	rounds := 0
+	for players := 1; players < count; players *= 2 {
+		rounds++
+	}
+	if rounds < 1 {
+		rounds = 1
+	}
+	return rounds
+}
+
+// pairSwissRound creates the matches of the tournament's current Swiss round. The first round
+// pairs the top half of the seeds against the bottom half; later rounds pair players with equal
+// points, avoiding rematches where possible. With an odd number of players, the lowest-standing
+// player who hasn't had a bye gets one.
+func pairSwissRound(tx *gorm.DB, t *models.Tournament, entrants []models.TournamentEntrant, played []models.TournamentMatch, bestOf []int) error {
+	standings := make([]models.TournamentEntrant, len(entrants))
+	copy(standings, entrants)
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		return standings[i].Seed < standings[j].Seed
+	})
+
+	var bye *models.TournamentEntrant
+	if len(standings)%2 == 1 {
+		index := len(standings) - 1
+		for i := len(standings) - 1; i >= 0; i-- {
+			if standings[i].Byes == 0 {
+				index = i
+				break
+			}
+		}
+		bye = &standings[index]
+		standings = append(standings[:index:index], standings[index+1:]...)
+	}
+
+	var pairs [][2]uint
+	if t.CurrentRound <= 1 {
+		half := len(standings) / 2
+		for i := 0; i < half; i++ {
+			pairs = append(pairs, [2]uint{standings[i].PlayerID, standings[i+half].PlayerID})
+		}
+	} else {
+		pairs = pairByStanding(standings, played)
+	}
+
+	now := time.Now()
+	round := t.CurrentRound
+	for i, pair := range pairs {
+		player1, player2 := pair[0], pair[1]
+		match := models.TournamentMatch{
+			TournamentID: t.ID,
+			Bracket:      models.TournamentBracketSwiss,
+			Round:        round,
+			Position:     i + 1,
+			BestOf:       bestOfForRound(bestOf, round),
+			Status:       models.TournamentMatchInProgress,
+			Player1ID:    &player1,
+			Player2ID:    &player2,
+			StartedAt:    &now,
+		}
+		if err := tx.Create(&match).Error; err != nil {
+			return err
+		}
+	}
+
+	if bye != nil {
+		playerID := bye.PlayerID
+		match := models.TournamentMatch{
+			TournamentID: t.ID,
+			Bracket:      models.TournamentBracketSwiss,
+			Round:        round,
+			Position:     len(pairs) + 1,
+			BestOf:       bestOfForRound(bestOf, round),
+			Player1ID:    &playerID,
+		}
+		if err := tx.Create(&match).Error; err != nil {
+			return err
+		}
+		return completeBye(tx, t, &match, &playerID, nil, now)
+	}
+	return nil
+}
+
+// pairByStanding pairs players in standing order, each with the highest-standing player left
+// whom they haven't played yet. A player who has played everyone left gets a rematch.
+func pairByStanding(standings []models.TournamentEntrant, played []models.TournamentMatch) [][2]uint {
+	met := make(map[[2]uint]bool, len(played))
+	for _, match := range played {
+		if match.Player1ID != nil && match.Player2ID != nil {
+			met[[2]uint{*match.Player1ID, *match.Player2ID}] = true
+			met[[2]uint{*match.Player2ID, *match.Player1ID}] = true
+		}
+	}
+
+	paired := make([]bool, len(standings))
+	var pairs [][2]uint
+	for i := range standings {
+		if paired[i] {
+			continue
+		}
+
+		opponent := -1
+		for j := i + 1; j < len(standings); j++ {
+			if paired[j] {
+				continue
+			}
+			if opponent < 0 {
+				opponent = j // Fallback if everyone left is a rematch
+			}
+			if !met[[2]uint{standings[i].PlayerID, standings[j].PlayerID}] {
+				opponent = j
+				break
+			}
+		}
+		if opponent < 0 {
+			break
+		}
+
+		paired[i], paired[opponent] = true, true
+		pairs = append(pairs, [2]uint{standings[i].PlayerID, standings[opponent].PlayerID})
+	}
+	return pairs
+}
+
+// swissPlacements places Swiss players by points, then by the points of the opponents they
+// played (Buchholz), then by seed
+func swissPlacements(entrants []models.TournamentEntrant, matches []models.TournamentMatch) map[uint]int {
+	points := make(map[uint]float64, len(entrants))
+	for _, entrant := range entrants {
+		points[entrant.PlayerID] = entrant.Points
+	}
+
+	buchholz := make(map[uint]float64, len(entrants))
+	for _, match := range matches {
+		if match.Player1ID == nil || match.Player2ID == nil {
+			continue
+		}
+		buchholz[*match.Player1ID] += points[*match.Player2ID]
+		buchholz[*match.Player2ID] += points[*match.Player1ID]
+	}
+
+	ranked := make([]models.TournamentEntrant, len(entrants))
+	copy(ranked, entrants)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if buchholz[a.PlayerID] != buchholz[b.PlayerID] {
+			return buchholz[a.PlayerID] > buchholz[b.PlayerID]
+		}
+		return a.Seed < b.Seed
+	})
+
+	placements := make(map[uint]int, len(ranked))
+	for i, entrant := range ranked {
+		placements[entrant.PlayerID] = i + 1
+	}
+	return placements
+}
@@ -0,0 +1,494 @@
+package tournament
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"boba-vim/internal/constant"
+	"boba-vim/internal/models"
+	"boba-vim/internal/services/cluster"
+	"boba-vim/internal/services/matchmaking"
+	"boba-vim/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// TournamentService runs tournaments: registration, seeding, drawing brackets, starting the games
+// of each match through the multiplayer game service and advancing winners from their results.
+// Tournament state lives in the database, so any instance can serve it; only the instance
+// holding the runner lease starts games and advances brackets.
+type TournamentService struct {
+	db       *gorm.DB
+	node     *cluster.Node
+	starter  GameStarter
+	notifier Notifier
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTournamentService creates a new tournament service
+func NewTournamentService(db *gorm.DB, node *cluster.Node, starter GameStarter, notifier Notifier) *TournamentService {
+	return &TournamentService{
+		db:       db,
+		node:     node,
+		starter:  starter,
+		notifier: notifier,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start starts a goroutine that advances running tournaments
+func (ts *TournamentService) Start() {
+	ticker := time.NewTicker(ProcessInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !ts.node.HoldLease(runnerLease, runnerLeaseTTL) {
+					continue // Another node is running the tournaments
+				}
+				ts.ProcessTournaments(time.Now())
+			case <-ts.stop:
+				return
+			}
+		}
+	}()
+
+	utils.Info("Started tournament runner")
+}
+
+// Stop stops advancing tournaments
+func (ts *TournamentService) Stop() {
+	ts.stopOnce.Do(func() {
+		close(ts.stop)
+		ts.node.Store.ReleaseLease(runnerLease, ts.node.ID)
+	})
+}
+
+// CreateTournament opens registration for a new tournament
+func (ts *TournamentService) CreateTournament(settings TournamentSettings) (*models.Tournament, error) {
+	if err := validateSettings(&settings); err != nil {
+		return nil, err
+	}
+
+	t := &models.Tournament{
+		Name:             settings.Name,
+		Format:           settings.Format,
+		Status:           models.TournamentStatusRegistration,
+		Seeding:          settings.Seeding,
+		MaxPlayers:       settings.MaxPlayers,
+		BestOf:           formatBestOf(settings.BestOf),
+		SwissRounds:      settings.SwissRounds,
+		MapID:            settings.MapID,
+		TargetScore:      settings.TargetScore,
+		TimeLimitSeconds: settings.TimeLimitSeconds,
+		Ranked:           settings.Ranked,
+	}
+	if err := ts.db.Create(t).Error; err != nil {
+		return nil, err
+	}
+
+	utils.Info("Tournament %d (%s, %s) opened for up to %d players", t.ID, t.Name, t.Format, t.MaxPlayers)
+	return t, nil
+}
+
+// ListTournaments returns tournaments, newest first. An empty status lists every tournament.
+func (ts *TournamentService) ListTournaments(status string, limit int) ([]models.Tournament, error) {
+	query := ts.db.Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var tournaments []models.Tournament
+	if err := query.Find(&tournaments).Error; err != nil {
+		return nil, err
+	}
+	return tournaments, nil
+}
+
+// GetBracket returns a tournament with its entrants by standing and its matches by round
+func (ts *TournamentService) GetBracket(id uint) (*BracketData, error) {
+	var t models.Tournament
+	if err := ts.db.First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, err
+	}
+
+	var entrants []models.TournamentEntrant
+	if err := ts.db.Where("tournament_id = ?", id).Order("registered_at ASC").Find(&entrants).Error; err != nil {
+		return nil, err
+	}
+	sortStandings(entrants)
+
+	var matches []models.TournamentMatch
+	if err := ts.db.Preload("Games", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number ASC")
+	}).Where("tournament_id = ?", id).Order("id ASC").Find(&matches).Error; err != nil {
+		return nil, err
+	}
+
+	return &BracketData{
+		Tournament: t,
+		Entrants:   entrants,
+		Rounds:     bracketRounds(matches),
+	}, nil
+}
+
+// Register adds a player to a tournament that is open for registration
+func (ts *TournamentService) Register(id, playerID uint, username, character string) (*models.TournamentEntrant, error) {
+	var entrant models.TournamentEntrant
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		t, err := findTournament(tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Status != models.TournamentStatusRegistration {
+			return ErrRegistrationClosed
+		}
+
+		var registered, existing int64
+		if err := tx.Model(&models.TournamentEntrant{}).Where("tournament_id = ?", id).Count(&registered).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TournamentEntrant{}).Where("tournament_id = ? AND player_id = ?", id, playerID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAlreadyRegistered
+		}
+		if int(registered) >= t.MaxPlayers {
+			return ErrTournamentFull
+		}
+
+		entrant = models.TournamentEntrant{
+			TournamentID: id,
+			PlayerID:     playerID,
+			Username:     username,
+			Character:    character,
+			RegisteredAt: time.Now(),
+		}
+		return tx.Create(&entrant).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Info("%s registered for tournament %d", username, id)
+	return &entrant, nil
+}
+
+// Withdraw removes a player from a tournament before it starts
+func (ts *TournamentService) Withdraw(id, playerID uint) error {
+	return ts.db.Transaction(func(tx *gorm.DB) error {
+		t, err := findTournament(tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Status != models.TournamentStatusRegistration {
+			return ErrRegistrationClosed
+		}
+
+		result := tx.Where("tournament_id = ? AND player_id = ?", id, playerID).Delete(&models.TournamentEntrant{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotRegistered
+		}
+		return nil
+	})
+}
+
+// StartTournament closes registration, seeds the entrants and draws the bracket, or the first
+// round of a Swiss tournament. The runner starts the first games on its next pass.
+func (ts *TournamentService) StartTournament(id uint) (*BracketData, error) {
+	var entrants []models.TournamentEntrant
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		t, err := findTournament(tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Status != models.TournamentStatusRegistration {
+			return ErrRegistrationClosed
+		}
+
+		if err := tx.Where("tournament_id = ?", id).Order("registered_at ASC").Find(&entrants).Error; err != nil {
+			return err
+		}
+		if len(entrants) < MinPlayers {
+			return ErrNotEnoughEntrants
+		}
+		if err := seedEntrants(tx, t, entrants); err != nil {
+			return err
+		}
+
+		bestOf := parseBestOf(t.BestOf)
+		switch t.Format {
+		case models.TournamentFormatSwiss:
+			if t.SwissRounds == 0 {
+				t.SwissRounds = defaultSwissRounds(len(entrants))
+			}
+			if t.SwissRounds > len(entrants)-1 {
+				t.SwissRounds = len(entrants) - 1
+			}
+			t.CurrentRound = 1
+			if err := pairSwissRound(tx, t, entrants, nil, bestOf); err != nil {
+				return err
+			}
+		case models.TournamentFormatDoubleElimination:
+			if err := planDoubleElimination(entrants).save(tx, t.ID, bestOf); err != nil {
+				return err
+			}
+		default:
+			if err := planSingleElimination(entrants).save(tx, t.ID, bestOf); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		t.Status = models.TournamentStatusInProgress
+		t.StartedAt = &now
+		return tx.Save(t).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bracket, err := ts.GetBracket(id)
+	if err != nil {
+		return nil, err
+	}
+
+	playerIDs := make([]uint, len(entrants))
+	for i, entrant := range entrants {
+		playerIDs[i] = entrant.PlayerID
+	}
+	ts.notify(playerIDs, MsgTypeTournamentStarted, bracket.Tournament.Name+" has started! Your first match is on its way.", bracket)
+
+	utils.Info("Tournament %d (%s) started with %d players", id, bracket.Tournament.Name, len(entrants))
+	return bracket, nil
+}
+
+// CancelTournament stops a tournament that hasn't finished. Games already being played finish,
+// but their results no longer advance the bracket.
+func (ts *TournamentService) CancelTournament(id uint) error {
+	return ts.db.Transaction(func(tx *gorm.DB) error {
+		t, err := findTournament(tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Status == models.TournamentStatusCompleted || t.Status == models.TournamentStatusCancelled {
+			return ErrTournamentFinished
+		}
+
+		now := time.Now()
+		t.Status = models.TournamentStatusCancelled
+		t.CompletedAt = &now
+		if err := tx.Save(t).Error; err != nil {
+			return err
+		}
+
+		utils.Info("Tournament %d (%s) cancelled", t.ID, t.Name)
+		return nil
+	})
+}
+
+// SettleMatch decides a match being played in favour of one of its players without playing the
+// rest of its series, for no-shows and games that never finished
+func (ts *TournamentService) SettleMatch(tournamentID, matchID, winnerID uint) (*models.TournamentMatch, error) {
+	var match models.TournamentMatch
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		t, err := findTournament(tx, tournamentID)
+		if err != nil {
+			return err
+		}
+		if t.Status != models.TournamentStatusInProgress {
+			return ErrTournamentNotRunning
+		}
+
+		if err := tx.Where("id = ? AND tournament_id = ?", matchID, tournamentID).First(&match).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTournamentMatchNotFound
+			}
+			return err
+		}
+		if match.Status != models.TournamentMatchInProgress {
+			return ErrMatchNotInProgress
+		}
+
+		var loserID uint
+		switch winnerID {
+		case *match.Player1ID:
+			loserID = *match.Player2ID
+		case *match.Player2ID:
+			loserID = *match.Player1ID
+		default:
+			return ErrNotMatchPlayer
+		}
+
+		return completeMatch(tx, t, &match, winnerID, loserID, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Info("Tournament %d match %d settled for player %d", tournamentID, matchID, winnerID)
+	return &match, nil
+}
+
+// ProcessTournaments advances every running tournament: filling matches whose players are known,
+// starting and scoring their games, pairing Swiss rounds and finishing tournaments
+func (ts *TournamentService) ProcessTournaments(now time.Time) {
+	var running []models.Tournament
+	if err := ts.db.Where("status = ?", models.TournamentStatusInProgress).Find(&running).Error; err != nil {
+		utils.Error("Failed to find running tournaments: %v", err)
+		return
+	}
+
+	for i := range running {
+		if err := ts.advanceTournament(&running[i], now); err != nil {
+			utils.Error("Failed to advance tournament %d: %v", running[i].ID, err)
+		}
+	}
+}
+
+// notify sends a message to tournament players
+func (ts *TournamentService) notify(playerIDs []uint, msgType matchmaking.MessageType, message string, data interface{}) {
+	if ts.notifier == nil {
+		return
+	}
+
+	for _, playerID := range playerIDs {
+		ts.notifier.SendMessage(playerID, matchmaking.WebSocketMessage{
+			Type:      msgType,
+			Message:   message,
+			Data:      data,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// findTournament loads a tournament
+func findTournament(tx *gorm.DB, id uint) (*models.Tournament, error) {
+	var t models.Tournament
+	if err := tx.First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// validateSettings checks a new tournament's settings and fills in defaults
+func validateSettings(settings *TournamentSettings) error {
+	settings.Name = strings.TrimSpace(settings.Name)
+	if settings.Name == "" {
+		settings.Name = "Tournament"
+	}
+
+	switch settings.Format {
+	case models.TournamentFormatSingleElimination, models.TournamentFormatDoubleElimination, models.TournamentFormatSwiss:
+	default:
+		return ErrInvalidFormat
+	}
+
+	switch settings.Seeding {
+	case "":
+		settings.Seeding = models.TournamentSeedingRating
+	case models.TournamentSeedingRating, models.TournamentSeedingStats, models.TournamentSeedingRegistration:
+	default:
+		return ErrInvalidSeeding
+	}
+
+	if settings.MaxPlayers < MinPlayers || settings.MaxPlayers > MaxPlayers {
+		return ErrInvalidMaxPlayers
+	}
+
+	if len(settings.BestOf) == 0 {
+		settings.BestOf = []int{1}
+	}
+	for _, games := range settings.BestOf {
+		if games < 1 || games > MaxBestOf || games%2 == 0 {
+			return ErrInvalidBestOf
+		}
+	}
+
+	if settings.Format == models.TournamentFormatSwiss {
+		if settings.SwissRounds < 0 || settings.SwissRounds > MaxSwissRounds {
+			return ErrInvalidSwissRounds
+		}
+	} else {
+		settings.SwissRounds = 0
+	}
+
+	if settings.MapID != 0 && constant.GetMapByID(settings.MapID) == nil {
+		return ErrInvalidMap
+	}
+	if settings.TargetScore != 0 && (settings.TargetScore < MinTargetScore || settings.TargetScore > MaxTargetScore) {
+		return ErrInvalidTargetScore
+	}
+	if settings.TimeLimitSeconds != 0 && (settings.TimeLimitSeconds < MinTimeLimitSeconds || settings.TimeLimitSeconds > MaxTimeLimitSeconds) {
+		return ErrInvalidTimeLimit
+	}
+	return nil
+}
+
+// bracketRounds groups matches into rounds: the winners bracket, then the losers bracket, then
+// the grand final, each by round
+func bracketRounds(matches []models.TournamentMatch) []BracketRound {
+	order := map[string]int{
+		models.TournamentBracketSwiss:      0,
+		models.TournamentBracketWinners:    0,
+		models.TournamentBracketLosers:     1,
+		models.TournamentBracketGrandFinal: 2,
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if order[a.Bracket] != order[b.Bracket] {
+			return order[a.Bracket] < order[b.Bracket]
+		}
+		if a.Round != b.Round {
+			return a.Round < b.Round
+		}
+		return a.Position < b.Position
+	})
+
+	var rounds []BracketRound
+	for _, match := range matches {
+		last := len(rounds) - 1
+		if last < 0 || rounds[last].Bracket != match.Bracket || rounds[last].Round != match.Round {
+			rounds = append(rounds, BracketRound{Bracket: match.Bracket, Round: match.Round, BestOf: match.BestOf})
+			last++
+		}
+		rounds[last].Matches = append(rounds[last].Matches, match)
+	}
+	return rounds
+}
+
+// sortStandings orders entrants by final placement once the tournament has finished, and by
+// seed before that; unseeded entrants keep their registration order
+func sortStandings(entrants []models.TournamentEntrant) {
+	sort.SliceStable(entrants, func(i, j int) bool {
+		a, b := entrants[i], entrants[j]
+		if (a.Placement > 0) != (b.Placement > 0) {
+			return a.Placement > 0
+		}
+		if a.Placement != b.Placement {
+			return a.Placement < b.Placement
+		}
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		return a.Seed < b.Seed
+	})
+}
@@ -0,0 +1,96 @@
+package tournament
+
+import (
+	"errors"
+	"time"
+
+	"boba-vim/internal/models"
+	gameService "boba-vim/internal/services/game"
+	"boba-vim/internal/services/matchmaking"
+)
+
+// Error definitions
+var (
+	ErrTournamentNotFound      = errors.New("tournament not found")
+	ErrTournamentMatchNotFound = errors.New("tournament match not found")
+	ErrInvalidFormat           = errors.New("format must be single_elimination, double_elimination or swiss")
+	ErrInvalidSeeding          = errors.New("seeding must be rating, stats or registration")
+	ErrInvalidMaxPlayers       = errors.New("a tournament holds 2 to 128 players")
+	ErrInvalidBestOf           = errors.New("best of must be a list of odd game counts from 1 to 9")
+	ErrInvalidSwissRounds      = errors.New("a Swiss tournament plays 1 to 10 rounds")
+	ErrInvalidMap              = errors.New("map not found")
+	ErrInvalidTargetScore      = errors.New("pearl target must be between 100 and 10000")
+	ErrInvalidTimeLimit        = errors.New("time limit must be 0 or between 60 and 3600 seconds")
+	ErrRegistrationClosed      = errors.New("registration for this tournament is closed")
+	ErrTournamentFull          = errors.New("tournament is full")
+	ErrAlreadyRegistered       = errors.New("player is already registered")
+	ErrNotRegistered           = errors.New("player is not registered")
+	ErrNotEnoughEntrants       = errors.New("a tournament needs at least 2 players to start")
+	ErrTournamentNotRunning    = errors.New("tournament is not in progress")
+	ErrTournamentFinished      = errors.New("tournament has already finished")
+	ErrMatchNotInProgress      = errors.New("match is not being played")
+	ErrNotMatchPlayer          = errors.New("winner must be one of the match's players")
+)
+
+// Message types sent to tournament players over the matchmaking WebSocket
+const (
+	MsgTypeTournamentStarted   matchmaking.MessageType = "tournament_started"
+	MsgTypeTournamentCompleted matchmaking.MessageType = "tournament_completed"
+)
+
+// Constants for limits and timing
+const (
+	MinPlayers          = 2
+	MaxPlayers          = 128
+	MaxBestOf           = 9
+	MaxSwissRounds      = 10
+	MinTargetScore      = 100
+	MaxTargetScore      = 10000
+	MinTimeLimitSeconds = 60
+	MaxTimeLimitSeconds = 3600
+	ProcessInterval     = 5 * time.Second
+	NextGameDelay       = 15 * time.Second // Breather between the games of a series
+	runnerLeaseTTL      = 15 * time.Second
+
+	// Only the node holding this lease starts tournament games and advances brackets
+	runnerLease = "tournaments:runner"
+)
+
+// GameStarter starts the games of tournament matches
+type GameStarter interface {
+	StartMultiplayerGameWithSettings(matchID string, mode string, participants []matchmaking.MatchParticipant, settings gameService.MultiplayerGameSettings) (interface{}, error)
+}
+
+// Notifier sends messages to players over the matchmaking WebSocket
+type Notifier interface {
+	SendMessage(playerID uint, message interface{}) error
+}
+
+// TournamentSettings are the rules an admin picks when creating a tournament
+type TournamentSettings struct {
+	Name             string `json:"name"`
+	Format           string `json:"format"`
+	Seeding          string `json:"seeding"`
+	MaxPlayers       int    `json:"max_players"`
+	BestOf           []int  `json:"best_of"`      // Games per series by round; later rounds use the last entry
+	SwissRounds      int    `json:"swiss_rounds"` // 0 plays enough rounds to find a single undefeated player
+	MapID            int    `json:"map_id"`       // 0 plays a random map
+	TargetScore      int    `json:"target_score"` // 0 uses the default target
+	TimeLimitSeconds int    `json:"time_limit_seconds"`
+	Ranked           bool   `json:"ranked"`
+}
+
+// BracketRound is one round of a bracket and its matches, top of the bracket first
+type BracketRound struct {
+	Bracket string                   `json:"bracket"`
+	Round   int                      `json:"round"`
+	BestOf  int                      `json:"best_of"`
+	Matches []models.TournamentMatch `json:"matches"`
+}
+
+// BracketData is the full state of a tournament: its rules, entrants by standing and every round
+type BracketData struct {
+	Tournament models.Tournament          `json:"tournament"`
+	Entrants   []models.TournamentEntrant `json:"entrants"`
+	Rounds     []BracketRound             `json:"rounds"`
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// profaneWords are the words FilterProfanity masks, in lowercase
+var profaneWords = map[string]bool{
+	"arse": true, "arsehole": true, "ass": true, "asshole": true, "assholes": true,
+	"bastard": true, "bastards": true, "bitch": true, "bitches": true, "bitching": true,
+	"bollocks": true, "bullshit": true, "cock": true, "cocks": true, "crap": true,
+	"cunt": true, "cunts": true, "damn": true, "dick": true, "dickhead": true, "dicks": true,
+	"douche": true, "douchebag": true, "fag": true, "faggot": true, "fuck": true, "fucked": true,
+	"fucker": true, "fuckers": true, "fuckin": true, "fucking": true, "fucks": true, "fuk": true,
+	"idiot": true, "idiots": true, "jackass": true, "kys": true, "loser": true, "losers": true,
+	"moron": true, "morons": true, "motherfucker": true, "noob": true, "nigga": true, "nigger": true,
+	"piss": true, "pissed": true, "prick": true, "pussy": true, "retard": true, "retarded": true,
+	"shit": true, "shits": true, "shitty": true, "slut": true, "stfu": true, "twat": true,
+	"wanker": true, "whore": true, "wtf": true,
+}
+
+// leetReplacer undoes the usual digit and symbol stand-ins for letters
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s",
+)
+
+// FilterProfanity masks the profane words of a text with asterisks, keeping their first letter.
+// Words are matched whole and case-insensitively, with digits and symbols standing in for letters
+// read as those letters. It reports whether any word was masked.
+func FilterProfanity(text string) (string, bool) {
+	runes := []rune(text)
+	filtered := false
+
+	for start := 0; start < len(runes); {
+		if !isProfanityWordRune(runes[start]) {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(runes) && isProfanityWordRune(runes[end]) {
+			end++
+		}
+
+		word := leetReplacer.Replace(strings.ToLower(string(runes[start:end])))
+		if profaneWords[word] {
+			for i := start + 1; i < end; i++ {
+				runes[i] = '*'
+			}
+			filtered = true
+		}
+		start = end
+	}
+
+	return string(runes), filtered
+}
+
+// isProfanityWordRune reports whether a rune can be part of a word FilterProfanity checks
+func isProfanityWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '@' || r == '$'
+}
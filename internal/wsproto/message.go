@@ -0,0 +1,135 @@
+package wsproto
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Message is a message to write to many connections. It is encoded at most once per encoding, the
+// first time a connection speaking that encoding needs it.
+type Message struct {
+	value    interface{}
+	mutex    sync.Mutex
+	encoded  map[string][]byte
+	prepared map[string]*websocket.PreparedMessage
+}
+
+// NewMessage wraps a message for writing to connections
+func NewMessage(value interface{}) *Message {
+	return &Message{
+		value:    value,
+		encoded:  make(map[string][]byte),
+		prepared: make(map[string]*websocket.PreparedMessage),
+	}
+}
+
+// NewEncodedMessage wraps a message already encoded, such as one forwarded by another server. Its
+// encodings must all hold the same message and include JSON; the first connection speaking an
+// encoding it lacks has the message decoded from its JSON and encoded again.
+func NewEncodedMessage(encoded map[string][]byte) *Message {
+	message := NewMessage(nil)
+	for encoding, data := range encoded {
+		message.encoded[encoding] = data
+	}
+	return message
+}
+
+// Bytes returns the message encoded with an encoding
+func (m *Message) Bytes(encoding string) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.bytes(encoding)
+}
+
+// bytes returns the message encoded with an encoding. The caller must hold the lock.
+func (m *Message) bytes(encoding string) ([]byte, error) {
+	if data, ok := m.encoded[encoding]; ok {
+		return data, nil
+	}
+	if m.value == nil {
+		data, ok := m.encoded[EncodingJSON]
+		if !ok {
+			return nil, ErrMissingJSON
+		}
+		value, err := decodeJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		m.value = value
+	}
+
+	data, err := Marshal(encoding, m.value)
+	if err != nil {
+		return nil, err
+	}
+	m.encoded[encoding] = data
+	return data, nil
+}
+
+// Prepared returns the frame of the message for connections speaking a protocol
+func (m *Message) Prepared(protocol Protocol) (*websocket.PreparedMessage, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if prepared, ok := m.prepared[protocol.Encoding]; ok {
+		return prepared, nil
+	}
+
+	data, err := m.bytes(protocol.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared, err := websocket.NewPreparedMessage(protocol.FrameType(), data)
+	if err != nil {
+		return nil, err
+	}
+	m.prepared[protocol.Encoding] = prepared
+	return prepared, nil
+}
+
+// decodeJSON decodes a JSON message into maps, slices and values that encode the way the message
+// itself did: whole numbers as integers rather than floats, and times, which JSON has as RFC 3339
+// strings, as times
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return convertValues(value), nil
+}
+
+// convertValues replaces the JSON numbers in a decoded value with integers, or floats for numbers
+// that aren't whole, and the strings holding times with times
+func convertValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = convertValues(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertValues(item)
+		}
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if n, err := v.Float64(); err == nil {
+			return n
+		}
+	}
+	return value
+}
@@ -0,0 +1,172 @@
+// Package wsproto is the wire protocol of the game's WebSockets. Clients choose how messages are
+// encoded with the WebSocket subprotocol they ask for, "bobavim.v1.json" or "bobavim.v1.msgpack",
+// whose number is the version of the message schemas. Clients that ask for none are spoken to in
+// JSON with the first schema version, as they were before subprotocols existed.
+//
+// Text frames always carry JSON and binary frames MessagePack, so messages are decoded by the type
+// of their frame whatever was negotiated.
+package wsproto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gorilla/websocket"
+	"github.com/ugorji/go/codec"
+)
+
+// Encodings of messages
+const (
+	EncodingJSON    = "json"
+	EncodingMsgPack = "msgpack"
+)
+
+// SchemaVersion is the newest version of the message schemas. It goes up when a message changes in
+// a way clients of the previous version can't read; servers keep speaking every version in
+// SupportedVersions so tabs opened before a release keep working.
+const SchemaVersion = 1
+
+// SupportedVersions are the schema versions servers speak, newest first
+var SupportedVersions = []int{SchemaVersion}
+
+// Protocol errors
+var (
+	ErrUnknownFrame    = errors.New("frame is neither text nor binary")
+	ErrMissingType     = errors.New("message has no type")
+	ErrUnknownEncoding = errors.New("unknown encoding")
+	ErrMissingJSON     = errors.New("encoded message has no JSON")
+)
+
+// Protocol is what a connection negotiated: a schema version and an encoding
+type Protocol struct {
+	Version  int
+	Encoding string
+}
+
+// Legacy is the protocol of clients that asked for no subprotocol
+var Legacy = Protocol{Version: 1, Encoding: EncodingJSON}
+
+// Envelope is the part every client message shares, decoded first to pick the message's schema
+type Envelope struct {
+	Type string `json:"type"`
+}
+
+// msgpackHandle encodes MessagePack with the field names of the json tags, strings as str and
+// times as the timestamp extension, and decodes maps the way encoding/json does
+var msgpackHandle = func() *codec.MsgpackHandle {
+	handle := &codec.MsgpackHandle{}
+	handle.WriteExt = true
+	handle.RawToString = true
+	handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	return handle
+}()
+
+// Subprotocol returns the name of a protocol's subprotocol
+func (p Protocol) Subprotocol() string {
+	return fmt.Sprintf("bobavim.v%d.%s", p.Version, p.Encoding)
+}
+
+// FrameType returns the WebSocket frame type of a protocol's messages
+func (p Protocol) FrameType() int {
+	if p.Encoding == EncodingMsgPack {
+		return websocket.BinaryMessage
+	}
+	return websocket.TextMessage
+}
+
+// Marshal encodes a message with a protocol's encoding
+func (p Protocol) Marshal(message interface{}) ([]byte, error) {
+	return Marshal(p.Encoding, message)
+}
+
+// Marshal encodes a message with an encoding
+func Marshal(encoding string, message interface{}) ([]byte, error) {
+	switch encoding {
+	case EncodingJSON:
+		return json.Marshal(message)
+	case EncodingMsgPack:
+		var data []byte
+		if err := codec.NewEncoderBytes(&data, msgpackHandle).Encode(message); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	return nil, ErrUnknownEncoding
+}
+
+// Unmarshal decodes a message received in a frame of the given type into v
+func Unmarshal(frameType int, data []byte, v interface{}) error {
+	switch frameType {
+	case websocket.TextMessage:
+		return json.Unmarshal(data, v)
+	case websocket.BinaryMessage:
+		return codec.NewDecoderBytes(data, msgpackHandle).Decode(v)
+	}
+	return ErrUnknownFrame
+}
+
+// ReadEnvelope decodes the type of a message received in a frame of the given type
+func ReadEnvelope(frameType int, data []byte) (Envelope, error) {
+	var envelope Envelope
+	if err := Unmarshal(frameType, data, &envelope); err != nil {
+		return Envelope{}, err
+	}
+	if envelope.Type == "" {
+		return Envelope{}, ErrMissingType
+	}
+	return envelope, nil
+}
+
+// Protocols lists the protocols servers speak, newest schema first and MessagePack before JSON
+// within a version
+func Protocols() []Protocol {
+	protocols := make([]Protocol, 0, 2*len(SupportedVersions))
+	for _, version := range SupportedVersions {
+		protocols = append(protocols,
+			Protocol{Version: version, Encoding: EncodingMsgPack},
+			Protocol{Version: version, Encoding: EncodingJSON})
+	}
+	return protocols
+}
+
+// Subprotocols lists the names of the protocols servers speak, in the order of Protocols
+func Subprotocols() []string {
+	protocols := Protocols()
+	subprotocols := make([]string, len(protocols))
+	for i, protocol := range protocols {
+		subprotocols[i] = protocol.Subprotocol()
+	}
+	return subprotocols
+}
+
+// Parse returns the protocol a subprotocol names, reporting false for names servers don't speak
+func Parse(subprotocol string) (Protocol, bool) {
+	for _, protocol := range Protocols() {
+		if protocol.Subprotocol() == subprotocol {
+			return protocol, true
+		}
+	}
+	return Protocol{}, false
+}
+
+// Negotiated returns the protocol an upgraded connection agreed on
+func Negotiated(conn *websocket.Conn) Protocol {
+	if protocol, ok := Parse(conn.Subprotocol()); ok {
+		return protocol
+	}
+	return Legacy
+}
+
+// NewUpgrader returns an upgrader that negotiates the protocol with the subprotocols clients ask
+// for, picking the first in the order of Protocols that a client offers
+func NewUpgrader() websocket.Upgrader {
+	return websocket.Upgrader{
+		Subprotocols: Subprotocols(),
+		CheckOrigin: func(r *http.Request) bool {
+			return true // Allow connections from any origin
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package wsproto
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type testPosition struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+type testMessage struct {
+	Type      string         `json:"type"`
+	Seq       uint64         `json:"seq"`
+	Score     int            `json:"score"`
+	Ratio     float64        `json:"ratio"`
+	Accepted  bool           `json:"accepted"`
+	Positions []testPosition `json:"positions"`
+	Winner    *uint          `json:"winner"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+func newTestMessage() testMessage {
+	winner := uint(7)
+	return testMessage{
+		Type:      "move_ack",
+		Seq:       42,
+		Score:     -3,
+		Ratio:     0.5,
+		Accepted:  true,
+		Positions: []testPosition{{Row: 1, Col: 2}, {Row: 3, Col: 4}},
+		Winner:    &winner,
+		Timestamp: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	for _, protocol := range Protocols() {
+		t.Run(protocol.Subprotocol(), func(t *testing.T) {
+			want := newTestMessage()
+
+			data, err := protocol.Marshal(want)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			var got testMessage
+			if err := Unmarshal(protocol.FrameType(), data, &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !got.Timestamp.Equal(want.Timestamp) {
+				t.Errorf("timestamp = %v, want %v", got.Timestamp, want.Timestamp)
+			}
+			got.Timestamp = want.Timestamp
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestMarshalUnknownEncoding(t *testing.T) {
+	if _, err := Marshal("xml", newTestMessage()); err != ErrUnknownEncoding {
+		t.Errorf("Marshal(xml) error = %v, want %v", err, ErrUnknownEncoding)
+	}
+}
+
+func TestReadEnvelope(t *testing.T) {
+	for _, protocol := range Protocols() {
+		t.Run(protocol.Subprotocol(), func(t *testing.T) {
+			data, err := protocol.Marshal(map[string]interface{}{"type": "chat", "quick": "gg"})
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			envelope, err := ReadEnvelope(protocol.FrameType(), data)
+			if err != nil || envelope.Type != "chat" {
+				t.Errorf("ReadEnvelope = %+v, %v, want type chat", envelope, err)
+			}
+
+			data, err = protocol.Marshal(map[string]interface{}{"quick": "gg"})
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if _, err := ReadEnvelope(protocol.FrameType(), data); err != ErrMissingType {
+				t.Errorf("ReadEnvelope without type error = %v, want %v", err, ErrMissingType)
+			}
+		})
+	}
+
+	if _, err := ReadEnvelope(websocket.PingMessage, []byte(`{"type":"chat"}`)); err != ErrUnknownFrame {
+		t.Errorf("ReadEnvelope of a ping error = %v, want %v", err, ErrUnknownFrame)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, protocol := range Protocols() {
+		if got, ok := Parse(protocol.Subprotocol()); !ok || got != protocol {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", protocol.Subprotocol(), got, ok, protocol)
+		}
+	}
+	if _, ok := Parse("bobavim.v0.json"); ok {
+		t.Error("Parse(bobavim.v0.json) succeeded, want failure")
+	}
+}
+
+func TestEncodedMessageTranscodes(t *testing.T) {
+	want := newTestMessage()
+	data, err := NewMessage(want).Bytes(EncodingJSON)
+	if err != nil {
+		t.Fatalf("Bytes(json): %v", err)
+	}
+
+	message := NewEncodedMessage(map[string][]byte{EncodingJSON: data})
+	if got, err := message.Bytes(EncodingJSON); err != nil || string(got) != string(data) {
+		t.Errorf("Bytes(json) = %s, %v, want the JSON it was given", got, err)
+	}
+
+	msgpack, err := message.Bytes(EncodingMsgPack)
+	if err != nil {
+		t.Fatalf("Bytes(msgpack): %v", err)
+	}
+
+	var got testMessage
+	if err := Unmarshal(websocket.BinaryMessage, msgpack, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !got.Timestamp.Equal(want.Timestamp) {
+		t.Errorf("timestamp = %v, want %v", got.Timestamp, want.Timestamp)
+	}
+	got.Timestamp = want.Timestamp
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("transcoded = %+v, want %+v", got, want)
+	}
+
+	// Whole numbers stay integers, as they are when the message itself is encoded
+	var fields map[string]interface{}
+	if err := Unmarshal(websocket.BinaryMessage, msgpack, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	for _, field := range []string{"seq", "score"} {
+		if _, ok := fields[field].(float64); ok {
+			t.Errorf("%s was transcoded as a float", field)
+		}
+	}
+	if _, ok := fields["ratio"].(float64); !ok {
+		t.Errorf("ratio = %T, want float64", fields["ratio"])
+	}
+}
+
+func TestEncodedMessageWithoutJSON(t *testing.T) {
+	message := NewEncodedMessage(map[string][]byte{EncodingMsgPack: {0x80}})
+	if _, err := message.Bytes(EncodingJSON); err != ErrMissingJSON {
+		t.Errorf("Bytes(json) error = %v, want %v", err, ErrMissingJSON)
+	}
+}
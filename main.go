@@ -7,8 +7,10 @@ import (
 	"boba-vim/internal/middleware"
 	"boba-vim/internal/services"
 	"boba-vim/internal/services/cleanup"
+	"boba-vim/internal/services/cluster"
 	"boba-vim/internal/services/email"
 	"boba-vim/internal/services/game"
+	"boba-vim/internal/services/season"
 	"boba-vim/internal/signal"
 	"boba-vim/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -39,6 +41,9 @@ func main() {
 		utils.Fatal("Failed to initialize database: %v", err)
 	}
 
+	// Join the instances sharing multiplayer state
+	node := cluster.Initialize(cfg)
+
 	// Initialize Gin router
 	router := gin.Default()
 
@@ -89,6 +94,11 @@ func main() {
 	newsletterHandler := handlers.NewNewsletterHandler(newsletterService)
 	paymentHandler := handlers.NewPaymentHandler(db, paymentService, emailService)
 	
+	// Initialize and start ranked season service
+	seasonService := season.NewSeasonService(db)
+	seasonService.StartPeriodicTransitions()
+	seasonHandler := handlers.NewSeasonHandler(seasonService)
+	
 	// Initialize and start cleanup service
 	cleanupService := cleanup.NewCleanupService(db, cfg)
 	cleanupService.StartPeriodicCleanup()
@@ -98,7 +108,7 @@ func main() {
 	pearlMoldService.StartPeriodicMovement()
 	
 	// Initialize game handlers with pearl mold service for collision fix
-	gameHandler := handlers.NewGameHandlerWithPearlMold(db, pearlMoldService)
+	gameHandler := handlers.NewGameHandlerWithPearlMold(db, pearlMoldService, node)
 	webHandler := handlers.NewWebHandlerWithPearlMold(db, pearlMoldService)
 
 	// Web routes
@@ -155,6 +165,56 @@ func main() {
 			multiplayer.GET("/player-position", gameHandler.GetMultiplayerPlayerPosition)
 			multiplayer.GET("/player-stats", gameHandler.GetMultiplayerPlayerStats)
 			multiplayer.GET("/recent-games", gameHandler.GetMultiplayerRecentGames)
+			multiplayer.GET("/live", gameHandler.GetLiveMultiplayerGames)
+			multiplayer.GET("/map-pool", gameHandler.GetRankedMapPool)
+			multiplayer.GET("/practice/bots", gameHandler.GetPracticeBots)
+			multiplayer.POST("/practice", gameHandler.StartPracticeGame)
+			multiplayer.GET("/rule-sets", gameHandler.GetMultiplayerRuleSets)
+			multiplayer.GET("/chat", gameHandler.GetMultiplayerChatOptions)
+		}
+
+		// Private lobby routes
+		lobbies := api.Group("/lobbies")
+		{
+			lobbies.POST("", gameHandler.CreateLobby)
+			lobbies.GET("/:code", gameHandler.GetLobby)
+			lobbies.POST("/:code/join", gameHandler.JoinLobby)
+			lobbies.POST("/:code/leave", gameHandler.LeaveLobby)
+			lobbies.PUT("/:code/settings", gameHandler.UpdateLobbySettings)
+			lobbies.POST("/:code/kick", gameHandler.KickLobbyMember)
+			lobbies.POST("/:code/start", gameHandler.StartLobby)
+		}
+
+		// Tournament routes
+		tournaments := api.Group("/tournaments")
+		{
+			tournaments.GET("", gameHandler.GetTournaments)
+			tournaments.GET("/:id", gameHandler.GetTournament)
+			tournaments.POST("/:id/register", gameHandler.RegisterForTournament)
+			tournaments.POST("/:id/withdraw", gameHandler.WithdrawFromTournament)
+		}
+
+		// Challenge routes
+		challenges := api.Group("/challenges")
+		{
+			challenges.POST("", gameHandler.CreateChallenge)
+			challenges.GET("", gameHandler.GetChallenges)
+			challenges.GET("/:code", gameHandler.GetChallenge)
+			challenges.POST("/:code/play", gameHandler.PlayChallenge)
+			challenges.POST("/:code/decline", gameHandler.DeclineChallenge)
+			challenges.POST("/:code/cancel", gameHandler.CancelChallenge)
+		}
+
+		// Friends routes
+		friendsGroup := api.Group("/friends")
+		{
+			friendsGroup.GET("", gameHandler.GetFriends)
+			friendsGroup.POST("/requests", gameHandler.SendFriendRequest)
+			friendsGroup.POST("/:id/accept", gameHandler.AcceptFriendRequest)
+			friendsGroup.DELETE("/:id", gameHandler.RemoveFriend)
+			friendsGroup.POST("/:id/block", gameHandler.BlockPlayer)
+			friendsGroup.DELETE("/:id/block", gameHandler.UnblockPlayer)
+			friendsGroup.POST("/:id/challenge", gameHandler.ChallengeFriend)
 		}
 
 		// Map routes
@@ -165,6 +225,9 @@ func main() {
 		api.POST("/pause-game", gameHandler.PauseGame)
 		api.POST("/resume-game", gameHandler.ResumeGame)
 		api.POST("/restart-game", gameHandler.RestartGame)
+		api.POST("/display-options", gameHandler.UpdateDisplayOptions)
+		api.GET("/vim-options", gameHandler.GetSessionVimOptions)
+		api.POST("/vim-options", gameHandler.UpdateSessionVimOptions)
 		api.GET("/completed-maps", gameHandler.GetCompletedMaps)
 		api.POST("/migrate-guest-progress", gameHandler.MigrateGuestProgress)
 
@@ -191,6 +254,8 @@ func main() {
 			user.DELETE("/favorites", userDataHandler.RemoveFromFavorites)
 			user.POST("/best-time", userDataHandler.UpdateBestTime)
 			user.GET("/stats", userDataHandler.GetUserStats)
+			user.GET("/vim-options", gameHandler.GetPlayerVimOptions)
+			user.POST("/vim-options", gameHandler.UpdatePlayerVimOptions)
 		}
 
 		// Survey routes
@@ -211,6 +276,15 @@ func main() {
 			newsletter.POST("/:id/read", newsletterHandler.MarkNewsletterAsRead)
 		}
 
+		// Ranked season routes (public)
+		seasons := api.Group("/seasons")
+		{
+			seasons.GET("", seasonHandler.GetSeasons)
+			seasons.GET("/current", seasonHandler.GetCurrentSeason)
+			seasons.GET("/:id", seasonHandler.GetSeason)
+			seasons.GET("/players/:playerID", seasonHandler.GetPlayerSeasonHistory)
+		}
+
 		// Payment routes
 		payment := api.Group("/payment")
 		{
@@ -250,6 +324,30 @@ func main() {
 				protected.GET("/game-metrics", adminHandler.GetGameMetrics)
 				protected.GET("/system-metrics", adminHandler.GetSystemMetrics)
 				protected.GET("/users-list", adminHandler.GetUsersList)
+				
+				// Ranked season management
+				protected.POST("/seasons", seasonHandler.CreateSeason)
+				protected.PUT("/seasons/:id", seasonHandler.UpdateSeason)
+				protected.POST("/seasons/:id/end", seasonHandler.EndSeason)
+				
+				// Ranked map pool management
+				protected.GET("/map-pool", gameHandler.GetRankedMapPool)
+				protected.POST("/map-pool", gameHandler.AddRankedMap)
+				protected.DELETE("/map-pool/:mapID", gameHandler.RemoveRankedMap)
+				
+				// Tournament management
+				protected.POST("/tournaments", gameHandler.CreateTournament)
+				protected.POST("/tournaments/:id/start", gameHandler.StartTournament)
+				protected.POST("/tournaments/:id/cancel", gameHandler.CancelTournament)
+				protected.POST("/tournaments/:id/matches/:matchID/winner", gameHandler.SettleTournamentMatch)
+				
+				// Multiplayer chat moderation
+				protected.GET("/multiplayer/:gameID/chat", gameHandler.GetMultiplayerChatLog)
+				
+				// Anti-cheat review of flagged play
+				protected.GET("/match-reviews", gameHandler.GetMatchReviews)
+				protected.GET("/match-reviews/:id", gameHandler.GetMatchReview)
+				protected.POST("/match-reviews/:id/resolve", gameHandler.ResolveMatchReview)
 			}
 		}
 
@@ -259,6 +357,7 @@ func main() {
 	ws := router.Group("/ws")
 	{
 		ws.GET("/multiplayer/:gameID", gameHandler.HandleMultiplayerGameWebSocket)
+		ws.GET("/multiplayer/:gameID/spectate", gameHandler.HandleSpectatorWebSocket)
 	}
 
 	// SEO and crawling routes
@@ -291,5 +390,7 @@ func main() {
 	
 	// Cleanup services
 	gameHandler.Cleanup()
+	seasonService.Stop()
+	node.Stop()
 }
 
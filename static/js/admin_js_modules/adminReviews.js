@@ -0,0 +1,194 @@
+// Admin Match Review Management
+class AdminReviews {
+    constructor() {
+        this.flagLabels = {
+            regular_timing: 'Regular timing',
+            inhuman_speed: 'Inhuman speed',
+            optimal_path_streak: 'Optimal path streak',
+            instant_reaction: 'Instant reactions',
+            countdown_moves: 'Countdown moves'
+        };
+        this.initializeEventListeners();
+    }
+
+    initializeEventListeners() {
+        document.getElementById('viewMatchReviewsBtn')?.addEventListener('click', () => this.showMatchReviews());
+        document.getElementById('closeMatchReviewsModal')?.addEventListener('click', () => this.closeModal('matchReviewsModal'));
+        document.getElementById('matchReviewStatusFilter')?.addEventListener('change', () => this.loadMatchReviews());
+
+        // Clear and confirm buttons are rendered with the list
+        document.getElementById('matchReviewsContent')?.addEventListener('click', (e) => {
+            const button = e.target.closest('[data-review-decision]');
+            if (button) {
+                this.resolveMatchReview(button.dataset.reviewId, button.dataset.reviewDecision);
+            }
+        });
+    }
+
+    showMatchReviews() {
+        document.getElementById('matchReviewsModal').style.display = 'flex';
+        this.loadMatchReviews();
+    }
+
+    async loadMatchReviews() {
+        const content = document.getElementById('matchReviewsContent');
+        const status = document.getElementById('matchReviewStatusFilter')?.value || '';
+        content.innerHTML = '<div class="loading-message">Loading match reviews...</div>';
+
+        try {
+            const params = new URLSearchParams({ status });
+            const response = await fetch(`/api/admin/match-reviews?${params}`, {
+                method: 'GET',
+                headers: {
+                    'Content-Type': 'application/json'
+                }
+            });
+
+            const result = await response.json();
+
+            if (result.success) {
+                content.innerHTML = this.renderMatchReviews(result.reviews);
+            } else {
+                content.innerHTML = '<div class="error-message">Failed to load match reviews</div>';
+            }
+        } catch (error) {
+            console.error('Error loading match reviews:', error);
+            content.innerHTML = '<div class="error-message">Failed to load match reviews</div>';
+        }
+    }
+
+    async resolveMatchReview(reviewId, decision) {
+        const verb = decision === 'cleared' ? 'Clear' : 'Confirm';
+        const notes = prompt(`${verb} this review? Add a note for the record (optional):`, '');
+        if (notes === null) {
+            return;
+        }
+
+        try {
+            const response = await fetch(`/api/admin/match-reviews/${reviewId}/resolve`, {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json'
+                },
+                body: JSON.stringify({ decision, notes })
+            });
+
+            const result = await response.json();
+
+            if (result.success) {
+                this.showMessage(decision === 'cleared' ? 'Review cleared' : 'Cheating confirmed', 'success');
+                this.loadMatchReviews();
+            } else {
+                this.showMessage(result.error || 'Failed to resolve review', 'error');
+            }
+        } catch (error) {
+            console.error('Error resolving match review:', error);
+            this.showMessage('Failed to resolve review', 'error');
+        }
+    }
+
+    renderMatchReviews(reviews) {
+        if (!reviews || reviews.length === 0) {
+            return '<div class="no-data">No match reviews found</div>';
+        }
+
+        return `
+            <table class="users-table">
+                <thead>
+                    <tr>
+                        <th>Flagged</th>
+                        <th>Player</th>
+                        <th>Game</th>
+                        <th>Reasons</th>
+                        <th>Figures</th>
+                        <th>Result</th>
+                        <th>Status</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    ${reviews.map(review => `
+                        <tr>
+                            <td>${new Date(review.created_at).toLocaleString()}</td>
+                            <td>${this.escapeHtml(review.username)} (#${review.player_id})</td>
+                            <td>${this.escapeHtml(review.game_id)}</td>
+                            <td>${this.renderFlags(review.flags)}</td>
+                            <td>${this.renderFigures(review)}</td>
+                            <td>${review.result_held ? 'Held from stats' : 'Counted'}</td>
+                            <td>${this.renderStatus(review)}</td>
+                        </tr>
+                    `).join('')}
+                </tbody>
+            </table>
+        `;
+    }
+
+    renderFlags(flags) {
+        return flags.split(',').filter(Boolean)
+            .map(flag => this.escapeHtml(this.flagLabels[flag] || flag))
+            .join('<br>');
+    }
+
+    renderFigures(review) {
+        const figures = [`${review.moves} moves`];
+        if (review.median_interval_ms > 0) {
+            figures.push(`Median interval ${review.median_interval_ms.toFixed(0)}ms, spread ${review.interval_spread.toFixed(2)}`);
+        }
+        if (review.judged_moves > 0) {
+            figures.push(`${review.optimal_moves}/${review.judged_moves} optimal, streak ${review.longest_optimal_streak}`);
+        }
+        if (review.instant_reactions > 0) {
+            figures.push(`${review.instant_reactions} instant reactions, fastest ${review.fastest_reaction_ms.toFixed(0)}ms`);
+        }
+        if (review.countdown_moves > 0) {
+            figures.push(`${review.countdown_moves} countdown moves`);
+        }
+        return figures.join('<br>');
+    }
+
+    renderStatus(review) {
+        if (review.status === 'pending') {
+            return `
+                <button class="button-base admin-btn" data-review-id="${review.id}" data-review-decision="cleared">Clear</button>
+                <button class="button-base admin-btn" data-review-id="${review.id}" data-review-decision="confirmed">Confirm</button>
+            `;
+        }
+
+        const reviewer = review.reviewed_by ? ` by ${this.escapeHtml(review.reviewed_by)}` : '';
+        const notes = review.review_notes ? `<br>${this.escapeHtml(review.review_notes)}` : '';
+        return `<span class="status-badge ${review.status === 'cleared' ? 'confirmed' : 'pending'}">${review.status}</span>${reviewer}${notes}`;
+    }
+
+    escapeHtml(text) {
+        const div = document.createElement('div');
+        div.textContent = text;
+        return div.innerHTML;
+    }
+
+    showMessage(message, type) {
+        const container = document.getElementById('messageContainer');
+        if (!container) return;
+
+        const messageEl = document.createElement('div');
+        messageEl.className = `message ${type}`;
+        messageEl.textContent = message;
+
+        container.appendChild(messageEl);
+
+        // Auto-remove after 3 seconds
+        setTimeout(() => {
+            messageEl.remove();
+        }, 3000);
+    }
+
+    closeModal(modalId) {
+        const modal = document.getElementById(modalId);
+        if (modal) {
+            modal.style.display = 'none';
+        }
+    }
+}
+
+// Initialize when DOM is loaded
+document.addEventListener('DOMContentLoaded', () => {
+    window.adminReviews = new AdminReviews();
+});
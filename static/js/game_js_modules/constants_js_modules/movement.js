@@ -62,8 +62,42 @@ export const MOVEMENT_KEYS = {
 
   // Match
   "%": { direction: "%", description: "MATCHING BRACKET" },
+
+  // Code structure
+  "[": {
+    direction: "[",
+    description: "[ combination pressed",
+  },
+  "]": {
+    direction: "]",
+    description: "] combination pressed",
+  },
+  "[{": { direction: "[{", description: "PREV UNMATCHED { ←" },
+  "]}": { direction: "]}", description: "NEXT UNMATCHED } →" },
+  "[(": { direction: "[(", description: "PREV UNMATCHED ( ←" },
+  "])": { direction: "])", description: "NEXT UNMATCHED ) →" },
+  "[[": { direction: "[[", description: "PREV SECTION START ←" },
+  "]]": { direction: "]]", description: "NEXT SECTION START →" },
+  "[]": { direction: "[]", description: "PREV SECTION END ←" },
+  "][": { direction: "][", description: "NEXT SECTION END →" },
+  gd: { direction: "gd", description: "LOCAL DECLARATION" },
+  gD: { direction: "gD", description: "GLOBAL DECLARATION" },
+
+  // Display lines
+  gj: { direction: "gj", description: "DISPLAY LINE DOWN ↓" },
+  gk: { direction: "gk", description: "DISPLAY LINE UP ↑" },
+  g0: { direction: "g0", description: "DISPLAY LINE START ←" },
+  "g^": { direction: "g^", description: "DISPLAY LINE FIRST NON-BLANK ←" },
+  gm: { direction: "gm", description: "DISPLAY LINE MIDDLE" },
+  g$: { direction: "g$", description: "DISPLAY LINE END →" },
 };
 
+// Keys completing a [ or ] combination
+export const BRACKET_COMMANDS = ["[{", "]}", "[(", "])", "[[", "]]", "[]", "]["];
+
+// Keys completing a g combination, besides gg which takes a line count
+export const G_COMMANDS = ["ge", "gE", "g_", "gd", "gD", "gj", "gk", "g0", "g^", "gm", "g$"];
+
 // Generate movement messages dynamically
 export const MOVEMENT_MESSAGES = Object.fromEntries(
   Object.entries(MOVEMENT_KEYS).map(([key, config]) => [
@@ -26,7 +26,7 @@ export const TUTORIAL_CONFIG = {
 };
 
 export const TUTORIAL_COMMANDS = VALID_MOVEMENT_KEYS
-  .filter(key => key !== 'g') // Exclude single 'g' from tutorial
+  .filter(key => !['g', '[', ']'].includes(key)) // Exclude combination prefixes from tutorial
   .map((key) => ({
     key: key,
     message: `Press ${key} to go ${MOVEMENT_KEYS[key].description}`,
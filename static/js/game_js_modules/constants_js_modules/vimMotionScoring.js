@@ -70,6 +70,30 @@ const VIM_MOTION_SCORES = {
     "%": 200,
   },
 
+  // Code movement ([{, ]}, [(, ]), [[, ]], [], ][, gd, gD)
+  codeMovement: {
+    "[{": 180,
+    "]}": 180,
+    "[(": 180,
+    "])": 180,
+    "[[": 170,
+    "]]": 170,
+    "[]": 170,
+    "][": 170,
+    "gd": 180,
+    "gD": 180,
+  },
+
+  // Display-line movement (gj, gk, g0, g^, gm, g$)
+  displayMovement: {
+    "gj": 110,
+    "gk": 110,
+    "g0": 120,
+    "g^": 130,
+    "gm": 130,
+    "g$": 120,
+  },
+
   // Arrow key penalty
   arrowPenalty: {
     "ArrowUp": -50,
@@ -109,6 +133,12 @@ export function getMotionScore(motion) {
   if (VIM_MOTION_SCORES.matchMovement[motion]) {
     return VIM_MOTION_SCORES.matchMovement[motion];
   }
+  if (VIM_MOTION_SCORES.codeMovement[motion]) {
+    return VIM_MOTION_SCORES.codeMovement[motion];
+  }
+  if (VIM_MOTION_SCORES.displayMovement[motion]) {
+    return VIM_MOTION_SCORES.displayMovement[motion];
+  }
   if (VIM_MOTION_SCORES.arrowPenalty[motion]) {
     return VIM_MOTION_SCORES.arrowPenalty[motion];
   }
@@ -169,6 +199,12 @@ export function getMotionCategory(motion) {
   if (VIM_MOTION_SCORES.matchMovement[motion]) {
     return "Match Movement";
   }
+  if (VIM_MOTION_SCORES.codeMovement[motion]) {
+    return "Code Movement";
+  }
+  if (VIM_MOTION_SCORES.displayMovement[motion]) {
+    return "Display Movement";
+  }
   if (VIM_MOTION_SCORES.arrowPenalty[motion]) {
     return "Arrow Penalty";
   }
@@ -4,8 +4,11 @@ import {
   showCharWaitingFeedback, 
   clearCharWaitingFeedback,
   showGCommandFeedback,
-  clearGCommandFeedback
+  clearGCommandFeedback,
+  showBracketCommandFeedback,
+  clearBracketCommandFeedback
 } from './movementHelpers.js';
+import { BRACKET_COMMANDS, G_COMMANDS } from '../constants_js_modules/movement.js';
 import { toggleSpriteVisibility } from './spriteVisibility.js';
 
 export function initializeMovement() {
@@ -19,6 +22,9 @@ export function initializeMovement() {
   // G-command state
   let waitingForGCommand = false;
   
+  // Bracket-command state: the [ or ] waiting for its second key
+  let waitingForBracketCommand = null;
+  
   // Number prefix state
   let numberPrefix = '';
   let accumulatingNumber = false;
@@ -164,7 +170,7 @@ export function initializeMovement() {
     }
     
     // Handle space key (start space command mode) - but only if not waiting for character search
-    if (key === ' ' && !waitingForChar && !waitingForGCommand) {
+    if (key === ' ' && !waitingForChar && !waitingForGCommand && !waitingForBracketCommand) {
       waitingForSpaceCommand = true;
       // Clear space command mode after 1 second if no key is pressed
       spaceTimeout = setTimeout(() => {
@@ -175,7 +181,7 @@ export function initializeMovement() {
     }
 
     // Handle number input accumulation
-    if (!waitingForChar && !waitingForGCommand && /^[0-9]$/.test(key)) {
+    if (!waitingForChar && !waitingForGCommand && !waitingForBracketCommand && /^[0-9]$/.test(key)) {
       // Don't start with 0 unless it's the only digit
       if (key === '0' && numberPrefix === '') {
         // 0 is a movement command (go to beginning of line), not a number prefix
@@ -344,6 +350,30 @@ export function initializeMovement() {
         
         movePlayer('gE', count);
         event.preventDefault();
+      } else if (G_COMMANDS.includes(`g${key}`)) {
+        const command = `g${key}`;
+        const count = numberPrefix === '' ? 1 : parseInt(numberPrefix);
+        const hasExplicitCount = numberPrefix !== '';
+        const finalDirection = count > 1 ? `${count}${command}` : command;
+        
+        waitingForGCommand = false;
+        clearGCommandFeedback();
+        
+        // Clear number prefix state
+        numberPrefix = '';
+        accumulatingNumber = false;
+        if (window.feedbackModule && window.feedbackModule.clearNumberPrefix) {
+          window.feedbackModule.clearNumberPrefix();
+        }
+        
+        if (window.gameState.tutorialMode) {
+          window.tutorialModule.handleTutorialMovement(finalDirection);
+        } else {
+          window.feedbackModule.showMovementFeedback(finalDirection, count, hasExplicitCount);
+        }
+        
+        movePlayer(command, count, hasExplicitCount);
+        event.preventDefault();
       } else if (key === 'Escape') {
         waitingForGCommand = false;
         clearGCommandFeedback();
@@ -376,6 +406,44 @@ export function initializeMovement() {
       return;
     }
 
+    if (waitingForBracketCommand) {
+      if (['Shift', 'Control', 'Alt', 'Meta'].includes(key)) {
+        return;
+      }
+      
+      const command = `${waitingForBracketCommand}${key}`;
+      const count = numberPrefix === '' ? 1 : parseInt(numberPrefix);
+      const hasExplicitCount = numberPrefix !== '';
+      
+      waitingForBracketCommand = null;
+      clearBracketCommandFeedback();
+      
+      // Clear number prefix state
+      numberPrefix = '';
+      accumulatingNumber = false;
+      if (window.feedbackModule && window.feedbackModule.clearNumberPrefix) {
+        window.feedbackModule.clearNumberPrefix();
+      }
+      
+      if (BRACKET_COMMANDS.includes(command)) {
+        const finalDirection = count > 1 ? `${count}${command}` : command;
+        
+        if (window.gameState.tutorialMode) {
+          window.tutorialModule.handleTutorialMovement(finalDirection);
+        } else {
+          window.feedbackModule.showMovementFeedback(finalDirection, count, hasExplicitCount);
+        }
+        
+        movePlayer(command, count, hasExplicitCount);
+      } else if (key !== 'Escape' && window.gameState.tutorialMode) {
+        // Handle invalid bracket command in tutorial mode
+        window.tutorialModule.handleTutorialMovement(command);
+      }
+      
+      event.preventDefault();
+      return;
+    }
+
     // Enter key alone no longer toggles sprite visibility (use Space+Enter instead)
 
     // Handle arrow keys specifically
@@ -456,6 +524,18 @@ export function initializeMovement() {
         return;
       }
 
+      if (key === '[' || key === ']') {
+        waitingForBracketCommand = key;
+        
+        // In tutorial mode, don't show the bracket command feedback
+        if (!window.gameState.tutorialMode) {
+          showBracketCommandFeedback(key);
+        }
+        
+        event.preventDefault();
+        return;
+      }
+
       // Handle movement with number prefix
       const count = numberPrefix === '' ? 1 : parseInt(numberPrefix);
       const hasExplicitCount = numberPrefix !== '';
@@ -33,7 +33,7 @@ export function clearCharWaitingFeedback() {
 export function showGCommandFeedback() {
   // Use the banner system for consistent wood-colored display
   if (window.gameBanner && window.gameBanner.showNormalBanner) {
-    window.gameBanner.showNormalBanner("g-COMMAND (press g, _, e, or E)", 0);
+    window.gameBanner.showNormalBanner("g-COMMAND (press g, _, e, E, d, D, j, k, 0, ^, m, or $)", 0);
   }
 }
 
@@ -43,3 +43,22 @@ export function clearGCommandFeedback() {
     window.gameBanner.resetGameBanner();
   }
 }
+
+export function showBracketCommandFeedback(prefix) {
+  const commandDescriptions = {
+    "[": "[-COMMAND (press {, (, [, or ])",
+    "]": "]-COMMAND (press }, ), ], or [)",
+  };
+
+  // Use the banner system for consistent wood-colored display
+  if (window.gameBanner && window.gameBanner.showNormalBanner) {
+    window.gameBanner.showNormalBanner(commandDescriptions[prefix], 0);
+  }
+}
+
+export function clearBracketCommandFeedback() {
+  // Use the banner system to reset to original state
+  if (window.gameBanner && window.gameBanner.resetGameBanner) {
+    window.gameBanner.resetGameBanner();
+  }
+}
@@ -23,6 +23,7 @@ let clientGameState = {
   preferredColumn: 0,
   gameMap: [],
   textGrid: [],
+  stateVersion: 0, // Version of gameMap on the server; 0 until a response carries one
   isInitialized: false
 };
 
@@ -197,6 +198,7 @@ async function movePlayerServerOnly(direction, count, hasExplicitCount) {
         direction: direction,
         count: count,
         has_explicit_count: hasExplicitCount,
+        state_version: clientGameState.stateVersion,
       }),
     });
 
@@ -204,6 +206,7 @@ async function movePlayerServerOnly(direction, count, hasExplicitCount) {
     networkAdapter.measureLatency(startTime);
 
     const result = await response.json();
+    applyMapState(result);
 
     // Always update client state from server response
     updateClientGameState(result);
@@ -239,6 +242,7 @@ async function validatePredictionOptimized(direction, count, prediction, hasExpl
         direction: direction,
         count: count,
         has_explicit_count: hasExplicitCount,
+        state_version: clientGameState.stateVersion,
       }),
     });
 
@@ -246,6 +250,7 @@ async function validatePredictionOptimized(direction, count, prediction, hasExpl
     networkAdapter.measureLatency(startTime);
 
     const result = await response.json();
+    applyMapState(result);
     
     // Remove prediction from active set
     activePredictions.delete(predictionId);
@@ -337,6 +342,30 @@ export function isPredictionActive() {
   return activePredictions.size > 0 || serverSyncPending;
 }
 
+// Bring the client's game map up to date from a move response, which carries either the cells the
+// move changed (map_changes) or the whole map (game_map) with the map's new version. Responses
+// arriving after a newer one are ignored. Leaves the resulting map in result.game_map for display.
+function applyMapState(result) {
+  if (result.state_version === undefined) {
+    return;
+  }
+
+  if (result.state_version >= clientGameState.stateVersion) {
+    if (result.map_changes) {
+      result.map_changes.forEach(({ row, col, value }) => {
+        if (clientGameState.gameMap[row]) {
+          clientGameState.gameMap[row][col] = value;
+        }
+      });
+    } else if (result.game_map) {
+      clientGameState.gameMap = result.game_map.map(row => [...row]);
+    }
+    clientGameState.stateVersion = result.state_version;
+  }
+
+  result.game_map = clientGameState.gameMap.map(row => [...row]);
+}
+
 // Initialize/update client state from server response
 function updateClientGameState(result) {
   try {
@@ -0,0 +1,215 @@
+/**
+ * Code-structure movement handlers for Vim Movement Predictor
+ * Handles [{, ]}, [(, ]), [[, ]], [], ][, gd and gD
+ * Matches Go backend movement/code.go
+ */
+
+import { isWordChar } from './utils.js';
+
+// Code movement handler (matches Go backend)
+export function handleCodeMovement(direction, currentRow, currentCol, textGrid) {
+  let newRow = currentRow;
+  let newCol = currentCol;
+  let found;
+
+  switch (direction) {
+    case "unmatched_brace_prev":
+      // [{: go to previous unmatched '{'
+      found = findUnmatchedBracketBackward(currentRow, currentCol, "{", "}", textGrid);
+      break;
+    case "unmatched_brace_next":
+      // ]}: go to next unmatched '}'
+      found = findUnmatchedBracketForward(currentRow, currentCol, "{", "}", textGrid);
+      break;
+    case "unmatched_paren_prev":
+      // [(: go to previous unmatched '('
+      found = findUnmatchedBracketBackward(currentRow, currentCol, "(", ")", textGrid);
+      break;
+    case "unmatched_paren_next":
+      // ]): go to next unmatched ')'
+      found = findUnmatchedBracketForward(currentRow, currentCol, "(", ")", textGrid);
+      break;
+    case "section_prev":
+      // [[: go to previous '{' in the first column
+      found = findSectionBackward(currentRow, currentCol, "{", textGrid);
+      break;
+    case "section_next":
+      // ]]: go to next '{' in the first column
+      found = findSectionForward(currentRow, currentCol, "{", textGrid);
+      break;
+    case "section_end_prev":
+      // []: go to previous '}' in the first column
+      found = findSectionBackward(currentRow, currentCol, "}", textGrid);
+      break;
+    case "section_end_next":
+      // ][: go to next '}' in the first column
+      found = findSectionForward(currentRow, currentCol, "}", textGrid);
+      break;
+    case "goto_local_declaration":
+      // gd: first occurrence of the identifier in the current section
+      found = findDeclaration(currentRow, currentCol, textGrid, true);
+      break;
+    case "goto_global_declaration":
+      // gD: first occurrence of the identifier in the file
+      found = findDeclaration(currentRow, currentCol, textGrid, false);
+      break;
+  }
+
+  if (found) {
+    newRow = found.row;
+    newCol = found.col;
+  }
+
+  return { newRow, newCol, newPreferredColumn: newCol };
+}
+
+// Find the previous opening bracket that is not closed before the cursor
+function findUnmatchedBracketBackward(row, col, openBracket, closeBracket, textGrid) {
+  if (row < 0 || row >= textGrid.length) {
+    return { row, col };
+  }
+
+  let nestLevel = 0;
+  let currentRow = row;
+  let currentCol = col - 1;
+
+  while (currentRow >= 0) {
+    while (currentCol >= 0) {
+      if (currentCol < textGrid[currentRow].length) {
+        const char = textGrid[currentRow][currentCol];
+        if (char === closeBracket) {
+          nestLevel++;
+        } else if (char === openBracket) {
+          if (nestLevel === 0) {
+            return { row: currentRow, col: currentCol };
+          }
+          nestLevel--;
+        }
+      }
+      currentCol--;
+    }
+    currentRow--;
+    if (currentRow >= 0) {
+      currentCol = textGrid[currentRow].length - 1;
+    }
+  }
+
+  return { row, col };
+}
+
+// Find the next closing bracket that was not opened after the cursor
+function findUnmatchedBracketForward(row, col, openBracket, closeBracket, textGrid) {
+  if (row < 0 || row >= textGrid.length) {
+    return { row, col };
+  }
+
+  let nestLevel = 0;
+  let currentRow = row;
+  let currentCol = col + 1;
+
+  while (currentRow < textGrid.length) {
+    while (currentCol < textGrid[currentRow].length) {
+      const char = textGrid[currentRow][currentCol];
+      if (char === openBracket) {
+        nestLevel++;
+      } else if (char === closeBracket) {
+        if (nestLevel === 0) {
+          return { row: currentRow, col: currentCol };
+        }
+        nestLevel--;
+      }
+      currentCol++;
+    }
+    currentRow++;
+    currentCol = 0;
+  }
+
+  return { row, col };
+}
+
+// Find the previous line starting with the given character, or the top of the file
+function findSectionBackward(row, col, sectionChar, textGrid) {
+  if (row < 0 || row >= textGrid.length) {
+    return { row, col };
+  }
+
+  for (let currentRow = row - 1; currentRow >= 0; currentRow--) {
+    if (textGrid[currentRow].length > 0 && textGrid[currentRow][0] === sectionChar) {
+      return { row: currentRow, col: 0 };
+    }
+  }
+
+  // Like vim, fall back to the first line when no section is found
+  return { row: 0, col: 0 };
+}
+
+// Find the next line starting with the given character, or the bottom of the file
+function findSectionForward(row, col, sectionChar, textGrid) {
+  if (row < 0 || row >= textGrid.length) {
+    return { row, col };
+  }
+
+  for (let currentRow = row + 1; currentRow < textGrid.length; currentRow++) {
+    if (textGrid[currentRow].length > 0 && textGrid[currentRow][0] === sectionChar) {
+      return { row: currentRow, col: 0 };
+    }
+  }
+
+  // Like vim, fall back to the last line when no section is found
+  return { row: textGrid.length - 1, col: 0 };
+}
+
+// Find the first occurrence of the identifier under the cursor, from the start of the
+// current section (gd) or the top of the file (gD)
+function findDeclaration(row, col, textGrid, local) {
+  if (row < 0 || row >= textGrid.length || col < 0 || col >= textGrid[row].length) {
+    return { row, col };
+  }
+
+  const line = textGrid[row];
+  if (!isWordChar(line[col])) {
+    return { row, col };
+  }
+
+  // Extract the identifier under the cursor
+  let start = col;
+  while (start > 0 && isWordChar(line[start - 1])) {
+    start--;
+  }
+  let end = col;
+  while (end < line.length - 1 && isWordChar(line[end + 1])) {
+    end++;
+  }
+  const identifier = line.slice(start, end + 1);
+
+  const startRow = local ? findSectionBackward(row, col, "{", textGrid).row : 0;
+
+  for (let searchRow = startRow; searchRow <= row; searchRow++) {
+    const searchLine = textGrid[searchRow];
+    for (let searchCol = 0; searchCol + identifier.length <= searchLine.length; searchCol++) {
+      if (searchRow === row && searchCol >= start) {
+        break;
+      }
+      if (isIdentifierAt(searchLine, searchCol, identifier)) {
+        return { row: searchRow, col: searchCol };
+      }
+    }
+  }
+
+  // The identifier under the cursor is its own first occurrence
+  return { row, col: start };
+}
+
+// Check whether the whole identifier starts at the given column
+function isIdentifierAt(line, col, identifier) {
+  if (col > 0 && isWordChar(line[col - 1])) {
+    return false;
+  }
+  for (let i = 0; i < identifier.length; i++) {
+    if (line[col + i] !== identifier[i]) {
+      return false;
+    }
+  }
+  const end = col + identifier.length;
+  return end >= line.length || !isWordChar(line[end]);
+}
@@ -65,7 +65,27 @@ export const MOVEMENT_KEY_TO_DIRECTION = {
   ",": "repeat_char_search_opposite",
   
   // Bracket matching
-  "%": "match_bracket"
+  "%": "match_bracket",
+  
+  // Code structure
+  "[{": "unmatched_brace_prev",
+  "]}": "unmatched_brace_next",
+  "[(": "unmatched_paren_prev",
+  "])": "unmatched_paren_next",
+  "[[": "section_prev",
+  "]]": "section_next",
+  "[]": "section_end_prev",
+  "][": "section_end_next",
+  "gd": "goto_local_declaration",
+  "gD": "goto_global_declaration",
+  
+  // Display lines
+  "gj": "display_down",
+  "gk": "display_up",
+  "g0": "display_line_start",
+  "g^": "display_first_non_blank",
+  "gm": "display_line_middle",
+  "g$": "display_line_end"
 };
 
 // Valid directions map (matches Go backend)
@@ -101,7 +121,23 @@ export const VALID_DIRECTIONS = {
   "till_char_backward": true,
   "repeat_char_search_same": true,
   "repeat_char_search_opposite": true,
-  "match_bracket": true
+  "match_bracket": true,
+  "unmatched_brace_prev": true,
+  "unmatched_brace_next": true,
+  "unmatched_paren_prev": true,
+  "unmatched_paren_next": true,
+  "section_prev": true,
+  "section_next": true,
+  "section_end_prev": true,
+  "section_end_next": true,
+  "goto_local_declaration": true,
+  "goto_global_declaration": true,
+  "display_down": true,
+  "display_up": true,
+  "display_line_start": true,
+  "display_first_non_blank": true,
+  "display_line_middle": true,
+  "display_line_end": true
 };
 
 // Re-export existing constants for convenience
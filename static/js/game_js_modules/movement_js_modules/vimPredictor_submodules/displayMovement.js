@@ -0,0 +1,130 @@
+/**
+ * Display-line movement handlers for Vim Movement Predictor
+ * Handles gj, gk, g0, g^, gm and g$
+ * Matches Go backend movement/display.go
+ */
+
+import { isSpace } from './utils.js';
+
+// Display-line movement handler (matches Go backend)
+// Long lines are split into display lines of the session's text width when wrapping is enabled;
+// otherwise every logical line is a single display line, like the server's default
+export function handleDisplayLineMovement(direction, currentRow, currentCol, preferredColumn, gameMap, textGrid) {
+  let newRow = currentRow;
+  let newCol = currentCol;
+  let newPreferredColumn = preferredColumn;
+
+  if (currentRow < 0 || currentRow >= gameMap.length) {
+    return { newRow, newCol, newPreferredColumn };
+  }
+
+  const width = getDisplayWidth();
+  const { lineStart, lineEnd } = findDisplayLine(currentCol, gameMap[currentRow].length, width);
+
+  switch (direction) {
+    case "display_down": {
+      // gj: move down one display line, keeping the screen column
+      const screenCol = screenColumn(preferredColumn, width);
+      if (lineEnd < gameMap[currentRow].length) {
+        newCol = clampCol(lineEnd + screenCol, gameMap[currentRow].length);
+      } else if (currentRow < gameMap.length - 1) {
+        newRow = currentRow + 1;
+        newCol = clampCol(screenCol, gameMap[newRow].length);
+      }
+      // Don't update preferred column for vertical movements
+      break;
+    }
+    case "display_up": {
+      // gk: move up one display line, keeping the screen column
+      const screenCol = screenColumn(preferredColumn, width);
+      if (lineStart > 0) {
+        newCol = clampCol(lineStart - width + screenCol, gameMap[currentRow].length);
+      } else if (currentRow > 0) {
+        newRow = currentRow - 1;
+        const rowLength = gameMap[newRow].length;
+        const lastLine = findDisplayLine(rowLength - 1, rowLength, width);
+        newCol = clampCol(lastLine.lineStart + screenCol, rowLength);
+      }
+      // Don't update preferred column for vertical movements
+      break;
+    }
+    case "display_line_start":
+      // g0: move to first character of the display line
+      newCol = lineStart;
+      newPreferredColumn = newCol;
+      break;
+    case "display_first_non_blank":
+      // g^: move to first non-blank character of the display line
+      newCol = lineStart;
+      if (currentRow < textGrid.length) {
+        for (let col = lineStart; col < lineEnd && col < textGrid[currentRow].length; col++) {
+          const char = textGrid[currentRow][col];
+          if (!isSpace(char) && char !== "") {
+            newCol = col;
+            break;
+          }
+        }
+      }
+      newPreferredColumn = newCol;
+      break;
+    case "display_line_middle": {
+      // gm: move half a screen width to the right of the display line start
+      let half = Math.floor((lineEnd - lineStart) / 2);
+      if (width > 0) {
+        half = Math.floor(width / 2);
+      }
+      newCol = clampCol(lineStart + half, lineEnd);
+      newPreferredColumn = newCol;
+      break;
+    }
+    case "display_line_end":
+      // g$: move to last character of the display line
+      newCol = clampCol(lineEnd - 1, lineEnd);
+      newPreferredColumn = newCol;
+      break;
+  }
+
+  return { newRow, newCol, newPreferredColumn };
+}
+
+// Width at which lines wrap, or 0 when lines are not wrapped
+function getDisplayWidth() {
+  const state = typeof window !== 'undefined' ? window.gameState : null;
+  if (!state || !state.wrap || !(state.text_width > 0)) {
+    return 0;
+  }
+  return state.text_width;
+}
+
+// Start (inclusive) and end (exclusive) columns of the display line containing col
+function findDisplayLine(col, rowLength, width) {
+  if (width <= 0 || rowLength <= width) {
+    return { lineStart: 0, lineEnd: rowLength };
+  }
+  if (col < 0) {
+    col = 0;
+  }
+
+  const lineStart = Math.floor(col / width) * width;
+  const lineEnd = Math.min(lineStart + width, rowLength);
+  return { lineStart, lineEnd };
+}
+
+// Convert a logical column into a column within its display line
+function screenColumn(col, width) {
+  if (width <= 0) {
+    return col;
+  }
+  return col % width;
+}
+
+// Clamp col to the characters of a line of the given length
+function clampCol(col, rowLength) {
+  if (col >= rowLength) {
+    col = rowLength - 1;
+  }
+  if (col < 0) {
+    col = 0;
+  }
+  return col;
+}
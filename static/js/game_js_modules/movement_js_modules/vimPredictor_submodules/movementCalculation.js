@@ -21,6 +21,21 @@ import {
   handleCharacterSearch, 
   handleBracketMatching 
 } from './specialMovement.js';
+import { handleCodeMovement } from './codeMovement.js';
+import { handleDisplayLineMovement } from './displayMovement.js';
+
+// Code-structure directions ([{, ]}, [(, ]), [[, ]], [], ][, gd, gD)
+const CODE_DIRECTIONS = [
+  "unmatched_brace_prev", "unmatched_brace_next", "unmatched_paren_prev", "unmatched_paren_next",
+  "section_prev", "section_next", "section_end_prev", "section_end_next",
+  "goto_local_declaration", "goto_global_declaration"
+];
+
+// Display-line directions (gj, gk, g0, g^, gm, g$)
+const DISPLAY_DIRECTIONS = [
+  "display_down", "display_up", "display_line_start",
+  "display_first_non_blank", "display_line_middle", "display_line_end"
+];
 
 // Main movement calculation function with count support (matches Go backend)
 export function calculateNewPositionWithCount(direction, currentRow, currentCol, gameMap, textGrid, preferredColumn, count, hasExplicitCount = false) {
@@ -81,6 +96,16 @@ export function calculateNewPositionWithCount(direction, currentRow, currentCol,
     newRow = result.newRow;
     newCol = result.newCol;
     newPreferredColumn = result.newPreferredColumn;
+  } else if (CODE_DIRECTIONS.includes(direction)) {
+    const result = handleCodeMovement(direction, currentRow, currentCol, textGrid);
+    newRow = result.newRow;
+    newCol = result.newCol;
+    newPreferredColumn = result.newPreferredColumn;
+  } else if (DISPLAY_DIRECTIONS.includes(direction)) {
+    const result = handleDisplayLineMovement(direction, currentRow, currentCol, preferredColumn, gameMap, textGrid);
+    newRow = result.newRow;
+    newCol = result.newCol;
+    newPreferredColumn = result.newPreferredColumn;
   } else {
     throw new Error(`unknown direction: ${direction}`);
   }
@@ -167,6 +192,16 @@ export function calculateNewPosition(direction, currentRow, currentCol, gameMap,
     newRow = result.newRow;
     newCol = result.newCol;
     newPreferredColumn = result.newPreferredColumn;
+  } else if (CODE_DIRECTIONS.includes(direction)) {
+    const result = handleCodeMovement(direction, currentRow, currentCol, textGrid);
+    newRow = result.newRow;
+    newCol = result.newCol;
+    newPreferredColumn = result.newPreferredColumn;
+  } else if (DISPLAY_DIRECTIONS.includes(direction)) {
+    const result = handleDisplayLineMovement(direction, currentRow, currentCol, preferredColumn, gameMap, textGrid);
+    newRow = result.newRow;
+    newCol = result.newCol;
+    newPreferredColumn = result.newPreferredColumn;
   } else {
     throw new Error(`unknown direction: ${direction}`);
   }
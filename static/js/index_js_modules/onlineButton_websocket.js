@@ -10,6 +10,8 @@ let currentMatchID = null;
 let reconnectTimer = null;
 let heartbeatTimer = null;
 let currentMatchData = null;
+let mapVetoTimer = null;
+let mapVetoKeyHandler = null;
 
 export function initializeOnlineButton() {
   const playOnline = document.getElementById("playOnline");
@@ -100,7 +102,8 @@ async function connectToMatchmaking(button, websocketUrl) {
     
     logger.debug("Connecting to WebSocket:", wsUrl);
 
-    matchmakingSocket = new WebSocket(wsUrl);
+    // Ask for the versioned JSON schema so later message formats don't break this tab
+    matchmakingSocket = new WebSocket(wsUrl, ["bobavim.v1.json"]);
     
     matchmakingSocket.onopen = function(event) {
       logger.debug("WebSocket connected");
@@ -170,8 +173,12 @@ function handleWebSocketMessage(message, button) {
     case "queue_timeout":
       setButtonState(button, "idle");
       isWaitingForOpponent = false;
-      showErrorAlert("Matchmaking timeout. No opponent found after 45 seconds.");
       disconnect();
+      if (message.data && message.data.practice_offered) {
+        showPracticeOffer(message.data);
+      } else {
+        showErrorAlert("Matchmaking timeout. No opponent found after 45 seconds.");
+      }
       break;
 
     case "match_found":
@@ -223,10 +230,15 @@ function handleWebSocketMessage(message, button) {
       }, 2000);
       break;
 
+    case "map_veto":
+      showMapVeto(message.data, message.message);
+      break;
+
     case "match_started":
       setButtonState(button, "match_started");
       isWaitingForOpponent = false;
       currentMatchID = null;
+      stopMapVeto();
       
       // Start countdown in the modal
       startMatchCountdown(message.data);
@@ -245,6 +257,11 @@ function handleWebSocketMessage(message, button) {
 
     case "error":
       logger.error("Matchmaking error:", message.message);
+      // A rejected ban or pick leaves the veto running
+      if (document.getElementById("mapVetoSection")) {
+        setMapVetoStatus(message.message);
+        break;
+      }
       setButtonState(button, "idle");
       showErrorAlert(message.message);
       break;
@@ -643,6 +660,153 @@ function hideMatchActions() {
   }
 }
 
+// Shows the map veto of a duel once both players accepted: players take turns banning maps until
+// two are left, then the player whose turn it is picks the map. Sent again after every action.
+function showMapVeto(vetoData, message) {
+  const modal = ensureMatchModal();
+  hideMatchActions();
+
+  let section = document.getElementById("mapVetoSection");
+  if (!section) {
+    section = document.createElement("div");
+    section.id = "mapVetoSection";
+    section.className = "map-veto-section";
+    section.innerHTML = `
+      <div class="map-veto-header">
+        <span class="map-veto-turn" id="mapVetoTurn"></span>
+        <span class="map-veto-timer" id="mapVetoTimer"></span>
+      </div>
+      <div class="map-veto-maps" id="mapVetoMaps"></div>
+      <div class="map-veto-status" id="mapVetoStatus"></div>
+    `;
+    modal.insertBefore(section, document.getElementById("countdownSection"));
+  }
+
+  const remaining = new Set(vetoData.remaining);
+  const myTurn = vetoData.your_turn;
+
+  const turnElement = document.getElementById("mapVetoTurn");
+  if (vetoData.selected_map) {
+    turnElement.textContent = `Map: ${vetoData.selected_map.name}`;
+  } else if (myTurn) {
+    turnElement.textContent = vetoData.turn_action === "ban" ? "Your turn: ban a map" : "Your turn: pick the map";
+  } else {
+    turnElement.textContent = `${vetoData.turn_username} is choosing a map to ${vetoData.turn_action}...`;
+  }
+  setMapVetoStatus(message);
+
+  const mapsElement = document.getElementById("mapVetoMaps");
+  mapsElement.replaceChildren();
+  vetoData.maps.forEach((map, index) => {
+    const mapButton = document.createElement("button");
+    mapButton.className = "map-veto-map";
+    mapButton.textContent = `${index + 1}. ${map.name}`;
+    mapButton.title = [map.difficulty, map.category].filter(Boolean).join(" - ");
+
+    if (vetoData.selected_map && vetoData.selected_map.id === map.id) {
+      mapButton.classList.add("picked");
+    } else if (!remaining.has(map.id)) {
+      mapButton.classList.add("banned");
+    }
+
+    mapButton.disabled = !myTurn || !remaining.has(map.id);
+    mapButton.addEventListener("click", () => sendMapVeto(vetoData, map.id));
+    mapsElement.appendChild(mapButton);
+  });
+
+  // Number keys ban or pick the map with that number
+  removeMapVetoKeyHandler();
+  if (myTurn) {
+    mapVetoKeyHandler = (event) => {
+      const map = vetoData.maps[parseInt(event.key, 10) - 1];
+      if (map && remaining.has(map.id)) {
+        event.preventDefault();
+        sendMapVeto(vetoData, map.id);
+      }
+    };
+    document.addEventListener("keydown", mapVetoKeyHandler);
+  }
+
+  startMapVetoTimer(vetoData.selected_map ? null : vetoData.turn_ends_at);
+}
+
+// Returns the match modal, opening one for a player who has none, such as a challenger whose
+// friend accepted
+function ensureMatchModal() {
+  const existing = document.querySelector("#matchFoundModal .match-modal");
+  if (existing) {
+    return existing;
+  }
+
+  document.body.insertAdjacentHTML("beforeend", `
+    <div id="matchFoundModal" class="match-modal-overlay">
+      <div class="match-modal">
+        <div class="match-header">
+          <h2>Choose the map</h2>
+        </div>
+        <div class="countdown-section" id="countdownSection" style="display: none;">
+          <div class="countdown-text">Get ready! Game starts in</div>
+          <div class="countdown-number" id="countdownNumber">3</div>
+        </div>
+      </div>
+    </div>
+  `);
+  return document.querySelector("#matchFoundModal .match-modal");
+}
+
+function sendMapVeto(vetoData, mapID) {
+  if (!matchmakingSocket || matchmakingSocket.readyState !== WebSocket.OPEN) {
+    setMapVetoStatus("Not connected to matchmaking");
+    return;
+  }
+
+  removeMapVetoKeyHandler();
+  matchmakingSocket.send(JSON.stringify({
+    type: vetoData.turn_action === "ban" ? "map_ban" : "map_pick",
+    match_id: vetoData.match_id,
+    map_id: mapID,
+  }));
+}
+
+function setMapVetoStatus(message) {
+  const statusElement = document.getElementById("mapVetoStatus");
+  if (statusElement) {
+    statusElement.textContent = message || "";
+  }
+}
+
+function startMapVetoTimer(turnEndsAt) {
+  clearInterval(mapVetoTimer);
+  mapVetoTimer = null;
+
+  const timerElement = document.getElementById("mapVetoTimer");
+  if (!timerElement) return;
+  if (!turnEndsAt) {
+    timerElement.textContent = "";
+    return;
+  }
+
+  const update = () => {
+    const secondsLeft = Math.max(0, Math.ceil((new Date(turnEndsAt) - Date.now()) / 1000));
+    timerElement.textContent = `${secondsLeft}s`;
+  };
+  update();
+  mapVetoTimer = setInterval(update, 1000);
+}
+
+function removeMapVetoKeyHandler() {
+  if (mapVetoKeyHandler) {
+    document.removeEventListener("keydown", mapVetoKeyHandler);
+    mapVetoKeyHandler = null;
+  }
+}
+
+function stopMapVeto() {
+  clearInterval(mapVetoTimer);
+  mapVetoTimer = null;
+  removeMapVetoKeyHandler();
+}
+
 function startMatchCountdown(matchData) {
   const countdownSection = document.getElementById("countdownSection");
   const countdownNumber = document.getElementById("countdownNumber");
@@ -668,6 +832,7 @@ function startMatchCountdown(matchData) {
 function closeMatchFoundModal() {
   // Disable multiplayer vim navigation
   disableMultiplayerVim();
+  stopMapVeto();
   
   const modal = document.getElementById("matchFoundModal");
   if (modal) {
@@ -686,8 +851,104 @@ function closeMatchFoundModal() {
 }
 
 
+// Offers a practice game against bots in the mode the player queued for, when no opponent was found
+async function showPracticeOffer(offer) {
+  let bots = [];
+  try {
+    const response = await fetch("/api/multiplayer/practice/bots");
+    const data = await response.json();
+    if (data.success) {
+      bots = data.bots;
+    }
+  } catch (error) {
+    logger.error("Error loading practice bots:", error);
+  }
+
+  if (bots.length === 0) {
+    showErrorAlert("Matchmaking timeout. No opponent found after 45 seconds.");
+    return;
+  }
+
+  document.body.insertAdjacentHTML("beforeend", `
+    <div id="practiceOfferModal" class="match-modal-overlay">
+      <div class="match-modal">
+        <div class="match-header">
+          <h2>No opponent found</h2>
+        </div>
+        <div class="practice-offer-text">Play an unranked practice game against bots instead?</div>
+        <div class="match-actions" id="practiceOfferActions"></div>
+      </div>
+    </div>
+  `);
+
+  const actions = document.getElementById("practiceOfferActions");
+  bots.forEach((bot) => {
+    const botButton = document.createElement("button");
+    botButton.className = "btn accept-btn";
+    botButton.textContent = bot.name;
+    botButton.title = `${bot.difficulty} bot`;
+    botButton.addEventListener("click", () => startPracticeGame(bot.difficulty, offer));
+    actions.appendChild(botButton);
+  });
+
+  const closeButton = document.createElement("button");
+  closeButton.className = "btn reject-btn";
+  closeButton.textContent = "No thanks";
+  closeButton.addEventListener("click", closePracticeOffer);
+  actions.appendChild(closeButton);
+
+  try {
+    import('./vimNavigation.js').then(module => {
+      module.disableVimNavigation();
+    }).catch(() => {});
+  } catch (e) {}
+}
+
+async function startPracticeGame(difficulty, offer) {
+  try {
+    const response = await fetch("/api/multiplayer/practice", {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify({
+        difficulty: difficulty,
+        selected_character: getSelectedCharacter(),
+        mode: offer.mode,
+        room_size: offer.room_size,
+      }),
+    });
+
+    const data = await response.json();
+
+    if (data.success) {
+      window.location.href = "/play?multiplayer=true&match_id=" + data.match_id;
+    } else {
+      closePracticeOffer();
+      showErrorAlert(data.error || "Failed to start practice game");
+    }
+  } catch (error) {
+    logger.error("Error starting practice game:", error);
+    closePracticeOffer();
+    showErrorAlert("Failed to start practice game");
+  }
+}
+
+function closePracticeOffer() {
+  const modal = document.getElementById("practiceOfferModal");
+  if (modal) {
+    modal.remove();
+    try {
+      import('./vimNavigation.js').then(module => {
+        module.enableVimNavigation();
+      }).catch(() => {});
+    } catch (e) {}
+  }
+}
+
 function closeAllModals() {
   closeMatchFoundModal();
+  closePracticeOffer();
   closeRegistrationModal();
 }
 
@@ -5,6 +5,7 @@ import { DisplayManager } from "./multiplayer_game_modules/displayManager.js";
 import { WebSocketManager } from "./multiplayer_game_modules/websocketManager.js";
 import { GameCompletionHandler } from "./multiplayer_game_modules/gameCompletionHandler.js";
 import { ModuleInitializer } from "./multiplayer_game_modules/moduleInitializer.js";
+import { ChatManager } from "./multiplayer_game_modules/chatManager.js";
 import { initializeGameSoundEffects } from "../game_js_modules/gameSoundEffects.js";
 
 class MultiplayerGame {
@@ -43,6 +44,7 @@ class MultiplayerGame {
     this.websocketManager = new WebSocketManager(this);
     this.gameCompletionHandler = new GameCompletionHandler(this);
     this.moduleInitializer = new ModuleInitializer(this);
+    this.chatManager = new ChatManager(this);
     
     this.moduleInitializer.initializeGameModules();
     this.init();
@@ -55,6 +57,7 @@ class MultiplayerGame {
     
     this.setupEventListeners();
     this.keyboardHandler.setupKeyboardControls();
+    this.chatManager.initialize();
     this.showDebugInfo();
     this.gameStateManager.loadGameState();
   }
@@ -0,0 +1,226 @@
+// Keys picking an emote in the chat picker (quick messages use the digits 1-9)
+const EMOTE_KEYS = "abcdefgh";
+
+// Number of messages kept in the chat log
+const MAX_CHAT_MESSAGES = 20;
+
+export class ChatManager {
+  constructor(game) {
+    this.game = game;
+    this.catalog = { quick_messages: [], emotes: [], max_text_length: 0 };
+    this.pickerOpen = false;
+    this.typing = false;
+
+    this.panel = null;
+    this.log = null;
+    this.picker = null;
+    this.input = null;
+  }
+
+  async initialize() {
+    this.createPanel();
+
+    try {
+      const response = await fetch('/api/multiplayer/chat');
+      const data = await response.json();
+      if (data.success && data.chat) {
+        this.catalog = data.chat;
+        this.renderPicker();
+      }
+    } catch (error) {
+      logger.warn('Could not load chat options:', error);
+    }
+  }
+
+  createPanel() {
+    const container = document.querySelector('.multiplayer-game-board') || document.body;
+
+    this.panel = document.createElement('div');
+    this.panel.className = 'multiplayer-chat';
+
+    this.log = document.createElement('div');
+    this.log.className = 'multiplayer-chat-log';
+
+    this.picker = document.createElement('div');
+    this.picker.className = 'multiplayer-chat-picker';
+    this.picker.style.display = 'none';
+
+    this.input = document.createElement('input');
+    this.input.className = 'multiplayer-chat-input';
+    this.input.type = 'text';
+    this.input.placeholder = 'Message (Enter to send, Esc to cancel)';
+    this.input.style.display = 'none';
+
+    const hint = document.createElement('div');
+    hint.className = 'multiplayer-chat-hint';
+    hint.textContent = 'Space+C chat';
+
+    this.panel.append(this.log, this.picker, this.input, hint);
+    container.appendChild(this.panel);
+  }
+
+  renderPicker() {
+    this.picker.replaceChildren();
+
+    const quickRow = document.createElement('div');
+    quickRow.className = 'multiplayer-chat-row';
+    this.catalog.quick_messages.slice(0, 9).forEach((option, index) => {
+      quickRow.appendChild(this.createOptionButton(`${index + 1}`, option.text, () => this.sendQuick(option.code)));
+    });
+
+    const emoteRow = document.createElement('div');
+    emoteRow.className = 'multiplayer-chat-row';
+    this.catalog.emotes.slice(0, EMOTE_KEYS.length).forEach((option, index) => {
+      emoteRow.appendChild(this.createOptionButton(EMOTE_KEYS[index], option.text, () => this.sendEmote(option.code)));
+    });
+
+    const textHint = document.createElement('div');
+    textHint.className = 'multiplayer-chat-hint';
+    textHint.textContent = 'Enter: type a message (friends only) | Esc: close';
+
+    this.picker.append(quickRow, emoteRow, textHint);
+  }
+
+  createOptionButton(key, text, onSelect) {
+    const button = document.createElement('button');
+    button.className = 'multiplayer-chat-option';
+    button.textContent = `${key} ${text}`;
+    button.addEventListener('click', () => {
+      onSelect();
+      this.closePicker();
+    });
+    return button;
+  }
+
+  // Handles keys while the picker or the text input is open. Returns true when the key was used.
+  handleKeydown(event) {
+    if (this.typing) {
+      if (event.key === 'Enter') {
+        this.sendText(this.input.value);
+        this.stopTyping();
+        event.preventDefault();
+      } else if (event.key === 'Escape') {
+        this.stopTyping();
+        event.preventDefault();
+      }
+      // Leave every other key to the text input
+      return true;
+    }
+
+    if (!this.pickerOpen) {
+      return false;
+    }
+
+    const key = event.key;
+    if (['Shift', 'Control', 'Alt', 'Meta'].includes(key)) {
+      return true;
+    }
+
+    const quickIndex = parseInt(key, 10) - 1;
+    const emoteIndex = EMOTE_KEYS.indexOf(key);
+    if (quickIndex >= 0 && quickIndex < this.catalog.quick_messages.length) {
+      this.sendQuick(this.catalog.quick_messages[quickIndex].code);
+    } else if (key.length === 1 && emoteIndex >= 0 && emoteIndex < this.catalog.emotes.length) {
+      this.sendEmote(this.catalog.emotes[emoteIndex].code);
+    } else if (key === 'Enter' && this.catalog.max_text_length > 0) {
+      this.closePicker();
+      this.startTyping();
+      event.preventDefault();
+      return true;
+    }
+
+    this.closePicker();
+    event.preventDefault();
+    return true;
+  }
+
+  togglePicker() {
+    if (this.pickerOpen) {
+      this.closePicker();
+    } else {
+      this.pickerOpen = true;
+      this.picker.style.display = 'block';
+    }
+  }
+
+  closePicker() {
+    this.pickerOpen = false;
+    this.picker.style.display = 'none';
+  }
+
+  startTyping() {
+    this.typing = true;
+    this.input.maxLength = this.catalog.max_text_length;
+    this.input.value = '';
+    this.input.style.display = 'block';
+    this.input.focus();
+  }
+
+  stopTyping() {
+    this.typing = false;
+    this.input.blur();
+    this.input.style.display = 'none';
+  }
+
+  sendQuick(code) {
+    this.send({ type: 'chat', quick: code });
+  }
+
+  sendEmote(code) {
+    this.send({ type: 'chat', emote: code });
+  }
+
+  sendText(text) {
+    const trimmed = text.trim();
+    if (trimmed) {
+      this.send({ type: 'chat', text: trimmed });
+    }
+  }
+
+  send(message) {
+    const websocket = this.game.websocket;
+    if (!websocket || websocket.readyState !== WebSocket.OPEN) {
+      this.addNotice('Chat unavailable: not connected');
+      return;
+    }
+    websocket.send(JSON.stringify(message));
+  }
+
+  // Renders a chat_message sent by any player of the game, including this one
+  addMessage(data) {
+    const entry = document.createElement('div');
+    entry.className = 'multiplayer-chat-message';
+    if (data.player_id === this.game.playerId) {
+      entry.classList.add('own');
+    }
+
+    const name = document.createElement('span');
+    name.className = 'multiplayer-chat-name';
+    name.textContent = `${data.username}: `;
+
+    const text = document.createElement('span');
+    text.className = data.kind === 'emote' ? 'multiplayer-chat-emote' : 'multiplayer-chat-text';
+    text.textContent = data.text;
+
+    entry.append(name, text);
+    this.appendEntry(entry);
+  }
+
+  // Renders a notice from the server or the client, such as a rejected message
+  addNotice(message) {
+    const entry = document.createElement('div');
+    entry.className = 'multiplayer-chat-message notice';
+    entry.textContent = message;
+    this.appendEntry(entry);
+  }
+
+  appendEntry(entry) {
+    if (!this.log) return;
+
+    this.log.appendChild(entry);
+    while (this.log.children.length > MAX_CHAT_MESSAGES) {
+      this.log.firstChild.remove();
+    }
+    this.log.scrollTop = this.log.scrollHeight;
+  }
+}
@@ -25,6 +25,11 @@ export class KeyboardHandler {
     const key = event.key;
     logger.debug('🎹 MULTIPLAYER KEYDOWN:', key, 'isConnected:', this.game.isConnected, 'hasClientGameState:', !!this.game.clientGameState);
 
+    // The open chat picker or text input takes every key
+    if (this.game.chatManager.handleKeydown(event)) {
+      return;
+    }
+
     // Handle number input accumulation
     if (this.numberPrefixHandler.shouldHandleNumberInput(key)) {
       if (this.numberPrefixHandler.handleNumberInput(key)) {
@@ -47,11 +52,23 @@ export class KeyboardHandler {
       }
     }
 
+    // Handle bracket-command completion
+    if (this.specialCommandHandler.waitingForBracketCommand) {
+      if (this.specialCommandHandler.handleBracketCommandCompletion(key, event)) {
+        return;
+      }
+    }
+
     // Handle G-command initiation (MUST be before general movement check)
     if (this.specialCommandHandler.handleGCommandInitiation(key, event)) {
       return;
     }
 
+    // Handle bracket-command initiation (MUST be before general movement check)
+    if (this.specialCommandHandler.handleBracketCommandInitiation(key, event)) {
+      return;
+    }
+
     // Handle character search initiation
     if (this.specialCommandHandler.handleCharSearchInitiation(key, event)) {
       return;
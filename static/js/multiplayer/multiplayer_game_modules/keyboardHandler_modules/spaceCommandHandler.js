@@ -58,6 +58,12 @@ export class SpaceCommandHandler {
           event.preventDefault();
           return true;
           
+        case 'c':
+          // Space + C: Open the quick chat and emote picker
+          this.game.chatManager.togglePicker();
+          event.preventDefault();
+          return true;
+          
         default:
           // Invalid space command, do nothing
           event.preventDefault();
@@ -1,3 +1,5 @@
+import { BRACKET_COMMANDS, G_COMMANDS } from "../../../game_js_modules/constants_js_modules/movement.js";
+
 export class SpecialCommandHandler {
   constructor(keyboardHandler) {
     this.keyboardHandler = keyboardHandler;
@@ -5,10 +7,11 @@ export class SpecialCommandHandler {
     this.waitingForChar = false;
     this.charSearchMotion = null;
     this.waitingForGCommand = false;
+    this.waitingForBracketCommand = null;
   }
 
   isWaitingForInput() {
-    return this.waitingForChar || this.waitingForGCommand;
+    return this.waitingForChar || this.waitingForGCommand || this.waitingForBracketCommand !== null;
   }
 
   handleCharSearchCompletion(key, event) {
@@ -61,6 +64,12 @@ export class SpecialCommandHandler {
       this.keyboardHandler.processMovement('g_', count, hasExplicitCount);
       event.preventDefault();
       return true;
+    } else if (G_COMMANDS.includes(`g${key}`)) {
+      logger.debug(`🎯 G-COMMAND COMPLETE: g${key}`, { count, hasExplicitCount });
+      this.waitingForGCommand = false;
+      this.keyboardHandler.processMovement(`g${key}`, count, hasExplicitCount);
+      event.preventDefault();
+      return true;
     } else {
       logger.debug('🎯 G-COMMAND INVALID:', key);
       this.waitingForGCommand = false;
@@ -80,6 +89,36 @@ export class SpecialCommandHandler {
     return false;
   }
 
+  handleBracketCommandCompletion(key, event) {
+    if (['Shift', 'Control', 'Alt', 'Meta'].includes(key)) {
+      return false;
+    }
+    
+    const command = `${this.waitingForBracketCommand}${key}`;
+    this.waitingForBracketCommand = null;
+    
+    if (BRACKET_COMMANDS.includes(command)) {
+      const { count, hasExplicitCount } = this.keyboardHandler.numberPrefixHandler.getCountAndReset();
+      logger.debug(`🎯 BRACKET-COMMAND COMPLETE: ${command}`, { count, hasExplicitCount });
+      this.keyboardHandler.processMovement(command, count, hasExplicitCount);
+    } else {
+      logger.debug('🎯 BRACKET-COMMAND INVALID:', command);
+      this.keyboardHandler.numberPrefixHandler.clearPrefix();
+    }
+    event.preventDefault();
+    return true;
+  }
+
+  handleBracketCommandInitiation(key, event) {
+    if (key === '[' || key === ']') {
+      this.waitingForBracketCommand = key;
+      logger.debug('🎯 BRACKET-COMMAND: Waiting for bracket command', key);
+      event.preventDefault();
+      return true;
+    }
+    return false;
+  }
+
   handleCharSearchInitiation(key, event) {
     if (['f', 'F', 't', 'T'].includes(key)) {
       this.waitingForChar = true;
@@ -96,6 +135,7 @@ export class SpecialCommandHandler {
       logger.debug('🔢 ESCAPE: Clearing all states', {
         waitingForChar: this.waitingForChar,
         waitingForGCommand: this.waitingForGCommand,
+        waitingForBracketCommand: this.waitingForBracketCommand,
         numberPrefix: this.keyboardHandler.numberPrefixHandler.numberPrefix,
         accumulatingNumber: this.keyboardHandler.numberPrefixHandler.accumulatingNumber
       });
@@ -113,6 +153,7 @@ export class SpecialCommandHandler {
     this.waitingForChar = false;
     this.charSearchMotion = null;
     this.waitingForGCommand = false;
+    this.waitingForBracketCommand = null;
   }
 
   getCharSearchDirection(motion, char) {
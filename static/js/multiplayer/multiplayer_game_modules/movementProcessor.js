@@ -19,6 +19,10 @@ export class MovementProcessor {
     this.moveQueue.queueMove(direction, count, hasExplicitCount);
   }
 
+  handleMoveAck(ack) {
+    this.serverCommunicator.handleMoveAck(ack);
+  }
+
   // Expose the pendingMoves for external access (used by websocket manager)
   get pendingMoves() {
     return this.stateReconciler.pendingMoves;
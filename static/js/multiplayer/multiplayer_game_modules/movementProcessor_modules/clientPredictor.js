@@ -96,7 +96,10 @@ export class ClientPredictor {
     });
     
     // Send to server with optimized timeout
-    this.movementProcessor.serverCommunicator.sendMoveToServer(direction, count, hasExplicitCount, moveId);
+    this.movementProcessor.serverCommunicator.sendMoveToServer(direction, count, hasExplicitCount, moveId, {
+      row: newPos.newRow,
+      col: newPos.newCol
+    });
   }
 
   applyMoveToClientState(direction, count, hasExplicitCount) {
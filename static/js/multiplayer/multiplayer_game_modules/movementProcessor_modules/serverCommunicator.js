@@ -1,71 +1,76 @@
 import { networkAdapter } from '../../../shared/networkAdapter.js';
 
+// Moves not acknowledged within this time are rolled back
+const MOVE_ACK_TIMEOUT = 2000;
+
 export class ServerCommunicator {
   constructor(movementProcessor) {
     this.movementProcessor = movementProcessor;
     this.game = movementProcessor.game;
+    this.moveSeq = 0;
+    this.pendingAcks = new Map(); // seq -> { moveId, startTime, timeoutId }
   }
 
-  async sendMoveToServer(direction, count, hasExplicitCount, moveId) {
-    const startTime = performance.now();
-    
-    try {
-      logger.debug('📡 SENDING MOVE TO SERVER:', {
-        gameId: this.game.gameId,
-        direction,
-        count,
-        hasExplicitCount,
-        moveId
-      });
-      
-      const controller = new AbortController();
-      const timeoutId = setTimeout(() => controller.abort(), 2000);
-      
-      const response = await fetch(`/api/multiplayer/game/${this.game.gameId}/move`, {
-        method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
-        body: JSON.stringify({
-          direction: direction,
-          count: count,
-          has_explicit_count: hasExplicitCount,
-          move_id: moveId
-        }),
-        signal: controller.signal
-      });
+  sendMoveToServer(direction, count, hasExplicitCount, moveId, predicted) {
+    const websocket = this.game.websocket;
+    if (!websocket || websocket.readyState !== WebSocket.OPEN) {
+      logger.warn('Cannot send move, WebSocket not connected:', moveId);
+      this.movementProcessor.stateReconciler.rollbackMove(moveId);
+      return;
+    }
 
-      clearTimeout(timeoutId);
-      
-      // Track network latency
-      networkAdapter.measureLatency(startTime);
-      
-      logger.debug('Server response status:', response.status);
-      
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`);
-      }
+    // Sequence numbers continue from the last move the server saw (e.g. before a page reload)
+    this.moveSeq = Math.max(this.moveSeq, this.game.gameState?.move_seq || 0) + 1;
+    const seq = this.moveSeq;
+
+    logger.debug('📡 SENDING MOVE TO SERVER:', {
+      gameId: this.game.gameId,
+      seq,
+      direction,
+      count,
+      hasExplicitCount,
+      moveId
+    });
+
+    const timeoutId = setTimeout(() => {
+      logger.warn('Move ack timed out:', seq, moveId);
+      this.pendingAcks.delete(seq);
+      this.movementProcessor.stateReconciler.rollbackMove(moveId);
+    }, MOVE_ACK_TIMEOUT);
+    this.pendingAcks.set(seq, { moveId, startTime: performance.now(), timeoutId });
 
-      const data = await response.json();
-      logger.debug('Server response data:', data);
-      
-      if (data.success) {
-        this.movementProcessor.stateReconciler.reconcileServerStateOptimized(data, moveId);
-        
-        if (data.completed) {
-          this.game.gameCompletionHandler.handleGameCompletion(data);
-        }
-      } else {
-        logger.warn('Server rejected move:', data.error);
-        this.movementProcessor.stateReconciler.rollbackMove(moveId);
-      }
+    try {
+      websocket.send(JSON.stringify({
+        type: 'move',
+        seq: seq,
+        direction: direction,
+        count: count,
+        has_explicit_count: hasExplicitCount,
+        predicted: predicted
+      }));
     } catch (error) {
-      if (error.name === 'AbortError') {
-        logger.warn('Move request timed out:', moveId);
-      } else {
-        logger.error('Error sending move to server:', error);
-      }
+      logger.error('Error sending move to server:', error);
+      clearTimeout(timeoutId);
+      this.pendingAcks.delete(seq);
       this.movementProcessor.stateReconciler.rollbackMove(moveId);
     }
   }
-}
\ No newline at end of file
+
+  handleMoveAck(ack) {
+    const pending = this.pendingAcks.get(ack.seq);
+    if (!pending) {
+      // Already rolled back after a timeout; the next game update brings the state back in line
+      logger.debug('Ignoring ack for unknown move seq:', ack.seq);
+      return;
+    }
+
+    clearTimeout(pending.timeoutId);
+    this.pendingAcks.delete(ack.seq);
+
+    // Track network latency
+    networkAdapter.measureLatency(pending.startTime);
+
+    logger.debug('Server move ack:', ack);
+    this.movementProcessor.stateReconciler.reconcileMoveAck(ack, pending.moveId);
+  }
+}
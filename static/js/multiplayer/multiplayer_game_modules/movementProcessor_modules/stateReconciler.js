@@ -11,33 +11,36 @@ export class StateReconciler {
     this.pendingMoves.push(move);
   }
 
-  reconcileServerStateOptimized(serverState, moveId) {
+  reconcileMoveAck(ack, moveId) {
     this.pendingMoves = this.pendingMoves.filter(move => move.id !== moveId);
-    
-    // Store old scores for sound effects
-    const oldP1Score = this.game.gameState?.player1?.score || 0;
-    const oldP2Score = this.game.gameState?.player2?.score || 0;
-    
-    this.game.gameState = serverState;
-    
-    // Play sound effects for score changes
-    const isPlayer1 = this.game.playerId === serverState.player1.id;
-    if (isPlayer1 && serverState.player1.score > oldP1Score) {
-      gameSoundEffectsManager.playPearlCollectedSound();
-    } else if (!isPlayer1 && serverState.player2.score > oldP2Score) {
+
+    if (!ack.accepted && !ack.duplicate) {
+      logger.warn('Server rejected move:', ack.error);
+    }
+
+    // The ack carries the authoritative state of this player and the pearl
+    const isPlayer1 = this.game.playerId === this.game.gameState.player1.id;
+    const serverPlayer = isPlayer1 ? this.game.gameState.player1 : this.game.gameState.player2;
+    const oldScore = serverPlayer.score || 0;
+
+    serverPlayer.position = ack.position;
+    serverPlayer.score = ack.score;
+    this.game.gameState.pearl_position = ack.pearl_position;
+    this.game.gameState.move_seq = Math.max(this.game.gameState.move_seq || 0, ack.seq);
+
+    if (ack.score > oldScore) {
       gameSoundEffectsManager.playPearlCollectedSound();
     }
-    
-    // Always sync client state to server state to prevent drift
-    this.game.clientGameState = this.game.gameStateManager.cloneGameState(serverState);
-    
-    // Only update display if no pending moves to avoid conflicts
-    if (this.pendingMoves.length === 0) {
+
+    if (this.pendingMoves.length === 0 || ack.conflict || !ack.accepted) {
+      // Start again from the server's position and replay the moves still in flight
+      this.game.clientGameState = this.game.gameStateManager.cloneGameState(this.game.gameState);
+      this.game.preferredColumn = ack.preferred_column;
+      this.reapplyPendingMoves();
       this.game.displayManager.updateGameDisplayOptimized();
     }
   }
 
-
   rollbackMove(moveId) {
     this.pendingMoves = this.pendingMoves.filter(move => move.id !== moveId);
     this.game.clientGameState = this.game.gameStateManager.cloneGameState(this.game.gameState);
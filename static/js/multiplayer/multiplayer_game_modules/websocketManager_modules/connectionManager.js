@@ -16,7 +16,8 @@ export class ConnectionManager {
     
     logger.debug('Connecting to WebSocket:', wsUrl);
     
-    this.websocket = new WebSocket(wsUrl);
+    // Ask for the versioned JSON schema so later message formats don't break this tab
+    this.websocket = new WebSocket(wsUrl, ['bobavim.v1.json']);
     this.game.websocket = this.websocket;
     this.websocketManager.websocket = this.websocket;
     
@@ -14,6 +14,18 @@ export class MessageHandler {
         logger.debug('🎮 Processing game update...');
         this.handleGameUpdate(message.data);
         break;
+      case 'move_ack':
+        logger.debug('📬 Move acknowledged');
+        this.game.movementProcessor.handleMoveAck(message.data);
+        break;
+      case 'chat_message':
+        logger.debug('💬 Chat message received');
+        this.game.chatManager.addMessage(message.data);
+        break;
+      case 'chat_error':
+        logger.debug('💬 Chat message rejected:', message.data);
+        this.game.chatManager.addNotice(message.data.error);
+        break;
       case 'game_complete':
         logger.debug('🏆 Game completed');
         this.game.gameCompletionHandler.handleGameCompletion(message.data);